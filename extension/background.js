@@ -1,10 +1,13 @@
-const PORT = 19191;
+const DEFAULT_ADDRESS = "127.0.0.1:19191";
 const RECONNECT_BASE_MS = 1000;
 const ALARM_NAME = "keepalive";
 const RECONNECT_MAX_MS = 30000;
 const DWELL_THRESHOLD_MS = 10000;
 const VISIT_MIN_MS = 5000;
 const IDLE_THRESHOLD_SECONDS = 60;
+// WebSocket protocol version, "MAJOR.MINOR". Must match the major version of
+// server.ProtocolVersion in the CLI.
+const PROTOCOL_VERSION = "1.0";
 const SKIP_PROTOCOLS = ["about:", "moz-extension:", "chrome:", "file:", "data:", "resource:"];
 
 let ws = null;
@@ -22,6 +25,11 @@ let pendingVisitBatch = null; // { id, count }
 let idleState = "active";
 // Per-tab summarize state: "idle" | "pending" | "ready"
 let tabSummarizeState = new Map();
+// TUI WebSocket server address and shared token, set in the options page.
+let wsAddress = DEFAULT_ADDRESS;
+let wsToken = "";
+// Protocol version the TUI answered the handshake with.
+let serverProtocol = null;
 
 function connect() {
   if (reconnectTimer) {
@@ -29,12 +37,14 @@ function connect() {
     reconnectTimer = null;
   }
 
-  const socket = new WebSocket(`ws://127.0.0.1:${PORT}`);
+  const socket = new WebSocket(`ws://${wsAddress}`);
   ws = socket;
 
   socket.addEventListener("open", async () => {
     console.log("Tabsordnung: connected");
     reconnectDelay = RECONNECT_BASE_MS;
+    // The handshake must be the first message when the TUI requires a token.
+    send({ type: "hello", token: wsToken, protocol: PROTOCOL_VERSION });
     browser.action.setIcon({ path: { "32": "icons/icon-32.svg" } });
     await sendSnapshot();
     await flushVisits();
@@ -42,6 +52,13 @@ function connect() {
 
   socket.addEventListener("message", async (event) => {
     const msg = JSON.parse(event.data);
+    if (msg.action === "hello") {
+      serverProtocol = msg.protocol || null;
+      if (serverProtocol !== PROTOCOL_VERSION) {
+        console.warn(`Tabsordnung: protocol ${PROTOCOL_VERSION}, tabsordnung speaks ${serverProtocol}`);
+      }
+      return;
+    }
     if (msg.action === "tab.visits_batch.ack") {
       await handleVisitsAck(msg);
       return;
@@ -56,8 +73,14 @@ function connect() {
     handleCommand(msg);
   });
 
-  socket.addEventListener("close", () => {
+  socket.addEventListener("close", (event) => {
     if (ws !== socket) return; // stale close — ignore
+    if (event.code === 1008) {
+      console.warn("Tabsordnung: connection rejected, check the token in the extension options");
+    } else if (event.code === 1002) {
+      console.warn(`Tabsordnung: protocol ${PROTOCOL_VERSION} not supported by tabsordnung, update the CLI or the extension`);
+    }
+    serverProtocol = null;
     console.log("Tabsordnung: disconnected, reconnecting...");
     ws = null;
     browser.action.setIcon({ path: { "32": "icons/icon-grey-32.svg" } });
@@ -76,6 +99,20 @@ function connect() {
   });
 }
 
+// Reconnect when the address or token is changed in the options page.
+browser.storage.onChanged.addListener((changes, area) => {
+  if (area === "local" && (changes.wsToken || changes.wsAddress)) {
+    if (changes.wsToken) wsToken = changes.wsToken.newValue || "";
+    if (changes.wsAddress) wsAddress = changes.wsAddress.newValue || DEFAULT_ADDRESS;
+    if (ws) {
+      ws.close();
+    } else {
+      reconnectDelay = RECONNECT_BASE_MS;
+      connect();
+    }
+  }
+});
+
 function scheduleReconnect() {
   if (reconnectTimer) return; // already scheduled
   reconnectTimer = setTimeout(() => {
@@ -104,7 +141,24 @@ function send(obj) {
 
 // --- Snapshot ---
 
+// Container names by cookieStoreId, so serializeTab can stay synchronous.
+const containerNames = new Map();
+
+async function loadContainerNames() {
+  if (!browser.contextualIdentities?.query) return;
+  try {
+    const identities = await browser.contextualIdentities.query({});
+    containerNames.clear();
+    for (const ci of identities) {
+      containerNames.set(ci.cookieStoreId, ci.name);
+    }
+  } catch (e) {
+    // Containers disabled (privacy.userContext.enabled = false).
+  }
+}
+
 async function sendSnapshot() {
+  await loadContainerNames();
   const tabs = await browser.tabs.query({});
   let groups = [];
   if (browser.tabGroups?.query) {
@@ -115,9 +169,25 @@ async function sendSnapshot() {
     type: "snapshot",
     tabs: tabs.map(serializeTab),
     groups: groups.map(serializeGroup),
+    closedTabs: await recentlyClosedTabs(),
   });
 }
 
+async function recentlyClosedTabs() {
+  if (!browser.sessions?.getRecentlyClosed) return [];
+  const sessions = await browser.sessions.getRecentlyClosed({ maxResults: 25 });
+  return sessions
+    .filter((s) => s.tab)
+    .map((s) => ({
+      sessionId: s.tab.sessionId,
+      url: s.tab.url || "",
+      title: s.tab.title || "",
+      closedAt: s.lastModified || 0,
+      windowId: s.tab.windowId,
+      favIconUrl: s.tab.favIconUrl || "",
+    }));
+}
+
 function serializeTab(tab) {
   return {
     id: tab.id,
@@ -128,6 +198,8 @@ function serializeTab(tab) {
     windowId: tab.windowId,
     index: tab.index,
     favIconUrl: tab.favIconUrl || "",
+    container: containerNames.get(tab.cookieStoreId) || "",
+    pinned: tab.pinned || false,
   };
 }
 
@@ -142,6 +214,12 @@ function serializeGroup(group) {
 
 // --- Events ---
 
+if (browser.contextualIdentities?.onCreated) {
+  browser.contextualIdentities.onCreated.addListener(loadContainerNames);
+  browser.contextualIdentities.onUpdated.addListener(loadContainerNames);
+  browser.contextualIdentities.onRemoved.addListener(loadContainerNames);
+}
+
 browser.tabs.onCreated.addListener((tab) => {
   ensureConnected();
   send({ type: "tab.created", tab: serializeTab(tab) });
@@ -238,14 +316,23 @@ async function handleCommand(msg) {
           await browser.tabs.group({ tabIds: msg.tabIds, groupId: msg.groupId });
         }
         break;
-      case "open":
+      case "open": {
+        const tabIds = [];
         for (const tab of (msg.tabs || [])) {
-          await browser.tabs.create({
+          const created = await browser.tabs.create({
             url: tab.url,
             pinned: tab.pinned || false,
           });
+          tabIds.push(created.id);
         }
-        break;
+        send({ id: msg.id, ok: true, tabIds });
+        return;
+      }
+      case "restore-tab": {
+        const restored = await browser.sessions.restore(msg.sessionId);
+        send({ id: msg.id, ok: true, tabIds: restored?.tab ? [restored.tab.id] : [] });
+        return;
+      }
       case "create-group":
         if (browser.tabs.group) {
           const tabIds = msg.tabIds || [];
@@ -362,6 +449,10 @@ async function handleCommand(msg) {
         return;
       }
       case "scrape-activity": {
+        // Each scraper returns an array of signal items:
+        //   { title, preview, snippet?, timestamp, kind? }
+        // kind is "dm", "mention" or "channel"; the TUI maps it to
+        // urgent/review/fyi. Leave it empty when the source can't tell.
         const scrapers = {
           gmail: () => {
             const rows = document.querySelectorAll("tr.zE");
@@ -392,6 +483,12 @@ async function handleCommand(msg) {
               return { title: name, preview: parts.join(" · "), timestamp: "", kind };
             });
           },
+          // Element web room list:
+          //   .mx_RoomTile                           room row; aria-label ends with "Unread messages."
+          //   .mx_NotificationBadge_count            unread count
+          //   .mx_RoomTile_dm / _icon_dm avatar      direct message -> kind "dm"
+          //   .mx_NotificationBadge_highlighted      mention/keyword -> kind "mention"
+          //   .mx_RoomTile_subtitle                  last message preview -> snippet
           matrix: () => {
             const rooms = document.querySelectorAll(".mx_RoomTile");
             const items = [];
@@ -412,13 +509,34 @@ async function handleCommand(msg) {
               } else if (hasHighlight) {
                 kind = "mention";
               }
-              items.push({ title: name, preview, timestamp: "", kind });
+              const snippet = room.querySelector(".mx_RoomTile_subtitle")?.textContent?.trim() || "";
+              items.push({ title: name, preview, snippet, timestamp: "", kind });
             });
             return items;
           },
         };
 
-        const scraper = scrapers[msg.source];
+        // Custom sources (sources.json) ship their own selectors.
+        const generic = (sel) => {
+          const text = (root, q) => (q && root.querySelector(q)?.textContent?.trim()) || "";
+          return Array.from(document.querySelectorAll(sel.item)).map(el => {
+            let kind = "channel";
+            if (sel.dm && el.matches(`${sel.dm}, :has(${sel.dm})`)) {
+              kind = "dm";
+            } else if (sel.mention && el.matches(`${sel.mention}, :has(${sel.mention})`)) {
+              kind = "mention";
+            }
+            return {
+              title: text(el, sel.title) || el.textContent.trim(),
+              preview: text(el, sel.preview) || "unread",
+              snippet: text(el, sel.snippet),
+              timestamp: "",
+              kind,
+            };
+          }).filter(item => item.title);
+        };
+
+        const scraper = msg.selectors ? generic : scrapers[msg.source];
         if (!scraper) {
           send({ id: msg.id, ok: false, error: `unknown source: ${msg.source}` });
           return;
@@ -427,6 +545,7 @@ async function handleCommand(msg) {
         const results = await browser.scripting.executeScript({
           target: { tabId: msg.tabId },
           func: scraper,
+          args: msg.selectors ? [msg.selectors] : [],
         });
 
         const items = results?.[0]?.result || [];
@@ -921,4 +1040,9 @@ loadPersistedVisits()
     }
   })
   .then(() => initializeActiveVisit())
+  .then(async () => {
+    const stored = await browser.storage.local.get(["wsAddress", "wsToken"]);
+    wsAddress = stored.wsAddress || DEFAULT_ADDRESS;
+    wsToken = stored.wsToken || "";
+  })
   .then(() => connect());
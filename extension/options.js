@@ -0,0 +1,16 @@
+const addressInput = document.getElementById("address");
+const tokenInput = document.getElementById("token");
+const status = document.getElementById("status");
+
+browser.storage.local.get(["wsAddress", "wsToken"]).then(({ wsAddress, wsToken }) => {
+  addressInput.value = wsAddress || "";
+  tokenInput.value = wsToken || "";
+});
+
+document.getElementById("save").addEventListener("click", async () => {
+  await browser.storage.local.set({
+    wsAddress: addressInput.value.trim(),
+    wsToken: tokenInput.value.trim(),
+  });
+  status.textContent = "Saved. Reconnecting...";
+});
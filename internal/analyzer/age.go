@@ -0,0 +1,40 @@
+package analyzer
+
+import (
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// AgeBuckets are the AgeHistogram keys, youngest first.
+var AgeBuckets = []string{"0-1d", "1-7d", "7-30d", "30-90d", "90d+"}
+
+// AgeHistogram counts tabs by time since they were last accessed. Every
+// bucket in AgeBuckets is present in the result, even when empty.
+func AgeHistogram(tabs []*types.Tab) map[string]int {
+	return ageHistogram(tabs, time.Now())
+}
+
+func ageHistogram(tabs []*types.Tab, now time.Time) map[string]int {
+	h := make(map[string]int, len(AgeBuckets))
+	for _, b := range AgeBuckets {
+		h[b] = 0
+	}
+	const day = 24 * time.Hour
+	for _, tab := range tabs {
+		age := now.Sub(tab.LastAccessed)
+		switch {
+		case age < day:
+			h["0-1d"]++
+		case age < 7*day:
+			h["1-7d"]++
+		case age < 30*day:
+			h["7-30d"]++
+		case age < 90*day:
+			h["30-90d"]++
+		default:
+			h["90d+"]++
+		}
+	}
+	return h
+}
@@ -0,0 +1,33 @@
+package analyzer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestAgeHistogram(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	tabs := []*types.Tab{
+		{LastAccessed: now.Add(-time.Hour)},
+		{LastAccessed: now.Add(-2 * day)},
+		{LastAccessed: now.Add(-6 * day)},
+		{LastAccessed: now.Add(-7 * day)},
+		{LastAccessed: now.Add(-45 * day)},
+		{LastAccessed: now.Add(-400 * day)},
+		{}, // never accessed
+	}
+
+	h := ageHistogram(tabs, now)
+	want := map[string]int{"0-1d": 1, "1-7d": 2, "7-30d": 1, "30-90d": 1, "90d+": 2}
+	for _, b := range AgeBuckets {
+		if h[b] != want[b] {
+			t.Errorf("bucket %s: got %d, want %d", b, h[b], want[b])
+		}
+	}
+	if len(h) != len(AgeBuckets) {
+		t.Errorf("expected %d buckets, got %d", len(AgeBuckets), len(h))
+	}
+}
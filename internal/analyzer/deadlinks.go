@@ -1,15 +1,20 @@
 package analyzer
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/lotas/tabsordnung/internal/github"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
+// deadLinkTimeout bounds each dead-link request.
+const deadLinkTimeout = 5 * time.Second
+
 type DeadLinkResult struct {
 	TabIndex int
 	IsDead   bool
@@ -27,34 +32,34 @@ func shouldSkip(url string) bool {
 	return false
 }
 
-func AnalyzeDeadLinks(tabs []*types.Tab, results chan<- DeadLinkResult) {
-	client := &http.Client{
-		Timeout: 5 * time.Second,
-		CheckRedirect: func(req *http.Request, via []*http.Request) error {
-			if len(via) >= 10 {
-				return fmt.Errorf("too many redirects")
-			}
-			return nil
-		},
-	}
-
+// AnalyzeDeadLinks sends a HEAD request for each tab, at most 10 at a time,
+// and marks tabs answering 404 or 410 or not answering at all as dead. Once
+// ctx is cancelled, pending tabs are skipped and interrupted requests don't
+// count as dead.
+func AnalyzeDeadLinks(ctx context.Context, tabs []*types.Tab, results chan<- DeadLinkResult) {
 	sem := make(chan struct{}, 10)
 	var wg sync.WaitGroup
 
 	for i, tab := range tabs {
-		if shouldSkip(tab.URL) {
+		if shouldSkip(UnwrapReaderURL(tab.URL)) {
 			continue
 		}
 
 		wg.Add(1)
 		go func(idx int, t *types.Tab) {
 			defer wg.Done()
-			sem <- struct{}{}
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
 			defer func() { <-sem }()
 
 			result := DeadLinkResult{TabIndex: idx}
 
-			req, err := http.NewRequest(http.MethodHead, t.URL, nil)
+			reqCtx, cancel := context.WithTimeout(ctx, deadLinkTimeout)
+			defer cancel()
+			req, err := http.NewRequestWithContext(reqCtx, http.MethodHead, UnwrapReaderURL(t.URL), nil)
 			if err != nil {
 				result.IsDead = true
 				result.Reason = "invalid URL"
@@ -64,8 +69,11 @@ func AnalyzeDeadLinks(tabs []*types.Tab, results chan<- DeadLinkResult) {
 				return
 			}
 
-			resp, err := client.Do(req)
+			resp, err := github.HTTPClient.Do(req)
 			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
 				result.IsDead = true
 				result.Reason = "unreachable"
 				t.IsDead = true
@@ -1,6 +1,7 @@
 package analyzer
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -33,7 +34,7 @@ func TestAnalyzeDeadLinks(t *testing.T) {
 	}
 
 	results := make(chan DeadLinkResult, len(tabs))
-	AnalyzeDeadLinks(tabs, results)
+	AnalyzeDeadLinks(context.Background(), tabs, results)
 	close(results)
 
 	for r := range results {
@@ -59,3 +60,20 @@ func TestAnalyzeDeadLinks(t *testing.T) {
 		t.Error("moz-extension: tab should not be checked")
 	}
 }
+
+func TestAnalyzeDeadLinksCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tabs := []*types.Tab{{URL: "http://127.0.0.1:1/unreachable"}}
+	results := make(chan DeadLinkResult, len(tabs))
+	AnalyzeDeadLinks(ctx, tabs, results)
+	close(results)
+
+	if len(results) != 0 {
+		t.Errorf("expected no results after cancellation, got %d", len(results))
+	}
+	if tabs[0].IsDead {
+		t.Error("tab should not be marked dead when the check was cancelled")
+	}
+}
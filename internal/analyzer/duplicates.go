@@ -8,14 +8,36 @@ import (
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
+// trackingParams are query parameters that only carry campaign or click
+// tracking data and never change the page content.
+var trackingParams = map[string]bool{
+	"fbclid": true, "gclid": true, "dclid": true, "msclkid": true, "yclid": true,
+	"mc_cid": true, "mc_eid": true, "igshid": true, "_hsenc": true, "_hsmi": true,
+	"mkt_tok": true, "ref_src": true,
+}
+
+func isTrackingParam(key string) bool {
+	k := strings.ToLower(key)
+	return strings.HasPrefix(k, "utm_") || trackingParams[k]
+}
+
+// NormalizeURL returns a canonical form of rawURL for duplicate detection:
+// the host is lowercased, the fragment and tracking parameters (utm_*,
+// fbclid, ...) are dropped, the remaining parameters are sorted, and a
+// trailing slash is removed.
 func NormalizeURL(rawURL string) string {
 	u, err := url.Parse(rawURL)
 	if err != nil {
 		return rawURL
 	}
+	u.Host = strings.ToLower(u.Host)
 	u.Fragment = ""
 	params := u.Query()
 	for k := range params {
+		if isTrackingParam(k) {
+			delete(params, k)
+			continue
+		}
 		sort.Strings(params[k])
 	}
 	u.RawQuery = params.Encode()
@@ -26,13 +48,38 @@ func NormalizeURL(rawURL string) string {
 	return result
 }
 
-func AnalyzeDuplicates(tabs []*types.Tab) {
-	groups := make(map[string][]int)
+// duplicateSets groups the indices of tabs by URL, compared after
+// NormalizeURL unless exact is set.
+func duplicateSets(tabs []*types.Tab, exact bool) map[string][]int {
+	sets := make(map[string][]int)
 	for i, tab := range tabs {
-		normalized := NormalizeURL(tab.URL)
-		groups[normalized] = append(groups[normalized], i)
+		key := tab.URL
+		if !exact {
+			key = NormalizeURL(tab.URL)
+		}
+		sets[key] = append(sets[key], i)
 	}
-	for _, indices := range groups {
+	return sets
+}
+
+// AnalyzeDuplicates marks tabs sharing a URL as duplicates. URLs are compared
+// after NormalizeURL unless exact is set, in which case only identical URLs
+// match. Each duplicate also records whether its copies share its group,
+// sit in other groups, or are open in another profile of a merged session.
+// Flags from an earlier pass are cleared first, since closing a copy can
+// leave its twin alone. It returns the tabs that became, or stopped being,
+// duplicates in this pass.
+func AnalyzeDuplicates(tabs []*types.Tab, exact bool) []*types.Tab {
+	was := make([]bool, len(tabs))
+	for i, tab := range tabs {
+		was[i] = tab.IsDuplicate
+		tab.IsDuplicate = false
+		tab.DuplicateOf = nil
+		tab.DuplicateInGroup = false
+		tab.DuplicateAcrossGroups = false
+		tab.DuplicateAcrossProfiles = false
+	}
+	for _, indices := range duplicateSets(tabs, exact) {
 		if len(indices) < 2 {
 			continue
 		}
@@ -42,9 +89,55 @@ func AnalyzeDuplicates(tabs []*types.Tab) {
 			for _, j := range indices {
 				if j != i {
 					others = append(others, j)
+					if tabs[j].Profile != tabs[i].Profile {
+						tabs[i].DuplicateAcrossProfiles = true
+					} else if tabs[j].GroupID == tabs[i].GroupID {
+						tabs[i].DuplicateInGroup = true
+					} else {
+						tabs[i].DuplicateAcrossGroups = true
+					}
 				}
 			}
 			tabs[i].DuplicateOf = others
 		}
 	}
+	var changed []*types.Tab
+	for i, tab := range tabs {
+		if tab.IsDuplicate != was[i] {
+			changed = append(changed, tab)
+		}
+	}
+	return changed
+}
+
+// DuplicatesToClose returns, for every set of tabs sharing a URL (compared
+// as in AnalyzeDuplicates), all copies except the most recently accessed
+// one. Pinned tabs are never returned. The sets are found afresh rather
+// than read from DuplicateOf, which goes stale as soon as a tab closes.
+func DuplicatesToClose(tabs []*types.Tab, exact bool) []*types.Tab {
+	closing := make(map[int]bool)
+	for _, indices := range duplicateSets(tabs, exact) {
+		if len(indices) < 2 {
+			continue
+		}
+		// Ties go to the lower index so exactly one copy survives.
+		keep := indices[0]
+		for _, i := range indices[1:] {
+			if tabs[i].LastAccessed.After(tabs[keep].LastAccessed) {
+				keep = i
+			}
+		}
+		for _, i := range indices {
+			if i != keep && !tabs[i].Pinned {
+				closing[i] = true
+			}
+		}
+	}
+	var result []*types.Tab
+	for i, tab := range tabs {
+		if closing[i] {
+			result = append(result, tab)
+		}
+	}
+	return result
 }
@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lotas/tabsordnung/internal/types"
 )
@@ -15,7 +16,7 @@ func TestAnalyzeDuplicates(t *testing.T) {
 		{URL: "https://example.com/page?a=1&b=2"},
 	}
 
-	AnalyzeDuplicates(tabs)
+	AnalyzeDuplicates(tabs, false)
 
 	if !tabs[0].IsDuplicate {
 		t.Error("tab 0 should be duplicate")
@@ -43,6 +44,9 @@ func TestNormalizeURL(t *testing.T) {
 		{"https://example.com/page/", "https://example.com/page"},
 		{"https://example.com/page?b=2&a=1", "https://example.com/page?a=1&b=2"},
 		{"https://example.com", "https://example.com"},
+		{"https://Example.COM/Page", "https://example.com/Page"},
+		{"https://example.com/page?utm_source=x&utm_medium=y&id=3", "https://example.com/page?id=3"},
+		{"https://example.com/page/?fbclid=abc#top", "https://example.com/page"},
 	}
 
 	for _, tt := range tests {
@@ -52,3 +56,112 @@ func TestNormalizeURL(t *testing.T) {
 		}
 	}
 }
+
+func TestAnalyzeDuplicatesExact(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "https://example.com/page?utm_source=newsletter"},
+		{URL: "https://example.com/page"},
+		{URL: "https://example.com/page"},
+	}
+
+	AnalyzeDuplicates(tabs, true)
+
+	if tabs[0].IsDuplicate {
+		t.Error("tab 0 should not be duplicate with exact matching")
+	}
+	if !tabs[1].IsDuplicate || !tabs[2].IsDuplicate {
+		t.Error("identical URLs should be duplicates")
+	}
+
+	normalized := []*types.Tab{
+		{URL: "https://example.com/page?utm_source=newsletter"},
+		{URL: "https://EXAMPLE.com/page/"},
+	}
+	AnalyzeDuplicates(normalized, false)
+	if !normalized[0].IsDuplicate || !normalized[1].IsDuplicate {
+		t.Error("tabs differing only by tracking params and host case should be duplicates")
+	}
+	if len(normalized[0].DuplicateOf) != 1 || normalized[0].DuplicateOf[0] != 1 {
+		t.Errorf("DuplicateOf = %v, want [1]", normalized[0].DuplicateOf)
+	}
+}
+
+func TestAnalyzeDuplicatesGroupSpan(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "https://example.com/a", GroupID: "g1"},
+		{URL: "https://example.com/a", GroupID: "g1"},
+		{URL: "https://example.com/b", GroupID: "g1"},
+		{URL: "https://example.com/b", GroupID: "g2"},
+	}
+
+	AnalyzeDuplicates(tabs, false)
+
+	if !tabs[0].DuplicateInGroup || tabs[0].DuplicateAcrossGroups {
+		t.Errorf("tab 0: in group %v, across %v; want in group only", tabs[0].DuplicateInGroup, tabs[0].DuplicateAcrossGroups)
+	}
+	if tabs[2].DuplicateInGroup || !tabs[2].DuplicateAcrossGroups {
+		t.Errorf("tab 2: in group %v, across %v; want across only", tabs[2].DuplicateInGroup, tabs[2].DuplicateAcrossGroups)
+	}
+	if !tabs[3].DuplicateAcrossGroups {
+		t.Error("tab 3 should be a duplicate across groups")
+	}
+}
+
+func TestAnalyzeDuplicatesAcrossProfiles(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "https://example.com", Profile: "Work"},
+		{URL: "https://example.com", Profile: "Home"},
+	}
+	AnalyzeDuplicates(tabs, false)
+	for i, tab := range tabs {
+		if !tab.IsDuplicate || !tab.DuplicateAcrossProfiles {
+			t.Errorf("tab %d should be a duplicate across profiles", i)
+		}
+		if tab.DuplicateInGroup || tab.DuplicateAcrossGroups {
+			t.Errorf("tab %d: in group %v, across groups %v; want neither", i, tab.DuplicateInGroup, tab.DuplicateAcrossGroups)
+		}
+	}
+}
+
+func TestDuplicatesToClose(t *testing.T) {
+	now := time.Now()
+	tabs := []*types.Tab{
+		{URL: "https://example.com/a", LastAccessed: now.Add(-2 * time.Hour)},
+		{URL: "https://example.com/a", LastAccessed: now},
+		{URL: "https://example.com/a", LastAccessed: now.Add(-time.Hour), Pinned: true},
+		{URL: "https://example.com/b", LastAccessed: now},
+		{URL: "https://example.com/b", LastAccessed: now},
+		{URL: "https://example.com/c", LastAccessed: now},
+	}
+	got := DuplicatesToClose(tabs, false)
+	if len(got) != 2 {
+		t.Fatalf("got %d tabs to close, want 2", len(got))
+	}
+	if got[0] != tabs[0] {
+		t.Error("older copy of /a should be closed")
+	}
+	if got[1] != tabs[4] {
+		t.Error("with equal access times, the later copy of /b should be closed")
+	}
+}
+
+func TestDuplicatesToCloseAfterClosingACopy(t *testing.T) {
+	now := time.Now()
+	tabs := []*types.Tab{
+		{URL: "https://example.com/a", LastAccessed: now.Add(-time.Hour)},
+		{URL: "https://example.com/a", LastAccessed: now.Add(-2 * time.Hour)},
+		{URL: "https://example.com/b", LastAccessed: now},
+	}
+	AnalyzeDuplicates(tabs, false)
+
+	// Close the newer copy; the older one is now the only /a.
+	tabs = append(tabs[:0], tabs[1:]...)
+	AnalyzeDuplicates(tabs, false)
+
+	if tabs[0].IsDuplicate || tabs[0].DuplicateOf != nil || tabs[0].DuplicateInGroup {
+		t.Errorf("remaining copy still flagged: %+v", tabs[0])
+	}
+	if got := DuplicatesToClose(tabs, false); len(got) != 0 {
+		t.Errorf("got %d tabs to close, want none", len(got))
+	}
+}
@@ -2,31 +2,39 @@ package analyzer
 
 import (
 	"bytes"
+	"cmp"
+	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"os/exec"
 	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/github"
+	"github.com/lotas/tabsordnung/internal/storage"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
-var githubURLPattern = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/(issues|pull)/(\d+)`)
+var githubURLPattern = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/(issues|pull|discussions)/(\d+)`)
 
 type githubRef struct {
 	Owner  string
 	Repo   string
-	Kind   string // "issue" or "pr"
+	Kind   string // "issue", "pr" or "discussion"
 	Number int
 	Tab    *types.Tab
 }
 
 func parseGitHubURL(rawURL string) *githubRef {
-	u, err := url.Parse(rawURL)
+	u, err := url.Parse(UnwrapReaderURL(rawURL))
 	if err != nil {
 		return nil
 	}
@@ -36,8 +44,11 @@ func parseGitHubURL(rawURL string) *githubRef {
 	}
 	num, _ := strconv.Atoi(matches[4])
 	kind := "issue"
-	if matches[3] == "pull" {
+	switch matches[3] {
+	case "pull":
 		kind = "pr"
+	case "discussions":
+		kind = "discussion"
 	}
 	return &githubRef{
 		Owner:  matches[1],
@@ -47,6 +58,8 @@ func parseGitHubURL(rawURL string) *githubRef {
 	}
 }
 
+// resolveGitHubToken returns the token of the gh CLI, falling back to the
+// GITHUB_TOKEN environment variable, or "" if neither has one.
 func resolveGitHubToken() string {
 	out, err := exec.Command("gh", "auth", "token").Output()
 	if err == nil {
@@ -55,7 +68,7 @@ func resolveGitHubToken() string {
 			return token
 		}
 	}
-	return ""
+	return strings.TrimSpace(os.Getenv("GITHUB_TOKEN"))
 }
 
 // repoKey returns "owner/repo" as a map key.
@@ -95,10 +108,14 @@ func buildGraphQLQuery(refs []*githubRef) (string, map[string]*githubRef) {
 
 		for ii, ref := range rg.refs {
 			var itemAlias string
-			if ref.Kind == "issue" {
+			switch ref.Kind {
+			case "issue":
 				itemAlias = fmt.Sprintf("i%d", ii)
 				b.WriteString(fmt.Sprintf(" %s: issue(number: %d) { state }", itemAlias, ref.Number))
-			} else {
+			case "discussion":
+				itemAlias = fmt.Sprintf("d%d", ii)
+				b.WriteString(fmt.Sprintf(" %s: discussion(number: %d) { closed }", itemAlias, ref.Number))
+			default:
 				itemAlias = fmt.Sprintf("p%d", ii)
 				b.WriteString(fmt.Sprintf(" %s: pullRequest(number: %d) { state }", itemAlias, ref.Number))
 			}
@@ -115,13 +132,66 @@ func buildGraphQLQuery(refs []*githubRef) (string, map[string]*githubRef) {
 // graphQLResponse is the top-level response shape.
 type graphQLResponse struct {
 	Data   map[string]json.RawMessage `json:"data"`
-	Errors []struct {
-		Message string `json:"message"`
-	} `json:"errors"`
+	Errors []graphQLError             `json:"errors"`
+}
+
+// graphQLError is one entry of the errors array. Path names the aliases of
+// the field that failed, e.g. ["r0"] for a whole repository or ["r0", "p1"]
+// for a single PR.
+type graphQLError struct {
+	Type    string `json:"type"`
+	Path    []any  `json:"path"`
+	Message string `json:"message"`
+}
+
+// applyErrorResponse marks the tabs of refs GitHub reported as missing or
+// forbidden, so they read as "not found"/"no access" instead of unknown.
+// Errors of other types leave the tabs alone.
+func applyErrorResponse(gqlResp graphQLResponse, aliasMap map[string]*githubRef) {
+	for _, e := range gqlResp.Errors {
+		var status string
+		switch e.Type {
+		case "NOT_FOUND":
+			status = types.GitHubNotFound
+		case "FORBIDDEN":
+			status = types.GitHubNoAccess
+		default:
+			continue
+		}
+		if len(e.Path) == 0 {
+			continue
+		}
+		repoAlias, _ := e.Path[0].(string)
+		if len(e.Path) >= 2 {
+			itemAlias, _ := e.Path[1].(string)
+			if ref, ok := aliasMap[repoAlias+"."+itemAlias]; ok {
+				ref.Tab.GitHubStatus = status
+			}
+			continue
+		}
+		for alias, ref := range aliasMap {
+			if strings.HasPrefix(alias, repoAlias+".") {
+				ref.Tab.GitHubStatus = status
+			}
+		}
+	}
 }
 
 type stateResponse struct {
-	State string `json:"state"`
+	State  string `json:"state"`
+	Closed *bool  `json:"closed"` // discussions only
+}
+
+// itemStatus returns the lowercase GitHubStatus for an item. Discussions
+// report a closed flag instead of a state and read as "open" or "closed".
+func itemStatus(state string, closed *bool) string {
+	if state == "" && closed != nil {
+		if *closed {
+			return "closed"
+		}
+		return "open"
+	}
+	return strings.ToLower(state)
 }
 
 // ResolveGitHubToken is an exported wrapper around the unexported resolveGitHubToken.
@@ -139,8 +209,9 @@ func ResolveGitHubUser(token string) (string, error) {
 	req.Header.Set("Authorization", "Bearer "+token)
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := github.HTTPClient.Do(req.WithContext(ctx))
 	if err != nil {
 		return "", err
 	}
@@ -165,7 +236,11 @@ func ResolveGitHubUser(token string) (string, error) {
 // triageItemResponse is the response shape for triage query items.
 type triageItemResponse struct {
 	State     string `json:"state"`
+	Closed    *bool  `json:"closed"` // discussions only
 	UpdatedAt string `json:"updatedAt"`
+	Author    *struct {
+		Login string `json:"login"`
+	} `json:"author"`
 	Assignees struct {
 		Nodes []struct {
 			Login string `json:"login"`
@@ -178,6 +253,9 @@ type triageItemResponse struct {
 			} `json:"requestedReviewer"`
 		} `json:"nodes"`
 	} `json:"reviewRequests"`
+	StatusCheckRollup *struct {
+		State string `json:"state"`
+	} `json:"statusCheckRollup"`
 }
 
 // buildTriageGraphQLQuery constructs a batched query with expanded triage fields.
@@ -212,12 +290,16 @@ func buildTriageGraphQLQuery(refs []*githubRef) (string, map[string]*githubRef)
 
 		for ii, ref := range rg.refs {
 			var itemAlias string
-			if ref.Kind == "issue" {
+			switch ref.Kind {
+			case "issue":
 				itemAlias = fmt.Sprintf("i%d", ii)
-				b.WriteString(fmt.Sprintf(" %s: issue(number: %d) { state updatedAt assignees(first: 10) { nodes { login } } }", itemAlias, ref.Number))
-			} else {
+				b.WriteString(fmt.Sprintf(" %s: issue(number: %d) { state updatedAt author { login } assignees(first: 10) { nodes { login } } }", itemAlias, ref.Number))
+			case "discussion":
+				itemAlias = fmt.Sprintf("d%d", ii)
+				b.WriteString(fmt.Sprintf(" %s: discussion(number: %d) { closed updatedAt author { login } }", itemAlias, ref.Number))
+			default:
 				itemAlias = fmt.Sprintf("p%d", ii)
-				b.WriteString(fmt.Sprintf(" %s: pullRequest(number: %d) { state updatedAt assignees(first: 10) { nodes { login } } reviewRequests(first: 100) { nodes { requestedReviewer { ... on User { login } } } } }", itemAlias, ref.Number))
+				b.WriteString(fmt.Sprintf(" %s: pullRequest(number: %d) { state updatedAt author { login } assignees(first: 10) { nodes { login } } reviewRequests(first: 100) { nodes { requestedReviewer { ... on User { login } } } } statusCheckRollup { state } }", itemAlias, ref.Number))
 			}
 			aliasMap[repoAlias+"."+itemAlias] = ref
 		}
@@ -230,8 +312,10 @@ func buildTriageGraphQLQuery(refs []*githubRef) (string, map[string]*githubRef)
 }
 
 // AnalyzeGitHubTriage fetches extended GitHub metadata for triage classification.
-// It sets both GitHubStatus and GitHubTriage on matching tabs.
-func AnalyzeGitHubTriage(tabs []*types.Tab, username string) {
+// It sets both GitHubStatus and GitHubTriage on matching tabs. Refs are queried
+// in chunks; a failed chunk doesn't stop the others unless GitHub is
+// throttling, and the first error is returned once all chunks are done.
+func AnalyzeGitHubTriage(ctx context.Context, tabs []*types.Tab, username string) error {
 	var refs []*githubRef
 	for _, tab := range tabs {
 		ref := parseGitHubURL(tab.URL)
@@ -242,38 +326,99 @@ func AnalyzeGitHubTriage(tabs []*types.Tab, username string) {
 		refs = append(refs, ref)
 	}
 	if len(refs) == 0 {
-		return
+		return nil
 	}
 
 	token := resolveGitHubToken()
 	if token == "" {
-		return
+		return nil
 	}
 
-	query, aliasMap := buildTriageGraphQLQuery(refs)
+	lowerUser := strings.ToLower(username)
 
-	body, _ := json.Marshal(map[string]string{"query": query})
-	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewReader(body))
-	if err != nil {
-		return
+	var firstErr error
+	for _, chunk := range github.ChunkRefs(refs, github.MaxRefsPerQuery) {
+		query, aliasMap := buildTriageGraphQLQuery(chunk)
+		var gqlResp graphQLResponse
+		if err := github.PostGraphQL(ctx, token, query, github.QueryTimeout, &gqlResp); err != nil {
+			if fatalChunkError(ctx, err) {
+				return err
+			}
+			firstErr = cmp.Or(firstErr, err)
+			continue
+		}
+		logGraphQLErrors("github.triage", gqlResp)
+		applyTriageResponse(gqlResp, aliasMap, lowerUser)
+		applyErrorResponse(gqlResp, aliasMap)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+	return firstErr
+}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
+// TriageCache remembers the triage details AnalyzeGitHubTriage found, so
+// that reloading the session within the GitHub TTL doesn't query them again.
+// It is safe for concurrent use.
+type TriageCache struct {
+	mu      sync.Mutex
+	entries map[string]triageEntry
+}
 
-	var gqlResp graphQLResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
-		return
+type triageEntry struct {
+	info *types.GitHubTriageInfo
+	at   time.Time
+}
+
+func NewTriageCache() *TriageCache {
+	return &TriageCache{entries: make(map[string]triageEntry)}
+}
+
+func triageKey(ref *githubRef) string {
+	return strings.ToLower(fmt.Sprintf("%s/%s#%d", ref.Owner, ref.Repo, ref.Number))
+}
+
+// Apply sets GitHubTriage on the tabs triaged within ttl and returns the
+// others, which still need AnalyzeGitHubTriage. A ttl of zero disables the
+// cache.
+func (c *TriageCache) Apply(tabs []*types.Tab, ttl time.Duration) []*types.Tab {
+	if ttl <= 0 {
+		return tabs
 	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var stale []*types.Tab
+	for _, tab := range tabs {
+		ref := parseGitHubURL(tab.URL)
+		if ref == nil {
+			continue
+		}
+		if e, ok := c.entries[triageKey(ref)]; ok && now.Sub(e.at) < ttl {
+			info := *e.info
+			tab.GitHubTriage = &info
+			continue
+		}
+		stale = append(stale, tab)
+	}
+	return stale
+}
 
-	lowerUser := strings.ToLower(username)
+// Store remembers the triage details of tabs that have them.
+func (c *TriageCache) Store(tabs []*types.Tab) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, tab := range tabs {
+		ref := parseGitHubURL(tab.URL)
+		if ref == nil || tab.GitHubTriage == nil {
+			continue
+		}
+		info := *tab.GitHubTriage
+		c.entries[triageKey(ref)] = triageEntry{info: &info, at: now}
+	}
+}
 
+// applyTriageResponse sets GitHubStatus and GitHubTriage on the tabs of the
+// refs answered in resp.
+func applyTriageResponse(gqlResp graphQLResponse, aliasMap map[string]*githubRef, lowerUser string) {
 	for repoAlias, repoRaw := range gqlResp.Data {
 		var items map[string]json.RawMessage
 		if err := json.Unmarshal(repoRaw, &items); err != nil {
@@ -291,7 +436,7 @@ func AnalyzeGitHubTriage(tabs []*types.Tab, username string) {
 			}
 
 			// Set status (same as AnalyzeGitHub)
-			ref.Tab.GitHubStatus = strings.ToLower(tr.State)
+			ref.Tab.GitHubStatus = itemStatus(tr.State, tr.Closed)
 
 			// Build triage info
 			info := &types.GitHubTriageInfo{}
@@ -301,6 +446,11 @@ func AnalyzeGitHubTriage(tabs []*types.Tab, username string) {
 				info.UpdatedAt = t
 			}
 
+			// Check if user opened it
+			if tr.Author != nil && strings.ToLower(tr.Author.Login) == lowerUser {
+				info.Authored = true
+			}
+
 			// Check if user is assigned
 			for _, a := range tr.Assignees.Nodes {
 				if strings.ToLower(a.Login) == lowerUser {
@@ -319,12 +469,28 @@ func AnalyzeGitHubTriage(tabs []*types.Tab, username string) {
 				}
 			}
 
+			// Summarize CI checks (PRs only)
+			if tr.StatusCheckRollup != nil {
+				switch tr.StatusCheckRollup.State {
+				case "SUCCESS":
+					info.ChecksStatus = "passing"
+				case "FAILURE", "ERROR":
+					info.ChecksStatus = "failing"
+				case "PENDING", "EXPECTED":
+					info.ChecksStatus = "pending"
+				}
+			}
+
 			ref.Tab.GitHubTriage = info
 		}
 	}
 }
 
-func AnalyzeGitHub(tabs []*types.Tab) {
+// AnalyzeGitHub sets GitHubStatus on GitHub issue and PR tabs. Like
+// AnalyzeGitHubTriage it keeps going past failed chunks. It returns a
+// *github.RateLimitError when GitHub keeps throttling the requests, or ctx's
+// error once it is cancelled.
+func AnalyzeGitHub(ctx context.Context, tabs []*types.Tab) error {
 	// Collect GitHub refs
 	var refs []*githubRef
 	for _, tab := range tabs {
@@ -336,36 +502,50 @@ func AnalyzeGitHub(tabs []*types.Tab) {
 		refs = append(refs, ref)
 	}
 	if len(refs) == 0 {
-		return
+		return nil
 	}
 
 	token := resolveGitHubToken()
 	if token == "" {
-		return
+		return nil
 	}
 
-	query, aliasMap := buildGraphQLQuery(refs)
-
-	body, _ := json.Marshal(map[string]string{"query": query})
-	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewReader(body))
-	if err != nil {
-		return
+	var firstErr error
+	for _, chunk := range github.ChunkRefs(refs, github.MaxRefsPerQuery) {
+		query, aliasMap := buildGraphQLQuery(chunk)
+		var gqlResp graphQLResponse
+		if err := github.PostGraphQL(ctx, token, query, github.QueryTimeout, &gqlResp); err != nil {
+			if fatalChunkError(ctx, err) {
+				return err
+			}
+			firstErr = cmp.Or(firstErr, err)
+			continue
+		}
+		logGraphQLErrors("github.analyze", gqlResp)
+		applyStateResponse(gqlResp, aliasMap)
+		applyErrorResponse(gqlResp, aliasMap)
 	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+	return firstErr
+}
 
-	client := &http.Client{Timeout: 5 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return
-	}
-	defer resp.Body.Close()
+// fatalChunkError reports whether err from one chunk's query should stop the
+// remaining chunks: GitHub is throttling us, or the caller gave up. Other
+// errors only lose that chunk's statuses.
+func fatalChunkError(ctx context.Context, err error) bool {
+	return github.IsRateLimited(err) || ctx.Err() != nil
+}
 
-	var gqlResp graphQLResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
+// logGraphQLErrors records the errors GitHub reported alongside a partial
+// response. Items that did resolve are still applied.
+func logGraphQLErrors(event string, gqlResp graphQLResponse) {
+	if len(gqlResp.Errors) == 0 {
 		return
 	}
+	applog.Info(event+".errors", "count", len(gqlResp.Errors), "first", gqlResp.Errors[0].Message)
+}
 
+// applyStateResponse sets GitHubStatus on the tabs of the refs answered in resp.
+func applyStateResponse(gqlResp graphQLResponse, aliasMap map[string]*githubRef) {
 	// Parse nested response: data.r0.i0.state, data.r0.p1.state, etc.
 	for repoAlias, repoRaw := range gqlResp.Data {
 		var items map[string]json.RawMessage
@@ -382,7 +562,80 @@ func AnalyzeGitHub(tabs []*types.Tab) {
 			if err := json.Unmarshal(itemRaw, &sr); err != nil {
 				continue
 			}
-			ref.Tab.GitHubStatus = strings.ToLower(sr.State)
+			ref.Tab.GitHubStatus = itemStatus(sr.State, sr.Closed)
 		}
 	}
 }
+
+// AnalyzeGitHubCached sets GitHubStatus like AnalyzeGitHub, but answers from
+// the github_entities table for entities refreshed within ttl. Only missing
+// or stale entities are queried, and their fresh state is stored for the next
+// load. A ttl of zero disables the cache.
+func AnalyzeGitHubCached(ctx context.Context, db *sql.DB, tabs []*types.Tab, ttl time.Duration) error {
+	if db == nil || ttl <= 0 {
+		return AnalyzeGitHub(ctx, tabs)
+	}
+
+	now := time.Now()
+	var stale []*githubRef
+	for _, tab := range tabs {
+		ref := parseGitHubURL(tab.URL)
+		if ref == nil {
+			continue
+		}
+		ref.Tab = tab
+		e, err := storage.GetGitHubEntity(db, ref.Owner, ref.Repo, ref.Number)
+		if err == nil && e != nil && e.State != "" && e.LastRefreshedAt != nil && now.Sub(*e.LastRefreshedAt) < ttl {
+			tab.GitHubStatus = e.State
+			continue
+		}
+		stale = append(stale, ref)
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	token := resolveGitHubToken()
+	if token == "" {
+		return nil
+	}
+
+	var entities []storage.GitHubEntity
+	seen := make(map[int64]bool)
+	for _, ref := range stale {
+		kind := ref.Kind
+		if kind == "pr" {
+			kind = "pull"
+		}
+		id, _, err := storage.UpsertGitHubEntity(db, ref.Owner, ref.Repo, ref.Number, kind, "tab")
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		// Re-read so RefreshEntities sees the previous state and can
+		// record status changes.
+		if e, err := storage.GetGitHubEntity(db, ref.Owner, ref.Repo, ref.Number); err == nil && e != nil {
+			entities = append(entities, *e)
+		}
+	}
+
+	_, err := github.RefreshEntities(ctx, db, entities, token, true)
+
+	// Apply whatever is stored now, even if the refresh stopped early: an
+	// older state is more useful than none.
+	var unresolved []*types.Tab
+	for _, ref := range stale {
+		e, _ := storage.GetGitHubEntity(db, ref.Owner, ref.Repo, ref.Number)
+		if e != nil && e.State != "" {
+			ref.Tab.GitHubStatus = e.State
+		} else {
+			unresolved = append(unresolved, ref.Tab)
+		}
+	}
+	// Entities the refresh couldn't resolve are usually deleted or
+	// private; ask again without the cache to find out which.
+	if err == nil && len(unresolved) > 0 {
+		err = AnalyzeGitHub(ctx, unresolved)
+	}
+	return err
+}
@@ -1,7 +1,14 @@
 package analyzer
 
 import (
+	"context"
+	"encoding/json"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
 )
 
 func TestParseGitHubURL(t *testing.T) {
@@ -35,6 +42,17 @@ func TestParseGitHubURL(t *testing.T) {
 			want:   &githubRef{Owner: "org", Repo: "repo", Kind: "issue", Number: 10},
 			wantOK: true,
 		},
+		{
+			name:   "discussion URL",
+			url:    "https://github.com/org/repo/discussions/12#discussioncomment-3",
+			want:   &githubRef{Owner: "org", Repo: "repo", Kind: "discussion", Number: 12},
+			wantOK: true,
+		},
+		{
+			name:   "commit URL",
+			url:    "https://github.com/org/repo/commit/0123abc",
+			wantOK: false,
+		},
 		{
 			name:   "not a GitHub URL",
 			url:    "https://google.com/search",
@@ -129,9 +147,137 @@ func TestBuildTriageGraphQLQuery(t *testing.T) {
 	if !containsAll(query, "assignees", "updatedAt") {
 		t.Errorf("query missing triage fields: %s", query)
 	}
+	if !containsAll(query, "author { login }") {
+		t.Errorf("query missing author: %s", query)
+	}
 	if !containsAll(query, "reviewRequests") {
 		t.Errorf("query missing reviewRequests: %s", query)
 	}
+	if !containsAll(query, "statusCheckRollup") {
+		t.Errorf("query missing statusCheckRollup: %s", query)
+	}
+}
+
+func TestApplyTriageResponse(t *testing.T) {
+	tab := &types.Tab{URL: "https://github.com/org/repo/pull/99"}
+	ref := &githubRef{Owner: "org", Repo: "repo", Kind: "pr", Number: 99, Tab: tab}
+	_, aliasMap := buildTriageGraphQLQuery([]*githubRef{ref})
+
+	var resp graphQLResponse
+	raw := `{"data":{"r0":{"p0":{"state":"OPEN","updatedAt":"2024-03-01T10:00:00Z",
+		"author":{"login":"bob"},
+		"assignees":{"nodes":[]},
+		"reviewRequests":{"nodes":[{"requestedReviewer":{"login":"Alice"}}]},
+		"statusCheckRollup":{"state":"FAILURE"}}}}}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+	applyTriageResponse(resp, aliasMap, "alice")
+
+	if tab.GitHubStatus != "open" {
+		t.Errorf("GitHubStatus = %q, want open", tab.GitHubStatus)
+	}
+	if tab.GitHubTriage == nil {
+		t.Fatal("GitHubTriage not set")
+	}
+	if !tab.GitHubTriage.ReviewRequested {
+		t.Error("expected ReviewRequested")
+	}
+	if tab.GitHubTriage.Assigned {
+		t.Error("did not expect Assigned")
+	}
+	if tab.GitHubTriage.Authored {
+		t.Error("did not expect Authored")
+	}
+	if tab.GitHubTriage.ChecksStatus != "failing" {
+		t.Errorf("ChecksStatus = %q, want failing", tab.GitHubTriage.ChecksStatus)
+	}
+}
+
+func TestApplyStateResponseDiscussion(t *testing.T) {
+	closed := &types.Tab{URL: "https://github.com/org/repo/discussions/3"}
+	open := &types.Tab{URL: "https://github.com/org/repo/discussions/4"}
+	refs := []*githubRef{
+		{Owner: "org", Repo: "repo", Kind: "discussion", Number: 3, Tab: closed},
+		{Owner: "org", Repo: "repo", Kind: "discussion", Number: 4, Tab: open},
+	}
+	query, aliasMap := buildGraphQLQuery(refs)
+	if !containsAll(query, "discussion(number: 3) { closed }", "discussion(number: 4) { closed }") {
+		t.Errorf("query missing discussion items: %s", query)
+	}
+
+	var resp graphQLResponse
+	raw := `{"data":{"r0":{"d0":{"closed":true},"d1":{"closed":false}}}}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+	applyStateResponse(resp, aliasMap)
+
+	if closed.GitHubStatus != "closed" {
+		t.Errorf("closed discussion GitHubStatus = %q, want closed", closed.GitHubStatus)
+	}
+	if open.GitHubStatus != "open" {
+		t.Errorf("open discussion GitHubStatus = %q, want open", open.GitHubStatus)
+	}
+}
+
+func TestApplyStateResponsePartial(t *testing.T) {
+	gone := &types.Tab{URL: "https://github.com/old/deleted/issues/1"}
+	ok := &types.Tab{URL: "https://github.com/org/repo/pull/2"}
+	refs := []*githubRef{
+		{Owner: "old", Repo: "deleted", Kind: "issue", Number: 1, Tab: gone},
+		{Owner: "org", Repo: "repo", Kind: "pr", Number: 2, Tab: ok},
+	}
+	_, aliasMap := buildGraphQLQuery(refs)
+
+	var resp graphQLResponse
+	raw := `{"data":{"r0":null,"r1":{"p0":{"state":"MERGED"}}},
+		"errors":[{"type":"NOT_FOUND","path":["r0"],"message":"Could not resolve to a Repository"}]}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+	applyStateResponse(resp, aliasMap)
+
+	if ok.GitHubStatus != "merged" {
+		t.Errorf("resolved tab GitHubStatus = %q, want merged", ok.GitHubStatus)
+	}
+	if gone.GitHubStatus != "" {
+		t.Errorf("unresolved tab GitHubStatus = %q, want empty", gone.GitHubStatus)
+	}
+}
+
+func TestApplyErrorResponse(t *testing.T) {
+	deleted1 := &types.Tab{URL: "https://github.com/old/deleted/issues/1"}
+	deleted2 := &types.Tab{URL: "https://github.com/old/deleted/pull/2"}
+	secret := &types.Tab{URL: "https://github.com/org/repo/pull/3"}
+	slow := &types.Tab{URL: "https://github.com/org/repo/issues/4", GitHubStatus: "open"}
+	refs := []*githubRef{
+		{Owner: "old", Repo: "deleted", Kind: "issue", Number: 1, Tab: deleted1},
+		{Owner: "old", Repo: "deleted", Kind: "pr", Number: 2, Tab: deleted2},
+		{Owner: "org", Repo: "repo", Kind: "pr", Number: 3, Tab: secret},
+		{Owner: "org", Repo: "repo", Kind: "issue", Number: 4, Tab: slow},
+	}
+	_, aliasMap := buildGraphQLQuery(refs)
+
+	var resp graphQLResponse
+	raw := `{"data":{"r0":null,"r1":{"p0":null,"i1":null}},"errors":[
+		{"type":"NOT_FOUND","path":["r0"],"message":"Could not resolve to a Repository"},
+		{"type":"FORBIDDEN","path":["r1","p0"],"message":"Resource not accessible"},
+		{"type":"SERVICE_UNAVAILABLE","path":["r1","i1"],"message":"timeout"}]}`
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		t.Fatal(err)
+	}
+	applyErrorResponse(resp, aliasMap)
+
+	if deleted1.GitHubStatus != types.GitHubNotFound || deleted2.GitHubStatus != types.GitHubNotFound {
+		t.Errorf("repo-level NOT_FOUND: got %q and %q", deleted1.GitHubStatus, deleted2.GitHubStatus)
+	}
+	if secret.GitHubStatus != types.GitHubNoAccess {
+		t.Errorf("item-level FORBIDDEN: got %q", secret.GitHubStatus)
+	}
+	if slow.GitHubStatus != "open" {
+		t.Errorf("other error types should leave the status alone, got %q", slow.GitHubStatus)
+	}
 }
 
 func containsAll(s string, subs ...string) bool {
@@ -155,3 +301,69 @@ func searchString(s, sub string) bool {
 	}
 	return false
 }
+
+func TestAnalyzeGitHubCachedUsesFreshEntities(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	id, _, err := storage.UpsertGitHubEntity(db, "golang", "go", 1234, "pull", "tab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.UpdateGitHubEntityStatus(db, id, storage.GitHubStatusUpdate{State: "merged"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The entity was just refreshed, so no GitHub query (or token) is needed.
+	tab := &types.Tab{URL: "https://github.com/golang/go/pull/1234"}
+	other := &types.Tab{URL: "https://example.com/"}
+	if err := AnalyzeGitHubCached(context.Background(), db, []*types.Tab{tab, other}, 30*time.Minute); err != nil {
+		t.Fatalf("AnalyzeGitHubCached: %v", err)
+	}
+	if tab.GitHubStatus != "merged" {
+		t.Errorf("GitHubStatus = %q, want merged", tab.GitHubStatus)
+	}
+	if other.GitHubStatus != "" {
+		t.Errorf("non-GitHub tab got status %q", other.GitHubStatus)
+	}
+}
+
+func TestTriageCache(t *testing.T) {
+	cache := NewTriageCache()
+	pr := &types.Tab{URL: "https://github.com/lotas/tabsordnung/pull/7", GitHubTriage: &types.GitHubTriageInfo{ReviewRequested: true}}
+	cache.Store([]*types.Tab{pr, {URL: "https://github.com/lotas/tabsordnung/issues/8"}})
+
+	// A reload brings new tabs for the same PR, here under /files.
+	reloaded := []*types.Tab{
+		{URL: "https://github.com/Lotas/tabsordnung/pull/7/files"},
+		{URL: "https://github.com/lotas/tabsordnung/issues/8"},
+	}
+	stale := cache.Apply(reloaded, time.Hour)
+	if len(stale) != 1 || stale[0] != reloaded[1] {
+		t.Fatalf("stale = %v, want only the issue without triage details", stale)
+	}
+	if reloaded[0].GitHubTriage == nil || !reloaded[0].GitHubTriage.ReviewRequested {
+		t.Errorf("cached triage not applied: %+v", reloaded[0].GitHubTriage)
+	}
+	if reloaded[0].GitHubTriage == pr.GitHubTriage {
+		t.Error("tabs share the cached triage details")
+	}
+	if stale := cache.Apply(reloaded, 0); len(stale) != 2 {
+		t.Errorf("ttl 0: got %d stale tabs, want both", len(stale))
+	}
+}
+
+func TestResolveGitHubTokenFromEnv(t *testing.T) {
+	t.Setenv("PATH", t.TempDir()) // no gh CLI
+	t.Setenv("GITHUB_TOKEN", "ghp_example\n")
+	if got := resolveGitHubToken(); got != "ghp_example" {
+		t.Errorf("resolveGitHubToken() = %q, want the GITHUB_TOKEN value", got)
+	}
+	t.Setenv("GITHUB_TOKEN", "")
+	if got := resolveGitHubToken(); got != "" {
+		t.Errorf("resolveGitHubToken() = %q without gh or GITHUB_TOKEN, want empty", got)
+	}
+}
@@ -0,0 +1,56 @@
+package analyzer
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// placeholderURLs are pages a tab shows while it has nothing real loaded:
+// a fresh tab, or one stuck before its first navigation.
+var placeholderURLs = map[string]bool{
+	"":             true,
+	"about:blank":  true,
+	"about:newtab": true,
+	"about:home":   true,
+	"about:reader": true,
+}
+
+// UnwrapReaderURL returns the page behind a Reader View URL such as
+// "about:reader?url=https%3A%2F%2Fexample.com%2F". Other URLs, and reader
+// URLs without a usable url parameter, are returned unchanged.
+func UnwrapReaderURL(u string) string {
+	query, ok := strings.CutPrefix(u, "about:reader?")
+	if !ok {
+		return u
+	}
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		return u
+	}
+	if inner := values.Get("url"); inner != "" {
+		return inner
+	}
+	return u
+}
+
+// IsPlaceholderURL reports whether u is a blank or loading page rather
+// than real content.
+func IsPlaceholderURL(u string) bool {
+	return placeholderURLs[UnwrapReaderURL(strings.TrimSpace(u))]
+}
+
+// AnalyzePlaceholders marks tabs showing a blank or loading page and
+// returns the tabs that became, or stopped being, placeholders in this pass.
+func AnalyzePlaceholders(tabs []*types.Tab) []*types.Tab {
+	var changed []*types.Tab
+	for _, tab := range tabs {
+		is := IsPlaceholderURL(tab.URL)
+		if is != tab.IsPlaceholder {
+			changed = append(changed, tab)
+		}
+		tab.IsPlaceholder = is
+	}
+	return changed
+}
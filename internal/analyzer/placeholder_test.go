@@ -0,0 +1,65 @@
+package analyzer
+
+import (
+	"testing"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestUnwrapReaderURL(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"about:reader?url=https%3A%2F%2Fexample.com%2Fpost%3Fid%3D1", "https://example.com/post?id=1"},
+		{"about:reader?url=https://example.com/", "https://example.com/"},
+		{"about:reader", "about:reader"},
+		{"about:reader?foo=bar", "about:reader?foo=bar"},
+		{"https://example.com/", "https://example.com/"},
+	}
+	for _, tt := range tests {
+		if got := UnwrapReaderURL(tt.in); got != tt.want {
+			t.Errorf("UnwrapReaderURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAnalyzePlaceholders(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "about:blank"},
+		{URL: "about:newtab"},
+		{URL: ""},
+		{URL: "about:reader"},
+		{URL: "about:reader?url=https%3A%2F%2Fexample.com%2F"},
+		{URL: "https://example.com/"},
+		{URL: "about:config"},
+	}
+	want := []bool{true, true, true, true, false, false, false}
+
+	changed := AnalyzePlaceholders(tabs)
+	for i, tab := range tabs {
+		if tab.IsPlaceholder != want[i] {
+			t.Errorf("tab %d (%q): IsPlaceholder = %v, want %v", i, tab.URL, tab.IsPlaceholder, want[i])
+		}
+	}
+	if len(changed) != 4 {
+		t.Errorf("expected 4 changed tabs, got %d", len(changed))
+	}
+
+	tabs[0].URL = "https://example.com/loaded"
+	if changed := AnalyzePlaceholders(tabs); len(changed) != 1 || changed[0] != tabs[0] {
+		t.Errorf("second pass: expected only the loaded tab to change, got %d", len(changed))
+	}
+	if tabs[0].IsPlaceholder {
+		t.Error("tab that finished loading should no longer be a placeholder")
+	}
+}
+
+func TestParseGitHubURLReaderMode(t *testing.T) {
+	ref := parseGitHubURL("about:reader?url=https%3A%2F%2Fgithub.com%2Fowner%2Frepo%2Fissues%2F7")
+	if ref == nil {
+		t.Fatal("expected reader URL to be recognised as a GitHub issue")
+	}
+	if ref.Owner != "owner" || ref.Repo != "repo" || ref.Number != 7 || ref.Kind != "issue" {
+		t.Errorf("unexpected ref: %+v", ref)
+	}
+}
@@ -6,15 +6,20 @@ import (
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
-func AnalyzeStale(tabs []*types.Tab, thresholdDays int) {
+// AnalyzeStale marks tabs not accessed within thresholdDays as stale and
+// returns the tabs that became stale, or stopped being stale, in this pass.
+func AnalyzeStale(tabs []*types.Tab, thresholdDays int) []*types.Tab {
 	threshold := time.Duration(thresholdDays) * 24 * time.Hour
 	now := time.Now()
+	var changed []*types.Tab
 
 	for _, tab := range tabs {
 		age := now.Sub(tab.LastAccessed)
 		tab.StaleDays = int(age.Hours() / 24)
-		if age > threshold {
-			tab.IsStale = true
+		if stale := age > threshold; stale != tab.IsStale {
+			tab.IsStale = stale
+			changed = append(changed, tab)
 		}
 	}
+	return changed
 }
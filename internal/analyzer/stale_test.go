@@ -32,4 +32,12 @@ func TestAnalyzeStale(t *testing.T) {
 	if !tabs[2].IsStale {
 		t.Error("30-day tab should be stale")
 	}
+
+	tabs[1].LastAccessed = now
+	if changed := AnalyzeStale(tabs, 7); len(changed) != 1 || changed[0] != tabs[1] {
+		t.Errorf("second pass: expected only the revisited tab to change, got %d", len(changed))
+	}
+	if tabs[1].IsStale {
+		t.Error("revisited tab should no longer be stale")
+	}
 }
@@ -4,8 +4,9 @@ import "github.com/lotas/tabsordnung/internal/types"
 
 func ComputeStats(data *types.SessionData) types.Stats {
 	stats := types.Stats{
-		TotalTabs:   len(data.AllTabs),
-		TotalGroups: len(data.Groups),
+		TotalWindows: len(data.WindowIDs()),
+		TotalTabs:    len(data.AllTabs),
+		TotalGroups:  len(data.Groups),
 	}
 	for _, tab := range data.AllTabs {
 		if tab.IsStale {
@@ -20,6 +21,105 @@ func ComputeStats(data *types.SessionData) types.Stats {
 		if tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged" {
 			stats.GitHubDoneTabs++
 		}
+		if tab.Pinned {
+			stats.PinnedTabs++
+		}
 	}
 	return stats
 }
+
+// StatsTracker keeps session stats up to date as tabs are added, removed
+// and changed, so live updates don't rescan every tab. ComputeStats remains
+// the reference; NewStatsTracker starts from a full count.
+type StatsTracker struct {
+	stats   types.Stats
+	tabs    map[*types.Tab]tabCounts
+	windows map[int]int // tab count per window ID
+}
+
+// tabCounts is what a tab contributed to the stats when last counted.
+type tabCounts struct {
+	window     int
+	stale      bool
+	dead       bool
+	duplicate  bool
+	githubDone bool
+	pinned     bool
+}
+
+func countsFor(tab *types.Tab) tabCounts {
+	return tabCounts{
+		window:     tab.WindowID,
+		stale:      tab.IsStale,
+		dead:       tab.IsDead,
+		duplicate:  tab.IsDuplicate,
+		githubDone: tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged",
+		pinned:     tab.Pinned,
+	}
+}
+
+// NewStatsTracker counts every tab in data.
+func NewStatsTracker(data *types.SessionData) *StatsTracker {
+	t := &StatsTracker{
+		tabs:    make(map[*types.Tab]tabCounts),
+		windows: make(map[int]int),
+	}
+	for _, tab := range data.AllTabs {
+		t.Update(tab)
+	}
+	t.SetGroups(len(data.Groups))
+	return t
+}
+
+// Update counts a new tab, or recounts a known one after its flags or
+// window changed.
+func (t *StatsTracker) Update(tab *types.Tab) {
+	if old, ok := t.tabs[tab]; ok {
+		t.apply(old, -1)
+	}
+	c := countsFor(tab)
+	t.tabs[tab] = c
+	t.apply(c, 1)
+}
+
+// Remove drops a tab's contribution. Unknown tabs are ignored.
+func (t *StatsTracker) Remove(tab *types.Tab) {
+	if old, ok := t.tabs[tab]; ok {
+		t.apply(old, -1)
+		delete(t.tabs, tab)
+	}
+}
+
+// SetGroups sets the group count, which is not derived from tabs.
+func (t *StatsTracker) SetGroups(n int) {
+	t.stats.TotalGroups = n
+}
+
+// Stats returns the current totals.
+func (t *StatsTracker) Stats() types.Stats {
+	return t.stats
+}
+
+func (t *StatsTracker) apply(c tabCounts, d int) {
+	t.stats.TotalTabs += d
+	t.windows[c.window] += d
+	if t.windows[c.window] == 0 {
+		delete(t.windows, c.window)
+	}
+	t.stats.TotalWindows = len(t.windows)
+	if c.stale {
+		t.stats.StaleTabs += d
+	}
+	if c.dead {
+		t.stats.DeadTabs += d
+	}
+	if c.duplicate {
+		t.stats.DuplicateTabs += d
+	}
+	if c.githubDone {
+		t.stats.GitHubDoneTabs += d
+	}
+	if c.pinned {
+		t.stats.PinnedTabs += d
+	}
+}
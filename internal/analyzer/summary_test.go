@@ -2,6 +2,7 @@ package analyzer
 
 import (
 	"testing"
+	"time"
 
 	"github.com/lotas/tabsordnung/internal/types"
 )
@@ -12,8 +13,8 @@ func TestComputeStats(t *testing.T) {
 			{IsStale: true},
 			{IsDead: true},
 			{IsDuplicate: true},
-			{IsStale: true, IsDead: true},
-			{},
+			{IsStale: true, IsDead: true, WindowID: 1},
+			{WindowID: 1, Pinned: true},
 		},
 		Groups: []*types.TabGroup{
 			{Name: "A"},
@@ -25,6 +26,9 @@ func TestComputeStats(t *testing.T) {
 	if stats.TotalTabs != 5 {
 		t.Errorf("total tabs: got %d, want 5", stats.TotalTabs)
 	}
+	if stats.TotalWindows != 2 {
+		t.Errorf("total windows: got %d, want 2", stats.TotalWindows)
+	}
 	if stats.TotalGroups != 2 {
 		t.Errorf("total groups: got %d, want 2", stats.TotalGroups)
 	}
@@ -37,4 +41,72 @@ func TestComputeStats(t *testing.T) {
 	if stats.DuplicateTabs != 1 {
 		t.Errorf("duplicate: got %d, want 1", stats.DuplicateTabs)
 	}
+	if stats.PinnedTabs != 1 {
+		t.Errorf("pinned: got %d, want 1", stats.PinnedTabs)
+	}
+}
+
+func TestStatsTrackerMatchesComputeStats(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	data := &types.SessionData{
+		AllTabs: []*types.Tab{
+			{BrowserID: 1, URL: "https://a.com", WindowID: 1, LastAccessed: time.Now()},
+			{BrowserID: 2, URL: "https://b.com", WindowID: 1, LastAccessed: old},
+			{BrowserID: 3, URL: "https://github.com/o/r/pull/1", WindowID: 2, LastAccessed: time.Now(), GitHubStatus: "merged"},
+		},
+		Groups: []*types.TabGroup{{ID: "ungrouped"}},
+	}
+	AnalyzeStale(data.AllTabs, 7)
+	AnalyzeDuplicates(data.AllTabs, false)
+	tracker := NewStatsTracker(data)
+
+	check := func(step string) {
+		t.Helper()
+		if got, want := tracker.Stats(), ComputeStats(data); got != want {
+			t.Errorf("%s: incremental %+v, full %+v", step, got, want)
+		}
+	}
+	reanalyze := func() {
+		for _, tab := range AnalyzeStale(data.AllTabs, 7) {
+			tracker.Update(tab)
+		}
+		for _, tab := range AnalyzeDuplicates(data.AllTabs, false) {
+			tracker.Update(tab)
+		}
+	}
+	check("initial")
+
+	// Add a duplicate of a.com in a new window.
+	dup := &types.Tab{BrowserID: 4, URL: "https://a.com/", WindowID: 3, LastAccessed: old}
+	data.AllTabs = append(data.AllTabs, dup)
+	tracker.Update(dup)
+	reanalyze()
+	check("add")
+
+	// Mark a tab dead and move it to another window.
+	data.AllTabs[0].IsDead = true
+	data.AllTabs[0].WindowID = 2
+	tracker.Update(data.AllTabs[0])
+	check("update")
+
+	// Close the only tab in window 3 and the merged PR.
+	tracker.Remove(dup)
+	tracker.Remove(data.AllTabs[2])
+	data.AllTabs = data.AllTabs[:2]
+	check("remove")
+
+	// Close one copy of a duplicate pair; the other is no longer a duplicate.
+	twin := &types.Tab{BrowserID: 5, URL: "https://b.com", WindowID: 1, LastAccessed: old}
+	data.AllTabs = append(data.AllTabs, twin)
+	tracker.Update(twin)
+	reanalyze()
+	check("add twin")
+	tracker.Remove(twin)
+	data.AllTabs = data.AllTabs[:2]
+	reanalyze()
+	check("close twin")
+
+	data.Groups = append(data.Groups, &types.TabGroup{ID: "g1"})
+	tracker.SetGroups(len(data.Groups))
+	check("groups")
 }
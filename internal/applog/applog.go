@@ -12,21 +12,47 @@ import (
 const (
 	maxFileSize   = 5 << 20 // 5 MB
 	maxValueLen   = 200
+	maxDebugLen   = 4000 // debug lines carry whole protocol messages
 	truncSuffix   = "…"
 )
 
+// Level is the severity of a log line. Lines below the configured level are
+// dropped.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelError
+)
+
+var levelNames = map[Level]string{LevelDebug: "DEBUG", LevelInfo: "INFO", LevelError: "ERROR"}
+
+// ParseLevel parses "debug", "info" or "error", ignoring case.
+func ParseLevel(s string) (Level, error) {
+	for l, name := range levelNames {
+		if strings.EqualFold(s, name) {
+			return l, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q (use debug, info or error)", s)
+}
+
 var (
-	mu   sync.Mutex
-	file *os.File
+	mu    sync.Mutex
+	file  *os.File
+	level = LevelInfo
 )
 
-// Init opens the log file for appending. Call once at startup.
-// If the file exceeds 5 MB, it is rotated (renamed to .log.1) before opening.
-// Safe to skip — all log calls become no-ops if not initialized.
-func Init(dir string) error {
-	path := filepath.Join(dir, "tabsordnung.log")
+// FileName is the log file's name when no path is given.
+const FileName = "tabsordnung.log"
 
-	if err := os.MkdirAll(dir, 0o755); err != nil {
+// Open opens the log file at path for appending and drops lines below min.
+// Call once at startup. If the file exceeds 5 MB, it is rotated (renamed to
+// .1) before opening. Safe to skip — all log calls become no-ops if not
+// initialized.
+func Open(path string, min Level) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return err
 	}
 
@@ -42,10 +68,19 @@ func Init(dir string) error {
 
 	mu.Lock()
 	file = f
+	level = min
 	mu.Unlock()
 	return nil
 }
 
+// Enabled reports whether lines at l are written, so callers can skip
+// building expensive fields.
+func Enabled(l Level) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != nil && l >= level
+}
+
 // Close flushes and closes the log file.
 func Close() {
 	mu.Lock()
@@ -56,46 +91,54 @@ func Close() {
 	}
 }
 
+// Debug logs a verbose event line, written only at level debug.
+//
+//	applog.Debug("ws.recv", "type", "tab.moved")
+func Debug(event string, kv ...any) {
+	write(LevelDebug, event, nil, kv)
+}
+
 // Info logs a structured event line.
 //
 //	applog.Info("ws.connected", "remote", addr)
 //	applog.Info("snapshot.created", "rev", 5, "tabs", 42)
 func Info(event string, kv ...any) {
-	write("INFO", event, nil, kv)
+	write(LevelInfo, event, nil, kv)
 }
 
 // Error logs an event with an error.
 //
 //	applog.Error("ws.send", err, "action", "close")
 func Error(event string, err error, kv ...any) {
-	write("ERROR", event, err, kv)
+	write(LevelError, event, err, kv)
 }
 
-func write(level, event string, err error, kv []any) {
-	mu.Lock()
-	f := file
-	mu.Unlock()
-	if f == nil {
+func write(l Level, event string, err error, kv []any) {
+	if !Enabled(l) {
 		return
 	}
 
 	var b strings.Builder
 	b.WriteString(time.Now().UTC().Format("2006-01-02T15:04:05.000Z"))
 	b.WriteByte(' ')
-	b.WriteString(level)
+	b.WriteString(levelNames[l])
 	b.WriteByte(' ')
 	b.WriteString(event)
 
 	if err != nil {
 		b.WriteString(" err=")
-		b.WriteString(quote(err.Error()))
+		b.WriteString(quote(err.Error(), maxValueLen))
 	}
 
+	limit := maxValueLen
+	if l == LevelDebug {
+		limit = maxDebugLen
+	}
 	for i := 0; i+1 < len(kv); i += 2 {
 		b.WriteByte(' ')
 		b.WriteString(fmt.Sprint(kv[i]))
 		b.WriteByte('=')
-		b.WriteString(quote(fmt.Sprint(kv[i+1])))
+		b.WriteString(quote(fmt.Sprint(kv[i+1]), limit))
 	}
 	b.WriteByte('\n')
 
@@ -106,9 +149,9 @@ func write(level, event string, err error, kv []any) {
 	}
 }
 
-func quote(s string) string {
-	if len(s) > maxValueLen {
-		s = s[:maxValueLen] + truncSuffix
+func quote(s string, limit int) string {
+	if len(s) > limit {
+		s = s[:limit] + truncSuffix
 	}
 	if strings.ContainsAny(s, " \t\n\"") {
 		return "\"" + strings.ReplaceAll(s, "\"", "\\\"") + "\""
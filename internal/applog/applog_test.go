@@ -0,0 +1,75 @@
+package applog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLevelFiltering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", FileName)
+	if err := Open(path, LevelInfo); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(Close)
+
+	if Enabled(LevelDebug) || !Enabled(LevelInfo) || !Enabled(LevelError) {
+		t.Error("Enabled doesn't follow the info level")
+	}
+	Debug("ws.recv", "type", "hidden")
+	Info("ws.connected", "remote", "127.0.0.1:5000")
+	Error("ws.send", errors.New("broken pipe"), "action", "close tab")
+	Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), data)
+	}
+	if !strings.Contains(lines[0], " INFO ws.connected remote=127.0.0.1:5000") {
+		t.Errorf("info line = %q", lines[0])
+	}
+	if !strings.Contains(lines[1], ` ERROR ws.send err="broken pipe" action="close tab"`) {
+		t.Errorf("error line = %q", lines[1])
+	}
+}
+
+func TestDebugLevelAndNoFile(t *testing.T) {
+	// Without Open nothing is written, and nothing fails.
+	if Enabled(LevelError) {
+		t.Fatal("logging enabled without a file")
+	}
+	Info("ignored")
+
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := Open(path, LevelDebug); err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	Debug("ws.recv.raw", "msg", strings.Repeat("x", maxValueLen+10))
+	Info("ws.recv", "msg", strings.Repeat("y", maxValueLen+10))
+	Close()
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "DEBUG ws.recv.raw") {
+		t.Fatalf("got:\n%s", data)
+	}
+	// Debug lines keep whole protocol messages; others are cut short.
+	if strings.Contains(lines[0], truncSuffix) || !strings.Contains(lines[1], truncSuffix) {
+		t.Errorf("unexpected truncation:\n%s", data)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	if l, err := ParseLevel("DEBUG"); err != nil || l != LevelDebug {
+		t.Errorf("ParseLevel(DEBUG) = %v, %v", l, err)
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
@@ -0,0 +1,70 @@
+package bugzilla
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// KeyEnv names the environment variable holding an API key sent to every
+// Bugzilla host that has no key of its own.
+const KeyEnv = "BUGZILLA_API_KEY"
+
+// KeysFilePath returns the path to the file mapping Bugzilla hosts to API
+// keys, a JSON object like {"bugzilla.mozilla.org": "key"}.
+func KeysFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tabsordnung", "bugzilla-keys.json")
+}
+
+// LoadKeys reads the host to API key mapping from p. A missing file is not
+// an error.
+func LoadKeys(p string) (map[string]string, error) {
+	if p == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var keys map[string]string
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", p, err)
+	}
+	return keys, nil
+}
+
+// HostKeyEnv returns the per-host key variable, e.g.
+// BUGZILLA_API_KEY_BUGZILLA_MOZILLA_ORG for bugzilla.mozilla.org.
+func HostKeyEnv(host string) string {
+	var b strings.Builder
+	b.WriteString(KeyEnv + "_")
+	for _, r := range strings.ToUpper(host) {
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('_')
+		}
+	}
+	return b.String()
+}
+
+// apiKey picks the key for host: the per-host variable first, then the keys
+// file, then KeyEnv. An empty result means anonymous access.
+func apiKey(host string, keys map[string]string, getenv func(string) string) string {
+	if k := strings.TrimSpace(getenv(HostKeyEnv(host))); k != "" {
+		return k
+	}
+	if k := strings.TrimSpace(keys[host]); k != "" {
+		return k
+	}
+	return strings.TrimSpace(getenv(KeyEnv))
+}
@@ -3,38 +3,56 @@ package bugzilla
 import (
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"os"
 	"time"
 
 	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/notify"
 	"github.com/lotas/tabsordnung/internal/storage"
 )
 
 const refreshCooldown = 10 * time.Minute
 
+// errCodeNotAuthorized is the Bugzilla error code for bugs the caller may
+// not see.
+const errCodeNotAuthorized = 102
+
+// ErrRestricted is returned for bugs the API key (or anonymous access)
+// is not authorized to see, such as security bugs.
+var ErrRestricted = errors.New("not authorized to access bug")
+
 // BugRefreshResult holds data parsed from the Bugzilla REST API.
 type BugRefreshResult struct {
 	Summary, Status, Resolution, AssignedTo string
+	Priority, Severity                      string
+	SeeAlso                                 []string // related URLs, e.g. GitHub PRs
 }
 
 type bugzillaRESTResponse struct {
 	Bugs []struct {
-		ID         int    `json:"id"`
-		Summary    string `json:"summary"`
-		Status     string `json:"status"`
-		Resolution string `json:"resolution"`
-		AssignedTo string `json:"assigned_to"`
+		ID         int      `json:"id"`
+		Summary    string   `json:"summary"`
+		Status     string   `json:"status"`
+		Resolution string   `json:"resolution"`
+		AssignedTo string   `json:"assigned_to"`
+		Priority   string   `json:"priority"`
+		Severity   string   `json:"severity"`
+		SeeAlso    []string `json:"see_also"`
 	} `json:"bugs"`
 	Error   bool   `json:"error"`
+	Code    int    `json:"code"`
 	Message string `json:"message"`
 }
 
 // fetchBugFromBase is the testable core — base is like "https://bugzilla.mozilla.org".
-func fetchBugFromBase(base string, bugID int) (*BugRefreshResult, error) {
+// An empty apiKey fetches anonymously.
+func fetchBugFromBase(base string, bugID int, apiKey string) (*BugRefreshResult, error) {
 	params := url.Values{}
-	params.Set("include_fields", "id,summary,status,resolution,assigned_to")
+	params.Set("include_fields", "id,summary,status,resolution,assigned_to,priority,severity,see_also")
 	apiURL := fmt.Sprintf("%s/rest/bug/%d?%s", base, bugID, params.Encode())
 
 	req, err := http.NewRequest("GET", apiURL, nil)
@@ -42,6 +60,9 @@ func fetchBugFromBase(base string, bugID int) (*BugRefreshResult, error) {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Accept", "application/json")
+	if apiKey != "" {
+		req.Header.Set("X-BUGZILLA-API-KEY", apiKey)
+	}
 
 	client := &http.Client{Timeout: 10 * time.Second}
 	resp, err := client.Do(req)
@@ -50,6 +71,9 @@ func fetchBugFromBase(base string, bugID int) (*BugRefreshResult, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrRestricted
+	}
 	if resp.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("rest status %d", resp.StatusCode)
 	}
@@ -58,6 +82,9 @@ func fetchBugFromBase(base string, bugID int) (*BugRefreshResult, error) {
 		return nil, fmt.Errorf("decode: %w", err)
 	}
 	if bzResp.Error {
+		if bzResp.Code == errCodeNotAuthorized {
+			return nil, ErrRestricted
+		}
 		return nil, fmt.Errorf("bugzilla: %s", bzResp.Message)
 	}
 	if len(bzResp.Bugs) == 0 {
@@ -70,23 +97,44 @@ func fetchBugFromBase(base string, bugID int) (*BugRefreshResult, error) {
 		Status:     b.Status,
 		Resolution: b.Resolution,
 		AssignedTo: b.AssignedTo,
+		Priority:   b.Priority,
+		Severity:   b.Severity,
+		SeeAlso:    b.SeeAlso,
 	}, nil
 }
 
-// FetchBug queries a public Bugzilla REST API. No auth required.
-func FetchBug(host string, bugID int) (*BugRefreshResult, error) {
-	return fetchBugFromBase("https://"+host, bugID)
+// FetchBug queries a Bugzilla REST API, authenticating with apiKey when it
+// is not empty.
+func FetchBug(host string, bugID int, apiKey string) (*BugRefreshResult, error) {
+	return fetchBugFromBase("https://"+host, bugID, apiKey)
 }
 
 // RefreshEntities enriches entities from the REST API.
 // Skips entities refreshed within the cooldown unless force=true.
+// Bugs are fetched anonymously unless an API key is configured for their
+// host; bugs the caller may not see are marked restricted.
 func RefreshEntities(db *sql.DB, entities []storage.BugzillaEntity, force bool) error {
 	now := time.Now()
+	var keys map[string]string
+	keysLoaded := false
 	for _, e := range entities {
 		if !force && e.LastRefreshedAt != nil && now.Sub(*e.LastRefreshedAt) < refreshCooldown {
 			continue
 		}
-		result, err := FetchBug(e.Host, e.BugID)
+		if !keysLoaded {
+			var err error
+			if keys, err = LoadKeys(KeysFilePath()); err != nil {
+				applog.Error("bugzilla.refresh.keys", err)
+			}
+			keysLoaded = true
+		}
+		result, err := FetchBug(e.Host, e.BugID, apiKey(e.Host, keys, os.Getenv))
+		if errors.Is(err, ErrRestricted) {
+			if err := storage.MarkBugzillaEntityRestricted(db, e.ID); err != nil {
+				applog.Error("bugzilla.refresh.update", err, "entity", e.ID)
+			}
+			continue
+		}
 		if err != nil {
 			applog.Error("bugzilla.refresh.fetch", err, "host", e.Host, "bugID", e.BugID)
 			continue
@@ -95,16 +143,30 @@ func RefreshEntities(db *sql.DB, entities []storage.BugzillaEntity, force bool)
 		if oldStatus != "" && oldStatus != result.Status {
 			detail := oldStatus + " -> " + result.Status
 			storage.RecordBugzillaEvent(db, e.ID, "status_changed", nil, nil, detail)
+			newStatus := result.Status
+			if result.Resolution != "" {
+				newStatus += " " + result.Resolution
+			}
+			notify.StateChanged(fmt.Sprintf("%s#%d", e.Host, e.BugID), result.Summary, oldStatus, newStatus)
+		}
+		if e.Priority != "" && e.Priority != result.Priority {
+			detail := e.Priority + " -> " + result.Priority
+			storage.RecordBugzillaEvent(db, e.ID, "priority_changed", nil, nil, detail)
 		}
 		update := storage.BugzillaStatusUpdate{
 			Title:      result.Summary,
 			Status:     result.Status,
 			Resolution: result.Resolution,
 			Assignee:   result.AssignedTo,
+			Priority:   result.Priority,
+			Severity:   result.Severity,
 		}
 		if err := storage.UpdateBugzillaEntityStatus(db, e.ID, update); err != nil {
 			applog.Error("bugzilla.refresh.update", err, "entity", e.ID)
 		}
+		if _, err := storage.LinkBugzillaToGitHub(db, e, result.SeeAlso); err != nil {
+			applog.Error("bugzilla.refresh.link", err, "entity", e.ID)
+		}
 	}
 	return nil
 }
@@ -2,6 +2,7 @@ package bugzilla
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -19,13 +20,15 @@ func TestFetchBugFromBase_ParsesCorrectly(t *testing.T) {
 			"bugs": []map[string]any{{
 				"id": 12345, "summary": "Memory leak in parser",
 				"status": "RESOLVED", "resolution": "FIXED",
+				"priority": "P1", "severity": "S2",
 				"assigned_to": "dev@example.com",
+				"see_also":    []string{"https://github.com/mozilla/gecko-dev/pull/42"},
 			}},
 		})
 	}))
 	defer srv.Close()
 
-	result, err := fetchBugFromBase(srv.URL, 12345)
+	result, err := fetchBugFromBase(srv.URL, 12345, "")
 	if err != nil {
 		t.Fatalf("fetchBugFromBase: %v", err)
 	}
@@ -41,6 +44,12 @@ func TestFetchBugFromBase_ParsesCorrectly(t *testing.T) {
 	if result.AssignedTo != "dev@example.com" {
 		t.Errorf("AssignedTo wrong: %q", result.AssignedTo)
 	}
+	if result.Priority != "P1" || result.Severity != "S2" {
+		t.Errorf("Priority/Severity wrong: %q/%q", result.Priority, result.Severity)
+	}
+	if len(result.SeeAlso) != 1 || result.SeeAlso[0] != "https://github.com/mozilla/gecko-dev/pull/42" {
+		t.Errorf("SeeAlso wrong: %v", result.SeeAlso)
+	}
 }
 
 func TestRefreshEntities_SkipsOnCooldown(t *testing.T) {
@@ -53,3 +62,67 @@ func TestRefreshEntities_SkipsOnCooldown(t *testing.T) {
 		t.Fatalf("expected skip on cooldown, got: %v", err)
 	}
 }
+
+func TestFetchBugFromBase_SendsAPIKey(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("X-BUGZILLA-API-KEY"); got != "secret" {
+			t.Errorf("api key header = %q, want secret", got)
+		}
+		json.NewEncoder(w).Encode(map[string]any{
+			"bugs": []map[string]any{{"id": 1, "summary": "Private bug"}},
+		})
+	}))
+	defer srv.Close()
+
+	result, err := fetchBugFromBase(srv.URL, 1, "secret")
+	if err != nil {
+		t.Fatalf("fetchBugFromBase: %v", err)
+	}
+	if result.Summary != "Private bug" {
+		t.Errorf("Summary wrong: %q", result.Summary)
+	}
+}
+
+func TestFetchBugFromBase_NotAuthorized(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-BUGZILLA-API-KEY") != "" {
+			t.Error("anonymous fetch should not send an api key")
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]any{
+			"error": true, "code": 102,
+			"message": "You are not authorized to access bug #1.",
+		})
+	}))
+	defer srv.Close()
+
+	if _, err := fetchBugFromBase(srv.URL, 1, ""); !errors.Is(err, ErrRestricted) {
+		t.Fatalf("expected ErrRestricted, got %v", err)
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	env := map[string]string{}
+	getenv := func(k string) string { return env[k] }
+	keys := map[string]string{"bugzilla.mozilla.org": "from-file"}
+
+	if got := apiKey("bugzilla.redhat.com", keys, getenv); got != "" {
+		t.Errorf("expected anonymous access by default, got %q", got)
+	}
+	if got := apiKey("bugzilla.mozilla.org", keys, getenv); got != "from-file" {
+		t.Errorf("expected key from file, got %q", got)
+	}
+
+	env[KeyEnv] = "global"
+	if got := apiKey("bugzilla.redhat.com", keys, getenv); got != "global" {
+		t.Errorf("expected global key, got %q", got)
+	}
+	if got := apiKey("bugzilla.mozilla.org", keys, getenv); got != "from-file" {
+		t.Errorf("file key should win over global key, got %q", got)
+	}
+
+	env["BUGZILLA_API_KEY_BUGZILLA_MOZILLA_ORG"] = "per-host"
+	if got := apiKey("bugzilla.mozilla.org", keys, getenv); got != "per-host" {
+		t.Errorf("expected per-host key, got %q", got)
+	}
+}
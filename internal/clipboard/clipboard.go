@@ -0,0 +1,62 @@
+// Package clipboard copies text to the system clipboard using the
+// platform's clipboard command.
+package clipboard
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no clipboard command is installed.
+var ErrUnavailable = errors.New("no clipboard command found (install wl-copy, xclip or xsel)")
+
+type command struct {
+	name string
+	args []string
+}
+
+// pick chooses the clipboard command for goos. On Linux wl-copy is preferred
+// under Wayland, then xclip and xsel.
+func pick(goos string, wayland bool, lookPath func(string) (string, error)) (command, error) {
+	var candidates []command
+	switch goos {
+	case "darwin":
+		candidates = []command{{name: "pbcopy"}}
+	case "windows":
+		candidates = []command{{name: "clip"}}
+	default:
+		if wayland {
+			candidates = append(candidates, command{name: "wl-copy"})
+		}
+		candidates = append(candidates,
+			command{name: "xclip", args: []string{"-selection", "clipboard"}},
+			command{name: "xsel", args: []string{"--clipboard", "--input"}},
+		)
+	}
+	for _, c := range candidates {
+		if _, err := lookPath(c.name); err == nil {
+			return c, nil
+		}
+	}
+	return command{}, ErrUnavailable
+}
+
+// Copy puts text on the system clipboard.
+func Copy(text string) error {
+	c, err := pick(runtime.GOOS, os.Getenv("WAYLAND_DISPLAY") != "", exec.LookPath)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(c.name, c.args...)
+	cmd.Stdin = strings.NewReader(text)
+	// Output is not captured: xclip keeps a child running to serve the
+	// selection, and waiting on its inherited pipes would block.
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s: %w", c.name, err)
+	}
+	return nil
+}
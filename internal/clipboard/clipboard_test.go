@@ -0,0 +1,48 @@
+package clipboard
+
+import (
+	"errors"
+	"testing"
+)
+
+func installed(names ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, n := range names {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestPick(t *testing.T) {
+	tests := []struct {
+		goos    string
+		wayland bool
+		have    []string
+		want    string
+	}{
+		{"darwin", false, []string{"pbcopy"}, "pbcopy"},
+		{"linux", true, []string{"wl-copy", "xclip"}, "wl-copy"},
+		{"linux", false, []string{"wl-copy", "xclip"}, "xclip"},
+		{"linux", true, []string{"xsel"}, "xsel"},
+		{"freebsd", false, []string{"xclip"}, "xclip"},
+	}
+	for _, tt := range tests {
+		c, err := pick(tt.goos, tt.wayland, installed(tt.have...))
+		if err != nil {
+			t.Errorf("%s wayland=%v: %v", tt.goos, tt.wayland, err)
+			continue
+		}
+		if c.name != tt.want {
+			t.Errorf("%s wayland=%v: got %s, want %s", tt.goos, tt.wayland, c.name, tt.want)
+		}
+	}
+}
+
+func TestPickNoneInstalled(t *testing.T) {
+	if _, err := pick("linux", false, installed()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("got %v, want ErrUnavailable", err)
+	}
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type jsonExport struct {
-	Profile    string       `json:"profile"`
+	Profile    string      `json:"profile"`
 	ExportedAt time.Time   `json:"exported_at"`
+	Analyses   []string    `json:"analyses,omitempty"`
 	Groups     []jsonGroup `json:"groups"`
 }
 
@@ -31,15 +32,36 @@ type jsonTab struct {
 	IsStale            bool      `json:"is_stale,omitempty"`
 	IsDead             bool      `json:"is_dead,omitempty"`
 	IsDuplicate        bool      `json:"is_duplicate,omitempty"`
+	IsPlaceholder      bool      `json:"is_placeholder,omitempty"`
 	DeadReason         string    `json:"dead_reason,omitempty"`
 	StaleDays          int       `json:"stale_days,omitempty"`
+	DuplicateInGroup   bool      `json:"duplicate_in_group,omitempty"`
+	DuplicateAcross    bool      `json:"duplicate_across_groups,omitempty"`
+	GitHubStatus       string    `json:"github_status,omitempty"`
+	GitHubChecks       string    `json:"github_checks,omitempty"`
 }
 
+// Names of the analyses JSONWithAnalysis can list as done.
+const (
+	AnalysisStale      = "stale"
+	AnalysisDuplicates = "duplicates"
+	AnalysisDead       = "dead"
+	AnalysisGitHub     = "github"
+)
+
 // JSON formats session data as a JSON document.
 func JSON(data *types.SessionData) (string, error) {
+	return JSONWithAnalysis(data, nil)
+}
+
+// JSONWithAnalysis is like JSON, but also lists the analyses that ran on
+// data, so a reader can tell a tab that passed a check from one that was
+// never checked: an unset is_dead only means "not dead" if "dead" is listed.
+func JSONWithAnalysis(data *types.SessionData, analyses []string) (string, error) {
 	out := jsonExport{
 		Profile:    data.Profile.Name,
 		ExportedAt: time.Now(),
+		Analyses:   analyses,
 		Groups:     make([]jsonGroup, 0, len(data.Groups)),
 	}
 
@@ -50,6 +72,10 @@ func JSON(data *types.SessionData) (string, error) {
 			Tabs:  make([]jsonTab, 0, len(g.Tabs)),
 		}
 		for _, tab := range g.Tabs {
+			var checks string
+			if tab.GitHubTriage != nil {
+				checks = tab.GitHubTriage.ChecksStatus
+			}
 			group.Tabs = append(group.Tabs, jsonTab{
 				Title:              tab.Title,
 				URL:                tab.URL,
@@ -61,8 +87,13 @@ func JSON(data *types.SessionData) (string, error) {
 				IsStale:            tab.IsStale,
 				IsDead:             tab.IsDead,
 				IsDuplicate:        tab.IsDuplicate,
+				IsPlaceholder:      tab.IsPlaceholder,
 				DeadReason:         tab.DeadReason,
 				StaleDays:          tab.StaleDays,
+				DuplicateInGroup:   tab.DuplicateInGroup,
+				DuplicateAcross:    tab.DuplicateAcrossGroups,
+				GitHubStatus:       tab.GitHubStatus,
+				GitHubChecks:       checks,
 			})
 		}
 		out.Groups = append(out.Groups, group)
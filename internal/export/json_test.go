@@ -2,6 +2,7 @@ package export
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -135,6 +136,50 @@ func TestJSON_AnalysisFields(t *testing.T) {
 	}
 }
 
+func TestJSONWithAnalysis(t *testing.T) {
+	now := time.Now()
+	data := &types.SessionData{
+		Profile: types.Profile{Name: "test"},
+		Groups: []*types.TabGroup{
+			{
+				Name: "Work",
+				Tabs: []*types.Tab{
+					{Title: "PR", URL: "https://github.com/o/r/pull/1", LastAccessed: now, GitHubStatus: "merged",
+						GitHubTriage: &types.GitHubTriageInfo{ChecksStatus: "passing"}},
+					{Title: "Copy", URL: "https://go.dev", LastAccessed: now, IsDuplicate: true, DuplicateInGroup: true},
+				},
+			},
+		},
+	}
+
+	result, err := JSONWithAnalysis(data, []string{AnalysisStale, AnalysisGitHub})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var parsed jsonExport
+	if err := json.Unmarshal([]byte(result), &parsed); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(parsed.Analyses) != 2 || parsed.Analyses[0] != "stale" || parsed.Analyses[1] != "github" {
+		t.Errorf("analyses = %v, want [stale github]", parsed.Analyses)
+	}
+	tabs := parsed.Groups[0].Tabs
+	if tabs[0].GitHubStatus != "merged" || tabs[0].GitHubChecks != "passing" {
+		t.Errorf("github status = %q, checks = %q", tabs[0].GitHubStatus, tabs[0].GitHubChecks)
+	}
+	if !tabs[1].DuplicateInGroup || tabs[1].DuplicateAcross {
+		t.Errorf("duplicate_in_group = %v, duplicate_across_groups = %v", tabs[1].DuplicateInGroup, tabs[1].DuplicateAcross)
+	}
+
+	plain, err := JSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(plain, `"analyses"`) {
+		t.Errorf("JSON without analyses lists them:\n%s", plain)
+	}
+}
+
 func TestJSON_EmptySession(t *testing.T) {
 	data := &types.SessionData{
 		Profile: types.Profile{Name: "empty"},
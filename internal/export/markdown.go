@@ -5,11 +5,19 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lotas/tabsordnung/internal/summarize"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
 // Markdown formats session data as a markdown document.
 func Markdown(data *types.SessionData) string {
+	return MarkdownWithSummaries(data, "")
+}
+
+// MarkdownWithSummaries is Markdown with each tab's Ollama summary from
+// summaryDir embedded under its entry. Tabs without a summary are listed
+// as usual. An empty summaryDir disables the lookup.
+func MarkdownWithSummaries(data *types.SessionData, summaryDir string) string {
 	var b strings.Builder
 
 	fmt.Fprintf(&b, "# Firefox Tabs — %s\n", data.Profile.Name)
@@ -29,12 +37,37 @@ func Markdown(data *types.SessionData) string {
 				title = tab.URL
 			}
 			fmt.Fprintf(&b, "- [%s](%s) — %s\n", title, tab.URL, relativeTime(tab.LastAccessed))
+			if summaryDir != "" {
+				writeSummary(&b, summaryDir, tab)
+			}
 		}
 	}
 
 	return b.String()
 }
 
+// writeSummary appends the tab's summary, if any, as an indented block that
+// markdown renders as part of the list item.
+func writeSummary(b *strings.Builder, summaryDir string, tab *types.Tab) {
+	summary, err := summarize.ReadSummary(summarize.SummaryPath(summaryDir, tab.URL, tab.Title))
+	if err != nil {
+		return
+	}
+	summary = strings.TrimSpace(summary)
+	if summary == "" {
+		return
+	}
+	b.WriteString("\n")
+	for _, line := range strings.Split(summary, "\n") {
+		if line == "" {
+			b.WriteString("\n")
+			continue
+		}
+		b.WriteString("  " + line + "\n")
+	}
+	b.WriteString("\n")
+}
+
 func relativeTime(t time.Time) string {
 	d := time.Since(t)
 	switch {
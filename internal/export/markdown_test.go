@@ -1,10 +1,13 @@
 package export
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/lotas/tabsordnung/internal/summarize"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
@@ -143,3 +146,26 @@ func TestMarkdown_SingularTabCount(t *testing.T) {
 		t.Errorf("expected singular 'tab' not 'tabs', got:\n%s", result)
 	}
 }
+
+func TestMarkdownWithSummaries(t *testing.T) {
+	dir := t.TempDir()
+	withSummary := &types.Tab{Title: "Go docs", URL: "https://go.dev/doc", LastAccessed: time.Now()}
+	without := &types.Tab{Title: "Example", URL: "https://example.com", LastAccessed: time.Now()}
+
+	path := summarize.SummaryPath(dir, withSummary.URL, withSummary.Title)
+	os.MkdirAll(filepath.Dir(path), 0o755)
+	os.WriteFile(path, []byte("# Go docs\n\n## Summary\n\nFirst line.\n\nSecond paragraph.\n"), 0o644)
+
+	data := &types.SessionData{
+		Groups: []*types.TabGroup{{Name: "Research", Tabs: []*types.Tab{withSummary, without}}},
+	}
+
+	result := MarkdownWithSummaries(data, dir)
+	want := "- [Go docs](https://go.dev/doc) — just now\n\n  First line.\n\n  Second paragraph.\n\n- [Example](https://example.com) — just now\n"
+	if !strings.Contains(result, want) {
+		t.Errorf("summary not embedded as expected, got:\n%s", result)
+	}
+	if strings.Contains(Markdown(data), "First line.") {
+		t.Error("plain Markdown should not include summaries")
+	}
+}
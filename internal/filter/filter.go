@@ -0,0 +1,161 @@
+// Package filter decides which tabs a filter mode shows. The TUI tree and
+// the CLI --filter flags share it, so both agree on what "stale" or ">30d"
+// means.
+package filter
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lotas/tabsordnung/internal/summarize"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// Filter is a filter mode plus the extra inputs some modes need.
+type Filter struct {
+	Mode       types.FilterMode
+	Container  string // container shown by FilterContainer
+	SummaryDir string // summary directory for FilterHasSummary/FilterNoSummary
+}
+
+// modes lists every filter mode with its CLI name and the short label the
+// TUI shows. The order matches types.FilterMode.
+var modes = []struct {
+	mode  types.FilterMode
+	name  string
+	label string
+}{
+	{types.FilterAll, "all", "all"},
+	{types.FilterStale, "stale", "stale"},
+	{types.FilterDead, "dead", "dead"},
+	{types.FilterDuplicate, "duplicate", "duplicate"},
+	{types.FilterAge7, "age7", ">7d"},
+	{types.FilterAge30, "age30", ">30d"},
+	{types.FilterAge90, "age90", ">90d"},
+	{types.FilterGitHubDone, "github-done", "gh done"},
+	{types.FilterHasSummary, "summarized", "summarized"},
+	{types.FilterNoSummary, "unsummarized", "unsummarized"},
+	{types.FilterGitHubWaiting, "github-waiting", "gh waiting"},
+	{types.FilterDuplicateInGroup, "duplicate-in-group", "dup in group"},
+	{types.FilterContainer, "container", "container"},
+	{types.FilterPlaceholder, "placeholder", "placeholder"},
+	{types.FilterPinned, "pinned", "pinned"},
+}
+
+// Match reports whether tab passes mode. Modes that need more than the tab
+// (container, summaries) behave as with an empty Filter field.
+func Match(tab *types.Tab, mode types.FilterMode) bool {
+	return Filter{Mode: mode}.Match(tab)
+}
+
+// Match reports whether tab passes the filter.
+func (f Filter) Match(tab *types.Tab) bool {
+	switch f.Mode {
+	case types.FilterStale:
+		return tab.IsStale
+	case types.FilterDead:
+		return tab.IsDead
+	case types.FilterDuplicate:
+		return tab.IsDuplicate
+	case types.FilterDuplicateInGroup:
+		return tab.DuplicateInGroup
+	case types.FilterContainer:
+		return tab.Container == f.Container
+	case types.FilterPlaceholder:
+		return tab.IsPlaceholder
+	case types.FilterPinned:
+		return tab.Pinned
+	case types.FilterAge7:
+		return tab.StaleDays > 7
+	case types.FilterAge30:
+		return tab.StaleDays > 30
+	case types.FilterAge90:
+		return tab.StaleDays > 90
+	case types.FilterGitHubDone:
+		return tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged"
+	case types.FilterGitHubWaiting:
+		return tab.GitHubStatus == "open" && tab.GitHubTriage != nil &&
+			(tab.GitHubTriage.ReviewRequested || tab.GitHubTriage.Assigned)
+	case types.FilterHasSummary:
+		return f.hasSummary(tab)
+	case types.FilterNoSummary:
+		return !f.hasSummary(tab)
+	default:
+		return true
+	}
+}
+
+func (f Filter) hasSummary(tab *types.Tab) bool {
+	if f.SummaryDir == "" {
+		return false
+	}
+	_, err := os.Stat(summarize.SummaryPath(f.SummaryDir, tab.URL, tab.Title))
+	return err == nil
+}
+
+// Parse reads a filter from its CLI name ("stale", "age30"), its TUI label
+// (">30d") or "container:NAME".
+func Parse(s string) (Filter, error) {
+	if name, ok := strings.CutPrefix(s, "container:"); ok {
+		if name == "" {
+			return Filter{}, fmt.Errorf("filter %q: missing container name", s)
+		}
+		return Filter{Mode: types.FilterContainer, Container: name}, nil
+	}
+	lower := strings.ToLower(strings.TrimSpace(s))
+	for _, m := range modes {
+		if m.mode == types.FilterContainer {
+			continue
+		}
+		if lower == m.name || lower == m.label {
+			return Filter{Mode: m.mode}, nil
+		}
+	}
+	return Filter{}, fmt.Errorf("unknown filter %q (want one of %s)", s, strings.Join(Names(), ", "))
+}
+
+// Names returns the CLI names of all filters.
+func Names() []string {
+	var names []string
+	for _, m := range modes {
+		if m.mode == types.FilterContainer {
+			names = append(names, "container:NAME")
+			continue
+		}
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// Label returns the short label the TUI shows for mode.
+func Label(mode types.FilterMode) string {
+	for _, m := range modes {
+		if m.mode == mode {
+			return m.label
+		}
+	}
+	return "all"
+}
+
+// Apply returns a copy of data holding only the tabs that pass f, with
+// empty groups dropped. Closed tabs are left out.
+func Apply(data *types.SessionData, f Filter) *types.SessionData {
+	out := &types.SessionData{Profile: data.Profile, ParsedAt: data.ParsedAt}
+	for _, g := range data.Groups {
+		var tabs []*types.Tab
+		for _, tab := range g.Tabs {
+			if f.Match(tab) {
+				tabs = append(tabs, tab)
+			}
+		}
+		if len(tabs) == 0 {
+			continue
+		}
+		fg := *g
+		fg.Tabs = tabs
+		out.Groups = append(out.Groups, &fg)
+		out.AllTabs = append(out.AllTabs, tabs...)
+	}
+	return out
+}
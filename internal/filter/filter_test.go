@@ -0,0 +1,132 @@
+package filter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lotas/tabsordnung/internal/summarize"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestMatch(t *testing.T) {
+	stale := &types.Tab{IsStale: true, StaleDays: 40}
+	fresh := &types.Tab{StaleDays: 1}
+	merged := &types.Tab{GitHubStatus: "merged"}
+	waiting := &types.Tab{GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{ReviewRequested: true}}
+	blank := &types.Tab{URL: "about:blank", IsPlaceholder: true}
+	pinned := &types.Tab{Pinned: true}
+
+	tests := []struct {
+		name string
+		tab  *types.Tab
+		mode types.FilterMode
+		want bool
+	}{
+		{"all", fresh, types.FilterAll, true},
+		{"stale", stale, types.FilterStale, true},
+		{"not stale", fresh, types.FilterStale, false},
+		{"older than 30d", stale, types.FilterAge30, true},
+		{"not older than 90d", stale, types.FilterAge90, false},
+		{"github done", merged, types.FilterGitHubDone, true},
+		{"github waiting", waiting, types.FilterGitHubWaiting, true},
+		{"merged is not waiting", merged, types.FilterGitHubWaiting, false},
+		{"no summary dir", fresh, types.FilterNoSummary, true},
+		{"placeholder", blank, types.FilterPlaceholder, true},
+		{"not placeholder", fresh, types.FilterPlaceholder, false},
+		{"pinned", pinned, types.FilterPinned, true},
+		{"not pinned", fresh, types.FilterPinned, false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.tab, tt.mode); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchContainerAndSummary(t *testing.T) {
+	dir := t.TempDir()
+	tab := &types.Tab{URL: "https://example.com", Title: "Example", Container: "Work"}
+	p := summarize.SummaryPath(dir, tab.URL, tab.Title)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte("# Example"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if !(Filter{Mode: types.FilterContainer, Container: "Work"}).Match(tab) {
+		t.Error("container Work should match")
+	}
+	if (Filter{Mode: types.FilterContainer, Container: "Personal"}).Match(tab) {
+		t.Error("container Personal should not match")
+	}
+	if !(Filter{Mode: types.FilterHasSummary, SummaryDir: dir}).Match(tab) {
+		t.Error("tab with summary file should match summarized")
+	}
+	if (Filter{Mode: types.FilterNoSummary, SummaryDir: dir}).Match(tab) {
+		t.Error("tab with summary file should not match unsummarized")
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Filter
+	}{
+		{"stale", Filter{Mode: types.FilterStale}},
+		{"AGE30", Filter{Mode: types.FilterAge30}},
+		{">90d", Filter{Mode: types.FilterAge90}},
+		{"github-waiting", Filter{Mode: types.FilterGitHubWaiting}},
+		{"placeholder", Filter{Mode: types.FilterPlaceholder}},
+		{"container:Work", Filter{Mode: types.FilterContainer, Container: "Work"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "bogus", "container", "container:"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("Parse(%q): expected error", bad)
+		}
+	}
+}
+
+func TestLabelsCoverAllModes(t *testing.T) {
+	for i, m := range modes {
+		if int(m.mode) != i {
+			t.Errorf("modes[%d] is %v; the table must follow types.FilterMode order", i, m.mode)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	a := &types.Tab{URL: "https://a.com", IsStale: true}
+	b := &types.Tab{URL: "https://b.com"}
+	c := &types.Tab{URL: "https://c.com", IsStale: true}
+	data := &types.SessionData{
+		Groups: []*types.TabGroup{
+			{ID: "g1", Name: "One", Tabs: []*types.Tab{a, b}},
+			{ID: "g2", Name: "Two", Tabs: []*types.Tab{b}},
+			{ID: "g3", Name: "Three", Tabs: []*types.Tab{c}},
+		},
+		AllTabs: []*types.Tab{a, b, c},
+	}
+
+	got := Apply(data, Filter{Mode: types.FilterStale})
+	if len(got.Groups) != 2 || got.Groups[0].Name != "One" || got.Groups[1].Name != "Three" {
+		t.Fatalf("groups = %+v, want One and Three", got.Groups)
+	}
+	if len(got.AllTabs) != 2 {
+		t.Errorf("got %d tabs, want 2", len(got.AllTabs))
+	}
+	if len(data.Groups[0].Tabs) != 2 {
+		t.Error("Apply must not modify the input session")
+	}
+}
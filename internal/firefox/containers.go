@@ -0,0 +1,63 @@
+package firefox
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// builtinContainerNames maps the localization IDs Firefox uses for its
+// default containers, which have no stored name, to their English labels.
+var builtinContainerNames = map[string]string{
+	"userContextPersonal.label": "Personal",
+	"userContextWork.label":     "Work",
+	"userContextBanking.label":  "Banking",
+	"userContextShopping.label": "Shopping",
+}
+
+type rawContainers struct {
+	Identities []struct {
+		UserContextID int    `json:"userContextId"`
+		Name          string `json:"name"`
+		L10nID        string `json:"l10nID"`
+		Public        bool   `json:"public"`
+	} `json:"identities"`
+}
+
+// ParseContainers parses a containers.json file into a map from
+// userContextId to container name. Internal (non-public) identities are skipped.
+func ParseContainers(data []byte) (map[int]string, error) {
+	var raw rawContainers
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse containers JSON: %w", err)
+	}
+	names := make(map[int]string)
+	for _, id := range raw.Identities {
+		if !id.Public {
+			continue
+		}
+		name := id.Name
+		if name == "" {
+			name = builtinContainerNames[id.L10nID]
+		}
+		if name == "" {
+			name = fmt.Sprintf("Container %d", id.UserContextID)
+		}
+		names[id.UserContextID] = name
+	}
+	return names, nil
+}
+
+// ReadContainers reads the container (contextual identity) names of a
+// profile. A profile without containers.json has no containers.
+func ReadContainers(profileDir string) (map[int]string, error) {
+	data, err := os.ReadFile(filepath.Join(profileDir, "containers.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ParseContainers(data)
+}
@@ -0,0 +1,49 @@
+package firefox
+
+import (
+	"testing"
+)
+
+func TestParseContainers(t *testing.T) {
+	data := []byte(`{"version": 5, "identities": [
+		{"userContextId": 1, "public": true, "icon": "fingerprint", "color": "blue", "l10nID": "userContextPersonal.label"},
+		{"userContextId": 2, "public": true, "icon": "briefcase", "color": "orange", "l10nID": "userContextWork.label"},
+		{"userContextId": 6, "public": true, "icon": "circle", "color": "red", "name": "Mozilla"},
+		{"userContextId": 4294967295, "public": false, "icon": "", "color": "", "name": "userContextIdInternal.thumbnail"}
+	]}`)
+
+	names, err := ParseContainers(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[int]string{1: "Personal", 2: "Work", 6: "Mozilla"}
+	if len(names) != len(want) {
+		t.Fatalf("got %d containers, want %d: %v", len(names), len(want), names)
+	}
+	for id, name := range want {
+		if names[id] != name {
+			t.Errorf("container %d = %q, want %q", id, names[id], name)
+		}
+	}
+}
+
+func TestParseSessionWithContainers(t *testing.T) {
+	data := []byte(`{"windows": [{"tabs": [
+		{"entries": [{"url": "https://work.example", "title": "Work"}], "index": 1, "userContextId": 2},
+		{"entries": [{"url": "https://other.example", "title": "Other"}], "index": 1, "userContextId": 9},
+		{"entries": [{"url": "https://plain.example", "title": "Plain"}], "index": 1}
+	]}]}`)
+
+	sd, err := ParseSessionWithContainers(data, map[int]string{2: "Work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sd.AllTabs) != 3 {
+		t.Fatalf("got %d tabs, want 3", len(sd.AllTabs))
+	}
+	for i, want := range []string{"Work", "Container 9", ""} {
+		if got := sd.AllTabs[i].Container; got != want {
+			t.Errorf("tab %d container = %q, want %q", i, got, want)
+		}
+	}
+}
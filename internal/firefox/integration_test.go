@@ -69,7 +69,7 @@ func TestIntegration_FullPipeline(t *testing.T) {
 
 	// Run analyzers
 	analyzer.AnalyzeStale(data.AllTabs, 7)
-	analyzer.AnalyzeDuplicates(data.AllTabs)
+	analyzer.AnalyzeDuplicates(data.AllTabs, false)
 	stats := analyzer.ComputeStats(data)
 
 	// Verify results
@@ -0,0 +1,87 @@
+package firefox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// MergedProfile returns a profile standing for the combination of
+// profiles, named like "Work + Personal".
+func MergedProfile(profiles []types.Profile) types.Profile {
+	names := make([]string, len(profiles))
+	for i, p := range profiles {
+		names[i] = p.Name
+	}
+	return types.Profile{Name: strings.Join(names, " + "), Members: profiles}
+}
+
+// ReadProfiles reads the newest session of each profile and merges them
+// with MergeSessions. A profile that can't be read is skipped and named in
+// the result's Warnings; only if no profile can be read is it an error.
+func ReadProfiles(profiles []types.Profile) (*types.SessionData, error) {
+	var sessions []*types.SessionData
+	var warnings []string
+	var firstErr error
+	for _, p := range profiles {
+		sd, err := ReadSessionFile(p.Path)
+		if err != nil {
+			err = fmt.Errorf("profile %s: %w", p.Name, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			warnings = append(warnings, "skipped "+err.Error())
+			continue
+		}
+		sd.Profile = p
+		sessions = append(sessions, sd)
+	}
+	if len(sessions) == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	out := MergeSessions(sessions)
+	out.Warnings = warnings
+	return out, nil
+}
+
+// MergeSessions combines the sessions of several profiles into one. Each
+// tab is tagged with its profile's name. Group IDs and window IDs are made
+// unique across profiles and group names are prefixed with the profile, so
+// every profile's groups stay together. The result's Profile is left empty.
+func MergeSessions(sessions []*types.SessionData) *types.SessionData {
+	out := &types.SessionData{ParsedAt: time.Now()}
+	windowOffset := 0
+	for _, sd := range sessions {
+		name := sd.Profile.Name
+		maxWindow := -1
+		retag := func(tab *types.Tab) {
+			tab.Profile = name
+			if tab.GroupID != "" {
+				tab.GroupID = name + "/" + tab.GroupID
+			}
+			tab.WindowID += windowOffset
+			maxWindow = max(maxWindow, tab.WindowID)
+		}
+		for _, g := range sd.Groups {
+			g.ID = name + "/" + g.ID
+			g.Name = name + " / " + g.Name
+			out.Groups = append(out.Groups, g)
+		}
+		for _, tab := range sd.AllTabs {
+			retag(tab)
+		}
+		for _, tab := range sd.ClosedTabs {
+			retag(tab)
+		}
+		out.AllTabs = append(out.AllTabs, sd.AllTabs...)
+		out.ClosedTabs = append(out.ClosedTabs, sd.ClosedTabs...)
+		windowOffset = max(windowOffset, maxWindow+1)
+	}
+	sort.SliceStable(out.ClosedTabs, func(i, j int) bool {
+		return out.ClosedTabs[i].ClosedAt.After(out.ClosedTabs[j].ClosedAt)
+	})
+	return out
+}
@@ -0,0 +1,86 @@
+package firefox
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestMergeSessions(t *testing.T) {
+	now := time.Now()
+	workTab := &types.Tab{URL: "https://a.com", GroupID: "g1", WindowID: 0}
+	workLoose := &types.Tab{URL: "https://b.com", WindowID: 1}
+	work := &types.SessionData{
+		Profile: types.Profile{Name: "Work"},
+		Groups: []*types.TabGroup{
+			{ID: "g1", Name: "Research", Tabs: []*types.Tab{workTab}},
+			{ID: "ungrouped", Name: "Ungrouped", Tabs: []*types.Tab{workLoose}},
+		},
+		AllTabs:    []*types.Tab{workTab, workLoose},
+		ClosedTabs: []*types.Tab{{URL: "https://old.com", ClosedAt: now.Add(-time.Hour)}},
+	}
+	homeTab := &types.Tab{URL: "https://a.com", GroupID: "g1", WindowID: 0}
+	home := &types.SessionData{
+		Profile:    types.Profile{Name: "Home"},
+		Groups:     []*types.TabGroup{{ID: "g1", Name: "Reading", Tabs: []*types.Tab{homeTab}}},
+		AllTabs:    []*types.Tab{homeTab},
+		ClosedTabs: []*types.Tab{{URL: "https://new.com", ClosedAt: now}},
+	}
+
+	merged := MergeSessions([]*types.SessionData{work, home})
+
+	if len(merged.AllTabs) != 3 || len(merged.Groups) != 3 {
+		t.Fatalf("got %d tabs in %d groups, want 3 in 3", len(merged.AllTabs), len(merged.Groups))
+	}
+	if merged.Groups[0].ID == merged.Groups[2].ID {
+		t.Errorf("group IDs should be unique across profiles, both are %q", merged.Groups[0].ID)
+	}
+	if merged.Groups[2].Name != "Home / Reading" {
+		t.Errorf("group name = %q, want %q", merged.Groups[2].Name, "Home / Reading")
+	}
+	if homeTab.Profile != "Home" || workTab.Profile != "Work" {
+		t.Errorf("profiles = %q, %q", workTab.Profile, homeTab.Profile)
+	}
+	if homeTab.GroupID != merged.Groups[2].ID {
+		t.Errorf("tab group ID %q does not match its group %q", homeTab.GroupID, merged.Groups[2].ID)
+	}
+	if workLoose.GroupID != "" {
+		t.Errorf("ungrouped tab should keep an empty group ID, got %q", workLoose.GroupID)
+	}
+	if homeTab.WindowID != 2 {
+		t.Errorf("second profile's window = %d, want 2", homeTab.WindowID)
+	}
+	if len(merged.WindowIDs()) != 3 {
+		t.Errorf("expected 3 distinct windows, got %v", merged.WindowIDs())
+	}
+	if merged.ClosedTabs[0].URL != "https://new.com" {
+		t.Errorf("closed tabs should be newest first, got %q", merged.ClosedTabs[0].URL)
+	}
+}
+
+func TestReadProfilesSkipsUnreadable(t *testing.T) {
+	good := t.TempDir()
+	os.WriteFile(filepath.Join(good, "sessionstore.jsonlz4"), []byte(`{"windows": [{"tabs": [
+		{"entries": [{"url": "https://a.com", "title": "A"}], "index": 1, "lastAccessed": 1707654321000}
+	]}]}`), 0644)
+	bad := t.TempDir()
+
+	data, err := ReadProfiles([]types.Profile{{Name: "Work", Path: good}, {Name: "Broken", Path: bad}})
+	if err != nil {
+		t.Fatalf("ReadProfiles: %v", err)
+	}
+	if len(data.AllTabs) != 1 || data.AllTabs[0].Profile != "Work" {
+		t.Errorf("got %d tabs, want Work's one", len(data.AllTabs))
+	}
+	if len(data.Warnings) != 1 || !strings.Contains(data.Warnings[0], "Broken") {
+		t.Errorf("Warnings = %q, want one naming Broken", data.Warnings)
+	}
+
+	if _, err := ReadProfiles([]types.Profile{{Name: "Broken", Path: bad}}); err == nil {
+		t.Error("expected an error when no profile can be read")
+	}
+}
@@ -1,11 +1,13 @@
 package firefox
 
 import (
+	"bytes"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"time"
 
 	"github.com/lotas/tabsordnung/internal/types"
@@ -56,6 +58,8 @@ type rawTab struct {
 	LastAccessed int64      `json:"lastAccessed"`
 	Image        string     `json:"image"`
 	Group        string     `json:"groupId"`
+	UserContext  int        `json:"userContextId"` // 0 = no container
+	Pinned       bool       `json:"pinned"`
 }
 
 type rawGroup struct {
@@ -65,9 +69,17 @@ type rawGroup struct {
 	Collapsed bool   `json:"collapsed"`
 }
 
+// rawClosedTab is an entry of a window's _closedTabs list.
+type rawClosedTab struct {
+	State    rawTab `json:"state"`
+	Title    string `json:"title"`
+	ClosedAt int64  `json:"closedAt"`
+}
+
 type rawWindow struct {
-	Tabs   []rawTab   `json:"tabs"`
-	Groups []rawGroup `json:"groups"`
+	Tabs       []rawTab       `json:"tabs"`
+	Groups     []rawGroup     `json:"groups"`
+	ClosedTabs []rawClosedTab `json:"_closedTabs"`
 }
 
 type rawSession struct {
@@ -76,6 +88,13 @@ type rawSession struct {
 
 // ParseSession parses raw JSON session data into a SessionData structure.
 func ParseSession(data []byte) (*types.SessionData, error) {
+	return ParseSessionWithContainers(data, nil)
+}
+
+// ParseSessionWithContainers is ParseSession with container names (from
+// ReadContainers) used to label tabs opened in a container. Containers
+// missing from the map are labelled "Container <id>".
+func ParseSessionWithContainers(data []byte, containers map[int]string) (*types.SessionData, error) {
 	var raw rawSession
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, fmt.Errorf("parse session JSON: %w", err)
@@ -124,8 +143,15 @@ func ParseSession(data []byte) (*types.SessionData, error) {
 				LastAccessed: time.UnixMilli(rt.LastAccessed),
 				Favicon:      rt.Image,
 				GroupID:      rt.Group,
-				WindowIndex:  winIdx,
+				WindowID:     winIdx,
 				TabIndex:     tabIdx,
+				Pinned:       rt.Pinned,
+			}
+			if rt.UserContext != 0 {
+				tab.Container = containers[rt.UserContext]
+				if tab.Container == "" {
+					tab.Container = fmt.Sprintf("Container %d", rt.UserContext)
+				}
 			}
 
 			sd.AllTabs = append(sd.AllTabs, tab)
@@ -147,31 +173,121 @@ func ParseSession(data []byte) (*types.SessionData, error) {
 		if len(ungrouped.Tabs) > 0 {
 			sd.Groups = append(sd.Groups, ungrouped)
 		}
+
+		for _, ct := range window.ClosedTabs {
+			if len(ct.State.Entries) == 0 {
+				continue
+			}
+			entryIdx := ct.State.Index - 1
+			if entryIdx < 0 || entryIdx >= len(ct.State.Entries) {
+				entryIdx = len(ct.State.Entries) - 1
+			}
+			entry := ct.State.Entries[entryIdx]
+			title := entry.Title
+			if title == "" {
+				title = ct.Title
+			}
+			sd.ClosedTabs = append(sd.ClosedTabs, &types.Tab{
+				URL:          entry.URL,
+				Title:        title,
+				LastAccessed: time.UnixMilli(ct.State.LastAccessed),
+				Favicon:      ct.State.Image,
+				WindowID:     winIdx,
+				ClosedAt:     time.UnixMilli(ct.ClosedAt),
+			})
+		}
 	}
 
+	sort.SliceStable(sd.ClosedTabs, func(i, j int) bool {
+		return sd.ClosedTabs[i].ClosedAt.After(sd.ClosedTabs[j].ClosedAt)
+	})
+
 	return sd, nil
 }
 
-// ReadSessionFile reads and parses a Firefox session recovery file from the given profile directory.
-// It tries recovery.jsonlz4 first (active session), then previous.jsonlz4 (last closed session).
+// sessionCandidates lists the session files Firefox may have written, in
+// preference order for files with the same modification time.
+var sessionCandidates = []string{
+	filepath.Join("sessionstore-backups", "recovery.jsonlz4"),
+	filepath.Join("sessionstore-backups", "recovery.baklz4"),
+	"sessionstore.jsonlz4",
+	filepath.Join("sessionstore-backups", "previous.jsonlz4"),
+}
+
+// SessionFiles returns the session files present in profileDir, newest
+// first. While Firefox runs, recovery.jsonlz4 is rewritten every few seconds
+// and sessionstore.jsonlz4 is only written on shutdown, so the newest file
+// reflects the tabs that are actually open.
+func SessionFiles(profileDir string) []string {
+	type candidate struct {
+		path    string
+		modTime time.Time
+	}
+	var found []candidate
+	for _, name := range sessionCandidates {
+		p := filepath.Join(profileDir, name)
+		info, err := os.Stat(p)
+		if err != nil || info.IsDir() {
+			continue
+		}
+		found = append(found, candidate{p, info.ModTime()})
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].modTime.After(found[j].modTime)
+	})
+	paths := make([]string, len(found))
+	for i, c := range found {
+		paths[i] = c.path
+	}
+	return paths
+}
+
+// ReadSessionFile reads and parses the freshest Firefox session file in the
+// given profile directory. If the newest file cannot be read (Firefox may be
+// halfway through writing it) the next newest is used.
 func ReadSessionFile(profileDir string) (*types.SessionData, error) {
-	backupDir := filepath.Join(profileDir, "sessionstore-backups")
-	var data []byte
-	var err error
-	for _, name := range []string{"recovery.jsonlz4", "previous.jsonlz4"} {
-		data, err = os.ReadFile(filepath.Join(backupDir, name))
+	paths := SessionFiles(profileDir)
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no session file found in %s", profileDir)
+	}
+	var firstErr error
+	for _, p := range paths {
+		sd, err := ReadSession(p, profileDir)
 		if err == nil {
-			break
+			return sd, nil
+		}
+		if firstErr == nil {
+			firstErr = err
 		}
 	}
+	return nil, firstErr
+}
+
+// ReadSession reads and parses the session file at path, which may be
+// mozlz4-compressed or plain JSON. Container names are read from
+// profileDir when it is not empty.
+func ReadSession(path, profileDir string) (*types.SessionData, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("no session file found in %s", backupDir)
+		return nil, fmt.Errorf("read session file: %w", err)
 	}
 
-	decompressed, err := DecompressMozLz4(data)
-	if err != nil {
-		return nil, fmt.Errorf("decompress session file: %w", err)
+	if bytes.HasPrefix(data, mozLz4Magic) {
+		data, err = DecompressMozLz4(data)
+		if err != nil {
+			return nil, fmt.Errorf("decompress %s: %w", filepath.Base(path), err)
+		}
+	}
+
+	var containers map[int]string
+	if profileDir != "" {
+		// Tabs are still useful without container names, so errors are ignored.
+		containers, _ = ReadContainers(profileDir)
 	}
 
-	return ParseSession(decompressed)
+	sd, err := ParseSessionWithContainers(data, containers)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filepath.Base(path), err)
+	}
+	return sd, nil
 }
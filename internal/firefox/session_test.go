@@ -3,7 +3,10 @@ package firefox
 import (
 	"encoding/binary"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/pierrec/lz4/v4"
 )
@@ -173,3 +176,99 @@ func TestParseSession(t *testing.T) {
 		t.Fatalf("expected 2 AllTabs, got %d", len(sd.AllTabs))
 	}
 }
+
+func writeMozLz4(t *testing.T, path, sessionJSON string, modTime time.Time) {
+	t.Helper()
+	src := []byte(sessionJSON)
+	dst := make([]byte, lz4.CompressBlockBound(len(src)))
+	n, err := lz4.CompressBlock(src, dst, nil)
+	if err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	sizeBytes := make([]byte, 4)
+	binary.LittleEndian.PutUint32(sizeBytes, uint32(len(src)))
+	payload := append([]byte("mozLz40\x00"), sizeBytes...)
+	payload = append(payload, dst[:n]...)
+	if err := os.WriteFile(path, payload, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func sessionWithURL(url string) string {
+	return `{"windows":[{"tabs":[{"entries":[{"url":"` + url + `","title":"t"}],"index":1}]}]}`
+}
+
+func TestReadSessionFilePrefersNewest(t *testing.T) {
+	profileDir := t.TempDir()
+	backupDir := filepath.Join(profileDir, "sessionstore-backups")
+	os.MkdirAll(backupDir, 0755)
+
+	now := time.Now()
+	writeMozLz4(t, filepath.Join(profileDir, "sessionstore.jsonlz4"), sessionWithURL("https://shutdown.example"), now.Add(-time.Hour))
+	writeMozLz4(t, filepath.Join(backupDir, "previous.jsonlz4"), sessionWithURL("https://previous.example"), now.Add(-2*time.Hour))
+	writeMozLz4(t, filepath.Join(backupDir, "recovery.jsonlz4"), sessionWithURL("https://recovery.example"), now)
+
+	data, err := ReadSessionFile(profileDir)
+	if err != nil {
+		t.Fatalf("ReadSessionFile: %v", err)
+	}
+	if got := data.AllTabs[0].URL; got != "https://recovery.example" {
+		t.Errorf("expected tabs from recovery.jsonlz4, got %s", got)
+	}
+
+	// A corrupt recovery file falls back to the next newest file.
+	os.WriteFile(filepath.Join(backupDir, "recovery.jsonlz4"), []byte("mozLz40\x00garbage"), 0644)
+	writeMozLz4(t, filepath.Join(backupDir, "recovery.baklz4"), sessionWithURL("https://backup.example"), now.Add(-time.Minute))
+
+	data, err = ReadSessionFile(profileDir)
+	if err != nil {
+		t.Fatalf("ReadSessionFile: %v", err)
+	}
+	if got := data.AllTabs[0].URL; got != "https://backup.example" {
+		t.Errorf("expected tabs from recovery.baklz4, got %s", got)
+	}
+}
+
+func TestReadSessionPlainJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "session.json")
+	os.WriteFile(path, []byte(sessionWithURL("https://plain.example")), 0644)
+
+	data, err := ReadSession(path, "")
+	if err != nil {
+		t.Fatalf("ReadSession: %v", err)
+	}
+	if len(data.AllTabs) != 1 || data.AllTabs[0].URL != "https://plain.example" {
+		t.Errorf("unexpected tabs: %+v", data.AllTabs)
+	}
+}
+
+func TestParseSessionClosedTabs(t *testing.T) {
+	data := []byte(`{"windows":[{
+		"tabs":[{"entries":[{"url":"https://open.example","title":"Open"}],"index":1}],
+		"_closedTabs":[
+			{"title":"Older","closedAt":1700000000000,"state":{"entries":[{"url":"https://older.example","title":"Older"}],"index":1}},
+			{"title":"Newer","closedAt":1700000100000,"state":{"entries":[{"url":"https://a.example"},{"url":"https://newer.example"}],"index":2}}
+		]
+	}]}`)
+
+	sd, err := ParseSession(data)
+	if err != nil {
+		t.Fatalf("ParseSession: %v", err)
+	}
+	if len(sd.AllTabs) != 1 {
+		t.Errorf("closed tabs should not be in AllTabs, got %d tabs", len(sd.AllTabs))
+	}
+	if len(sd.ClosedTabs) != 2 {
+		t.Fatalf("got %d closed tabs, want 2", len(sd.ClosedTabs))
+	}
+	newer := sd.ClosedTabs[0]
+	if newer.URL != "https://newer.example" || newer.Title != "Newer" {
+		t.Errorf("closed tabs should be newest first with the current entry, got %+v", newer)
+	}
+	if newer.ClosedAt.UnixMilli() != 1700000100000 {
+		t.Errorf("ClosedAt = %v", newer.ClosedAt)
+	}
+}
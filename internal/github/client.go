@@ -0,0 +1,27 @@
+package github
+
+import (
+	"net"
+	"net/http"
+	"time"
+)
+
+// HTTPClient is shared by GitHub API requests and the dead-link checker, so
+// checks running side by side reuse connections instead of each dialing
+// their own. It has no overall timeout: callers bound each request with a
+// context deadline, which also lets them cancel in-flight requests.
+var HTTPClient = &http.Client{
+	Transport: &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   5 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	},
+}
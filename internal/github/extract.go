@@ -6,17 +6,23 @@ import (
 	"strconv"
 )
 
-// EntityRef identifies a GitHub PR or issue.
+// EntityRef identifies a GitHub PR, issue or discussion.
 type EntityRef struct {
 	Owner  string
 	Repo   string
 	Number int
-	Kind   string // "pull", "issue", or "" (unknown, from signal subject)
+	Kind   string // "pull", "issue", "discussion", or "" (unknown, from signal subject)
 }
 
 var (
-	// Matches https://github.com/owner/repo/pull/123 or /issues/123
-	urlPattern = regexp.MustCompile(`https?://github\.com/([^/]+)/([^/]+)/(issues|pull)/(\d+)`)
+	// Matches https://github.com/owner/repo/pull/123, /issues/123 or /discussions/123
+	urlPattern = regexp.MustCompile(`https?://github\.com/([^/]+)/([^/]+)/(issues|pull|discussions)/(\d+)`)
+
+	// Matches https://github.com/owner/repo/commit/<sha>
+	commitPattern = regexp.MustCompile(`^https?://github\.com/[^/]+/[^/]+/commit/[0-9a-fA-F]{7,40}`)
+
+	// Matches https://gist.github.com/<user>/<id> or /<id>
+	gistPattern = regexp.MustCompile(`^https?://gist\.github\.com/(?:[^/]+/)?[0-9a-fA-F]{7,}`)
 
 	// Matches [owner/repo] ... (#123) in email subjects
 	subjectPattern = regexp.MustCompile(`\[([a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+)\].*#(\d+)`)
@@ -43,16 +49,55 @@ func ExtractFromURL(rawURL string) *EntityRef {
 	if err != nil {
 		return nil
 	}
-	kind := "issue"
-	if matches[3] == "pull" {
-		kind = "pull"
-	}
 	return &EntityRef{
 		Owner:  matches[1],
 		Repo:   matches[2],
 		Number: num,
-		Kind:   kind,
+		Kind:   kindFromPath(matches[3]),
+	}
+}
+
+// kindFromPath maps the URL path segment before the number to an entity kind.
+func kindFromPath(segment string) string {
+	switch segment {
+	case "pull":
+		return "pull"
+	case "discussions":
+		return "discussion"
+	}
+	return "issue"
+}
+
+// KindPath is the inverse of kindFromPath: the URL path segment for kind.
+func KindPath(kind string) string {
+	switch kind {
+	case "issue":
+		return "issues"
+	case "discussion":
+		return "discussions"
 	}
+	return "pull"
+}
+
+// ClassifyURL names the kind of GitHub page rawURL points at: "pull",
+// "issue" or "discussion" for tracked entities, "commit" or "gist" for pages
+// that are recognized but have no state to track, or "" for anything else.
+func ClassifyURL(rawURL string) string {
+	if ref := ExtractFromURL(rawURL); ref != nil {
+		return ref.Kind
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	clean := u.Scheme + "://" + u.Host + u.Path
+	switch {
+	case commitPattern.MatchString(clean):
+		return "commit"
+	case gistPattern.MatchString(clean):
+		return "gist"
+	}
+	return ""
 }
 
 // ExtractFromSignalText extracts a GitHub entity reference from signal text fields
@@ -115,14 +160,10 @@ func extractURLFromText(text string) *EntityRef {
 	if err != nil {
 		return nil
 	}
-	kind := "issue"
-	if matches[3] == "pull" {
-		kind = "pull"
-	}
 	return &EntityRef{
 		Owner:  matches[1],
 		Repo:   matches[2],
 		Number: num,
-		Kind:   kind,
+		Kind:   kindFromPath(matches[3]),
 	}
 }
@@ -12,6 +12,7 @@ func TestExtractFromURL(t *testing.T) {
 		{"https://github.com/mozilla/gecko-dev/pull/123", &EntityRef{"mozilla", "gecko-dev", 123, "pull"}},
 		{"https://github.com/mozilla/gecko-dev/issues/456", &EntityRef{"mozilla", "gecko-dev", 456, "issue"}},
 		{"https://github.com/org/repo/pull/1#discussion_r123", &EntityRef{"org", "repo", 1, "pull"}},
+		{"https://github.com/org/repo/discussions/9", &EntityRef{"org", "repo", 9, "discussion"}},
 		{"https://mail.google.com/inbox", nil},
 		{"https://github.com/org/repo", nil},
 		{"", nil},
@@ -36,6 +37,29 @@ func TestExtractFromURL(t *testing.T) {
 	}
 }
 
+func TestClassifyURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/org/repo/pull/1", "pull"},
+		{"https://github.com/org/repo/issues/2", "issue"},
+		{"https://github.com/org/repo/discussions/3", "discussion"},
+		{"https://github.com/org/repo/commit/9fceb02d0ae598e95dc970b74767f19372d61af8", "commit"},
+		{"https://github.com/org/repo/commit/9fceb02#diff-abc", "commit"},
+		{"https://gist.github.com/alice/aa5a315d61ae9438b18d", "gist"},
+		{"https://gist.github.com/aa5a315d61ae9438b18d", "gist"},
+		{"https://github.com/org/repo", ""},
+		{"https://github.com/org/repo/commits/main", ""},
+		{"https://example.com/org/repo/commit/9fceb02", ""},
+	}
+	for _, tt := range tests {
+		if got := ClassifyURL(tt.url); got != tt.want {
+			t.Errorf("ClassifyURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
 func TestExtractFromSignalText(t *testing.T) {
 	tests := []struct {
 		title, preview, snippet string
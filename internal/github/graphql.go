@@ -0,0 +1,234 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+)
+
+// GraphQLEndpoint is the GitHub GraphQL API URL. Tests point it at a local server.
+var GraphQLEndpoint = "https://api.github.com/graphql"
+
+// MaxRefsPerQuery bounds how many issues/PRs go into a single GraphQL query,
+// keeping each request well below GitHub's node and complexity limits.
+const MaxRefsPerQuery = 50
+
+// QueryTimeout bounds each GraphQL request made while analyzing tabs. It is
+// set from --gh-timeout.
+var QueryTimeout = 10 * time.Second
+
+const (
+	maxRetries  = 3
+	baseBackoff = 2 * time.Second
+	// maxNetworkRetries is how often a request that failed without a
+	// response (connection reset, timeout) is retried.
+	maxNetworkRetries = 1
+	// maxWait is the longest we sleep for a single rate-limit window. Longer
+	// waits are reported as a RateLimitError instead of blocking the caller.
+	maxWait = 60 * time.Second
+)
+
+// jitter spreads retries of transient failures so concurrent clients don't
+// retry in lockstep: it returns a duration between d and 1.5*d. It is
+// replaced in tests.
+var jitter = func(d time.Duration) time.Duration {
+	return d + rand.N(d/2+1)
+}
+
+// sleep waits for d or until ctx is done. It is replaced in tests.
+var sleep = func(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RateLimitError is returned when GitHub keeps throttling requests after the
+// retries are used up, or asks us to wait longer than we are willing to.
+type RateLimitError struct {
+	Status     int
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("github rate limited (status %d), retry in %s", e.Status, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("github rate limited (status %d)", e.Status)
+}
+
+// IsRateLimited reports whether err is (or wraps) a RateLimitError.
+func IsRateLimited(err error) bool {
+	var rl *RateLimitError
+	return errors.As(err, &rl)
+}
+
+var (
+	throttleMu    sync.Mutex
+	throttleUntil time.Time
+)
+
+// ThrottledUntil returns the time until which GitHub asked us to back off.
+// It is zero or in the past when requests are not being throttled.
+func ThrottledUntil() time.Time {
+	throttleMu.Lock()
+	defer throttleMu.Unlock()
+	return throttleUntil
+}
+
+func setThrottled(until time.Time) {
+	throttleMu.Lock()
+	throttleUntil = until
+	throttleMu.Unlock()
+}
+
+// PostGraphQL sends a GraphQL query and decodes the JSON response into out.
+// It retries on 403/429 (primary and secondary rate limits) and 5xx
+// responses, honouring Retry-After and X-RateLimit-Reset, with exponential
+// backoff otherwise. Requests that fail without a response are retried once.
+// Waits for 5xx and network errors are jittered. Each attempt is bounded by
+// timeout; cancelling ctx aborts the request and any pending backoff.
+func PostGraphQL(ctx context.Context, token, query string, timeout time.Duration, out any) error {
+	body, err := json.Marshal(map[string]string{"query": query})
+	if err != nil {
+		return fmt.Errorf("marshal graphql query: %w", err)
+	}
+
+	if wait := time.Until(ThrottledUntil()); wait > maxWait {
+		return &RateLimitError{Status: http.StatusTooManyRequests, RetryAfter: wait}
+	}
+
+	networkRetries := 0
+	for attempt := 0; ; attempt++ {
+		reqCtx, cancel := context.WithTimeout(ctx, timeout)
+		req, err := http.NewRequestWithContext(reqCtx, "POST", GraphQLEndpoint, bytes.NewReader(body))
+		if err != nil {
+			cancel()
+			return fmt.Errorf("create request: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := HTTPClient.Do(req)
+		if err != nil {
+			cancel()
+			if ctx.Err() != nil || networkRetries >= maxNetworkRetries {
+				return fmt.Errorf("graphql request: %w", err)
+			}
+			networkRetries++
+			wait := jitter(baseBackoff)
+			applog.Info("github.graphql.retry", "error", err.Error(), "wait", wait.String())
+			if err := sleep(ctx, wait); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode == http.StatusOK {
+			setThrottled(time.Time{})
+			if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+				if reset := rateLimitReset(resp.Header); !reset.IsZero() {
+					setThrottled(reset)
+				}
+			}
+			err := json.NewDecoder(resp.Body).Decode(out)
+			resp.Body.Close()
+			cancel()
+			if err != nil {
+				return fmt.Errorf("decode graphql response: %w", err)
+			}
+			return nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		cancel()
+
+		retryable := resp.StatusCode == http.StatusForbidden ||
+			resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= 500
+		if !retryable {
+			return fmt.Errorf("graphql response status: %d", resp.StatusCode)
+		}
+
+		wait := retryWait(resp.Header)
+		// A 403 without any rate-limit headers is a permission problem,
+		// not throttling.
+		if resp.StatusCode == http.StatusForbidden && wait == 0 && resp.Header.Get("X-RateLimit-Remaining") == "" {
+			return fmt.Errorf("graphql response status: %d", resp.StatusCode)
+		}
+		if wait == 0 {
+			wait = baseBackoff << attempt
+			if resp.StatusCode >= 500 {
+				wait = jitter(wait)
+			}
+		}
+		if resp.StatusCode < 500 {
+			setThrottled(time.Now().Add(wait))
+		}
+		if attempt >= maxRetries || wait > maxWait {
+			if resp.StatusCode >= 500 {
+				return fmt.Errorf("graphql response status: %d", resp.StatusCode)
+			}
+			return &RateLimitError{Status: resp.StatusCode, RetryAfter: wait}
+		}
+
+		applog.Info("github.graphql.backoff", "status", resp.StatusCode, "wait", wait.String(), "attempt", attempt+1)
+		if err := sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// retryWait returns how long GitHub asked us to wait, or 0 if the response
+// carries no hint.
+func retryWait(h http.Header) time.Duration {
+	if s := h.Get("Retry-After"); s != "" {
+		if secs, err := strconv.Atoi(s); err == nil && secs >= 0 {
+			return time.Duration(secs) * time.Second
+		}
+	}
+	if h.Get("X-RateLimit-Remaining") == "0" {
+		if reset := rateLimitReset(h); !reset.IsZero() {
+			if d := time.Until(reset); d > 0 {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+func rateLimitReset(h http.Header) time.Time {
+	secs, err := strconv.ParseInt(h.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.Unix(secs, 0)
+}
+
+// ChunkRefs splits refs into consecutive slices of at most n elements.
+func ChunkRefs[T any](refs []T, n int) [][]T {
+	var chunks [][]T
+	for len(refs) > n {
+		chunks = append(chunks, refs[:n])
+		refs = refs[n:]
+	}
+	if len(refs) > 0 {
+		chunks = append(chunks, refs)
+	}
+	return chunks
+}
@@ -0,0 +1,204 @@
+package github
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func withTestEndpoint(t *testing.T, h http.HandlerFunc) *[]time.Duration {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+
+	oldEndpoint, oldSleep, oldJitter := GraphQLEndpoint, sleep, jitter
+	var slept []time.Duration
+	GraphQLEndpoint = srv.URL
+	sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	jitter = func(d time.Duration) time.Duration { return d + time.Millisecond }
+	t.Cleanup(func() {
+		GraphQLEndpoint, sleep, jitter = oldEndpoint, oldSleep, oldJitter
+		setThrottled(time.Time{})
+	})
+	return &slept
+}
+
+func TestPostGraphQLRetriesAfterRateLimit(t *testing.T) {
+	var calls atomic.Int32
+	slept := withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"data":{"viewer":{"login":"octocat"}}}`))
+	})
+
+	var out struct {
+		Data struct {
+			Viewer struct {
+				Login string `json:"login"`
+			} `json:"viewer"`
+		} `json:"data"`
+	}
+	if err := PostGraphQL(context.Background(), "tok", "{ viewer { login } }", time.Second, &out); err != nil {
+		t.Fatalf("PostGraphQL: %v", err)
+	}
+	if out.Data.Viewer.Login != "octocat" {
+		t.Errorf("login = %q, want octocat", out.Data.Viewer.Login)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if len(*slept) != 1 || (*slept)[0] != 3*time.Second {
+		t.Errorf("slept = %v, want [3s]", *slept)
+	}
+	if !ThrottledUntil().IsZero() {
+		t.Error("throttle state should be cleared after a successful request")
+	}
+}
+
+func TestPostGraphQLGivesUpWithRateLimitError(t *testing.T) {
+	var calls atomic.Int32
+	slept := withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.Header().Set("X-RateLimit-Remaining", "100")
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var out map[string]any
+	err := PostGraphQL(context.Background(), "tok", "{}", time.Second, &out)
+	if !IsRateLimited(err) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if calls.Load() != maxRetries+1 {
+		t.Errorf("calls = %d, want %d", calls.Load(), maxRetries+1)
+	}
+	want := []time.Duration{2 * time.Second, 4 * time.Second, 8 * time.Second}
+	if len(*slept) != len(want) {
+		t.Fatalf("slept = %v, want %v", *slept, want)
+	}
+	for i := range want {
+		if (*slept)[i] != want[i] {
+			t.Errorf("slept[%d] = %v, want %v", i, (*slept)[i], want[i])
+		}
+	}
+	if !ThrottledUntil().After(time.Now()) {
+		t.Error("expected throttle state to be set")
+	}
+}
+
+func TestPostGraphQLLongResetIsNotSlept(t *testing.T) {
+	slept := withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", "4102444800") // 2100-01-01
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var out map[string]any
+	if err := PostGraphQL(context.Background(), "tok", "{}", time.Second, &out); !IsRateLimited(err) {
+		t.Fatalf("expected RateLimitError, got %v", err)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("should not sleep past maxWait, slept %v", *slept)
+	}
+}
+
+func TestPostGraphQLForbiddenWithoutRateLimitHeaders(t *testing.T) {
+	withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	var out map[string]any
+	err := PostGraphQL(context.Background(), "tok", "{}", time.Second, &out)
+	if err == nil || IsRateLimited(err) {
+		t.Fatalf("expected a plain status error, got %v", err)
+	}
+}
+
+func TestPostGraphQLRetriesNetworkErrorOnce(t *testing.T) {
+	var calls atomic.Int32
+	slept := withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) <= 2 {
+			// Drop the connection without a response.
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err == nil {
+				conn.Close()
+			}
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	})
+
+	var out map[string]any
+	if err := PostGraphQL(context.Background(), "tok", "{}", time.Second, &out); err == nil {
+		t.Fatal("expected an error after the network retry failed too")
+	}
+	if calls.Load() != 2 {
+		t.Errorf("calls = %d, want 2", calls.Load())
+	}
+	if len(*slept) != 1 || (*slept)[0] != baseBackoff+time.Millisecond {
+		t.Errorf("slept = %v, want [%v]", *slept, baseBackoff+time.Millisecond)
+	}
+}
+
+func TestPostGraphQLJittersServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	slept := withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"data":{}}`))
+	})
+
+	var out map[string]any
+	if err := PostGraphQL(context.Background(), "tok", "{}", time.Second, &out); err != nil {
+		t.Fatalf("PostGraphQL: %v", err)
+	}
+	if len(*slept) != 1 || (*slept)[0] != baseBackoff+time.Millisecond {
+		t.Errorf("slept = %v, want [%v]", *slept, baseBackoff+time.Millisecond)
+	}
+	if !ThrottledUntil().IsZero() {
+		t.Error("server errors should not set the throttle state")
+	}
+}
+
+func TestJitter(t *testing.T) {
+	for range 100 {
+		if d := jitter(2 * time.Second); d < 2*time.Second || d > 3*time.Second {
+			t.Fatalf("jitter(2s) = %v, want between 2s and 3s", d)
+		}
+	}
+}
+
+func TestPostGraphQLCancelled(t *testing.T) {
+	withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{}}`))
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var out map[string]any
+	if err := PostGraphQL(ctx, "tok", "{}", time.Second, &out); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestChunkRefs(t *testing.T) {
+	refs := make([]int, 7)
+	chunks := ChunkRefs(refs, 3)
+	if len(chunks) != 3 || len(chunks[0]) != 3 || len(chunks[2]) != 1 {
+		t.Errorf("unexpected chunks: %v", chunks)
+	}
+	if got := ChunkRefs([]int{}, 3); len(got) != 0 {
+		t.Errorf("expected no chunks for empty input, got %v", got)
+	}
+}
@@ -1,19 +1,21 @@
 package github
 
 import (
-	"bytes"
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"strings"
 	"time"
 
 	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/notify"
 	"github.com/lotas/tabsordnung/internal/storage"
 )
 
-const refreshCooldown = 10 * time.Minute
+// RefreshSince is how recently an entity may have been refreshed before
+// RefreshEntities skips it as fresh. Forced refreshes ignore it.
+var RefreshSince = 10 * time.Minute
 
 // EntityRefreshResult holds parsed GraphQL response data for a single entity.
 type EntityRefreshResult struct {
@@ -122,10 +124,15 @@ func BuildEntityGraphQLQuery(refs []EntityRef) (string, map[string]int) {
 
 		for ii, item := range rg.items {
 			var itemAlias string
-			if item.ref.Kind == "issue" {
+			switch item.ref.Kind {
+			case "issue":
 				itemAlias = fmt.Sprintf("i%d", ii)
 				b.WriteString(fmt.Sprintf(" %s: issue(number: %d) { state title author { login } updatedAt assignees(first: 10) { nodes { login } } }", itemAlias, item.ref.Number))
-			} else {
+			case "discussion":
+				// Discussions have no state or assignees, only a closed flag.
+				itemAlias = fmt.Sprintf("d%d", ii)
+				b.WriteString(fmt.Sprintf(" %s: discussion(number: %d) { closed title author { login } updatedAt }", itemAlias, item.ref.Number))
+			default:
 				itemAlias = fmt.Sprintf("p%d", ii)
 				b.WriteString(fmt.Sprintf(" %s: pullRequest(number: %d) { state title author { login } updatedAt assignees(first: 10) { nodes { login } } reviewDecision statusCheckRollup { state } }", itemAlias, item.ref.Number))
 			}
@@ -139,9 +146,11 @@ func BuildEntityGraphQLQuery(refs []EntityRef) (string, map[string]int) {
 	return b.String(), aliasMap
 }
 
-// refreshItemResponse is the response shape for a single issue or PR from GraphQL.
+// refreshItemResponse is the response shape for a single issue, PR or
+// discussion from GraphQL.
 type refreshItemResponse struct {
 	State  string `json:"state"`
+	Closed *bool  `json:"closed"` // discussions only
 	Title  string `json:"title"`
 	Author *struct {
 		Login string `json:"login"`
@@ -167,22 +176,24 @@ type refreshGraphQLResponse struct {
 }
 
 // RefreshEntities queries the GitHub GraphQL API to enrich entities with current state.
-// It skips entities that were refreshed within the cooldown period (unless force=true).
-// Returns nil without error if token is empty (graceful skip).
-func RefreshEntities(db *sql.DB, entities []storage.GitHubEntity, token string, force bool) error {
+// It skips entities refreshed within RefreshSince (unless force=true) and
+// returns how many it queried; the rest were fresh.
+// Returns 0 without error if token is empty (graceful skip). Cancelling ctx
+// stops after the chunk in flight.
+func RefreshEntities(ctx context.Context, db *sql.DB, entities []storage.GitHubEntity, token string, force bool) (int, error) {
 	if token == "" {
-		return nil
+		return 0, nil
 	}
 	if len(entities) == 0 {
-		return nil
+		return 0, nil
 	}
 
-	// Filter entities by cooldown
+	// Skip entities refreshed recently enough
 	now := time.Now()
 	var filtered []storage.GitHubEntity
 	var filteredRefs []EntityRef
 	for _, e := range entities {
-		if !force && e.LastRefreshedAt != nil && now.Sub(*e.LastRefreshedAt) < refreshCooldown {
+		if !force && e.LastRefreshedAt != nil && now.Sub(*e.LastRefreshedAt) < RefreshSince {
 			continue
 		}
 		filtered = append(filtered, e)
@@ -195,40 +206,45 @@ func RefreshEntities(db *sql.DB, entities []storage.GitHubEntity, token string,
 	}
 
 	if len(filteredRefs) == 0 {
-		return nil
+		return 0, nil
 	}
 
-	applog.Info("github.refresh", "count", len(filteredRefs))
-
-	// Build and execute GraphQL query
-	query, aliasMap := BuildEntityGraphQLQuery(filteredRefs)
-
-	body, err := json.Marshal(map[string]string{"query": query})
-	if err != nil {
-		return fmt.Errorf("marshal graphql query: %w", err)
+	applog.Info("github.refresh", "count", len(filteredRefs), "fresh", len(entities)-len(filteredRefs))
+
+	// Query in chunks so large tab sets stay under GraphQL node limits.
+	updated := 0
+	for start := 0; start < len(filteredRefs); start += MaxRefsPerQuery {
+		end := min(start+MaxRefsPerQuery, len(filteredRefs))
+		n, err := refreshChunk(ctx, db, filtered[start:end], filteredRefs[start:end], token)
+		updated += n
+		if err != nil {
+			applog.Error("github.refresh", err, "updated", updated, "total", len(filteredRefs))
+			return len(filteredRefs), err
+		}
 	}
 
-	req, err := http.NewRequest("POST", "https://api.github.com/graphql", bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
-	}
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("Content-Type", "application/json")
+	applog.Info("github.refresh.done", "updated", updated, "total", len(filteredRefs))
+	return len(filteredRefs), nil
+}
 
-	client := &http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("graphql request: %w", err)
+// RefreshStatus describes the outcome of refreshing refreshed of total
+// entities, e.g. "Refreshed 3 of 120 (rest fresh)".
+func RefreshStatus(refreshed, total int) string {
+	s := fmt.Sprintf("Refreshed %d of %d", refreshed, total)
+	if refreshed < total {
+		s += " (rest fresh)"
 	}
-	defer resp.Body.Close()
+	return s
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("graphql response status: %d", resp.StatusCode)
-	}
+// refreshChunk runs one GraphQL query for refs and stores the results on the
+// matching entities. It returns the number of entities updated.
+func refreshChunk(ctx context.Context, db *sql.DB, filtered []storage.GitHubEntity, refs []EntityRef, token string) (int, error) {
+	query, aliasMap := BuildEntityGraphQLQuery(refs)
 
 	var gqlResp refreshGraphQLResponse
-	if err := json.NewDecoder(resp.Body).Decode(&gqlResp); err != nil {
-		return fmt.Errorf("decode graphql response: %w", err)
+	if err := PostGraphQL(ctx, token, query, 15*time.Second, &gqlResp); err != nil {
+		return 0, err
 	}
 
 	if len(gqlResp.Errors) > 0 {
@@ -254,6 +270,17 @@ func RefreshEntities(db *sql.DB, entities []storage.GitHubEntity, token string,
 				applog.Error("github.refresh.parse", err, "alias", fullAlias)
 				continue
 			}
+			if item.State == "" && item.Closed != nil {
+				item.State = "OPEN"
+				if *item.Closed {
+					item.State = "CLOSED"
+				}
+			}
+			// Deleted or inaccessible items come back as null; keep
+			// what we have rather than blanking the state.
+			if item.State == "" {
+				continue
+			}
 
 			result := EntityRefreshResult{
 				State:     item.State,
@@ -288,14 +315,20 @@ func RefreshEntities(db *sql.DB, entities []storage.GitHubEntity, token string,
 		entity := filtered[idx]
 		update := result.ToStatusUpdate()
 
-		// Detect state change and record event
+		// Detect state change and record event. Compare against the
+		// stored state, which may be newer than the caller's copy.
 		oldState := entity.State
+		if stored, err := storage.GetGitHubEntity(db, entity.Owner, entity.Repo, entity.Number); err == nil && stored != nil {
+			oldState = stored.State
+		}
 		newState := update.State
 		if oldState != "" && oldState != newState {
 			detail := fmt.Sprintf("%s -> %s", oldState, newState)
 			if err := storage.RecordGitHubEvent(db, entity.ID, "status_changed", nil, nil, detail); err != nil {
 				applog.Error("github.refresh.event", err, "entity", entity.ID)
 			}
+			ref := fmt.Sprintf("%s/%s#%d", entity.Owner, entity.Repo, entity.Number)
+			notify.StateChanged(ref, update.Title, oldState, newState)
 		}
 
 		if err := storage.UpdateGitHubEntityStatus(db, entity.ID, update); err != nil {
@@ -303,6 +336,5 @@ func RefreshEntities(db *sql.DB, entities []storage.GitHubEntity, token string,
 		}
 	}
 
-	applog.Info("github.refresh.done", "updated", len(results), "total", len(filteredRefs))
-	return nil
+	return len(results), nil
 }
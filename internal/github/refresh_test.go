@@ -1,9 +1,16 @@
 package github
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/lotas/tabsordnung/internal/storage"
 )
 
 func TestBuildEntityGraphQLQuery(t *testing.T) {
@@ -11,6 +18,7 @@ func TestBuildEntityGraphQLQuery(t *testing.T) {
 		{Owner: "mozilla", Repo: "gecko-dev", Number: 123, Kind: "pull"},
 		{Owner: "mozilla", Repo: "gecko-dev", Number: 456, Kind: "issue"},
 		{Owner: "nickel-chromium", Repo: "tabsordnung", Number: 7, Kind: "pull"},
+		{Owner: "nickel-chromium", Repo: "tabsordnung", Number: 8, Kind: "discussion"},
 	}
 	query, aliasMap := BuildEntityGraphQLQuery(refs)
 
@@ -19,9 +27,12 @@ func TestBuildEntityGraphQLQuery(t *testing.T) {
 		t.Fatal("BuildEntityGraphQLQuery returned empty query")
 	}
 
-	// Verify aliasMap has 3 entries
-	if len(aliasMap) != 3 {
-		t.Errorf("aliasMap has %d entries, want 3", len(aliasMap))
+	// Verify aliasMap has 4 entries
+	if len(aliasMap) != 4 {
+		t.Errorf("aliasMap has %d entries, want 4", len(aliasMap))
+	}
+	if !strings.Contains(query, "discussion(number: 8) { closed") {
+		t.Errorf("query missing discussion(number: 8):\n%s", query)
 	}
 
 	// Verify query contains expected fragments
@@ -222,3 +233,107 @@ func TestToStatusUpdate_BadTimestamp(t *testing.T) {
 		t.Errorf("GHUpdatedAt = %v, want nil for bad timestamp", update.GHUpdatedAt)
 	}
 }
+
+func TestRefreshEntitiesRecordsStatusChange(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+
+	state := "OPEN"
+	withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"data":{"r0":{"p0":{"state":%q,"title":"Fix leak"},"p1":null}}}`, state)
+	})
+
+	id, _, _ := storage.UpsertGitHubEntity(db, "mozilla", "gecko-dev", 42, "pull", "tab")
+	goneID, _, _ := storage.UpsertGitHubEntity(db, "mozilla", "gecko-dev", 43, "pull", "tab")
+	refresh := func() {
+		t.Helper()
+		entities, err := storage.ListGitHubEntities(db, storage.GitHubFilter{})
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		sort.Slice(entities, func(i, j int) bool { return entities[i].Number < entities[j].Number })
+		if _, err := RefreshEntities(context.Background(), db, entities, "tok", true); err != nil {
+			t.Fatalf("RefreshEntities: %v", err)
+		}
+	}
+	statusChanges := func(entityID int64) []string {
+		t.Helper()
+		events, err := storage.ListGitHubEntityEvents(db, entityID)
+		if err != nil {
+			t.Fatalf("events: %v", err)
+		}
+		var details []string
+		for _, ev := range events {
+			if ev.EventType == "status_changed" {
+				details = append(details, ev.Detail)
+			}
+		}
+		return details
+	}
+
+	refresh() // first fetch: "" -> open is not a transition
+	refresh() // unchanged
+	if got := statusChanges(id); len(got) != 0 {
+		t.Fatalf("expected no status_changed events yet, got %v", got)
+	}
+
+	state = "MERGED"
+	refresh()
+	if got := statusChanges(id); len(got) != 1 || got[0] != "open -> merged" {
+		t.Fatalf("expected one open -> merged event, got %v", got)
+	}
+	if got := statusChanges(goneID); len(got) != 0 {
+		t.Errorf("a null item should not record a transition, got %v", got)
+	}
+}
+
+func TestRefreshEntitiesSkipsFresh(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+
+	var queried int
+	withTestEndpoint(t, func(w http.ResponseWriter, r *http.Request) {
+		queried++
+		fmt.Fprint(w, `{"data":{"r0":{"p0":{"state":"OPEN","title":"Stale"}}}}`)
+	})
+
+	fresh := time.Now().Add(-time.Minute)
+	stale := time.Now().Add(-time.Hour)
+	entities := []storage.GitHubEntity{
+		{Owner: "mozilla", Repo: "gecko-dev", Number: 1, Kind: "pull", LastRefreshedAt: &fresh},
+		{Owner: "mozilla", Repo: "gecko-dev", Number: 2, Kind: "pull", LastRefreshedAt: &stale},
+		{Owner: "mozilla", Repo: "gecko-dev", Number: 3, Kind: "pull", LastRefreshedAt: &fresh},
+	}
+	for i := range entities {
+		id, _, _ := storage.UpsertGitHubEntity(db, "mozilla", "gecko-dev", entities[i].Number, "pull", "tab")
+		entities[i].ID = id
+	}
+
+	n, err := RefreshEntities(context.Background(), db, entities, "tok", false)
+	if err != nil {
+		t.Fatalf("RefreshEntities: %v", err)
+	}
+	if n != 1 || queried != 1 {
+		t.Fatalf("refreshed %d in %d queries, want 1 in 1", n, queried)
+	}
+	if got := RefreshStatus(n, len(entities)); got != "Refreshed 1 of 3 (rest fresh)" {
+		t.Errorf("RefreshStatus = %q", got)
+	}
+
+	n, err = RefreshEntities(context.Background(), db, entities[:1], "tok", true)
+	if err != nil {
+		t.Fatalf("forced RefreshEntities: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("forced refresh refreshed %d, want 1", n)
+	}
+	if got := RefreshStatus(n, 1); got != "Refreshed 1 of 1" {
+		t.Errorf("RefreshStatus = %q", got)
+	}
+}
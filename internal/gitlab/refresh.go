@@ -0,0 +1,145 @@
+package gitlab
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/notify"
+	"github.com/lotas/tabsordnung/internal/storage"
+)
+
+const refreshCooldown = 10 * time.Minute
+
+const (
+	// TokenEnv names the environment variable holding a personal access
+	// token with read_api scope.
+	TokenEnv = "GITLAB_TOKEN"
+
+	// HostEnv names a self-managed GitLab host that TokenEnv is also sent
+	// to. The token always goes to gitlab.com; every other host is queried
+	// anonymously so it never leaks to a server found in an arbitrary tab.
+	HostEnv = "GITLAB_HOST"
+)
+
+// ErrNotFound is returned for merge requests and issues that do not exist
+// or that the token (or anonymous access) may not see; GitLab does not tell
+// the two apart.
+var ErrNotFound = errors.New("not found")
+
+// EntityRefreshResult holds data parsed from the GitLab REST API.
+type EntityRefreshResult struct {
+	Title, State, Author string
+	Assignees            []string
+}
+
+type gitlabRESTResponse struct {
+	Title  string `json:"title"`
+	State  string `json:"state"`
+	Author *struct {
+		Username string `json:"username"`
+	} `json:"author"`
+	Assignees []struct {
+		Username string `json:"username"`
+	} `json:"assignees"`
+}
+
+var client = &http.Client{Timeout: 10 * time.Second}
+
+// fetchFromBase is the testable core — base is like "https://gitlab.com".
+// An empty token fetches anonymously.
+func fetchFromBase(base, project, kind string, number int, token string) (*EntityRefreshResult, error) {
+	path := "issues"
+	if kind == "merge_request" {
+		path = "merge_requests"
+	}
+	apiURL := fmt.Sprintf("%s/api/v4/projects/%s/%s/%d", base, url.PathEscape(project), path, number)
+
+	req, err := http.NewRequest("GET", apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("PRIVATE-TOKEN", token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rest request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rest status %d", resp.StatusCode)
+	}
+	var glResp gitlabRESTResponse
+	if err := json.NewDecoder(resp.Body).Decode(&glResp); err != nil {
+		return nil, fmt.Errorf("decode: %w", err)
+	}
+
+	result := &EntityRefreshResult{Title: glResp.Title, State: glResp.State}
+	if glResp.Author != nil {
+		result.Author = glResp.Author.Username
+	}
+	for _, a := range glResp.Assignees {
+		result.Assignees = append(result.Assignees, a.Username)
+	}
+	return result, nil
+}
+
+// FetchEntity queries a GitLab instance's REST API for a merge request or
+// issue, authenticating with token when it is not empty.
+func FetchEntity(host, project, kind string, number int, token string) (*EntityRefreshResult, error) {
+	return fetchFromBase("https://"+host, project, kind, number, token)
+}
+
+// token picks the token for host: TokenEnv for gitlab.com and the HostEnv
+// host, nothing for anyone else.
+func token(host string, getenv func(string) string) string {
+	if host != "gitlab.com" && !strings.EqualFold(host, strings.TrimSpace(getenv(HostEnv))) {
+		return ""
+	}
+	return strings.TrimSpace(getenv(TokenEnv))
+}
+
+// RefreshEntities enriches entities from the REST API.
+// Skips entities refreshed within the cooldown unless force=true.
+func RefreshEntities(db *sql.DB, entities []storage.GitLabEntity, force bool) error {
+	now := time.Now()
+	for _, e := range entities {
+		if !force && e.LastRefreshedAt != nil && now.Sub(*e.LastRefreshedAt) < refreshCooldown {
+			continue
+		}
+		result, err := FetchEntity(e.Host, e.Project, e.Kind, e.Number, token(e.Host, os.Getenv))
+		if err != nil {
+			applog.Error("gitlab.refresh.fetch", err, "host", e.Host, "ref", e.Ref())
+			continue
+		}
+		if e.State != "" && e.State != result.State {
+			detail := e.State + " -> " + result.State
+			storage.RecordGitLabEvent(db, e.ID, "status_changed", nil, nil, detail)
+			notify.StateChanged(e.Host+"/"+e.Ref(), result.Title, e.State, result.State)
+		}
+		update := storage.GitLabStatusUpdate{
+			Title:     result.Title,
+			State:     result.State,
+			Author:    result.Author,
+			Assignees: result.Assignees,
+		}
+		if err := storage.UpdateGitLabEntityStatus(db, e.ID, update); err != nil {
+			applog.Error("gitlab.refresh.update", err, "entity", e.ID)
+		}
+	}
+	return nil
+}
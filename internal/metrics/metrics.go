@@ -0,0 +1,121 @@
+// Package metrics exposes tab and signal counts in the Prometheus text
+// format, so a long-running TUI can be scraped and graphed.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// Exporter holds the latest counts published by the TUI. The zero value is
+// not usable; call New.
+type Exporter struct {
+	mu      sync.Mutex
+	stats   types.Stats
+	signals map[string]int
+	updated time.Time
+}
+
+func New() *Exporter {
+	return &Exporter{signals: make(map[string]int)}
+}
+
+// SetStats records the tab counts of the current session.
+func (e *Exporter) SetStats(s types.Stats) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.stats = s
+	e.updated = time.Now()
+}
+
+// SetSignalCounts records the number of active signals per source, as
+// returned by storage.ActiveSignalCounts.
+func (e *Exporter) SetSignalCounts(counts map[string]int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.signals = maps.Clone(counts)
+	if e.signals == nil {
+		e.signals = make(map[string]int)
+	}
+	e.updated = time.Now()
+}
+
+// gauge is one metric without labels.
+type gauge struct {
+	name, help string
+	value      int
+}
+
+// WriteTo writes all metrics in the Prometheus text exposition format.
+func (e *Exporter) WriteTo(w io.Writer) (int64, error) {
+	e.mu.Lock()
+	s, signals, updated := e.stats, maps.Clone(e.signals), e.updated
+	e.mu.Unlock()
+
+	var b strings.Builder
+	for _, g := range []gauge{
+		{"tabsordnung_windows", "Browser windows in the session.", s.TotalWindows},
+		{"tabsordnung_tabs", "Open tabs.", s.TotalTabs},
+		{"tabsordnung_groups", "Tab groups.", s.TotalGroups},
+		{"tabsordnung_stale_tabs", "Tabs not accessed within --stale-days.", s.StaleTabs},
+		{"tabsordnung_dead_tabs", "Tabs whose URL no longer resolves.", s.DeadTabs},
+		{"tabsordnung_duplicate_tabs", "Tabs open more than once.", s.DuplicateTabs},
+		{"tabsordnung_github_done_tabs", "Tabs of closed or merged GitHub issues and PRs.", s.GitHubDoneTabs},
+		{"tabsordnung_pinned_tabs", "Pinned tabs.", s.PinnedTabs},
+	} {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s gauge\n%s %d\n", g.name, g.help, g.name, g.name, g.value)
+	}
+
+	b.WriteString("# HELP tabsordnung_active_signals Signals neither completed nor snoozed, by source.\n")
+	b.WriteString("# TYPE tabsordnung_active_signals gauge\n")
+	for _, source := range slices.Sorted(maps.Keys(signals)) {
+		fmt.Fprintf(&b, "tabsordnung_active_signals{source=%q} %d\n", source, signals[source])
+	}
+
+	if !updated.IsZero() {
+		b.WriteString("# HELP tabsordnung_last_update_timestamp_seconds When the counts last changed.\n")
+		b.WriteString("# TYPE tabsordnung_last_update_timestamp_seconds gauge\n")
+		fmt.Fprintf(&b, "tabsordnung_last_update_timestamp_seconds %d\n", updated.Unix())
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// Handler serves the metrics on GET.
+func (e *Exporter) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "use GET", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		e.WriteTo(w)
+	})
+}
+
+// Serve serves /metrics on ln until ctx is cancelled.
+func (e *Exporter) Serve(ctx context.Context, ln net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e.Handler())
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
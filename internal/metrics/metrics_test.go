@@ -0,0 +1,96 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestWriteTo(t *testing.T) {
+	e := New()
+	e.SetStats(types.Stats{TotalWindows: 2, TotalTabs: 40, TotalGroups: 5, StaleTabs: 7, DeadTabs: 1, DuplicateTabs: 3, PinnedTabs: 4})
+	e.SetSignalCounts(map[string]int{"slack": 2, "gmail": 5})
+
+	var b strings.Builder
+	if _, err := e.WriteTo(&b); err != nil {
+		t.Fatal(err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		"# TYPE tabsordnung_tabs gauge\ntabsordnung_tabs 40\n",
+		"tabsordnung_windows 2\n",
+		"tabsordnung_stale_tabs 7\n",
+		"tabsordnung_dead_tabs 1\n",
+		"tabsordnung_github_done_tabs 0\n",
+		"tabsordnung_active_signals{source=\"gmail\"} 5\ntabsordnung_active_signals{source=\"slack\"} 2\n",
+		"tabsordnung_last_update_timestamp_seconds ",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteToBeforeUpdate(t *testing.T) {
+	var b strings.Builder
+	New().WriteTo(&b)
+	if !strings.Contains(b.String(), "tabsordnung_tabs 0\n") {
+		t.Errorf("expected zero gauges, got:\n%s", b.String())
+	}
+	if strings.Contains(b.String(), "last_update") {
+		t.Error("timestamp reported before any update")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	e := New()
+	e.SetStats(types.Stats{TotalTabs: 3})
+
+	rec := httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("GET: %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	if !strings.Contains(rec.Body.String(), "tabsordnung_tabs 3\n") {
+		t.Errorf("body:\n%s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	e.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got %d, want 405", rec.Code)
+	}
+}
+
+func TestServe(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	e.SetStats(types.Stats{TotalTabs: 9})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- e.Serve(ctx, ln) }()
+
+	resp, err := http.Get("http://" + ln.Addr().String() + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if !strings.Contains(string(body), "tabsordnung_tabs 9\n") {
+		t.Errorf("body:\n%s", body)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Serve returned %v after cancel", err)
+	}
+}
@@ -0,0 +1,75 @@
+// Package notify shows desktop notifications using the platform's
+// notification command.
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync/atomic"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+)
+
+// ErrUnavailable is returned when no notification command is installed.
+var ErrUnavailable = errors.New("no notification command found (install notify-send)")
+
+var enabled atomic.Bool
+
+// Enable turns on notifications for StateChanged. They are off by default.
+func Enable() { enabled.Store(true) }
+
+type command struct {
+	name string
+	args []string
+}
+
+// build returns the command showing a notification on goos: osascript on
+// macOS, notify-send elsewhere.
+func build(goos, title, body string, lookPath func(string) (string, error)) (command, error) {
+	var c command
+	switch goos {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		c = command{name: "osascript", args: []string{"-e", script}}
+	case "windows":
+		return command{}, ErrUnavailable
+	default:
+		c = command{name: "notify-send", args: []string{"--app-name=tabsordnung", title, body}}
+	}
+	if _, err := lookPath(c.name); err != nil {
+		return command{}, ErrUnavailable
+	}
+	return c, nil
+}
+
+func appleScriptString(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+// Send shows a desktop notification.
+func Send(title, body string) error {
+	c, err := build(runtime.GOOS, title, body, exec.LookPath)
+	if err != nil {
+		return err
+	}
+	if out, err := exec.Command(c.name, c.args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%s: %w: %s", c.name, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// StateChanged notifies that a tracked entity (ref, like "owner/repo#42")
+// moved from one state to another, if notifications are enabled. Failures
+// are logged, not returned.
+func StateChanged(ref, title, from, to string) {
+	if !enabled.Load() {
+		return
+	}
+	if err := Send(fmt.Sprintf("%s: %s → %s", ref, from, to), title); err != nil {
+		applog.Error("notify.send", err, "ref", ref)
+	}
+}
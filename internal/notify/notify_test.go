@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"errors"
+	"testing"
+)
+
+func installed(names ...string) func(string) (string, error) {
+	return func(name string) (string, error) {
+		for _, n := range names {
+			if n == name {
+				return "/usr/bin/" + name, nil
+			}
+		}
+		return "", errors.New("not found")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	c, err := build("linux", "PR merged", "Fix leak", installed("notify-send"))
+	if err != nil {
+		t.Fatalf("linux: %v", err)
+	}
+	if c.name != "notify-send" || c.args[len(c.args)-2] != "PR merged" || c.args[len(c.args)-1] != "Fix leak" {
+		t.Errorf("linux: unexpected command %+v", c)
+	}
+
+	c, err = build("darwin", `Bug "1"`, `a\b`, installed("osascript"))
+	if err != nil {
+		t.Fatalf("darwin: %v", err)
+	}
+	want := `display notification "a\\b" with title "Bug \"1\""`
+	if c.name != "osascript" || len(c.args) != 2 || c.args[1] != want {
+		t.Errorf("darwin: got %+v, want script %s", c, want)
+	}
+}
+
+func TestBuildUnavailable(t *testing.T) {
+	if _, err := build("linux", "t", "b", installed()); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("linux without notify-send: got %v, want ErrUnavailable", err)
+	}
+	if _, err := build("windows", "t", "b", installed("notify-send")); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("windows: got %v, want ErrUnavailable", err)
+	}
+}
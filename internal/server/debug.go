@@ -0,0 +1,53 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Limits for protocol messages written to the debug log.
+const (
+	debugMaxString = 200 // longer strings (page content, summaries) are cut
+	debugMaxItems  = 5   // longer arrays (snapshot tabs) keep this many items
+)
+
+// debugJSON renders a protocol message for the debug log: every field is
+// kept, but long strings and arrays are truncated so a snapshot or page
+// content doesn't flood the log.
+func debugJSON(data []byte) string {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return string(data)
+	}
+	out, err := json.Marshal(truncateJSON(v))
+	if err != nil {
+		return string(data)
+	}
+	return string(out)
+}
+
+func truncateJSON(v any) any {
+	switch v := v.(type) {
+	case string:
+		if len(v) > debugMaxString {
+			return fmt.Sprintf("%s… (%d bytes)", v[:debugMaxString], len(v))
+		}
+		return v
+	case []any:
+		n := min(len(v), debugMaxItems)
+		out := make([]any, 0, n+1)
+		for _, item := range v[:n] {
+			out = append(out, truncateJSON(item))
+		}
+		if len(v) > n {
+			out = append(out, fmt.Sprintf("… %d more", len(v)-n))
+		}
+		return out
+	case map[string]any:
+		for k, item := range v {
+			v[k] = truncateJSON(item)
+		}
+		return v
+	}
+	return v
+}
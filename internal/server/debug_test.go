@@ -0,0 +1,24 @@
+package server
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDebugJSON(t *testing.T) {
+	content := strings.Repeat("x", 500)
+	got := debugJSON([]byte(`{"type":"content","id":"cmd-1","content":"` + content + `","tabIds":[1,2,3,4,5,6,7]}`))
+
+	for _, want := range []string{`"type":"content"`, `"id":"cmd-1"`, `(500 bytes)`, `[1,2,3,4,5,"… 2 more"]`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("debugJSON missing %s:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, content) {
+		t.Error("long content was not truncated")
+	}
+
+	if got := debugJSON([]byte("not json")); got != "not json" {
+		t.Errorf("invalid JSON = %q, want it unchanged", got)
+	}
+}
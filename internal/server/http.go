@@ -0,0 +1,289 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+)
+
+// tabsResponse is the body of GET /tabs.
+type tabsResponse struct {
+	ReceivedAt time.Time   `json:"received_at"`
+	Groups     []httpGroup `json:"groups"`
+	Tabs       []httpTab   `json:"tabs"`
+}
+
+type httpGroup struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Color     string `json:"color,omitempty"`
+	Collapsed bool   `json:"collapsed,omitempty"`
+}
+
+type httpTab struct {
+	ID           int       `json:"id"`
+	URL          string    `json:"url"`
+	Title        string    `json:"title"`
+	WindowID     int       `json:"window_id"`
+	Index        int       `json:"index"`
+	GroupID      string    `json:"group_id,omitempty"`
+	Container    string    `json:"container,omitempty"`
+	Pinned       bool      `json:"pinned,omitempty"`
+	LastAccessed time.Time `json:"last_accessed"`
+}
+
+// rememberSnapshot keeps the latest snapshot for GET /tabs.
+func (s *Server) rememberSnapshot(msg IncomingMsg) {
+	s.mu.Lock()
+	s.snapshot = &msg
+	s.snapshotAt = time.Now()
+	s.mu.Unlock()
+}
+
+// authorized reports whether r carries the server's token as
+// "Authorization: Bearer <token>". Without a token every request is allowed.
+func (s *Server) authorized(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1
+}
+
+var errCrossOrigin = errors.New("cross-origin requests are not allowed")
+
+// sameOriginOnly reports whether r may use the HTTP API. Browsers send an
+// Origin header with cross-origin requests; without a token any web page
+// could otherwise drive the tabs, so only the extension's own origin is
+// accepted. Scripts and curl send no Origin and are let through.
+func sameOriginOnly(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	return origin == "" || strings.HasPrefix(origin, "moz-extension://")
+}
+
+var errForeignHost = errors.New("address the server by IP, localhost or its bind address")
+
+// allowedHost reports whether r is addressed to the server by an IP, a
+// loopback name or the host it binds to. DNS rebinding makes a web page
+// same-origin with the server under the attacker's own hostname, so the
+// browser sends no Origin; the Host header still carries that name.
+func (s *Server) allowedHost(r *http.Request) bool {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimPrefix(host, "["), "]"))
+	return IsLoopback(host) || net.ParseIP(host) != nil ||
+		(s.bind != "" && host == strings.ToLower(s.bind))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+// TabsHandler serves GET /tabs: the tabs and groups of the latest snapshot
+// the extension sent, as JSON.
+func (s *Server) TabsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writeError(w, http.StatusMethodNotAllowed, "use GET")
+			return
+		}
+		if !s.allowedHost(r) {
+			applog.Error("http.host", errForeignHost, "path", r.URL.Path, "host", r.Host)
+			writeError(w, http.StatusForbidden, errForeignHost.Error())
+			return
+		}
+		if !sameOriginOnly(r) {
+			applog.Error("http.origin", errCrossOrigin, "path", r.URL.Path, "origin", r.Header.Get("Origin"))
+			writeError(w, http.StatusForbidden, errCrossOrigin.Error())
+			return
+		}
+		if !s.authorized(r) {
+			applog.Error("http.auth", errBadToken, "path", r.URL.Path, "remote", r.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, errBadToken.Error())
+			return
+		}
+
+		s.mu.Lock()
+		snap, at := s.snapshot, s.snapshotAt
+		s.mu.Unlock()
+		if snap == nil {
+			writeError(w, http.StatusServiceUnavailable, "no snapshot received from the extension yet")
+			return
+		}
+		data, err := ParseSnapshot(*snap)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err.Error())
+			return
+		}
+
+		resp := tabsResponse{
+			ReceivedAt: at,
+			Groups:     make([]httpGroup, 0, len(data.Groups)),
+			Tabs:       make([]httpTab, 0, len(data.AllTabs)),
+		}
+		for _, g := range data.Groups {
+			resp.Groups = append(resp.Groups, httpGroup{
+				ID:        g.ID,
+				Name:      g.Name,
+				Color:     g.Color,
+				Collapsed: g.Collapsed,
+			})
+		}
+		for _, t := range data.AllTabs {
+			resp.Tabs = append(resp.Tabs, httpTab{
+				ID:           t.BrowserID,
+				URL:          t.URL,
+				Title:        t.Title,
+				WindowID:     t.WindowID,
+				Index:        t.TabIndex,
+				GroupID:      t.GroupID,
+				Container:    t.Container,
+				Pinned:       t.Pinned,
+				LastAccessed: t.LastAccessed,
+			})
+		}
+		writeJSON(w, http.StatusOK, resp)
+	})
+}
+
+// defaultCommandTimeout is how long POST /command waits for the extension.
+const defaultCommandTimeout = 10 * time.Second
+
+// httpActions are the commands POST /command forwards to the extension.
+var httpActions = map[string]bool{"close": true, "focus": true, "move": true}
+
+var httpCmdCounter atomic.Int64
+
+// cmdResponse is the body of POST /command: the extension's response to
+// the command with the same ID.
+type cmdResponse struct {
+	ID      string `json:"id"`
+	OK      bool   `json:"ok"`
+	Error   string `json:"error,omitempty"`
+	TabIDs  []int  `json:"tabIds,omitempty"`
+	GroupID int    `json:"groupId,omitempty"`
+}
+
+// deliverResponse hands a command response to the POST /command request
+// waiting for it. It reports whether anyone was waiting.
+func (s *Server) deliverResponse(msg IncomingMsg) bool {
+	if msg.ID == "" || msg.OK == nil {
+		return false
+	}
+	s.mu.Lock()
+	ch, ok := s.pending[msg.ID]
+	delete(s.pending, msg.ID)
+	s.mu.Unlock()
+	if ok {
+		ch <- msg
+	}
+	return ok
+}
+
+// CommandHandler serves POST /command: it forwards an OutgoingMsg with a
+// close, focus or move action to the extension and answers with the
+// extension's response. A missing ID is filled in.
+func (s *Server) CommandHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			writeError(w, http.StatusMethodNotAllowed, "use POST")
+			return
+		}
+		if !s.allowedHost(r) {
+			applog.Error("http.host", errForeignHost, "path", r.URL.Path, "host", r.Host)
+			writeError(w, http.StatusForbidden, errForeignHost.Error())
+			return
+		}
+		if !sameOriginOnly(r) {
+			applog.Error("http.origin", errCrossOrigin, "path", r.URL.Path, "origin", r.Header.Get("Origin"))
+			writeError(w, http.StatusForbidden, errCrossOrigin.Error())
+			return
+		}
+		// A JSON content type can't be sent cross-origin without a CORS
+		// preflight, which this server never answers.
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt != "application/json" {
+			writeError(w, http.StatusUnsupportedMediaType, "use Content-Type: application/json")
+			return
+		}
+		if !s.authorized(r) {
+			applog.Error("http.auth", errBadToken, "path", r.URL.Path, "remote", r.RemoteAddr)
+			writeError(w, http.StatusUnauthorized, errBadToken.Error())
+			return
+		}
+		var msg OutgoingMsg
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid command: "+err.Error())
+			return
+		}
+		if !httpActions[msg.Action] {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("unsupported action %q (use close, focus or move)", msg.Action))
+			return
+		}
+		if !s.Connected() {
+			writeError(w, http.StatusServiceUnavailable, "extension not connected")
+			return
+		}
+		if msg.ID == "" {
+			msg.ID = fmt.Sprintf("http-%d", httpCmdCounter.Add(1))
+		}
+
+		ch := make(chan IncomingMsg, 1)
+		s.mu.Lock()
+		if _, dup := s.pending[msg.ID]; dup {
+			s.mu.Unlock()
+			writeError(w, http.StatusConflict, fmt.Sprintf("command %q is already pending", msg.ID))
+			return
+		}
+		s.pending[msg.ID] = ch
+		s.mu.Unlock()
+		defer func() {
+			s.mu.Lock()
+			delete(s.pending, msg.ID)
+			s.mu.Unlock()
+		}()
+
+		applog.Info("http.command", "action", msg.Action, "id", msg.ID)
+		if err := s.Send(msg); err != nil {
+			writeError(w, http.StatusBadGateway, "send command: "+err.Error())
+			return
+		}
+
+		timer := time.NewTimer(s.commandTimeout)
+		defer timer.Stop()
+		select {
+		case resp := <-ch:
+			out := cmdResponse{
+				ID:      resp.ID,
+				OK:      *resp.OK,
+				Error:   resp.Error,
+				TabIDs:  resp.TabIDs,
+				GroupID: resp.GroupID,
+			}
+			status := http.StatusOK
+			if !out.OK {
+				status = http.StatusBadGateway
+			}
+			writeJSON(w, status, out)
+		case <-timer.C:
+			writeError(w, http.StatusGatewayTimeout, fmt.Sprintf("no response from extension within %s", s.commandTimeout))
+		case <-r.Context().Done():
+		}
+	})
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+func TestTabsHandler(t *testing.T) {
+	srv := New(0)
+	srv.SetToken("s3cret")
+	msgs := srv.Messages()
+
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.Handler())
+	mux.Handle("/tabs", srv.TabsHandler())
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	get := func(token string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest("GET", ts.URL+"/tabs", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("GET /tabs: %v", err)
+		}
+		return resp
+	}
+
+	if resp := get("wrong"); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d, want 401", resp.StatusCode)
+	}
+	if resp := get(""); resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("no token: status %d, want 401", resp.StatusCode)
+	}
+	if resp := get("s3cret"); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("before snapshot: status %d, want 503", resp.StatusCode)
+	}
+	req, _ := http.NewRequest("GET", ts.URL+"/tabs", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	req.Header.Set("Origin", "https://evil.example")
+	if resp, err := http.DefaultClient.Do(req); err != nil {
+		t.Fatal(err)
+	} else if resp.Body.Close(); resp.StatusCode != http.StatusForbidden {
+		t.Errorf("cross-origin GET: status %d, want 403", resp.StatusCode)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	data, _ := json.Marshal(IncomingMsg{Type: "hello", Token: "s3cret"})
+	conn.Write(ctx, websocket.MessageText, data)
+	data, _ = json.Marshal(IncomingMsg{
+		Type:   "snapshot",
+		Tabs:   json.RawMessage(`[{"id":7,"url":"https://example.com","title":"Example","groupId":3,"windowId":1,"index":2,"pinned":true}]`),
+		Groups: json.RawMessage(`[{"id":3,"title":"Work","color":"blue"}]`),
+	})
+	conn.Write(ctx, websocket.MessageText, data)
+	select {
+	case <-msgs:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for snapshot")
+	}
+
+	resp := get("s3cret")
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d, want 200", resp.StatusCode)
+	}
+	var got tabsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(got.Groups) != 1 || got.Groups[0].Name != "Work" || got.Groups[0].ID != "3" {
+		t.Errorf("groups = %+v", got.Groups)
+	}
+	if len(got.Tabs) != 1 {
+		t.Fatalf("tabs = %+v", got.Tabs)
+	}
+	tab := got.Tabs[0]
+	if tab.ID != 7 || tab.URL != "https://example.com" || tab.GroupID != "3" || tab.Index != 2 || !tab.Pinned {
+		t.Errorf("tab = %+v", tab)
+	}
+	if got.ReceivedAt.IsZero() {
+		t.Error("received_at not set")
+	}
+}
+
+func TestCommandHandler(t *testing.T) {
+	srv := New(0)
+	srv.commandTimeout = 200 * time.Millisecond
+
+	mux := http.NewServeMux()
+	mux.Handle("/", srv.Handler())
+	mux.Handle("/command", srv.CommandHandler())
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	post := func(body string) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(ts.URL+"/command", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /command: %v", err)
+		}
+		defer resp.Body.Close()
+		var out map[string]any
+		json.NewDecoder(resp.Body).Decode(&out)
+		return resp, out
+	}
+
+	var out map[string]any
+	if resp, _ := post(`{"action":"focus","tabId":1}`); resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("without extension: status %d, want 503", resp.StatusCode)
+	}
+	if resp, _ := post(`{"action":"get-content","tabId":1}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("unsupported action: status %d, want 400", resp.StatusCode)
+	}
+
+	// Web pages must not be able to drive the tabs: a "simple" text/plain
+	// POST needs no CORS preflight, and a page's Origin is not the
+	// extension's.
+	resp, err := http.Post(ts.URL+"/command", "text/plain", strings.NewReader(`{"action":"close","tabIds":[1]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Errorf("text/plain: status %d, want 415", resp.StatusCode)
+	}
+	for origin, want := range map[string]int{
+		"https://evil.example":   http.StatusForbidden,
+		"moz-extension://abc-12": http.StatusServiceUnavailable,
+	} {
+		req, _ := http.NewRequest("POST", ts.URL+"/command", strings.NewReader(`{"action":"close","tabIds":[1]}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Origin", origin)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("Origin %s: status %d, want %d", origin, resp.StatusCode, want)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	time.Sleep(50 * time.Millisecond)
+
+	// Fake extension: answer focus commands, ignore everything else.
+	go func() {
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var cmd OutgoingMsg
+			json.Unmarshal(data, &cmd)
+			if cmd.Action != "focus" {
+				continue
+			}
+			ok := cmd.TabID == 7
+			resp := IncomingMsg{Type: "response", ID: cmd.ID, OK: &ok}
+			if !ok {
+				resp.Error = "no such tab"
+			}
+			data, _ = json.Marshal(resp)
+			conn.Write(ctx, websocket.MessageText, data)
+		}
+	}()
+
+	resp, out = post(`{"id":"mine","action":"focus","tabId":7}`)
+	if resp.StatusCode != http.StatusOK || out["id"] != "mine" || out["ok"] != true {
+		t.Errorf("focus: status %d, body %v", resp.StatusCode, out)
+	}
+	resp, out = post(`{"action":"focus","tabId":8}`)
+	if resp.StatusCode != http.StatusBadGateway || out["ok"] != false || out["error"] != "no such tab" {
+		t.Errorf("failed focus: status %d, body %v", resp.StatusCode, out)
+	}
+	if id, _ := out["id"].(string); !strings.HasPrefix(id, "http-") {
+		t.Errorf("generated id = %q", id)
+	}
+	if resp, _ := post(`{"action":"close","tabIds":[7]}`); resp.StatusCode != http.StatusGatewayTimeout {
+		t.Errorf("unanswered: status %d, want 504", resp.StatusCode)
+	}
+
+	select {
+	case msg := <-srv.Messages():
+		t.Errorf("response leaked to the TUI: %+v", msg)
+	default:
+	}
+}
+
+// TestForeignHostRejected covers DNS rebinding: a page served from
+// attacker.example that resolves to 127.0.0.1 is same-origin with the
+// server and sends no Origin, but its Host names the attacker.
+func TestForeignHostRejected(t *testing.T) {
+	srv := New(0)
+	for _, tc := range []struct {
+		name    string
+		handler http.Handler
+		method  string
+		host    string
+		want    int
+	}{
+		{"tabs", srv.TabsHandler(), "GET", "attacker.example:19191", http.StatusForbidden},
+		{"command", srv.CommandHandler(), "POST", "attacker.example:19191", http.StatusForbidden},
+		{"websocket", srv.Handler(), "GET", "attacker.example:19191", http.StatusForbidden},
+		{"localhost", srv.TabsHandler(), "GET", "localhost:19191", http.StatusServiceUnavailable},
+		{"IPv4", srv.TabsHandler(), "GET", "127.0.0.1:19191", http.StatusServiceUnavailable},
+		{"IPv6", srv.TabsHandler(), "GET", "[::1]:19191", http.StatusServiceUnavailable},
+	} {
+		req := httptest.NewRequest(tc.method, "/", strings.NewReader(`{"action":"focus","tabId":1}`))
+		req.Host = tc.host
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		tc.handler.ServeHTTP(rec, req)
+		if rec.Code != tc.want {
+			t.Errorf("%s with Host %s: status %d, want %d", tc.name, tc.host, rec.Code, tc.want)
+		}
+	}
+}
@@ -18,6 +18,18 @@ type wireTab struct {
 	WindowID     int    `json:"windowId"`
 	Index        int    `json:"index"`
 	FavIconURL   string `json:"favIconUrl"`
+	Container    string `json:"container"` // container name; "" for the default container
+	Pinned       bool   `json:"pinned"`
+}
+
+// wireClosedTab is a recently closed tab from browser.sessions.
+type wireClosedTab struct {
+	SessionID  string `json:"sessionId"`
+	URL        string `json:"url"`
+	Title      string `json:"title"`
+	ClosedAt   int64  `json:"closedAt"`
+	WindowID   int    `json:"windowId"`
+	FavIconURL string `json:"favIconUrl"`
 }
 
 type wireGroup struct {
@@ -62,8 +74,10 @@ func ParseSnapshot(msg IncomingMsg) (*types.SessionData, error) {
 			LastAccessed: time.UnixMilli(wt.LastAccessed),
 			GroupID:      strconv.Itoa(wt.GroupID),
 			Favicon:      wt.FavIconURL,
-			WindowIndex:  wt.WindowID,
+			WindowID:     wt.WindowID,
 			TabIndex:     wt.Index,
+			Container:    wt.Container,
+			Pinned:       wt.Pinned,
 		}
 		allTabs = append(allTabs, tab)
 
@@ -79,10 +93,29 @@ func ParseSnapshot(msg IncomingMsg) (*types.SessionData, error) {
 		result = append(result, ungrouped)
 	}
 
+	var closedTabs []*types.Tab
+	if len(msg.ClosedTabs) > 0 {
+		var closed []wireClosedTab
+		if err := json.Unmarshal(msg.ClosedTabs, &closed); err != nil {
+			return nil, fmt.Errorf("parse closed tabs: %w", err)
+		}
+		for _, ct := range closed {
+			closedTabs = append(closedTabs, &types.Tab{
+				URL:       ct.URL,
+				Title:     ct.Title,
+				Favicon:   ct.FavIconURL,
+				WindowID:  ct.WindowID,
+				ClosedAt:  time.UnixMilli(ct.ClosedAt),
+				SessionID: ct.SessionID,
+			})
+		}
+	}
+
 	return &types.SessionData{
-		Groups:   result,
-		AllTabs:  allTabs,
-		ParsedAt: time.Now(),
+		Groups:     result,
+		AllTabs:    allTabs,
+		ParsedAt:   time.Now(),
+		ClosedTabs: closedTabs,
 	}, nil
 }
 
@@ -103,7 +136,9 @@ func ParseTab(raw json.RawMessage) (*types.Tab, error) {
 		LastAccessed: time.UnixMilli(wt.LastAccessed),
 		GroupID:      groupID,
 		Favicon:      wt.FavIconURL,
-		WindowIndex:  wt.WindowID,
+		WindowID:     wt.WindowID,
 		TabIndex:     wt.Index,
+		Container:    wt.Container,
+		Pinned:       wt.Pinned,
 	}, nil
 }
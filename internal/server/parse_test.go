@@ -79,3 +79,53 @@ func TestParseSnapshotNoGroups(t *testing.T) {
 		t.Errorf("expected single Ungrouped group, got %v", data.Groups)
 	}
 }
+
+func TestParseTabContainer(t *testing.T) {
+	tab, err := ParseTab(json.RawMessage(`{"id": 3, "url": "https://bank.example", "groupId": -1, "container": "Banking"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tab.Container != "Banking" {
+		t.Errorf("Container = %q, want Banking", tab.Container)
+	}
+}
+
+func TestParseTabPinned(t *testing.T) {
+	tab, err := ParseTab(json.RawMessage(`{"id": 4, "url": "https://mail.example", "groupId": -1, "pinned": true}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tab.Pinned {
+		t.Error("expected tab to be pinned")
+	}
+}
+
+func TestParseSnapshotClosedTabs(t *testing.T) {
+	snapshot := `{
+		"type": "snapshot",
+		"tabs": [],
+		"groups": [],
+		"closedTabs": [
+			{"sessionId": "abc", "url": "https://closed.example", "title": "Closed", "closedAt": 1700000000000, "windowId": 1}
+		]
+	}`
+
+	var msg IncomingMsg
+	if err := json.Unmarshal([]byte(snapshot), &msg); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ParseSnapshot(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.ClosedTabs) != 1 {
+		t.Fatalf("got %d closed tabs, want 1", len(data.ClosedTabs))
+	}
+	ct := data.ClosedTabs[0]
+	if ct.SessionID != "abc" || ct.URL != "https://closed.example" || ct.ClosedAt.UnixMilli() != 1700000000000 {
+		t.Errorf("unexpected closed tab: %+v", ct)
+	}
+	if len(data.AllTabs) != 0 {
+		t.Errorf("closed tabs should not be in AllTabs, got %d", len(data.AllTabs))
+	}
+}
@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+	"nhooyr.io/websocket"
+)
+
+// Version is a WebSocket protocol version. Peers with the same major
+// version can talk; the minor version counts backwards-compatible additions.
+type Version struct {
+	Major, Minor int
+}
+
+// ProtocolVersion is the protocol this server speaks. Bump Minor for
+// additions old extensions can ignore and Major for breaking changes.
+var ProtocolVersion = Version{Major: 1, Minor: 0}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d", v.Major, v.Minor)
+}
+
+// IsZero reports whether v is unknown, e.g. because the extension predates
+// protocol versions.
+func (v Version) IsZero() bool {
+	return v == Version{}
+}
+
+// ParseVersion parses "MAJOR.MINOR".
+func ParseVersion(s string) (Version, error) {
+	major, minor, ok := strings.Cut(s, ".")
+	if !ok {
+		return Version{}, fmt.Errorf("invalid protocol version %q", s)
+	}
+	var v Version
+	var err1, err2 error
+	v.Major, err1 = strconv.Atoi(major)
+	v.Minor, err2 = strconv.Atoi(minor)
+	if err1 != nil || err2 != nil || v.Major < 0 || v.Minor < 0 {
+		return Version{}, fmt.Errorf("invalid protocol version %q", s)
+	}
+	return v, nil
+}
+
+var errUnsupportedProtocol = errors.New("unsupported protocol version")
+
+// negotiate picks the protocol version for a connection from the
+// extension's "hello". Extensions that don't send a version are accepted
+// for compatibility and get the zero Version; a different major version is
+// refused. Otherwise both sides use the lower minor version.
+func negotiate(hello IncomingMsg) (Version, error) {
+	if hello.Protocol == "" {
+		applog.Info("ws.protocol.unversioned", "server", ProtocolVersion)
+		return Version{}, nil
+	}
+	theirs, err := ParseVersion(hello.Protocol)
+	if err != nil {
+		return Version{}, err
+	}
+	if theirs.Major != ProtocolVersion.Major {
+		return Version{}, fmt.Errorf("%w: extension speaks %s, tabsordnung %s", errUnsupportedProtocol, theirs, ProtocolVersion)
+	}
+	if theirs != ProtocolVersion {
+		applog.Info("ws.protocol.mismatch", "extension", theirs, "server", ProtocolVersion)
+	}
+	v := theirs
+	if ProtocolVersion.Minor < v.Minor {
+		v.Minor = ProtocolVersion.Minor
+	}
+	return v, nil
+}
+
+// greet answers the extension's "hello" with the server's protocol version
+// and records the version negotiated for conn. A refused version closes the
+// connection.
+func (s *Server) greet(ctx context.Context, conn *websocket.Conn, hello IncomingMsg) error {
+	v, err := negotiate(hello)
+	if err != nil {
+		applog.Error("ws.protocol", err)
+		conn.Close(websocket.StatusProtocolError, err.Error())
+		return err
+	}
+	s.mu.Lock()
+	s.protocols[conn] = v
+	s.mu.Unlock()
+	data, err := json.Marshal(OutgoingMsg{Action: "hello", Protocol: ProtocolVersion.String()})
+	if err != nil {
+		return err
+	}
+	return conn.Write(ctx, websocket.MessageText, data)
+}
+
+// Protocol returns the protocol version negotiated with the connected
+// extension, so features can be gated on it. It is zero when no extension is
+// connected or the extension did not report a version.
+func (s *Server) Protocol() Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return Version{}
+	}
+	return s.protocols[s.conn]
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"nhooyr.io/websocket"
+)
+
+func TestParseVersion(t *testing.T) {
+	v, err := ParseVersion("1.2")
+	if err != nil || v != (Version{1, 2}) {
+		t.Fatalf("ParseVersion(1.2) = %v, %v", v, err)
+	}
+	if v.String() != "1.2" {
+		t.Errorf("String() = %q", v.String())
+	}
+	for _, bad := range []string{"", "1", "a.b", "1.-1", "1.2.3"} {
+		if _, err := ParseVersion(bad); err == nil {
+			t.Errorf("ParseVersion(%q) succeeded", bad)
+		}
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	orig := ProtocolVersion
+	defer func() { ProtocolVersion = orig }()
+	ProtocolVersion = Version{Major: 1, Minor: 2}
+
+	tests := []struct {
+		theirs  string
+		want    Version
+		refused bool
+	}{
+		{theirs: "1.2", want: Version{1, 2}},
+		{theirs: "1.5", want: Version{1, 2}},
+		{theirs: "1.0", want: Version{1, 0}},
+		{theirs: "", want: Version{}},
+		{theirs: "2.0", refused: true},
+		{theirs: "0.9", refused: true},
+	}
+	for _, tt := range tests {
+		got, err := negotiate(IncomingMsg{Type: "hello", Protocol: tt.theirs})
+		if tt.refused {
+			if !errors.Is(err, errUnsupportedProtocol) {
+				t.Errorf("negotiate(%q): err = %v, want unsupported", tt.theirs, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("negotiate(%q) = %v, %v; want %v", tt.theirs, got, err, tt.want)
+		}
+	}
+}
+
+func TestServerHelloHandshake(t *testing.T) {
+	srv := New(0)
+	srv.SetToken("s3cret")
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	// A matching version is answered with the server's version and recorded.
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	data, _ := json.Marshal(IncomingMsg{Type: "hello", Token: "s3cret", Protocol: ProtocolVersion.String()})
+	conn.Write(ctx, websocket.MessageText, data)
+	_, data, err = conn.Read(ctx)
+	if err != nil {
+		t.Fatalf("read hello reply: %v", err)
+	}
+	var reply OutgoingMsg
+	if err := json.Unmarshal(data, &reply); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if reply.Action != "hello" || reply.Protocol != ProtocolVersion.String() {
+		t.Errorf("reply = %+v", reply)
+	}
+	for !srv.Connected() && ctx.Err() == nil {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := srv.Protocol(); got != ProtocolVersion {
+		t.Errorf("Protocol() = %v, want %v", got, ProtocolVersion)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	// An unknown major version is refused with a protocol error.
+	next := Version{Major: ProtocolVersion.Major + 1}
+	bad, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer bad.CloseNow()
+	data, _ = json.Marshal(IncomingMsg{Type: "hello", Token: "s3cret", Protocol: next.String()})
+	bad.Write(ctx, websocket.MessageText, data)
+	if _, _, err := bad.Read(ctx); websocket.CloseStatus(err) != websocket.StatusProtocolError {
+		t.Fatalf("expected protocol error close, got %v", err)
+	}
+}
@@ -2,13 +2,21 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"sync"
+	"syscall"
+	"time"
 
 	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/signal"
 	"nhooyr.io/websocket"
 )
 
@@ -20,11 +28,14 @@ type IncomingMsg struct {
 	Groups json.RawMessage `json:"groups,omitempty"`
 	TabID  int             `json:"tabId,omitempty"`
 	Group  json.RawMessage `json:"group,omitempty"`
+	// Recently closed tabs, sent with snapshots
+	ClosedTabs json.RawMessage `json:"closedTabs,omitempty"`
 	// Command response fields
 	ID        string          `json:"id,omitempty"`
 	OK        *bool           `json:"ok,omitempty"`
 	Error     string          `json:"error,omitempty"`
 	GroupID   int             `json:"groupId,omitempty"`
+	TabIDs    []int           `json:"tabIds,omitempty"`
 	Content   string          `json:"content,omitempty"`
 	Items     string          `json:"items,omitempty"`
 	Visits    json.RawMessage `json:"visits,omitempty"`
@@ -33,8 +44,22 @@ type IncomingMsg struct {
 	ChannelID string          `json:"channelId,omitempty"`
 	ThreadTS  string          `json:"threadTs,omitempty"`
 	Status    string          `json:"status,omitempty"`
+	// Shared token and protocol version ("1.0"), sent in the "hello"
+	// handshake message
+	Token    string `json:"token,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
 }
 
+// TypeDisconnected is the type of the message delivered on Messages when
+// the extension's connection closes (but not when a new connection
+// replaces it).
+const TypeDisconnected = "disconnected"
+
+// disconnectNoticeTimeout is how long a TypeDisconnected message waits for
+// room on a full Messages channel. Unlike other messages it must not be
+// dropped, or the TUI would keep showing a connection that is gone.
+const disconnectNoticeTimeout = 10 * time.Second
+
 // TabToOpen specifies a tab to create in the browser.
 type TabToOpen struct {
 	URL    string `json:"url"`
@@ -79,35 +104,184 @@ type OutgoingMsg struct {
 	Color   string      `json:"color,omitempty"`
 	Source  string      `json:"source,omitempty"`
 	Title   string      `json:"title,omitempty"`
+	// browser.sessions ID for "restore-tab"
+	SessionID string `json:"sessionId,omitempty"`
+	// Selectors for scraping a custom signal source
+	Selectors *signal.Selectors `json:"selectors,omitempty"`
 	// Popup response fields
 	TabInfo *TabInfoPayload `json:"tabInfo,omitempty"`
 	Summary string          `json:"summary,omitempty"`
 	Error   string          `json:"error,omitempty"`
 	Status  string          `json:"status,omitempty"`
+	// Server protocol version, sent in the "hello" reply
+	Protocol string `json:"protocol,omitempty"`
 }
 
 // Server manages the WebSocket connection to the extension.
 type Server struct {
 	port    int
+	bind    string
+	token   string
 	msgs    chan IncomingMsg
 	mu      sync.Mutex
 	conn    *websocket.Conn
 	connCtx context.Context
+	// protocols holds the version negotiated with each open connection.
+	protocols map[*websocket.Conn]Version
+	// lastSeen is when the extension last sent a message or answered a ping.
+	lastSeen time.Time
+	// snapshot is the latest "snapshot" message, served by GET /tabs.
+	snapshot   *IncomingMsg
+	snapshotAt time.Time
+	// pending maps the IDs of commands sent via POST /command to the
+	// channel awaiting the extension's response.
+	pending map[string]chan IncomingMsg
+	// commandTimeout bounds how long POST /command waits for a response.
+	commandTimeout time.Duration
+	// running is closed when the current ListenAndServe call has released
+	// its port.
+	running chan struct{}
+	// Heartbeat settings; tests shorten them.
+	pingInterval time.Duration
+	pingTimeout  time.Duration
 }
 
 // New creates a new Server. Port 0 means the caller manages the listener.
 func New(port int) *Server {
 	return &Server{
-		port: port,
-		msgs: make(chan IncomingMsg, 64),
+		port:    port,
+		bind:    DefaultBind,
+		msgs:    make(chan IncomingMsg, 64),
+		pending: make(map[string]chan IncomingMsg),
+
+		protocols: make(map[*websocket.Conn]Version),
+
+		commandTimeout: defaultCommandTimeout,
+		pingInterval:   defaultPingInterval,
+		pingTimeout:    defaultPingTimeout,
 	}
 }
 
+// DefaultBind is the address the server listens on unless told otherwise.
+const DefaultBind = "127.0.0.1"
+
+// SetBind sets the host or IP the server listens on. An empty string
+// listens on all interfaces.
+func (s *Server) SetBind(host string) {
+	s.bind = host
+}
+
+// Bind returns the host or IP the server listens on.
+func (s *Server) Bind() string {
+	return s.bind
+}
+
+// Addr returns the host:port the server listens on.
+func (s *Server) Addr() string {
+	return net.JoinHostPort(s.bind, strconv.Itoa(s.port))
+}
+
+// IsLoopback reports whether host only accepts connections from this
+// machine.
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
 // Port returns the configured port.
 func (s *Server) Port() int {
 	return s.port
 }
 
+// SetToken requires connecting extensions to present token in a "hello"
+// message before anything else. An empty token disables the check.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// TokenRequired reports whether connections must authenticate.
+func (s *Server) TokenRequired() bool {
+	return s.token != ""
+}
+
+// Default heartbeat settings.
+const (
+	defaultPingInterval = 15 * time.Second
+	defaultPingTimeout  = 10 * time.Second
+)
+
+// LastSeen returns when the connected extension last sent a message or
+// answered a ping. It is zero when no extension is connected.
+func (s *Server) LastSeen() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return time.Time{}
+	}
+	return s.lastSeen
+}
+
+func (s *Server) touch() {
+	s.mu.Lock()
+	s.lastSeen = time.Now()
+	s.mu.Unlock()
+}
+
+// heartbeat pings the extension until ctx is done. A ping that is not
+// answered within the ping timeout means the socket is half-open (for example
+// the browser went to sleep), so the connection is closed, which ends the
+// handler's read loop and reports the disconnect.
+func (s *Server) heartbeat(ctx context.Context, conn *websocket.Conn) {
+	ticker := time.NewTicker(s.pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		pingCtx, cancel := context.WithTimeout(ctx, s.pingTimeout)
+		err := conn.Ping(pingCtx)
+		cancel()
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			applog.Error("ws.ping", err)
+			conn.CloseNow()
+			return
+		}
+		s.touch()
+	}
+}
+
+// handshakeTimeout bounds how long a new connection has to authenticate.
+const handshakeTimeout = 10 * time.Second
+
+var errBadToken = errors.New("missing or invalid token")
+
+// authenticate reads the connection's first message and checks that it is
+// a "hello" carrying the server's token. It returns the hello.
+func (s *Server) authenticate(ctx context.Context, conn *websocket.Conn) (IncomingMsg, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		return IncomingMsg{}, err
+	}
+	var msg IncomingMsg
+	if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "hello" {
+		return IncomingMsg{}, errBadToken
+	}
+	if subtle.ConstantTimeCompare([]byte(msg.Token), []byte(s.token)) != 1 {
+		return IncomingMsg{}, errBadToken
+	}
+	return msg, nil
+}
+
 // Messages returns the channel of incoming messages from the extension.
 func (s *Server) Messages() <-chan IncomingMsg {
 	return s.msgs
@@ -136,12 +310,20 @@ func (s *Server) Send(msg OutgoingMsg) error {
 	if err != nil {
 		return err
 	}
+	if applog.Enabled(applog.LevelDebug) {
+		applog.Debug("ws.send.raw", "action", msg.Action, "id", msg.ID, "bytes", len(data), "msg", debugJSON(data))
+	}
 	return conn.Write(ctx, websocket.MessageText, data)
 }
 
 // Handler returns an http.Handler that accepts WebSocket upgrades.
 func (s *Server) Handler() http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.allowedHost(r) {
+			applog.Error("ws.host", errForeignHost, "host", r.Host)
+			http.Error(w, errForeignHost.Error(), http.StatusForbidden)
+			return
+		}
 		conn, err := websocket.Accept(w, r, &websocket.AcceptOptions{
 			InsecureSkipVerify: true,
 		})
@@ -154,6 +336,17 @@ func (s *Server) Handler() http.Handler {
 		conn.SetReadLimit(16 << 20) // 16 MB — snapshots with many tabs can be large
 
 		ctx := r.Context()
+		if s.token != "" {
+			hello, err := s.authenticate(ctx, conn)
+			if err != nil {
+				applog.Error("ws.auth", err, "remote", r.RemoteAddr)
+				conn.Close(websocket.StatusPolicyViolation, "unauthorized")
+				return
+			}
+			if err := s.greet(ctx, conn, hello); err != nil {
+				return
+			}
+		}
 		s.mu.Lock()
 		if s.conn != nil {
 			applog.Info("ws.replaced")
@@ -161,32 +354,66 @@ func (s *Server) Handler() http.Handler {
 		}
 		s.conn = conn
 		s.connCtx = ctx
+		s.lastSeen = time.Now()
 		s.mu.Unlock()
 
 		applog.Info("ws.connected", "remote", r.RemoteAddr)
 
 		defer func() {
 			s.mu.Lock()
-			if s.conn == conn {
+			current := s.conn == conn
+			if current {
 				s.conn = nil
 				s.connCtx = nil
 			}
+			delete(s.protocols, conn)
 			s.mu.Unlock()
 			conn.CloseNow()
 			applog.Info("ws.disconnected")
+			if current {
+				select {
+				case s.msgs <- IncomingMsg{Type: TypeDisconnected}:
+				case <-time.After(disconnectNoticeTimeout):
+					applog.Error("ws.disconnected", errors.New("message queue full, disconnect notice dropped"))
+				}
+			}
 		}()
 
+		pingCtx, stopPing := context.WithCancel(ctx)
+		defer stopPing()
+		go s.heartbeat(pingCtx, conn)
+
 		for {
 			_, data, err := conn.Read(ctx)
 			if err != nil {
 				return
 			}
+			s.touch()
 			var msg IncomingMsg
 			if err := json.Unmarshal(data, &msg); err != nil {
 				applog.Error("ws.parse", err)
 				continue
 			}
+			if msg.Type == "hello" {
+				// Without a token the handshake arrives here; with one,
+				// authenticate already handled it.
+				if s.token == "" {
+					if err := s.greet(ctx, conn, msg); err != nil {
+						return
+					}
+				}
+				continue
+			}
 			applog.Info("ws.recv", "type", msg.Type)
+			if applog.Enabled(applog.LevelDebug) {
+				applog.Debug("ws.recv.raw", "type", msg.Type, "id", msg.ID, "bytes", len(data), "msg", debugJSON(data))
+			}
+			if msg.Type == "snapshot" {
+				s.rememberSnapshot(msg)
+			}
+			if s.deliverResponse(msg) {
+				continue
+			}
 			select {
 			case s.msgs <- msg:
 			default:
@@ -195,19 +422,123 @@ func (s *Server) Handler() http.Handler {
 	})
 }
 
-// ListenAndServe starts the WebSocket server on the configured port.
+// ListenAndServe starts the WebSocket server on the configured port,
+// alongside the HTTP endpoints GET /tabs and POST /command for other tools.
+// Cancelling ctx closes the listener and the extension's connection; the
+// call returns nil once the port is released. A new call waits for the
+// previous one to release the port first.
 func (s *Server) ListenAndServe(ctx context.Context) error {
+	done := make(chan struct{})
+	s.mu.Lock()
+	prev := s.running
+	s.running = done
+	s.mu.Unlock()
+	defer close(done)
+	if prev != nil {
+		<-prev
+	}
+
 	mux := http.NewServeMux()
 	mux.Handle("/", s.Handler())
+	mux.Handle("/tabs", s.TabsHandler())
+	mux.Handle("/command", s.CommandHandler())
 
-	addr := fmt.Sprintf("127.0.0.1:%d", s.port)
+	addr := s.Addr()
 	applog.Info("server.start", "addr", addr)
 	srv := &http.Server{Addr: addr, Handler: mux}
 
+	stopped := make(chan struct{})
+	defer close(stopped)
 	go func() {
-		<-ctx.Done()
+		select {
+		case <-ctx.Done():
+		case <-stopped:
+			return
+		}
 		srv.Close()
+		s.closeConn()
 	}()
 
-	return srv.ListenAndServe()
+	err := srv.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		applog.Info("server.stop", "addr", addr)
+		return nil
+	}
+	if errors.Is(err, syscall.EADDRINUSE) {
+		err = &PortInUseError{Port: s.port, Err: err}
+	}
+	applog.Error("server.listen", err, "addr", addr)
+	return err
+}
+
+// DefaultConnectTimeout is how long command-line live mode waits for the
+// extension to connect.
+const DefaultConnectTimeout = 10 * time.Second
+
+// AwaitSnapshot waits up to timeout for the extension to connect and send
+// its first snapshot. It tells w what it is waiting for and, every few
+// seconds, how long is left. errc carries the result of ListenAndServe, so
+// a listen failure ends the wait at once.
+func (s *Server) AwaitSnapshot(errc <-chan error, timeout time.Duration, w io.Writer) (IncomingMsg, error) {
+	fmt.Fprintf(w, "Waiting up to %s for the Firefox extension to connect on %s...\n", timeout, s.Addr())
+	deadline := time.Now().Add(timeout)
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	ticker := time.NewTicker(5 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case msg := <-s.msgs:
+			if msg.Type == "snapshot" {
+				return msg, nil
+			}
+		case err := <-errc:
+			if err == nil {
+				err = errors.New("server stopped")
+			}
+			return IncomingMsg{}, err
+		case <-ticker.C:
+			left := time.Until(deadline).Round(time.Second)
+			fmt.Fprintf(w, "Still waiting for the extension (%s left)...\n", left)
+		case <-timer.C:
+			return IncomingMsg{}, fmt.Errorf("timed out after %s waiting for the extension on %s (is Firefox running with the extension? raise --timeout on a slow start)", timeout, s.Addr())
+		}
+	}
+}
+
+// PortInUseError is returned by ListenAndServe when another process already
+// listens on the port.
+type PortInUseError struct {
+	Port int
+	Err  error
+}
+
+func (e *PortInUseError) Error() string {
+	return fmt.Sprintf("port %d in use — is another tabsordnung running? try --port", e.Port)
+}
+
+func (e *PortInUseError) Unwrap() error { return e.Err }
+
+// CheckPort reports whether the live mode server could listen on bind:port
+// by briefly listening on it. A taken port yields a *PortInUseError.
+func CheckPort(bind string, port int) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort(bind, strconv.Itoa(port)))
+	if err != nil {
+		if errors.Is(err, syscall.EADDRINUSE) {
+			return &PortInUseError{Port: port, Err: err}
+		}
+		return err
+	}
+	return ln.Close()
+}
+
+// closeConn closes the extension's connection, if any. WebSocket
+// connections are hijacked, so closing the http.Server leaves them open.
+func (s *Server) closeConn() {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn != nil {
+		conn.Close(websocket.StatusGoingAway, "server shutting down")
+	}
 }
@@ -3,6 +3,8 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"net"
 	"net/http/httptest"
 	"strings"
 	"testing"
@@ -82,3 +84,309 @@ func TestServerSendsCommand(t *testing.T) {
 		t.Errorf("got %+v, want cmd-1/close", got)
 	}
 }
+
+func TestServerReportsDisconnect(t *testing.T) {
+	srv := New(0)
+	msgs := srv.Messages()
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+	conn, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	select {
+	case msg := <-msgs:
+		if msg.Type != TypeDisconnected {
+			t.Errorf("got type %q, want %q", msg.Type, TypeDisconnected)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for disconnect message")
+	}
+	if srv.Connected() {
+		t.Error("server still reports a connection")
+	}
+}
+
+func TestServerReportsDisconnectWhenQueueFull(t *testing.T) {
+	srv := New(0)
+	msgs := srv.Messages()
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	for i := 0; i < cap(msgs)+5; i++ {
+		if err := conn.Write(ctx, websocket.MessageText, []byte(`{"type":"tab-updated"}`)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+	for len(msgs) < cap(msgs) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+	time.Sleep(50 * time.Millisecond)
+
+	for {
+		select {
+		case msg := <-msgs:
+			if msg.Type == TypeDisconnected {
+				return
+			}
+		case <-ctx.Done():
+			t.Fatal("disconnect message was dropped")
+		}
+	}
+}
+
+func TestServerRequiresToken(t *testing.T) {
+	srv := New(0)
+	srv.SetToken("s3cret")
+	msgs := srv.Messages()
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	wsURL := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	// Wrong token: the server closes the connection with a policy violation.
+	bad, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer bad.CloseNow()
+	data, _ := json.Marshal(IncomingMsg{Type: "hello", Token: "wrong"})
+	bad.Write(ctx, websocket.MessageText, data)
+	if _, _, err := bad.Read(ctx); websocket.CloseStatus(err) != websocket.StatusPolicyViolation {
+		t.Fatalf("expected policy violation close, got %v", err)
+	}
+	if srv.Connected() {
+		t.Fatal("unauthenticated connection was registered")
+	}
+
+	// Right token: messages after the handshake are delivered.
+	good, _, err := websocket.Dial(ctx, wsURL, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer good.CloseNow()
+	data, _ = json.Marshal(IncomingMsg{Type: "hello", Token: "s3cret"})
+	good.Write(ctx, websocket.MessageText, data)
+	data, _ = json.Marshal(IncomingMsg{Type: "snapshot"})
+	good.Write(ctx, websocket.MessageText, data)
+
+	select {
+	case msg := <-msgs:
+		if msg.Type != "snapshot" {
+			t.Errorf("got type %q, want snapshot", msg.Type)
+		}
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for message")
+	}
+}
+
+func TestServerAddr(t *testing.T) {
+	srv := New(19191)
+	if got := srv.Addr(); got != "127.0.0.1:19191" {
+		t.Errorf("default Addr = %q", got)
+	}
+	srv.SetBind("::")
+	if got := srv.Addr(); got != "[::]:19191" {
+		t.Errorf("Addr = %q, want [::]:19191", got)
+	}
+
+	for host, want := range map[string]bool{
+		"127.0.0.1":    true,
+		"localhost":    true,
+		"::1":          true,
+		"0.0.0.0":      false,
+		"":             false,
+		"192.168.1.20": false,
+	} {
+		if got := IsLoopback(host); got != want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
+
+func TestServerDetectsDeadConnection(t *testing.T) {
+	srv := New(0)
+	srv.pingInterval, srv.pingTimeout = 20*time.Millisecond, 50*time.Millisecond
+	msgs := srv.Messages()
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// The client never reads, so it never answers pings, like a suspended
+	// browser holding a half-open socket.
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	time.Sleep(10 * time.Millisecond)
+	if srv.LastSeen().IsZero() {
+		t.Error("LastSeen should be set while connected")
+	}
+
+	select {
+	case msg := <-msgs:
+		if msg.Type != TypeDisconnected {
+			t.Errorf("got type %q, want %q", msg.Type, TypeDisconnected)
+		}
+	case <-ctx.Done():
+		t.Fatal("dead connection was not detected")
+	}
+	if !srv.LastSeen().IsZero() {
+		t.Error("LastSeen should be zero after disconnect")
+	}
+}
+
+// freePort returns a TCP port that was free a moment ago.
+func freePort(t *testing.T) int {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestListenAndServeReleasesPortOnCancel(t *testing.T) {
+	srv := New(freePort(t))
+	ctx, stop := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(ctx) }()
+
+	dialCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	var conn *websocket.Conn
+	for {
+		var err error
+		conn, _, err = websocket.Dial(dialCtx, "ws://"+srv.Addr(), nil)
+		if err == nil {
+			break
+		}
+		if dialCtx.Err() != nil {
+			t.Fatalf("dial: %v", err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	defer conn.CloseNow()
+
+	stop()
+	select {
+	case err := <-errc:
+		if err != nil {
+			t.Fatalf("ListenAndServe: %v", err)
+		}
+	case <-dialCtx.Done():
+		t.Fatal("ListenAndServe did not return after cancel")
+	}
+
+	// The extension's connection is closed too.
+	if _, _, err := conn.Read(dialCtx); websocket.CloseStatus(err) != websocket.StatusGoingAway {
+		t.Errorf("expected going-away close, got %v", err)
+	}
+
+	ln, err := net.Listen("tcp", srv.Addr())
+	if err != nil {
+		t.Fatalf("port still bound after stop: %v", err)
+	}
+	ln.Close()
+
+	// Starting again binds the same port.
+	ctx, stop = context.WithCancel(context.Background())
+	go func() { errc <- srv.ListenAndServe(ctx) }()
+	time.Sleep(50 * time.Millisecond)
+	stop()
+	if err := <-errc; err != nil {
+		t.Fatalf("restart: %v", err)
+	}
+}
+
+func TestListenAndServePortInUse(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+
+	srv := New(ln.Addr().(*net.TCPAddr).Port)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(context.Background()) }()
+
+	select {
+	case err := <-errc:
+		var inUse *PortInUseError
+		if !errors.As(err, &inUse) || inUse.Port != srv.Port() {
+			t.Fatalf("err = %v, want PortInUseError", err)
+		}
+		if !strings.Contains(err.Error(), "try --port") {
+			t.Errorf("message = %q", err.Error())
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("ListenAndServe did not fail on a bound port")
+	}
+}
+
+func TestCheckPort(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+
+	var inUse *PortInUseError
+	if err := CheckPort("127.0.0.1", port); !errors.As(err, &inUse) || inUse.Port != port {
+		t.Fatalf("CheckPort on a taken port = %v, want PortInUseError", err)
+	}
+	ln.Close()
+	if err := CheckPort("127.0.0.1", port); err != nil {
+		t.Fatalf("CheckPort on a free port: %v", err)
+	}
+}
+
+func TestAwaitSnapshot(t *testing.T) {
+	srv := New(19191)
+	errc := make(chan error, 1)
+
+	var out strings.Builder
+	if _, err := srv.AwaitSnapshot(errc, 20*time.Millisecond, &out); err == nil || !strings.Contains(err.Error(), "timed out after 20ms") {
+		t.Errorf("err = %v, want timeout", err)
+	}
+	if !strings.Contains(out.String(), "Waiting up to 20ms") {
+		t.Errorf("output = %q", out.String())
+	}
+
+	srv.msgs <- IncomingMsg{Type: "tab.created"}
+	srv.msgs <- IncomingMsg{Type: "snapshot", ID: "first"}
+	msg, err := srv.AwaitSnapshot(errc, time.Second, &out)
+	if err != nil || msg.ID != "first" {
+		t.Errorf("got %+v, %v; want the snapshot", msg, err)
+	}
+
+	errc <- &PortInUseError{Port: 19191}
+	if _, err := srv.AwaitSnapshot(errc, time.Second, &out); !strings.Contains(err.Error(), "port 19191 in use") {
+		t.Errorf("err = %v, want port in use", err)
+	}
+}
@@ -0,0 +1,61 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TokenEnv is the environment variable holding the shared token the
+// extension must present when it connects.
+const TokenEnv = "TABSORDNUNG_WS_TOKEN"
+
+// TokenFilePath returns the path of the generated token file.
+func TokenFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tabsordnung", "ws-token")
+}
+
+// ResolveToken returns the WebSocket token from TokenEnv or, failing that,
+// the token file. It returns "" when neither is set, which leaves the
+// server unauthenticated.
+func ResolveToken() (string, error) {
+	if t := strings.TrimSpace(os.Getenv(TokenEnv)); t != "" {
+		return t, nil
+	}
+	p := TokenFilePath()
+	if p == "" {
+		return "", nil
+	}
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("read token file: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// GenerateToken creates a random token and writes it to path, readable
+// only by the current user.
+func GenerateToken(path string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate token: %w", err)
+	}
+	token := hex.EncodeToString(b)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("create token dir: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("write token file: %w", err)
+	}
+	return token, nil
+}
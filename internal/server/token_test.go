@@ -0,0 +1,35 @@
+package server
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveToken(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(TokenEnv, "")
+
+	if got, err := ResolveToken(); err != nil || got != "" {
+		t.Fatalf("no token configured: got %q, %v", got, err)
+	}
+
+	generated, err := GenerateToken(TokenFilePath())
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if len(generated) != 32 {
+		t.Errorf("token length = %d, want 32", len(generated))
+	}
+	if TokenFilePath() != filepath.Join(home, ".config", "tabsordnung", "ws-token") {
+		t.Errorf("unexpected token path %s", TokenFilePath())
+	}
+	if got, _ := ResolveToken(); got != generated {
+		t.Errorf("ResolveToken = %q, want generated %q", got, generated)
+	}
+
+	t.Setenv(TokenEnv, "from-env")
+	if got, _ := ResolveToken(); got != "from-env" {
+		t.Errorf("ResolveToken = %q, want env token", got)
+	}
+}
@@ -2,6 +2,7 @@ package signal
 
 import (
 	"encoding/json"
+	"net/url"
 	"strings"
 
 	"github.com/lotas/tabsordnung/internal/applog"
@@ -15,17 +16,20 @@ type SignalItem struct {
 	Kind      string `json:"kind"` // "dm", "mention", "channel", or ""
 }
 
-func DetectSource(url string) string {
-	lower := strings.ToLower(url)
-	switch {
-	case strings.Contains(lower, "mail.google.com"):
-		return "gmail"
-	case strings.Contains(lower, "slack.com"):
-		return "slack"
-	case strings.Contains(lower, "element.io"),
-		strings.Contains(lower, "chat.mozilla.org"),
-		strings.Contains(lower, "matrix."):
-		return "matrix"
+// DetectSource returns the signal source name for a tab URL, or "" if the
+// URL doesn't belong to a registered source. Matching is done on the host
+// so paths like github.com/foo/matrix.js don't count as Matrix.
+func DetectSource(rawURL string) string {
+	host := strings.ToLower(rawURL)
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		host = strings.ToLower(u.Hostname())
+	}
+	for _, def := range registry {
+		for _, pattern := range def.Hosts {
+			if matchHost(host, pattern) {
+				return def.Name
+			}
+		}
 	}
 	return ""
 }
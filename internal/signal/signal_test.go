@@ -14,6 +14,12 @@ func TestDetectSource(t *testing.T) {
 		{"https://my-company.slack.com/", "slack"},
 		{"https://app.element.io/#/room/!abc:matrix.org", "matrix"},
 		{"https://matrix.example.com/", "matrix"},
+		{"https://app.element.io/#/home", "matrix"},
+		{"https://element.example.org/#/room/!abc:example.org", "matrix"},
+		{"https://chat.mozilla.org/#/room/#general:mozilla.org", "matrix"},
+		{"https://app.matrix.org/", "matrix"},
+		{"https://github.com/foo/matrix.js", ""},
+		{"https://example.com/notslack.com", ""},
 		{"https://github.com/foo/bar", ""},
 		{"https://example.com", ""},
 	}
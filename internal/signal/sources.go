@@ -0,0 +1,148 @@
+package signal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SourceDef describes a signal source: which tab hosts belong to it, how
+// the kinds its scraper reports map to urgency, and, for sources without a
+// built-in scraper in the extension, the CSS selectors used to scrape it.
+type SourceDef struct {
+	Name      string            `json:"name"`
+	Hosts     []string          `json:"hosts"`
+	Urgency   map[string]string `json:"urgency,omitempty"`
+	Selectors *Selectors        `json:"selectors,omitempty"`
+}
+
+// Selectors tell the extension's generic scraper how to read unread items
+// from a page. Item selects one element per signal; the other selectors are
+// evaluated inside it. An item matching DM gets kind "dm", one matching
+// Mention gets kind "mention", anything else "channel".
+type Selectors struct {
+	Item    string `json:"item"`
+	Title   string `json:"title"`
+	Preview string `json:"preview,omitempty"`
+	Snippet string `json:"snippet,omitempty"`
+	DM      string `json:"dm,omitempty"`
+	Mention string `json:"mention,omitempty"`
+}
+
+// defaultUrgency maps the kinds reported by the built-in scrapers.
+var defaultUrgency = map[string]string{
+	"dm":      "urgent",
+	"mention": "review",
+	"channel": "fyi",
+}
+
+var builtinSources = []SourceDef{
+	{Name: "gmail", Hosts: []string{"mail.google.com"}},
+	{Name: "slack", Hosts: []string{"slack.com"}},
+	// Element (app.element.io and self-hosted element.* instances),
+	// matrix.org, the Mozilla chat server, and homeservers at matrix.<domain>.
+	{Name: "matrix", Hosts: []string{"element.io", "matrix.org", "chat.mozilla.org", "element.*", "matrix.*"}},
+}
+
+// registry holds the known sources in match order. Custom sources are
+// placed before the built-ins so they can claim hosts the defaults match.
+var registry = append([]SourceDef(nil), builtinSources...)
+
+// Register adds a source definition, replacing any existing source with
+// the same name. It is meant to be called at startup, before any lookups.
+func Register(def SourceDef) {
+	for i, s := range registry {
+		if s.Name == def.Name {
+			registry = append(registry[:i], registry[i+1:]...)
+			break
+		}
+	}
+	registry = append([]SourceDef{def}, registry...)
+}
+
+// ResetSources restores the built-in source definitions.
+func ResetSources() {
+	registry = append([]SourceDef(nil), builtinSources...)
+}
+
+// Lookup returns the definition of the named source.
+func Lookup(name string) (SourceDef, bool) {
+	for _, s := range registry {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return SourceDef{}, false
+}
+
+// UrgencyForKind returns the urgency for a signal kind, using the source's
+// own mapping first and the built-in dm/mention/channel mapping otherwise.
+func UrgencyForKind(source, kind string) (string, bool) {
+	if kind == "" {
+		return "", false
+	}
+	if def, ok := Lookup(source); ok {
+		if u, ok := def.Urgency[kind]; ok {
+			return u, true
+		}
+	}
+	u, ok := defaultUrgency[kind]
+	return u, ok
+}
+
+// SourcesFilePath returns the path to the custom signal sources file.
+func SourcesFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tabsordnung", "sources.json")
+}
+
+// LoadSources registers the source definitions from a JSON file holding an
+// array of SourceDef. A missing file is not an error. A file with an invalid
+// entry registers nothing, leaving the built-in sources in place.
+func LoadSources(p string) error {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	var defs []SourceDef
+	if err := json.Unmarshal(data, &defs); err != nil {
+		return fmt.Errorf("parse %s: %w", p, err)
+	}
+	for _, def := range defs {
+		if def.Name == "" || len(def.Hosts) == 0 {
+			return fmt.Errorf("parse %s: source needs a name and at least one host", p)
+		}
+		for kind, u := range def.Urgency {
+			switch u {
+			case "urgent", "review", "fyi":
+			default:
+				return fmt.Errorf("parse %s: source %q: invalid urgency %q for kind %q", p, def.Name, u, kind)
+			}
+		}
+	}
+	for _, def := range defs {
+		Register(def)
+	}
+	return nil
+}
+
+// matchHost reports whether host matches a source host pattern. Patterns
+// containing "*" are globs ("matrix.*", "*.example.com"); plain domains
+// match themselves and their subdomains.
+func matchHost(host, pattern string) bool {
+	pattern = strings.ToLower(pattern)
+	if strings.Contains(pattern, "*") {
+		ok, _ := path.Match(pattern, host)
+		return ok
+	}
+	return host == pattern || strings.HasSuffix(host, "."+pattern)
+}
@@ -0,0 +1,92 @@
+package signal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSourcesRegistersCustomSource(t *testing.T) {
+	t.Cleanup(ResetSources)
+
+	path := filepath.Join(t.TempDir(), "sources.json")
+	os.WriteFile(path, []byte(`[
+		{
+			"name": "mattermost",
+			"hosts": ["chat.example.com", "*.mm.example.org"],
+			"urgency": {"dm": "urgent", "mention": "urgent", "channel": "fyi"},
+			"selectors": {"item": ".unread", "title": ".name", "mention": ".badge"}
+		}
+	]`), 0644)
+
+	if err := LoadSources(path); err != nil {
+		t.Fatalf("LoadSources: %v", err)
+	}
+
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://chat.example.com/team/channels/town-square", "mattermost"},
+		{"https://eu.mm.example.org/", "mattermost"},
+		{"https://app.slack.com/client/T1/C2", "slack"}, // built-ins still apply
+		{"https://example.com/", ""},
+	}
+	for _, tt := range tests {
+		if got := DetectSource(tt.url); got != tt.want {
+			t.Errorf("DetectSource(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+
+	def, ok := Lookup("mattermost")
+	if !ok || def.Selectors == nil || def.Selectors.Item != ".unread" {
+		t.Errorf("Lookup(mattermost) = %+v, %v", def, ok)
+	}
+}
+
+func TestUrgencyForKind(t *testing.T) {
+	t.Cleanup(ResetSources)
+	Register(SourceDef{Name: "custom", Hosts: []string{"custom.example.com"}, Urgency: map[string]string{"mention": "urgent", "page": "urgent"}})
+
+	tests := []struct {
+		source, kind string
+		want         string
+		ok           bool
+	}{
+		{"slack", "dm", "urgent", true},
+		{"slack", "mention", "review", true},
+		{"matrix", "channel", "fyi", true},
+		{"gmail", "", "", false},
+		{"custom", "mention", "urgent", true}, // overridden
+		{"custom", "page", "urgent", true},    // source-specific kind
+		{"custom", "channel", "fyi", true},    // falls back to defaults
+		{"slack", "page", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := UrgencyForKind(tt.source, tt.kind)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("UrgencyForKind(%q, %q) = %q, %v; want %q, %v", tt.source, tt.kind, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestLoadSourcesMissingFile(t *testing.T) {
+	if err := LoadSources(filepath.Join(t.TempDir(), "nope.json")); err != nil {
+		t.Errorf("expected nil error for missing file, got %v", err)
+	}
+}
+
+func TestLoadSourcesRejectsBadUrgency(t *testing.T) {
+	t.Cleanup(ResetSources)
+	path := filepath.Join(t.TempDir(), "sources.json")
+	os.WriteFile(path, []byte(`[
+		{"name": "ok", "hosts": ["ok.example.com"]},
+		{"name": "x", "hosts": ["x.com"], "urgency": {"dm": "asap"}}
+	]`), 0644)
+	if err := LoadSources(path); err == nil {
+		t.Error("expected error for invalid urgency")
+	}
+	if _, ok := Lookup("ok"); ok {
+		t.Error("a rejected file still registered its valid sources")
+	}
+}
@@ -3,6 +3,7 @@ package snapshot
 import (
 	"database/sql"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/lotas/tabsordnung/internal/storage"
@@ -45,10 +46,16 @@ func DiffAgainstCurrent(db *sql.DB, profile string, rev int, current *types.Sess
 		}
 	}
 
+	return DiffWithSession(snap, current), nil
+}
+
+// DiffWithSession compares an already loaded snapshot against current
+// session data. Removed lists the snapshot's tabs that are no longer open.
+func DiffWithSession(snap *storage.SnapshotFull, current *types.SessionData) *DiffResult {
 	result := diffSnapshots(snap, current)
 	result.RevFrom = snap.Rev
 	result.RevTo = 0
-	return result, nil
+	return result
 }
 
 // DiffRevisions compares two stored snapshots.
@@ -86,10 +93,18 @@ func DiffRevisions(db *sql.DB, profile string, rev1, rev2 int) (*DiffResult, err
 			result.Removed = append(result.Removed, entry)
 		}
 	}
+	result.sort()
 
 	return result, nil
 }
 
+// sort orders the added and removed entries by URL, so the output does not
+// depend on map iteration order.
+func (d *DiffResult) sort() {
+	sort.Slice(d.Added, func(i, j int) bool { return d.Added[i].URL < d.Added[j].URL })
+	sort.Slice(d.Removed, func(i, j int) bool { return d.Removed[i].URL < d.Removed[j].URL })
+}
+
 // FormatDiff returns a human-readable string representation of a DiffResult.
 func FormatDiff(d *DiffResult) string {
 	var sb strings.Builder
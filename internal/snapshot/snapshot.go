@@ -14,7 +14,8 @@ import (
 )
 
 // Create converts a SessionData into storage types and persists a snapshot.
-// It first checks the latest snapshot for the profile and skips saving if
+// The stale, dead, duplicate and GitHub status flags already set on the tabs
+// are stored with them, so run the analyzers first. It first checks the latest snapshot for the profile and skips saving if
 // the URL sets are identical. Returns the rev number, whether a new snapshot
 // was created, the diff against the previous snapshot (nil if first), and error.
 func Create(db *sql.DB, session *types.SessionData, label string) (rev int, created bool, diff *DiffResult, err error) {
@@ -74,9 +75,13 @@ func Create(db *sql.DB, session *types.SessionData, label string) (rev int, crea
 	tabs := make([]storage.SnapshotTab, 0, len(session.AllTabs))
 	for _, t := range session.AllTabs {
 		tab := storage.SnapshotTab{
-			URL:    t.URL,
-			Title:  t.Title,
-			Pinned: t.Pinned,
+			URL:          t.URL,
+			Title:        t.Title,
+			Pinned:       t.Pinned,
+			IsStale:      t.IsStale,
+			IsDead:       t.IsDead,
+			IsDuplicate:  t.IsDuplicate,
+			GitHubStatus: t.GitHubStatus,
 		}
 		if t.GroupID != "" {
 			if idx, ok := groupIndex[t.GroupID]; ok {
@@ -104,6 +109,11 @@ func Create(db *sql.DB, session *types.SessionData, label string) (rev int, crea
 		} else if n > 0 {
 			applog.Info("snapshot.bugzilla.extract", "entities", n, "rev", newRev)
 		}
+		if n, glErr := storage.ExtractGitLabFromSnapshot(db, snapshotID); glErr != nil {
+			applog.Error("snapshot.gitlab.extract", glErr)
+		} else if n > 0 {
+			applog.Info("snapshot.gitlab.extract", "entities", n, "rev", newRev)
+		}
 	}
 
 	applog.Info("snapshot.created", "rev", newRev, "tabs", len(tabs), "profile", profile)
@@ -158,90 +168,215 @@ func diffSnapshots(snap *storage.SnapshotFull, current *types.SessionData) *Diff
 			result.Removed = append(result.Removed, entry)
 		}
 	}
+	result.sort()
 
 	return result
 }
 
+// ToSessionData converts a stored snapshot back into session data so it can
+// be fed to the exporters. Snapshots don't record access times, so every tab
+// carries the snapshot's creation time as LastAccessed.
+func ToSessionData(snap *storage.SnapshotFull) *types.SessionData {
+	sd := &types.SessionData{
+		Profile:  types.Profile{Name: snap.Profile},
+		ParsedAt: snap.CreatedAt,
+	}
+
+	groups := make([]*types.TabGroup, len(snap.Groups))
+	for i, g := range snap.Groups {
+		groups[i] = &types.TabGroup{
+			ID:    g.FirefoxID,
+			Name:  g.Name,
+			Color: g.Color,
+		}
+	}
+	ungrouped := &types.TabGroup{ID: "", Name: "Ungrouped"}
+
+	for i, t := range snap.Tabs {
+		tab := &types.Tab{
+			URL:          t.URL,
+			Title:        t.Title,
+			LastAccessed: snap.CreatedAt,
+			Pinned:       t.Pinned,
+			IsStale:      t.IsStale,
+			IsDead:       t.IsDead,
+			IsDuplicate:  t.IsDuplicate,
+			GitHubStatus: t.GitHubStatus,
+			TabIndex:     i,
+		}
+		if t.GroupIndex != nil && *t.GroupIndex >= 0 && *t.GroupIndex < len(groups) {
+			g := groups[*t.GroupIndex]
+			tab.GroupID = g.ID
+			g.Tabs = append(g.Tabs, tab)
+		} else {
+			ungrouped.Tabs = append(ungrouped.Tabs, tab)
+		}
+		sd.AllTabs = append(sd.AllTabs, tab)
+	}
+
+	sd.Groups = groups
+	if len(ungrouped.Tabs) > 0 {
+		sd.Groups = append(sd.Groups, ungrouped)
+	}
+	return sd
+}
+
 // Restore reopens tabs from a snapshot via the live mode WebSocket bridge.
-func Restore(db *sql.DB, profile string, rev int, port int) error {
+// If keep is non-nil, only tabs for which it returns true are restored.
+// srv is served until the restore is done; the extension has timeout to
+// connect.
+func Restore(db *sql.DB, profile string, rev int, srv *server.Server, keep func(storage.SnapshotTab) bool, timeout time.Duration) error {
 	applog.Info("snapshot.restore.start", "rev", rev, "profile", profile)
 	snap, err := storage.GetSnapshot(db, profile, rev)
 	if err != nil {
 		return err
 	}
+	snap = FilterTabs(snap, keep)
+	if len(snap.Tabs) == 0 {
+		return fmt.Errorf("no tabs to restore from snapshot #%d", rev)
+	}
 
-	srv := server.New(port)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go srv.ListenAndServe(ctx)
-
-	fmt.Fprintf(os.Stderr, "Waiting for Firefox extension on port %d...\n", port)
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(ctx) }()
 
 	// Wait for initial "snapshot" message from extension (confirms connection).
-	select {
-	case msg := <-srv.Messages():
-		if msg.Type != "snapshot" {
-			return fmt.Errorf("expected initial \"snapshot\" message, got %q", msg.Type)
-		}
-	case <-time.After(10 * time.Second):
-		return fmt.Errorf("timed out waiting for extension to connect")
+	if _, err := srv.AwaitSnapshot(errc, timeout, os.Stderr); err != nil {
+		return err
 	}
 
-	// Create groups first, storing the returned GroupIDs.
-	groupIDs := make(map[int]int) // group slice index -> browser GroupID
-	for i, g := range snap.Groups {
-		msgID := fmt.Sprintf("create-group-%d", i)
-		if err := srv.Send(server.OutgoingMsg{
-			ID:     msgID,
-			Action: "create-group",
-			Name:   g.Name,
-			Color:  g.Color,
-		}); err != nil {
-			return fmt.Errorf("send create-group for %q: %w", g.Name, err)
-		}
+	if err := restoreTabs(srv, snap); err != nil {
+		return err
+	}
 
-		// Wait for response with 5s timeout.
-		select {
-		case resp := <-srv.Messages():
-			if resp.OK != nil && !*resp.OK {
-				return fmt.Errorf("create-group %q failed: %s", g.Name, resp.Error)
-			}
-			groupIDs[i] = resp.GroupID
-		case <-time.After(5 * time.Second):
-			return fmt.Errorf("timed out waiting for create-group response for %q", g.Name)
+	applog.Info("snapshot.restore.done", "rev", rev, "tabs", len(snap.Tabs))
+	fmt.Fprintf(os.Stderr, "Restored %d tabs from snapshot #%d\n", len(snap.Tabs), rev)
+	return nil
+}
+
+// InGroup returns a tab filter matching tabs in the named group. The name
+// "Ungrouped" also matches tabs that don't belong to any group.
+func InGroup(name string) func(storage.SnapshotTab) bool {
+	return func(t storage.SnapshotTab) bool {
+		if t.GroupName == "" {
+			return name == "Ungrouped"
 		}
+		return t.GroupName == name
 	}
+}
 
-	// Build tabs to open.
-	tabs := make([]server.TabToOpen, 0, len(snap.Tabs))
+// FilterTabs returns a copy of snap holding only the tabs for which keep
+// returns true. Groups are kept as is so GroupIndex stays valid.
+// A nil keep returns snap unchanged.
+func FilterTabs(snap *storage.SnapshotFull, keep func(storage.SnapshotTab) bool) *storage.SnapshotFull {
+	if keep == nil {
+		return snap
+	}
+	filtered := *snap
+	filtered.Tabs = nil
 	for _, t := range snap.Tabs {
-		tabs = append(tabs, server.TabToOpen{
-			URL:    t.URL,
-			Pinned: t.Pinned,
-		})
+		if keep(t) {
+			filtered.Tabs = append(filtered.Tabs, t)
+		}
 	}
+	filtered.TabCount = len(filtered.Tabs)
+	return &filtered
+}
+
+// RestoreBatch is a set of tabs opened together during a restore. When
+// Group is set, the opened tabs are gathered into a new tab group with
+// the group's name and color.
+type RestoreBatch struct {
+	Group *storage.SnapshotGroup
+	Tabs  []server.TabToOpen
+}
 
-	// Send a single "open" message with all tabs.
-	if err := srv.Send(server.OutgoingMsg{
-		ID:     "open-tabs",
-		Action: "open",
-		Tabs:   tabs,
-	}); err != nil {
-		return fmt.Errorf("send open tabs: %w", err)
+// PlanRestore splits the snapshot's tabs into batches: pinned tabs first
+// (browsers don't allow grouping them), then one batch per group, then
+// ungrouped tabs. Empty batches are omitted.
+func PlanRestore(snap *storage.SnapshotFull) []RestoreBatch {
+	var pinned, ungrouped []server.TabToOpen
+	grouped := make([][]server.TabToOpen, len(snap.Groups))
+	for _, t := range snap.Tabs {
+		tab := server.TabToOpen{URL: t.URL, Pinned: t.Pinned}
+		switch {
+		case t.Pinned:
+			pinned = append(pinned, tab)
+		case t.GroupIndex != nil && *t.GroupIndex >= 0 && *t.GroupIndex < len(grouped):
+			grouped[*t.GroupIndex] = append(grouped[*t.GroupIndex], tab)
+		default:
+			ungrouped = append(ungrouped, tab)
+		}
 	}
 
-	// Wait for confirmation with 30s timeout.
-	select {
-	case resp := <-srv.Messages():
-		if resp.OK != nil && !*resp.OK {
-			return fmt.Errorf("open tabs failed: %s", resp.Error)
+	var batches []RestoreBatch
+	if len(pinned) > 0 {
+		batches = append(batches, RestoreBatch{Tabs: pinned})
+	}
+	for i := range snap.Groups {
+		if len(grouped[i]) > 0 {
+			batches = append(batches, RestoreBatch{Group: &snap.Groups[i], Tabs: grouped[i]})
 		}
-	case <-time.After(30 * time.Second):
-		return fmt.Errorf("timed out waiting for open tabs confirmation")
 	}
+	if len(ungrouped) > 0 {
+		batches = append(batches, RestoreBatch{Tabs: ungrouped})
+	}
+	return batches
+}
 
-	applog.Info("snapshot.restore.done", "rev", rev, "tabs", len(snap.Tabs))
-	fmt.Fprintf(os.Stderr, "Restored %d tabs from snapshot #%d\n", len(snap.Tabs), rev)
+// restoreTabs reopens the snapshot's tabs through a connected extension,
+// recreating each group with its saved name and color.
+func restoreTabs(srv *server.Server, snap *storage.SnapshotFull) error {
+	for i, batch := range PlanRestore(snap) {
+		resp, err := request(srv, server.OutgoingMsg{
+			ID:     fmt.Sprintf("open-%d", i),
+			Action: "open",
+			Tabs:   batch.Tabs,
+		}, 30*time.Second)
+		if err != nil {
+			return fmt.Errorf("open tabs: %w", err)
+		}
+		if batch.Group == nil || len(resp.TabIDs) == 0 {
+			continue
+		}
+		resp, err = request(srv, server.OutgoingMsg{
+			ID:     fmt.Sprintf("create-group-%d", i),
+			Action: "create-group",
+			TabIDs: resp.TabIDs,
+			Name:   batch.Group.Name,
+			Color:  batch.Group.Color,
+		}, 5*time.Second)
+		if err != nil {
+			return fmt.Errorf("create-group %q: %w", batch.Group.Name, err)
+		}
+		if resp.GroupID < 0 {
+			applog.Info("snapshot.restore.nogroups", "group", batch.Group.Name)
+		}
+	}
 	return nil
 }
+
+// request sends a command and waits for the response carrying the same ID,
+// skipping unrelated messages (tab events) the extension sends meanwhile.
+func request(srv *server.Server, msg server.OutgoingMsg, timeout time.Duration) (server.IncomingMsg, error) {
+	if err := srv.Send(msg); err != nil {
+		return server.IncomingMsg{}, fmt.Errorf("send %s: %w", msg.Action, err)
+	}
+	deadline := time.After(timeout)
+	for {
+		select {
+		case resp := <-srv.Messages():
+			if resp.ID != msg.ID {
+				continue
+			}
+			if resp.OK != nil && !*resp.OK {
+				return resp, fmt.Errorf("%s failed: %s", msg.Action, resp.Error)
+			}
+			return resp, nil
+		case <-deadline:
+			return server.IncomingMsg{}, fmt.Errorf("timed out waiting for %s response", msg.Action)
+		}
+	}
+}
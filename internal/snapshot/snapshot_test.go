@@ -1,13 +1,19 @@
 package snapshot
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
+	"net/http/httptest"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/lotas/tabsordnung/internal/server"
 	"github.com/lotas/tabsordnung/internal/storage"
 	"github.com/lotas/tabsordnung/internal/types"
+	"nhooyr.io/websocket"
 )
 
 // testDB creates a temporary SQLite database for testing.
@@ -170,3 +176,206 @@ func TestCreateWithLabel(t *testing.T) {
 		t.Errorf("expected label 'before cleanup', got %q", snap.Name)
 	}
 }
+
+func TestCreateStoresAnalyzerFlags(t *testing.T) {
+	db := testDB(t)
+
+	session := &types.SessionData{
+		AllTabs: []*types.Tab{
+			{URL: "https://dead.com", Title: "Dead", IsDead: true, IsStale: true},
+			{URL: "https://github.com/a/b/pull/1", Title: "PR", GitHubStatus: "merged"},
+			{URL: "https://dup.com", Title: "Dup", IsDuplicate: true},
+		},
+		Profile:  types.Profile{Name: "default"},
+		ParsedAt: time.Now(),
+	}
+
+	rev, _, _, err := Create(db, session, "")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	snap, err := storage.GetSnapshot(db, "default", rev)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+	byURL := make(map[string]storage.SnapshotTab)
+	for _, tab := range snap.Tabs {
+		byURL[tab.URL] = tab
+	}
+	if tab := byURL["https://dead.com"]; !tab.IsDead || !tab.IsStale || tab.IsDuplicate {
+		t.Errorf("dead tab flags = %+v", tab)
+	}
+	if tab := byURL["https://github.com/a/b/pull/1"]; tab.GitHubStatus != "merged" || tab.IsDead {
+		t.Errorf("github tab flags = %+v", tab)
+	}
+	if tab := byURL["https://dup.com"]; !tab.IsDuplicate {
+		t.Errorf("duplicate tab flags = %+v", tab)
+	}
+
+	sd := ToSessionData(snap)
+	dead := 0
+	for _, tab := range sd.AllTabs {
+		if tab.IsDead {
+			dead++
+		}
+	}
+	if dead != 1 {
+		t.Errorf("expected 1 dead tab after round trip, got %d", dead)
+	}
+}
+
+func TestRestoreTabsRecreatesGroups(t *testing.T) {
+	srv := server.New(0)
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(ts.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.CloseNow()
+	time.Sleep(50 * time.Millisecond)
+
+	// Fake extension: assigns sequential tab IDs and records commands.
+	var cmds []server.OutgoingMsg
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		nextTabID := 100
+		for {
+			_, data, err := conn.Read(ctx)
+			if err != nil {
+				return
+			}
+			var cmd server.OutgoingMsg
+			json.Unmarshal(data, &cmd)
+			cmds = append(cmds, cmd)
+			ok := true
+			resp := server.IncomingMsg{ID: cmd.ID, OK: &ok}
+			switch cmd.Action {
+			case "open":
+				for range cmd.Tabs {
+					resp.TabIDs = append(resp.TabIDs, nextTabID)
+					nextTabID++
+				}
+			case "create-group":
+				resp.GroupID = 7
+			}
+			out, _ := json.Marshal(resp)
+			conn.Write(ctx, websocket.MessageText, out)
+		}
+	}()
+
+	work := 0
+	snap := &storage.SnapshotFull{
+		Groups: []storage.SnapshotGroup{{Name: "Work", Color: "red"}},
+		Tabs: []storage.SnapshotTab{
+			{URL: "https://pinned.com", Pinned: true},
+			{URL: "https://a.com", GroupIndex: &work},
+			{URL: "https://b.com", GroupIndex: &work},
+			{URL: "https://loose.com"},
+		},
+	}
+	if err := restoreTabs(srv, snap); err != nil {
+		t.Fatalf("restoreTabs: %v", err)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+	<-done
+
+	var actions []string
+	for _, c := range cmds {
+		actions = append(actions, c.Action)
+	}
+	want := []string{"open", "open", "create-group", "open"}
+	if strings.Join(actions, ",") != strings.Join(want, ",") {
+		t.Fatalf("actions = %v, want %v", actions, want)
+	}
+	if !cmds[0].Tabs[0].Pinned {
+		t.Error("expected first open to re-pin the pinned tab")
+	}
+	cg := cmds[2]
+	if cg.Name != "Work" || cg.Color != "red" {
+		t.Errorf("create-group name/color = %q/%q", cg.Name, cg.Color)
+	}
+	if len(cg.TabIDs) != 2 || cg.TabIDs[0] != 101 || cg.TabIDs[1] != 102 {
+		t.Errorf("create-group tabIds = %v, want [101 102]", cg.TabIDs)
+	}
+}
+
+func TestFilterTabsInGroup(t *testing.T) {
+	work := 0
+	snap := &storage.SnapshotFull{
+		Groups: []storage.SnapshotGroup{{Name: "Work"}},
+		Tabs: []storage.SnapshotTab{
+			{URL: "https://a.com", GroupIndex: &work, GroupName: "Work"},
+			{URL: "https://b.com"},
+		},
+	}
+
+	got := FilterTabs(snap, InGroup("Work"))
+	if len(got.Tabs) != 1 || got.Tabs[0].URL != "https://a.com" {
+		t.Errorf("InGroup(Work) = %v", got.Tabs)
+	}
+	if got.TabCount != 1 || len(got.Groups) != 1 {
+		t.Errorf("TabCount = %d, groups = %d", got.TabCount, len(got.Groups))
+	}
+	if len(snap.Tabs) != 2 {
+		t.Error("FilterTabs modified the original snapshot")
+	}
+
+	got = FilterTabs(snap, InGroup("Ungrouped"))
+	if len(got.Tabs) != 1 || got.Tabs[0].URL != "https://b.com" {
+		t.Errorf("InGroup(Ungrouped) = %v", got.Tabs)
+	}
+
+	if FilterTabs(snap, nil) != snap {
+		t.Error("nil filter should return the snapshot unchanged")
+	}
+}
+
+func TestToSessionData(t *testing.T) {
+	db := testDB(t)
+
+	session := &types.SessionData{
+		Groups: []*types.TabGroup{
+			{ID: "g1", Name: "Work", Color: "blue"},
+		},
+		AllTabs: []*types.Tab{
+			{URL: "https://work.com", Title: "Work", GroupID: "g1"},
+			{URL: "https://loose.com", Title: "Loose", Pinned: true},
+		},
+		Profile: types.Profile{Name: "default"},
+	}
+	if _, _, _, err := Create(db, session, ""); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	snap, err := storage.GetSnapshot(db, "default", 1)
+	if err != nil {
+		t.Fatalf("GetSnapshot: %v", err)
+	}
+
+	data := ToSessionData(snap)
+	if data.Profile.Name != "default" {
+		t.Errorf("profile = %q", data.Profile.Name)
+	}
+	if len(data.AllTabs) != 2 {
+		t.Fatalf("expected 2 tabs, got %d", len(data.AllTabs))
+	}
+	if len(data.Groups) != 2 {
+		t.Fatalf("expected Work + Ungrouped, got %d groups", len(data.Groups))
+	}
+	work := data.Groups[0]
+	if work.Name != "Work" || work.Color != "blue" || len(work.Tabs) != 1 || work.Tabs[0].URL != "https://work.com" {
+		t.Errorf("unexpected Work group: %+v", work)
+	}
+	if work.Tabs[0].GroupID != "g1" {
+		t.Errorf("tab GroupID = %q, want g1", work.Tabs[0].GroupID)
+	}
+	ungrouped := data.Groups[1]
+	if ungrouped.ID != "" || len(ungrouped.Tabs) != 1 || !ungrouped.Tabs[0].Pinned {
+		t.Errorf("unexpected Ungrouped group: %+v", ungrouped)
+	}
+}
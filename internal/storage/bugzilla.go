@@ -21,6 +21,9 @@ type BugzillaEntity struct {
 	Status          string
 	Resolution      string
 	Assignee        string
+	Priority        string // "P1".."P5", or "--" when untriaged
+	Severity        string // "S1".."S4", "--" or "N/A"
+	Restricted      bool   // the bug could not be fetched without (other) credentials
 	FirstSeenAt     time.Time
 	FirstSeenSource string
 	LastRefreshedAt *time.Time
@@ -29,6 +32,7 @@ type BugzillaEntity struct {
 // BugzillaStatusUpdate holds API-fetched fields to persist.
 type BugzillaStatusUpdate struct {
 	Title, Status, Resolution, Assignee string
+	Priority, Severity                  string
 }
 
 // BugzillaEntityEvent is a timeline entry for a Bugzilla entity.
@@ -100,7 +104,7 @@ func RecordBugzillaEvent(db *sql.DB, entityID int64, eventType string, signalID
 func ListBugzillaEntities(db *sql.DB) ([]BugzillaEntity, error) {
 	rows, err := db.Query(
 		`SELECT id, host, bug_id, title, status, resolution, assignee,
-		        first_seen_at, first_seen_source, last_refreshed_at
+		        priority, severity, restricted, first_seen_at, first_seen_source, last_refreshed_at
 		 FROM bugzilla_entities
 		 ORDER BY first_seen_at DESC, id DESC`,
 	)
@@ -115,7 +119,7 @@ func ListBugzillaEntities(db *sql.DB) ([]BugzillaEntity, error) {
 		var lr sql.NullTime
 		if err := rows.Scan(&e.ID, &e.Host, &e.BugID,
 			&e.Title, &e.Status, &e.Resolution, &e.Assignee,
-			&e.FirstSeenAt, &e.FirstSeenSource, &lr); err != nil {
+			&e.Priority, &e.Severity, &e.Restricted, &e.FirstSeenAt, &e.FirstSeenSource, &lr); err != nil {
 			return nil, fmt.Errorf("scan bugzilla entity: %w", err)
 		}
 		if lr.Valid {
@@ -130,8 +134,8 @@ func ListBugzillaEntities(db *sql.DB) ([]BugzillaEntity, error) {
 func UpdateBugzillaEntityStatus(db *sql.DB, id int64, u BugzillaStatusUpdate) error {
 	res, err := db.Exec(
 		`UPDATE bugzilla_entities SET title=?, status=?, resolution=?, assignee=?,
-		 last_refreshed_at=CURRENT_TIMESTAMP WHERE id=?`,
-		u.Title, u.Status, u.Resolution, u.Assignee, id)
+		 priority=?, severity=?, restricted=0, last_refreshed_at=CURRENT_TIMESTAMP WHERE id=?`,
+		u.Title, u.Status, u.Resolution, u.Assignee, u.Priority, u.Severity, id)
 	if err != nil {
 		return fmt.Errorf("update bugzilla entity status: %w", err)
 	}
@@ -142,6 +146,24 @@ func UpdateBugzillaEntityStatus(db *sql.DB, id int64, u BugzillaStatusUpdate) er
 	return nil
 }
 
+// MarkBugzillaEntityRestricted flags a bug the API refused to show and
+// clears the fields fetched earlier, so stale data is not displayed as
+// current. It sets last_refreshed_at.
+func MarkBugzillaEntityRestricted(db *sql.DB, id int64) error {
+	res, err := db.Exec(
+		`UPDATE bugzilla_entities SET status='', resolution='', assignee='',
+		 priority='', severity='', restricted=1, last_refreshed_at=CURRENT_TIMESTAMP WHERE id=?`,
+		id)
+	if err != nil {
+		return fmt.Errorf("mark bugzilla entity restricted: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("entity %d not found", id)
+	}
+	return nil
+}
+
 // extractBugTitleFromText extracts a bug title from text containing "[Bug NNNN] title".
 // Returns the title portion after the bracket, trimmed, or "" if no match.
 func extractBugTitleFromText(text string) string {
@@ -224,6 +246,9 @@ type BugzillaJSONOutput struct {
 	Status          string `json:"status"`
 	Resolution      string `json:"resolution"`
 	Assignee        string `json:"assignee"`
+	Priority        string `json:"priority"`
+	Severity        string `json:"severity"`
+	Restricted      bool   `json:"restricted,omitempty"`
 	FirstSeenAt     string `json:"first_seen_at"`
 	FirstSeenSource string `json:"first_seen_source"`
 	LastRefreshedAt string `json:"last_refreshed_at,omitempty"`
@@ -258,6 +283,12 @@ func FormatBugzillaMarkdown(entities []BugzillaEntity, events map[int64][]Bugzil
 				}
 				statusStr += "]"
 			}
+			if rank := e.Rank(); rank != "" {
+				statusStr += " [" + rank + "]"
+			}
+			if e.Restricted {
+				statusStr += " [restricted]"
+			}
 			titleStr := ""
 			if t := strings.TrimSpace(e.Title); t != "" {
 				titleStr = " " + t
@@ -280,6 +311,18 @@ func FormatBugzillaMarkdown(entities []BugzillaEntity, events map[int64][]Bugzil
 	return b.String()
 }
 
+// Rank formats the bug's priority and severity as "P1 S2", leaving out
+// unset values.
+func (e BugzillaEntity) Rank() string {
+	var parts []string
+	for _, v := range []string{e.Priority, e.Severity} {
+		if v != "" && v != "--" && v != "N/A" {
+			parts = append(parts, v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 func firstSeenSourceBugzilla(e BugzillaEntity, events map[int64][]BugzillaEntityEvent) string {
 	entityEvents, ok := events[e.ID]
 	if !ok || len(entityEvents) == 0 {
@@ -307,6 +350,9 @@ func FormatBugzillaJSON(entities []BugzillaEntity) (string, error) {
 			Status:          e.Status,
 			Resolution:      e.Resolution,
 			Assignee:        e.Assignee,
+			Priority:        e.Priority,
+			Severity:        e.Severity,
+			Restricted:      e.Restricted,
 			FirstSeenAt:     e.FirstSeenAt.Format(time.RFC3339),
 			FirstSeenSource: e.FirstSeenSource,
 		}
@@ -175,6 +175,7 @@ func TestUpdateBugzillaEntityStatus(t *testing.T) {
 	update := BugzillaStatusUpdate{
 		Title: "Fix memory leak", Status: "RESOLVED",
 		Resolution: "FIXED", Assignee: "dev@example.com",
+		Priority: "P1", Severity: "S2",
 	}
 	if err := UpdateBugzillaEntityStatus(db, id, update); err != nil {
 		t.Fatalf("update: %v", err)
@@ -187,9 +188,29 @@ func TestUpdateBugzillaEntityStatus(t *testing.T) {
 	if e.Title != "Fix memory leak" || e.Status != "RESOLVED" || e.Resolution != "FIXED" || e.Assignee != "dev@example.com" {
 		t.Errorf("unexpected: %+v", e)
 	}
+	if e.Priority != "P1" || e.Severity != "S2" {
+		t.Errorf("priority/severity = %q/%q, want P1/S2", e.Priority, e.Severity)
+	}
 	if e.LastRefreshedAt == nil {
 		t.Error("LastRefreshedAt should be set")
 	}
+
+	if err := MarkBugzillaEntityRestricted(db, id); err != nil {
+		t.Fatalf("mark restricted: %v", err)
+	}
+	entities, _ = ListBugzillaEntities(db)
+	e = entities[0]
+	if !e.Restricted || e.Status != "" || e.Assignee != "" || e.Priority != "" {
+		t.Errorf("expected restricted entity with cleared fields, got %+v", e)
+	}
+
+	if err := UpdateBugzillaEntityStatus(db, id, update); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	entities, _ = ListBugzillaEntities(db)
+	if entities[0].Restricted {
+		t.Error("a successful refresh should clear the restricted flag")
+	}
 }
 
 func TestExtractBugzillaFromSnapshot_WithTitle(t *testing.T) {
@@ -327,6 +348,9 @@ func TestFormatBugzillaMarkdown(t *testing.T) {
 			ID:              1,
 			Host:            "bugzilla.mozilla.org",
 			BugID:           1900001,
+			Status:          "NEW",
+			Priority:        "P1",
+			Severity:        "--",
 			FirstSeenAt:     now.Add(-48 * time.Hour),
 			FirstSeenSource: "tab",
 		},
@@ -347,8 +371,8 @@ func TestFormatBugzillaMarkdown(t *testing.T) {
 	if !strings.Contains(out, "## bugzilla.mozilla.org (1)") {
 		t.Fatalf("expected bugzilla.mozilla.org group header, got:\n%s", out)
 	}
-	if !strings.Contains(out, "- bugzilla.mozilla.org#1900001") {
-		t.Fatalf("expected issue line, got:\n%s", out)
+	if !strings.Contains(out, "- bugzilla.mozilla.org#1900001 [NEW] [P1]") {
+		t.Fatalf("expected issue line with priority, got:\n%s", out)
 	}
 	if !strings.Contains(out, "First seen: "+entities[1].FirstSeenAt.Format("2006-01-02")+" (signal)") {
 		t.Fatalf("expected fallback first-seen source from events, got:\n%s", out)
@@ -365,6 +389,8 @@ func TestFormatBugzillaJSON(t *testing.T) {
 		{
 			Host:            "bugzilla.mozilla.org",
 			BugID:           1900001,
+			Priority:        "P2",
+			Severity:        "S3",
 			FirstSeenAt:     time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC),
 			FirstSeenSource: "signal",
 		},
@@ -389,7 +415,61 @@ func TestFormatBugzillaJSON(t *testing.T) {
 	if row.URL != "https://bugzilla.mozilla.org/show_bug.cgi?id=1900001" {
 		t.Fatalf("unexpected url: %q", row.URL)
 	}
+	if row.Priority != "P2" || row.Severity != "S3" {
+		t.Fatalf("unexpected priority/severity: %+v", row)
+	}
 	if row.FirstSeenAt != "2026-02-20T10:00:00Z" || row.FirstSeenSource != "signal" {
 		t.Fatalf("unexpected first seen fields: %+v", row)
 	}
 }
+
+func TestLinkBugzillaToGitHub(t *testing.T) {
+	db := testDB(t)
+	id, _, err := UpsertBugzillaEntity(db, "bugzilla.mozilla.org", 1900001, "tab")
+	if err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	bug := BugzillaEntity{ID: id, Host: "bugzilla.mozilla.org", BugID: 1900001}
+	urls := []string{
+		"https://github.com/mozilla/gecko-dev/pull/42",
+		"https://bugs.webkit.org/show_bug.cgi?id=1",
+		"https://github.com/mozilla/gecko-dev/issues/7",
+	}
+
+	n, err := LinkBugzillaToGitHub(db, bug, urls)
+	if err != nil {
+		t.Fatalf("link: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("want 2 new links, got %d", n)
+	}
+	// Linking again is a no-op.
+	if n, _ := LinkBugzillaToGitHub(db, bug, urls); n != 0 {
+		t.Fatalf("want 0 new links on repeat, got %d", n)
+	}
+
+	linked, err := ListLinkedGitHubEntities(db, id)
+	if err != nil {
+		t.Fatalf("list linked github: %v", err)
+	}
+	if len(linked) != 2 || linked[0].Number != 7 || linked[1].Number != 42 || linked[1].Kind != "pull" {
+		t.Fatalf("unexpected linked github entities: %+v", linked)
+	}
+
+	bugs, err := ListLinkedBugzillaEntities(db, linked[1].ID)
+	if err != nil {
+		t.Fatalf("list linked bugs: %v", err)
+	}
+	if len(bugs) != 1 || bugs[0].BugID != 1900001 {
+		t.Fatalf("unexpected linked bugs: %+v", bugs)
+	}
+
+	bzEvents, _ := ListBugzillaEntityEvents(db, id)
+	if len(bzEvents) != 2 || bzEvents[0].EventType != "linked" {
+		t.Errorf("expected 2 linked events on the bug, got %+v", bzEvents)
+	}
+	ghEvents, _ := ListGitHubEntityEvents(db, linked[1].ID)
+	if len(ghEvents) != 1 || ghEvents[0].Detail != "bugzilla.mozilla.org#1900001" {
+		t.Errorf("expected linked event on the PR, got %+v", ghEvents)
+	}
+}
@@ -0,0 +1,36 @@
+package storage
+
+import (
+	"errors"
+	"time"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// busyTimeout is how long a connection waits for another one holding the
+// write lock before SQLite reports SQLITE_BUSY.
+const busyTimeout = 5 * time.Second
+
+// busyRetries is how often retryBusy retries a write that still found the
+// database busy after busyTimeout.
+const busyRetries = 3
+
+// isBusy reports whether err is SQLite's "database is locked".
+func isBusy(err error) bool {
+	var e *sqlite.Error
+	return errors.As(err, &e) && e.Code()&0xff == sqlite3.SQLITE_BUSY
+}
+
+// retryBusy runs the write fn, running it again after a short pause while
+// it fails with SQLITE_BUSY. fn must be a complete transaction, so a failed
+// attempt leaves nothing behind.
+func retryBusy(fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || !isBusy(err) || attempt > busyRetries {
+			return err
+		}
+		time.Sleep(time.Duration(attempt) * 200 * time.Millisecond)
+	}
+}
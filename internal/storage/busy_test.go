@@ -0,0 +1,130 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestConcurrentSnapshots opens the database twice, like the TUI and a
+// cron "snapshot" do, and writes from both at once.
+func TestConcurrentSnapshots(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tabsordnung.db")
+	tui, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer tui.Close()
+	cron, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("second OpenDB: %v", err)
+	}
+	defer cron.Close()
+
+	const perWriter = 20
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*perWriter)
+	for w, db := range []*sql.DB{tui, cron} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				tabs := []SnapshotTab{{URL: fmt.Sprintf("https://example.com/%d/%d", w, i), Title: "t"}}
+				if _, err := CreateSnapshot(db, "default", nil, tabs, ""); err != nil {
+					errs <- err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("CreateSnapshot: %v", err)
+	}
+
+	snaps, err := ListSnapshotsByProfile(tui, "default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snaps) != 2*perWriter {
+		t.Fatalf("got %d snapshots, want %d", len(snaps), 2*perWriter)
+	}
+	revs := make(map[int]bool)
+	for _, s := range snaps {
+		if revs[s.Rev] {
+			t.Errorf("rev %d assigned twice", s.Rev)
+		}
+		revs[s.Rev] = true
+	}
+}
+
+func TestRetryBusy(t *testing.T) {
+	calls := 0
+	err := retryBusy(func() error {
+		calls++
+		return errors.New("not busy")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("non-busy error: calls = %d, err = %v; want one call", calls, err)
+	}
+
+	calls = 0
+	if err := retryBusy(func() error { calls++; return nil }); err != nil || calls != 1 {
+		t.Errorf("success: calls = %d, err = %v", calls, err)
+	}
+}
+
+// TestRetryBusyWaitsForLock holds the write lock from one connection while
+// another, with no busy timeout of its own, writes through retryBusy.
+func TestRetryBusyWaitsForLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tabsordnung.db")
+	holder, err := OpenDB(path)
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer holder.Close()
+	writer, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(0)&_txlock=immediate")
+	if err != nil {
+		t.Fatalf("open writer: %v", err)
+	}
+	defer writer.Close()
+
+	ctx := context.Background()
+	lock, err := holder.Conn(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.Close()
+	if _, err := lock.ExecContext(ctx, "BEGIN IMMEDIATE"); err != nil {
+		t.Fatalf("take the write lock: %v", err)
+	}
+	released := make(chan struct{})
+	go func() {
+		time.Sleep(300 * time.Millisecond)
+		lock.ExecContext(ctx, "COMMIT")
+		close(released)
+	}()
+
+	calls := 0
+	var first error
+	err = retryBusy(func() error {
+		calls++
+		_, err := writer.Exec(`INSERT INTO settings (key, value) VALUES ('busy', 'x')`)
+		if calls == 1 {
+			first = err
+		}
+		return err
+	})
+	<-released
+	if !isBusy(first) {
+		t.Fatalf("first attempt: err = %v, want SQLITE_BUSY", first)
+	}
+	if err != nil || calls < 2 {
+		t.Errorf("calls = %d, err = %v; want a retry that succeeds", calls, err)
+	}
+}
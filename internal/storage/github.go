@@ -2,6 +2,7 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"regexp"
@@ -17,7 +18,7 @@ type GitHubEntity struct {
 	Owner           string
 	Repo            string
 	Number          int
-	Kind            string // "pull" or "issue"
+	Kind            string // "pull", "issue" or "discussion"
 	Title           string
 	State           string // "open", "closed", "merged", ""
 	Author          string
@@ -44,7 +45,7 @@ type GitHubEntityEvent struct {
 // GitHubFilter controls which entities are returned by ListGitHubEntities.
 type GitHubFilter struct {
 	State string // "open", "closed", "merged", or "" for all
-	Kind  string // "pull", "issue", or "" for all
+	Kind  string // "pull", "issue", "discussion", or "" for all
 	Repo  string // "owner/repo" or "" for all
 }
 
@@ -447,14 +448,58 @@ func FormatGitHubJSON(entities []GitHubEntity) (string, error) {
 	return string(data) + "\n", nil
 }
 
+// githubCSVHeader is the header row written by FormatGitHubCSV.
+var githubCSVHeader = []string{
+	"owner", "repo", "number", "kind", "title", "state", "author", "assignees",
+	"review_status", "checks_status", "first_seen_at", "gh_updated_at", "url",
+}
+
+// FormatGitHubCSV formats entities as CSV with a header row. Timestamps are
+// RFC 3339; missing values are empty.
+func FormatGitHubCSV(entities []GitHubEntity) (string, error) {
+	var b strings.Builder
+	w := csv.NewWriter(&b)
+	if err := w.Write(githubCSVHeader); err != nil {
+		return "", err
+	}
+	for _, e := range entities {
+		var review, checks, ghUpdated string
+		if e.ReviewStatus != nil {
+			review = *e.ReviewStatus
+		}
+		if e.ChecksStatus != nil {
+			checks = *e.ChecksStatus
+		}
+		if e.GHUpdatedAt != nil {
+			ghUpdated = e.GHUpdatedAt.Format(time.RFC3339)
+		}
+		record := []string{
+			e.Owner, e.Repo, strconv.Itoa(e.Number), e.Kind, e.Title, e.State, e.Author, e.Assignees,
+			review, checks, e.FirstSeenAt.Format(time.RFC3339), ghUpdated,
+			fmt.Sprintf("https://github.com/%s/%s/%s/%d", e.Owner, e.Repo, entityURLPath(e.Kind), e.Number),
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
 func entityURLPath(kind string) string {
-	if kind == "issue" {
+	switch kind {
+	case "issue":
 		return "issues"
+	case "discussion":
+		return "discussions"
 	}
 	return "pull"
 }
 
-// ghRef holds the parsed components of a GitHub issue/PR URL.
+// ghRef holds the parsed components of a GitHub issue, PR or discussion URL.
 type ghRef struct {
 	owner  string
 	repo   string
@@ -462,7 +507,7 @@ type ghRef struct {
 	kind   string
 }
 
-var ghURLPattern = regexp.MustCompile(`https?://github\.com/([^/]+)/([^/]+)/(issues|pull)/(\d+)`)
+var ghURLPattern = regexp.MustCompile(`https?://github\.com/([^/]+)/([^/]+)/(issues|pull|discussions)/(\d+)`)
 
 func extractGitHubRef(rawURL string) *ghRef {
 	matches := ghURLPattern.FindStringSubmatch(rawURL)
@@ -471,8 +516,11 @@ func extractGitHubRef(rawURL string) *ghRef {
 	}
 	num, _ := strconv.Atoi(matches[4])
 	kind := "issue"
-	if matches[3] == "pull" {
+	switch matches[3] {
+	case "pull":
 		kind = "pull"
+	case "discussions":
+		kind = "discussion"
 	}
 	return &ghRef{owner: matches[1], repo: matches[2], number: num, kind: kind}
 }
@@ -380,6 +380,8 @@ func TestExtractGitHubFromSnapshot(t *testing.T) {
 		{URL: "https://github.com/mozilla/gecko-dev/pull/123", Title: "Fix bug"},
 		{URL: "https://mail.google.com/inbox", Title: "Gmail"},
 		{URL: "https://github.com/org/repo/issues/42", Title: "Feature request"},
+		{URL: "https://github.com/org/repo/discussions/7", Title: "RFC"},
+		{URL: "https://github.com/org/repo/commit/9fceb02", Title: "Commit"},
 	}, "")
 	if err != nil {
 		t.Fatalf("CreateSnapshot: %v", err)
@@ -393,13 +395,17 @@ func TestExtractGitHubFromSnapshot(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ExtractGitHubFromSnapshot: %v", err)
 	}
-	if count != 2 {
-		t.Fatalf("expected 2 entities extracted, got %d", count)
+	if count != 3 {
+		t.Fatalf("expected 3 entities extracted, got %d", count)
 	}
 
 	entities, _ := ListGitHubEntities(db, GitHubFilter{})
-	if len(entities) != 2 {
-		t.Fatalf("expected 2 entities, got %d", len(entities))
+	if len(entities) != 3 {
+		t.Fatalf("expected 3 entities, got %d", len(entities))
+	}
+	discussions, _ := ListGitHubEntities(db, GitHubFilter{Kind: "discussion"})
+	if len(discussions) != 1 || discussions[0].Number != 7 {
+		t.Fatalf("expected discussion #7, got %+v", discussions)
 	}
 }
 
@@ -640,3 +646,33 @@ func TestFormatGitHubJSON(t *testing.T) {
 func ptrTime(t time.Time) *time.Time {
 	return &t
 }
+
+func TestFormatGitHubCSV(t *testing.T) {
+	ghUpdated := time.Date(2026, 2, 24, 8, 30, 0, 0, time.UTC)
+	review := "approved"
+	entities := []GitHubEntity{
+		{
+			Owner:        "mozilla",
+			Repo:         "gecko-dev",
+			Number:       1234,
+			Kind:         "pull",
+			Title:        "Fix login, again",
+			State:        "open",
+			Author:       "user1",
+			Assignees:    "user1,user2",
+			ReviewStatus: &review,
+			FirstSeenAt:  time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC),
+			GHUpdatedAt:  &ghUpdated,
+		},
+	}
+
+	out, err := FormatGitHubCSV(entities)
+	if err != nil {
+		t.Fatalf("FormatGitHubCSV: %v", err)
+	}
+	want := "owner,repo,number,kind,title,state,author,assignees,review_status,checks_status,first_seen_at,gh_updated_at,url\n" +
+		"mozilla,gecko-dev,1234,pull,\"Fix login, again\",open,user1,\"user1,user2\",approved,,2026-01-15T10:00:00Z,2026-02-24T08:30:00Z,https://github.com/mozilla/gecko-dev/pull/1234\n"
+	if out != want {
+		t.Errorf("unexpected CSV:\n got: %q\nwant: %q", out, want)
+	}
+}
@@ -0,0 +1,492 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// GitLabEntity represents a tracked GitLab merge request or issue on any
+// GitLab instance.
+type GitLabEntity struct {
+	ID              int64
+	Host            string
+	Project         string // full project path, e.g. "group/subgroup/project"
+	Number          int    // project-scoped IID
+	Kind            string // "merge_request" or "issue"
+	Title           string
+	State           string // "opened", "closed", "merged" or "locked"
+	Author          string
+	Assignees       string // comma-separated usernames
+	FirstSeenAt     time.Time
+	FirstSeenSource string
+	LastRefreshedAt *time.Time
+}
+
+// GitLabStatusUpdate holds API-fetched fields to persist.
+type GitLabStatusUpdate struct {
+	Title, State, Author string
+	Assignees            []string
+}
+
+// GitLabEntityEvent is a timeline entry for a GitLab entity.
+type GitLabEntityEvent struct {
+	ID         int64
+	EntityID   int64
+	EventType  string // "tab_seen", "signal_seen" or "status_changed"
+	SignalID   *int64
+	SnapshotID *int64
+	Detail     string
+	CreatedAt  time.Time
+}
+
+// Ref formats the entity the way GitLab does: "group/project!12" for merge
+// requests and "group/project#12" for issues.
+func (e GitLabEntity) Ref() string {
+	return GitLabRef(e.Project, e.Kind, e.Number)
+}
+
+// URL returns the entity's web page.
+func (e GitLabEntity) URL() string {
+	return GitLabURL(e.Host, e.Project, e.Kind, e.Number)
+}
+
+// GitLabRef formats a project-scoped reference, using "!" for merge
+// requests and "#" for issues.
+func GitLabRef(project, kind string, number int) string {
+	sep := "#"
+	if kind == "merge_request" {
+		sep = "!"
+	}
+	return fmt.Sprintf("%s%s%d", project, sep, number)
+}
+
+// GitLabURL builds the web URL of a merge request or issue.
+func GitLabURL(host, project, kind string, number int) string {
+	path := "issues"
+	if kind == "merge_request" {
+		path = "merge_requests"
+	}
+	return fmt.Sprintf("https://%s/%s/-/%s/%d", host, project, path, number)
+}
+
+type gitlabRef struct {
+	host    string
+	project string
+	number  int
+	kind    string
+}
+
+// UpsertGitLabEntity looks up an entity by host+project+kind+number. If it
+// does not exist, it inserts a new row. Merge requests and issues are
+// numbered separately, so kind is part of the key. Returns (id, isNew, error).
+func UpsertGitLabEntity(db *sql.DB, host, project string, number int, kind, source string) (int64, bool, error) {
+	var id int64
+	err := db.QueryRow(
+		`SELECT id FROM gitlab_entities WHERE host = ? AND project = ? AND kind = ? AND number = ?`,
+		host, project, kind, number,
+	).Scan(&id)
+	if err == nil {
+		return id, false, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, false, fmt.Errorf("select gitlab entity: %w", err)
+	}
+
+	res, err := db.Exec(
+		`INSERT INTO gitlab_entities (host, project, number, kind, first_seen_source)
+		 VALUES (?, ?, ?, ?, ?)`,
+		host, project, number, kind, source,
+	)
+	if err != nil {
+		return 0, false, fmt.Errorf("insert gitlab entity: %w", err)
+	}
+	id, err = res.LastInsertId()
+	if err != nil {
+		return 0, false, fmt.Errorf("get last insert id: %w", err)
+	}
+	return id, true, nil
+}
+
+// RecordGitLabEvent inserts a timeline event for a GitLab entity.
+func RecordGitLabEvent(db *sql.DB, entityID int64, eventType string, signalID *int64, snapshotID *int64, detail string) error {
+	_, err := db.Exec(
+		`INSERT OR IGNORE INTO gitlab_entity_events (entity_id, event_type, signal_id, snapshot_id, detail)
+		 VALUES (?, ?, ?, ?, ?)`,
+		entityID, eventType, signalID, snapshotID, detail,
+	)
+	if err != nil {
+		return fmt.Errorf("insert gitlab entity event: %w", err)
+	}
+	return nil
+}
+
+// ListGitLabEntities returns tracked entities ordered by first_seen_at DESC.
+func ListGitLabEntities(db *sql.DB) ([]GitLabEntity, error) {
+	rows, err := db.Query(
+		`SELECT id, host, project, number, kind, title, state, author, assignees,
+		        first_seen_at, first_seen_source, last_refreshed_at
+		 FROM gitlab_entities
+		 ORDER BY first_seen_at DESC, id DESC`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query gitlab entities: %w", err)
+	}
+	defer rows.Close()
+
+	var result []GitLabEntity
+	for rows.Next() {
+		var e GitLabEntity
+		var lr sql.NullTime
+		if err := rows.Scan(&e.ID, &e.Host, &e.Project, &e.Number, &e.Kind,
+			&e.Title, &e.State, &e.Author, &e.Assignees,
+			&e.FirstSeenAt, &e.FirstSeenSource, &lr); err != nil {
+			return nil, fmt.Errorf("scan gitlab entity: %w", err)
+		}
+		if lr.Valid {
+			e.LastRefreshedAt = &lr.Time
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// UpdateGitLabEntityStatus persists API-fetched fields and sets last_refreshed_at.
+func UpdateGitLabEntityStatus(db *sql.DB, id int64, u GitLabStatusUpdate) error {
+	res, err := db.Exec(
+		`UPDATE gitlab_entities SET title=?, state=?, author=?, assignees=?,
+		 last_refreshed_at=CURRENT_TIMESTAMP WHERE id=?`,
+		u.Title, u.State, u.Author, strings.Join(u.Assignees, ","), id)
+	if err != nil {
+		return fmt.Errorf("update gitlab entity status: %w", err)
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("entity %d not found", id)
+	}
+	return nil
+}
+
+// GitLabEntityCount returns the number of tracked GitLab entities.
+func GitLabEntityCount(db *sql.DB) (int, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM gitlab_entities`).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("count gitlab entities: %w", err)
+	}
+	return count, nil
+}
+
+// ListGitLabEntityEvents returns all events for an entity, ordered by created_at ASC.
+func ListGitLabEntityEvents(db *sql.DB, entityID int64) ([]GitLabEntityEvent, error) {
+	rows, err := db.Query(
+		`SELECT id, entity_id, event_type, signal_id, snapshot_id, detail, created_at
+		 FROM gitlab_entity_events WHERE entity_id = ? ORDER BY created_at ASC`,
+		entityID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query gitlab entity events: %w", err)
+	}
+	defer rows.Close()
+
+	var result []GitLabEntityEvent
+	for rows.Next() {
+		var ev GitLabEntityEvent
+		var signalID, snapshotID sql.NullInt64
+		if err := rows.Scan(&ev.ID, &ev.EntityID, &ev.EventType, &signalID, &snapshotID, &ev.Detail, &ev.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scan gitlab entity event: %w", err)
+		}
+		if signalID.Valid {
+			v := signalID.Int64
+			ev.SignalID = &v
+		}
+		if snapshotID.Valid {
+			v := snapshotID.Int64
+			ev.SnapshotID = &v
+		}
+		result = append(result, ev)
+	}
+	return result, rows.Err()
+}
+
+// GitLabJSONOutput is the structure for `tabsordnung gitlab --json` output.
+type GitLabJSONOutput struct {
+	Host            string `json:"host"`
+	Project         string `json:"project"`
+	Number          int    `json:"number"`
+	Kind            string `json:"kind"`
+	URL             string `json:"url"`
+	Title           string `json:"title"`
+	State           string `json:"state"`
+	Author          string `json:"author"`
+	Assignees       string `json:"assignees"`
+	FirstSeenAt     string `json:"first_seen_at"`
+	FirstSeenSource string `json:"first_seen_source"`
+	LastRefreshedAt string `json:"last_refreshed_at,omitempty"`
+}
+
+// FormatGitLabMarkdown formats entities grouped by project as markdown.
+func FormatGitLabMarkdown(entities []GitLabEntity, events map[int64][]GitLabEntityEvent) string {
+	if len(entities) == 0 {
+		return "No GitLab merge requests or issues found.\n"
+	}
+
+	grouped := make(map[string][]GitLabEntity)
+	for _, e := range entities {
+		key := e.Host + "/" + e.Project
+		grouped[key] = append(grouped[key], e)
+	}
+	projects := make([]string, 0, len(grouped))
+	for p := range grouped {
+		projects = append(projects, p)
+	}
+	sort.Strings(projects)
+
+	var b strings.Builder
+	for _, project := range projects {
+		items := grouped[project]
+		fmt.Fprintf(&b, "## %s (%d)\n\n", project, len(items))
+		for _, e := range items {
+			stateStr := ""
+			if e.State != "" {
+				stateStr = " [" + e.State + "]"
+			}
+			titleStr := ""
+			if t := strings.TrimSpace(e.Title); t != "" {
+				titleStr = " " + t
+			}
+			fmt.Fprintf(&b, "- %s%s%s\n", e.Ref(), stateStr, titleStr)
+			var details []string
+			if e.Author != "" {
+				details = append(details, "Author: "+e.Author)
+			}
+			if e.Assignees != "" {
+				details = append(details, "Assignees: "+e.Assignees)
+			}
+			if len(details) > 0 {
+				fmt.Fprintf(&b, "  %s\n", strings.Join(details, " · "))
+			}
+			source := e.FirstSeenSource
+			if source == "" {
+				source = firstSeenSourceGitLab(e, events)
+			}
+			fmt.Fprintf(&b, "  First seen: %s (%s)\n  URL: %s\n\n",
+				e.FirstSeenAt.Format("2006-01-02"), source, e.URL())
+		}
+	}
+	return b.String()
+}
+
+func firstSeenSourceGitLab(e GitLabEntity, events map[int64][]GitLabEntityEvent) string {
+	entityEvents, ok := events[e.ID]
+	if !ok || len(entityEvents) == 0 {
+		return "unknown"
+	}
+	switch entityEvents[0].EventType {
+	case "tab_seen":
+		return "tab"
+	case "signal_seen":
+		return "signal"
+	default:
+		return "unknown"
+	}
+}
+
+// FormatGitLabJSON formats entities as a flat JSON array.
+func FormatGitLabJSON(entities []GitLabEntity) (string, error) {
+	out := make([]GitLabJSONOutput, 0, len(entities))
+	for _, e := range entities {
+		item := GitLabJSONOutput{
+			Host:            e.Host,
+			Project:         e.Project,
+			Number:          e.Number,
+			Kind:            e.Kind,
+			URL:             e.URL(),
+			Title:           e.Title,
+			State:           e.State,
+			Author:          e.Author,
+			Assignees:       e.Assignees,
+			FirstSeenAt:     e.FirstSeenAt.Format(time.RFC3339),
+			FirstSeenSource: e.FirstSeenSource,
+		}
+		if e.LastRefreshedAt != nil {
+			item.LastRefreshedAt = e.LastRefreshedAt.Format(time.RFC3339)
+		}
+		out = append(out, item)
+	}
+
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+// ExtractGitLabFromSnapshot scans a snapshot's tabs for GitLab merge request
+// and issue URLs and upserts entities. Returns the number of entities found.
+func ExtractGitLabFromSnapshot(db *sql.DB, snapshotID int64) (int, error) {
+	rows, err := db.Query("SELECT url FROM snapshot_tabs WHERE snapshot_id = ?", snapshotID)
+	if err != nil {
+		return 0, fmt.Errorf("query snapshot tabs: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		var tabURL string
+		if err := rows.Scan(&tabURL); err != nil {
+			continue
+		}
+		ref := extractGitLabFromURL(tabURL)
+		if ref == nil {
+			continue
+		}
+		id, _, err := UpsertGitLabEntity(db, ref.host, ref.project, ref.number, ref.kind, "tab")
+		if err != nil {
+			continue
+		}
+		_ = RecordGitLabEvent(db, id, "tab_seen", nil, &snapshotID, "")
+		count++
+	}
+	return count, rows.Err()
+}
+
+// ExtractGitLabFromSignals scans signal fields for GitLab URLs and upserts
+// entities. Returns the number of entities found.
+func ExtractGitLabFromSignals(db *sql.DB, signals []SignalRecord) (int, error) {
+	count := 0
+	for _, sig := range signals {
+		ref := extractGitLabFromSignalRecord(sig)
+		if ref == nil {
+			continue
+		}
+		id, _, err := UpsertGitLabEntity(db, ref.host, ref.project, ref.number, ref.kind, "signal")
+		if err != nil {
+			continue
+		}
+		sigID := sig.ID
+		_ = RecordGitLabEvent(db, id, "signal_seen", &sigID, nil, "")
+		count++
+	}
+	return count, nil
+}
+
+// BackfillGitLabEntities scans all existing snapshot tabs and signals for
+// GitLab references. Safe to run multiple times (upsert-based).
+func BackfillGitLabEntities(db *sql.DB) (int, error) {
+	seen := make(map[string]bool) // "host/project/kind/number"
+
+	rows, err := db.Query(`
+		SELECT st.url, s.id, s.created_at
+		FROM snapshot_tabs st
+		JOIN snapshots s ON s.id = st.snapshot_id
+		ORDER BY s.created_at ASC`)
+	if err != nil {
+		return 0, fmt.Errorf("query snapshot tabs: %w", err)
+	}
+
+	for rows.Next() {
+		var tabURL string
+		var snapID int64
+		var createdAt time.Time
+		if err := rows.Scan(&tabURL, &snapID, &createdAt); err != nil {
+			continue
+		}
+		ref := extractGitLabFromURL(tabURL)
+		if ref == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%d", ref.host, ref.project, ref.kind, ref.number)
+		id, isNew, err := UpsertGitLabEntity(db, ref.host, ref.project, ref.number, ref.kind, "tab")
+		if err != nil {
+			continue
+		}
+		if isNew {
+			db.Exec("UPDATE gitlab_entities SET first_seen_at = ? WHERE id = ?", createdAt, id)
+		}
+		if !seen[key] {
+			_ = RecordGitLabEvent(db, id, "tab_seen", nil, &snapID, "")
+		}
+		seen[key] = true
+	}
+	rows.Close()
+
+	signals, err := ListSignals(db, "", true)
+	if err != nil {
+		return 0, fmt.Errorf("list signals for backfill: %w", err)
+	}
+	for _, sig := range signals {
+		ref := extractGitLabFromSignalRecord(sig)
+		if ref == nil {
+			continue
+		}
+		key := fmt.Sprintf("%s/%s/%s/%d", ref.host, ref.project, ref.kind, ref.number)
+		id, isNew, err := UpsertGitLabEntity(db, ref.host, ref.project, ref.number, ref.kind, "signal")
+		if err != nil {
+			continue
+		}
+		if isNew {
+			db.Exec("UPDATE gitlab_entities SET first_seen_at = ? WHERE id = ?", sig.CapturedAt, id)
+		}
+		if !seen[key] {
+			sigID := sig.ID
+			_ = RecordGitLabEvent(db, id, "signal_seen", &sigID, nil, "")
+		}
+		seen[key] = true
+	}
+
+	return len(seen), nil
+}
+
+func extractGitLabFromSignalRecord(sig SignalRecord) *gitlabRef {
+	for _, text := range []string{sig.Snippet, sig.Preview, sig.Title} {
+		if text == "" {
+			continue
+		}
+		for _, candidate := range urlCandidatePattern.FindAllString(text, -1) {
+			if ref := extractGitLabFromURL(candidate); ref != nil {
+				return ref
+			}
+		}
+	}
+	return nil
+}
+
+// extractGitLabFromURL recognizes GitLab merge request and issue pages,
+// https://<host>/<project>/-/merge_requests/<iid> and .../-/issues/<iid>, on
+// any host. Project paths may contain subgroups.
+func extractGitLabFromURL(rawURL string) *gitlabRef {
+	if rawURL == "" {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return nil
+	}
+	project, rest, ok := strings.Cut(strings.Trim(u.Path, "/"), "/-/")
+	if !ok || project == "" {
+		return nil
+	}
+	parts := strings.Split(rest, "/")
+	if len(parts) < 2 {
+		return nil
+	}
+	var kind string
+	switch parts[0] {
+	case "merge_requests":
+		kind = "merge_request"
+	case "issues":
+		kind = "issue"
+	default:
+		return nil
+	}
+	number, ok := parsePositiveInt(parts[1])
+	if !ok {
+		return nil
+	}
+	return &gitlabRef{host: strings.ToLower(u.Hostname()), project: project, number: number, kind: kind}
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExtractGitLabFromURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		host    string
+		project string
+		kind    string
+		number  int
+	}{
+		{"https://gitlab.com/gitlab-org/gitlab/-/merge_requests/123", "gitlab.com", "gitlab-org/gitlab", "merge_request", 123},
+		{"https://gitlab.com/gitlab-org/gitlab/-/issues/45#note_1", "gitlab.com", "gitlab-org/gitlab", "issue", 45},
+		{"https://gitlab.gnome.org/GNOME/gtk/-/merge_requests/7/diffs", "gitlab.gnome.org", "GNOME/gtk", "merge_request", 7},
+		{"https://GitLab.Example.com/group/sub/project/-/issues/9", "gitlab.example.com", "group/sub/project", "issue", 9},
+		{"https://gitlab.com/gitlab-org/gitlab/-/merge_requests", "", "", "", 0},
+		{"https://gitlab.com/gitlab-org/gitlab/-/pipelines/1", "", "", "", 0},
+		{"https://gitlab.com/gitlab-org/gitlab/-/issues/abc", "", "", "", 0},
+		{"https://gitlab.com/-/issues/1", "", "", "", 0},
+		{"https://github.com/owner/repo/issues/1", "", "", "", 0},
+		{"not a url", "", "", "", 0},
+	}
+	for _, tt := range tests {
+		ref := extractGitLabFromURL(tt.url)
+		if tt.host == "" {
+			if ref != nil {
+				t.Errorf("extractGitLabFromURL(%q) = %+v, want nil", tt.url, *ref)
+			}
+			continue
+		}
+		if ref == nil {
+			t.Errorf("extractGitLabFromURL(%q) = nil", tt.url)
+			continue
+		}
+		if ref.host != tt.host || ref.project != tt.project || ref.kind != tt.kind || ref.number != tt.number {
+			t.Errorf("extractGitLabFromURL(%q) = %+v", tt.url, *ref)
+		}
+	}
+}
+
+func TestGitLabRefAndURL(t *testing.T) {
+	mr := GitLabEntity{Host: "gitlab.com", Project: "group/project", Kind: "merge_request", Number: 7}
+	if got := mr.Ref(); got != "group/project!7" {
+		t.Errorf("MR Ref = %q", got)
+	}
+	if got := mr.URL(); got != "https://gitlab.com/group/project/-/merge_requests/7" {
+		t.Errorf("MR URL = %q", got)
+	}
+	issue := GitLabEntity{Host: "gitlab.com", Project: "group/project", Kind: "issue", Number: 7}
+	if got := issue.Ref(); got != "group/project#7" {
+		t.Errorf("issue Ref = %q", got)
+	}
+	if got := issue.URL(); got != "https://gitlab.com/group/project/-/issues/7" {
+		t.Errorf("issue URL = %q", got)
+	}
+}
+
+func TestUpsertGitLabEntity_KindIsPartOfKey(t *testing.T) {
+	db := testDB(t)
+
+	mrID, isNew, err := UpsertGitLabEntity(db, "gitlab.com", "group/project", 7, "merge_request", "tab")
+	if err != nil || !isNew {
+		t.Fatalf("first upsert: id=%d new=%v err=%v", mrID, isNew, err)
+	}
+	again, isNew, err := UpsertGitLabEntity(db, "gitlab.com", "group/project", 7, "merge_request", "signal")
+	if err != nil || isNew || again != mrID {
+		t.Fatalf("repeat upsert: id=%d new=%v err=%v, want id=%d", again, isNew, err, mrID)
+	}
+	issueID, isNew, err := UpsertGitLabEntity(db, "gitlab.com", "group/project", 7, "issue", "tab")
+	if err != nil || !isNew || issueID == mrID {
+		t.Fatalf("issue upsert: id=%d new=%v err=%v", issueID, isNew, err)
+	}
+
+	count, err := GitLabEntityCount(db)
+	if err != nil {
+		t.Fatalf("GitLabEntityCount: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 entities, got %d", count)
+	}
+}
+
+func TestUpdateGitLabEntityStatus(t *testing.T) {
+	db := testDB(t)
+
+	id, _, err := UpsertGitLabEntity(db, "gitlab.com", "group/project", 3, "merge_request", "tab")
+	if err != nil {
+		t.Fatalf("UpsertGitLabEntity: %v", err)
+	}
+	err = UpdateGitLabEntityStatus(db, id, GitLabStatusUpdate{
+		Title: "Fix parser", State: "merged", Author: "alice",
+		Assignees: []string{"bob", "carol"},
+	})
+	if err != nil {
+		t.Fatalf("UpdateGitLabEntityStatus: %v", err)
+	}
+	if err := RecordGitLabEvent(db, id, "status_changed", nil, nil, "opened -> merged"); err != nil {
+		t.Fatalf("RecordGitLabEvent: %v", err)
+	}
+
+	entities, err := ListGitLabEntities(db)
+	if err != nil {
+		t.Fatalf("ListGitLabEntities: %v", err)
+	}
+	if len(entities) != 1 {
+		t.Fatalf("expected 1 entity, got %d", len(entities))
+	}
+	e := entities[0]
+	if e.Title != "Fix parser" || e.State != "merged" || e.Author != "alice" || e.Assignees != "bob,carol" {
+		t.Errorf("unexpected fields: %+v", e)
+	}
+	if e.LastRefreshedAt == nil {
+		t.Error("expected LastRefreshedAt to be set")
+	}
+
+	events, err := ListGitLabEntityEvents(db, id)
+	if err != nil {
+		t.Fatalf("ListGitLabEntityEvents: %v", err)
+	}
+	if len(events) != 1 || events[0].EventType != "status_changed" || events[0].Detail != "opened -> merged" {
+		t.Errorf("unexpected events: %+v", events)
+	}
+
+	if err := UpdateGitLabEntityStatus(db, 9999, GitLabStatusUpdate{}); err == nil {
+		t.Error("expected error for missing entity")
+	}
+}
+
+func TestExtractGitLabFromSnapshot(t *testing.T) {
+	db := testDB(t)
+
+	_, err := CreateSnapshot(db, "default", nil, []SnapshotTab{
+		{URL: "https://gitlab.com/group/project/-/merge_requests/1", Title: "MR"},
+		{URL: "https://gitlab.example.com/team/app/-/issues/2", Title: "Issue"},
+		{URL: "https://example.com", Title: "Example"},
+	}, "")
+	if err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+
+	var snapID int64
+	db.QueryRow("SELECT id FROM snapshots WHERE profile = 'default' AND rev = 1").Scan(&snapID)
+
+	count, err := ExtractGitLabFromSnapshot(db, snapID)
+	if err != nil {
+		t.Fatalf("ExtractGitLabFromSnapshot: %v", err)
+	}
+	if count != 2 {
+		t.Fatalf("expected 2 entities extracted, got %d", count)
+	}
+
+	entities, err := ListGitLabEntities(db)
+	if err != nil {
+		t.Fatalf("ListGitLabEntities: %v", err)
+	}
+	if len(entities) != 2 {
+		t.Fatalf("expected 2 entities, got %d", len(entities))
+	}
+	for _, e := range entities {
+		if e.FirstSeenSource != "tab" {
+			t.Errorf("%s: FirstSeenSource = %q, want tab", e.Ref(), e.FirstSeenSource)
+		}
+	}
+}
+
+func TestExtractGitLabFromSignals(t *testing.T) {
+	db := testDB(t)
+
+	now := time.Now()
+	InsertSignal(db, SignalRecord{
+		Source: "gmail", Title: "GitLab", Preview: "New merge request",
+		Snippet:  "Review https://gitlab.com/group/project/-/merge_requests/12 please",
+		SourceTS: "1:00 PM", CapturedAt: now,
+	})
+	InsertSignal(db, SignalRecord{
+		Source: "slack", Title: "#general", Preview: "no link", SourceTS: "2:00 PM", CapturedAt: now,
+	})
+
+	signals, _ := ListSignals(db, "", false)
+	count, err := ExtractGitLabFromSignals(db, signals)
+	if err != nil {
+		t.Fatalf("ExtractGitLabFromSignals: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 entity extracted, got %d", count)
+	}
+
+	entities, _ := ListGitLabEntities(db)
+	if len(entities) != 1 || entities[0].Ref() != "group/project!12" {
+		t.Fatalf("unexpected entities: %+v", entities)
+	}
+}
+
+func TestFormatGitLabMarkdown(t *testing.T) {
+	now := time.Date(2026, 2, 26, 12, 0, 0, 0, time.UTC)
+	entities := []GitLabEntity{
+		{
+			ID: 1, Host: "gitlab.com", Project: "group/project", Number: 5, Kind: "merge_request",
+			Title: "Add feature", State: "opened", Author: "alice",
+			FirstSeenAt: now.Add(-48 * time.Hour), FirstSeenSource: "tab",
+		},
+		{
+			ID: 2, Host: "gitlab.com", Project: "group/project", Number: 9, Kind: "issue",
+			FirstSeenAt: now.Add(-24 * time.Hour),
+		},
+	}
+	events := map[int64][]GitLabEntityEvent{
+		2: {{EventType: "signal_seen"}},
+	}
+
+	out := FormatGitLabMarkdown(entities, events)
+	if !strings.Contains(out, "## gitlab.com/group/project (2)") {
+		t.Fatalf("expected project group header, got:\n%s", out)
+	}
+	if !strings.Contains(out, "- group/project!5 [opened] Add feature") {
+		t.Fatalf("expected MR line, got:\n%s", out)
+	}
+	if !strings.Contains(out, "First seen: "+entities[1].FirstSeenAt.Format("2006-01-02")+" (signal)") {
+		t.Fatalf("expected fallback first-seen source from events, got:\n%s", out)
+	}
+
+	empty := FormatGitLabMarkdown(nil, nil)
+	if empty != "No GitLab merge requests or issues found.\n" {
+		t.Fatalf("unexpected empty output: %q", empty)
+	}
+}
+
+func TestFormatGitLabJSON(t *testing.T) {
+	entities := []GitLabEntity{
+		{
+			Host: "gitlab.com", Project: "group/project", Number: 5, Kind: "merge_request",
+			State: "merged", Assignees: "bob",
+			FirstSeenAt:     time.Date(2026, 2, 20, 10, 0, 0, 0, time.UTC),
+			FirstSeenSource: "signal",
+		},
+	}
+
+	out, err := FormatGitLabJSON(entities)
+	if err != nil {
+		t.Fatalf("FormatGitLabJSON: %v", err)
+	}
+
+	var got []GitLabJSONOutput
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("json.Unmarshal: %v\noutput:\n%s", err, out)
+	}
+	if len(got) != 1 {
+		t.Fatalf("expected 1 entity, got %d", len(got))
+	}
+	row := got[0]
+	if row.URL != "https://gitlab.com/group/project/-/merge_requests/5" {
+		t.Fatalf("unexpected url: %q", row.URL)
+	}
+	if row.Kind != "merge_request" || row.State != "merged" || row.Assignees != "bob" {
+		t.Fatalf("unexpected fields: %+v", row)
+	}
+	if row.FirstSeenAt != "2026-02-20T10:00:00Z" || row.FirstSeenSource != "signal" {
+		t.Fatalf("unexpected first seen fields: %+v", row)
+	}
+}
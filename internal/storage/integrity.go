@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// CorruptError is returned by OpenDB when the database file is damaged.
+type CorruptError struct {
+	Path     string
+	Problems []string // as reported by PRAGMA integrity_check
+}
+
+func (e *CorruptError) Error() string {
+	const maxShown = 3
+	problems := e.Problems
+	more := ""
+	if len(problems) > maxShown {
+		more = fmt.Sprintf(" (and %d more)", len(problems)-maxShown)
+		problems = problems[:maxShown]
+	}
+	return fmt.Sprintf("database %s is corrupt: %s%s. Move it aside (or restore a backup) and tabsordnung will create a new one",
+		e.Path, strings.Join(problems, "; "), more)
+}
+
+// isCorrupt reports whether err is SQLite finding a damaged file or one
+// that is not a database at all.
+func isCorrupt(err error) bool {
+	var e *sqlite.Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	code := e.Code() & 0xff
+	return code == sqlite3.SQLITE_CORRUPT || code == sqlite3.SQLITE_NOTADB
+}
+
+// maxIntegrityProblems caps how many problems checkIntegrity collects.
+const maxIntegrityProblems = 10
+
+// checkIntegrity runs PRAGMA integrity_check and returns a *CorruptError
+// if it finds problems.
+func checkIntegrity(db *sql.DB, path string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA integrity_check(%d)", maxIntegrityProblems))
+	if err != nil {
+		if isCorrupt(err) {
+			return &CorruptError{Path: path, Problems: []string{err.Error()}}
+		}
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	defer rows.Close()
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("integrity check: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		if isCorrupt(err) {
+			return &CorruptError{Path: path, Problems: []string{err.Error()}}
+		}
+		return fmt.Errorf("integrity check: %w", err)
+	}
+	if len(problems) > 0 {
+		return &CorruptError{Path: path, Problems: problems}
+	}
+	return nil
+}
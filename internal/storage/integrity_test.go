@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOpenDB_NotADatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tabsordnung.db")
+	if err := os.WriteFile(path, []byte(strings.Repeat("not a database ", 500)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := OpenDB(path)
+	var corrupt *CorruptError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("OpenDB = %v, want CorruptError", err)
+	}
+	if corrupt.Path != path || !strings.Contains(err.Error(), "Move it aside") {
+		t.Errorf("unhelpful error: %v", err)
+	}
+}
+
+func TestOpenDB_DamagedPages(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tabsordnung.db")
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range 200 {
+		if _, err := db.Exec(`INSERT INTO snapshots (rev, profile, tab_count, name) VALUES (?, 'default', 1, ?)`,
+			i+1, fmt.Sprintf("%0200d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var pageSize, pages int
+	db.QueryRow("PRAGMA page_size").Scan(&pageSize)
+	db.QueryRow("PRAGMA page_count").Scan(&pages)
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	// Scribble over the second half of the file, leaving the header and
+	// schema page intact.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := pageSize * (pages / 2); i < len(data); i++ {
+		data[i] = 0xAB
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = OpenDB(path)
+	var corrupt *CorruptError
+	if !errors.As(err, &corrupt) {
+		t.Fatalf("OpenDB = %v, want CorruptError", err)
+	}
+}
+
+func TestRunMigrations_FailedMigrationRollsBack(t *testing.T) {
+	db := testDB(t)
+
+	orig := migrations
+	defer func() { migrations = orig }()
+	last := orig[len(orig)-1].Version
+	migrations = append(orig[:len(orig):len(orig)], migration{
+		Version:     last + 1,
+		Description: "half applied",
+		SQL: `CREATE TABLE half_applied (x INTEGER);
+INSERT INTO no_such_table VALUES (1);`,
+	})
+
+	if err := runMigrations(db); err == nil {
+		t.Fatal("expected the broken migration to fail")
+	}
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_applied'`).Scan(&n)
+	if n != 0 {
+		t.Error("table from the failed migration was not rolled back")
+	}
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, last+1).Scan(&n)
+	if n != 0 {
+		t.Error("failed migration was recorded as applied")
+	}
+
+	// Once fixed, the migration applies on the next start.
+	migrations[len(migrations)-1].SQL = `CREATE TABLE half_applied (x INTEGER);`
+	if err := runMigrations(db); err != nil {
+		t.Fatalf("runMigrations after fix: %v", err)
+	}
+	db.QueryRow(`SELECT COUNT(*) FROM schema_migrations WHERE version = ?`, last+1).Scan(&n)
+	if n != 1 {
+		t.Error("fixed migration was not recorded")
+	}
+}
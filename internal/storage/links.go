@@ -0,0 +1,96 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// LinkBugzillaToGitHub records the github.com issues and PRs among urls
+// (a bug's "see also" field) as related to the bug. Referenced GitHub
+// entities are created if they are not tracked yet, and each new link adds
+// a "linked" event to both timelines. Returns the number of new links.
+func LinkBugzillaToGitHub(db *sql.DB, bug BugzillaEntity, urls []string) (int, error) {
+	count := 0
+	for _, u := range urls {
+		ref := extractGitHubRef(u)
+		if ref == nil {
+			continue
+		}
+		ghID, _, err := UpsertGitHubEntity(db, ref.owner, ref.repo, ref.number, ref.kind, "bugzilla")
+		if err != nil {
+			return count, err
+		}
+		res, err := db.Exec(
+			`INSERT OR IGNORE INTO bugzilla_github_links (bugzilla_id, github_id) VALUES (?, ?)`,
+			bug.ID, ghID,
+		)
+		if err != nil {
+			return count, fmt.Errorf("insert bugzilla github link: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		count++
+		ghRef := fmt.Sprintf("%s/%s#%d", ref.owner, ref.repo, ref.number)
+		if err := RecordBugzillaEvent(db, bug.ID, "linked", nil, nil, ghRef); err != nil {
+			return count, err
+		}
+		bugRef := fmt.Sprintf("%s#%d", bug.Host, bug.BugID)
+		if err := RecordGitHubEvent(db, ghID, "linked", nil, nil, bugRef); err != nil {
+			return count, err
+		}
+	}
+	return count, nil
+}
+
+// ListLinkedGitHubEntities returns the GitHub entities linked to a bug,
+// ordered by owner/repo/number. Only identity, title and state are filled in.
+func ListLinkedGitHubEntities(db *sql.DB, bugzillaID int64) ([]GitHubEntity, error) {
+	rows, err := db.Query(
+		`SELECT g.id, g.owner, g.repo, g.number, g.kind, g.title, g.state
+		 FROM bugzilla_github_links l JOIN github_entities g ON g.id = l.github_id
+		 WHERE l.bugzilla_id = ?
+		 ORDER BY g.owner, g.repo, g.number`,
+		bugzillaID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query linked github entities: %w", err)
+	}
+	defer rows.Close()
+
+	var result []GitHubEntity
+	for rows.Next() {
+		var e GitHubEntity
+		if err := rows.Scan(&e.ID, &e.Owner, &e.Repo, &e.Number, &e.Kind, &e.Title, &e.State); err != nil {
+			return nil, fmt.Errorf("scan linked github entity: %w", err)
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// ListLinkedBugzillaEntities returns the bugs linked to a GitHub entity,
+// ordered by host and bug ID. Only identity, title and status are filled in.
+func ListLinkedBugzillaEntities(db *sql.DB, githubID int64) ([]BugzillaEntity, error) {
+	rows, err := db.Query(
+		`SELECT b.id, b.host, b.bug_id, b.title, b.status, b.resolution
+		 FROM bugzilla_github_links l JOIN bugzilla_entities b ON b.id = l.bugzilla_id
+		 WHERE l.github_id = ?
+		 ORDER BY b.host, b.bug_id`,
+		githubID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query linked bugzilla entities: %w", err)
+	}
+	defer rows.Close()
+
+	var result []BugzillaEntity
+	for rows.Next() {
+		var e BugzillaEntity
+		if err := rows.Scan(&e.ID, &e.Host, &e.BugID, &e.Title, &e.Status, &e.Resolution); err != nil {
+			return nil, fmt.Errorf("scan linked bugzilla entity: %w", err)
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
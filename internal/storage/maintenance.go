@@ -0,0 +1,123 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+)
+
+// TableCount is the number of rows in one table.
+type TableCount struct {
+	Name string
+	Rows int
+}
+
+// DatabaseInfo describes the database file, its schema version and how
+// much data it holds.
+type DatabaseInfo struct {
+	Path      string
+	Size      int64 // bytes in the main file
+	WALSize   int64 // bytes in the -wal file, 0 if there is none
+	PageSize  int
+	Pages     int
+	FreePages int // pages VACUUM would release
+	// Migration is the newest applied migration; LatestMigration is the
+	// newest one this build knows about.
+	Migration       int
+	LatestMigration int
+	Tables          []TableCount
+}
+
+// SchemaVersion returns the newest migration applied to the database file
+// at path and the newest one this build knows about. Unlike OpenDB it opens
+// the file read-only and never creates or migrates it, so it is safe to call
+// against a database another build is using.
+func SchemaVersion(path string) (applied, latest int, err error) {
+	latest = migrations[len(migrations)-1].Version
+	if _, err := os.Stat(path); err != nil {
+		return 0, latest, err
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, latest, fmt.Errorf("open database: %w", err)
+	}
+	defer db.Close()
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&applied); err != nil {
+		return 0, latest, fmt.Errorf("read migration version: %w", err)
+	}
+	return applied, latest, nil
+}
+
+// DBInfo reports the size, migration version and row counts of db.
+func DBInfo(db *sql.DB) (*DatabaseInfo, error) {
+	info := &DatabaseInfo{LatestMigration: migrations[len(migrations)-1].Version}
+
+	var seq int
+	var name string
+	if err := db.QueryRow("PRAGMA database_list").Scan(&seq, &name, &info.Path); err != nil {
+		return nil, fmt.Errorf("database path: %w", err)
+	}
+	if fi, err := os.Stat(info.Path); err == nil {
+		info.Size = fi.Size()
+	}
+	if fi, err := os.Stat(info.Path + "-wal"); err == nil {
+		info.WALSize = fi.Size()
+	}
+
+	for pragma, dst := range map[string]*int{
+		"page_size":      &info.PageSize,
+		"page_count":     &info.Pages,
+		"freelist_count": &info.FreePages,
+	} {
+		if err := db.QueryRow("PRAGMA " + pragma).Scan(dst); err != nil {
+			return nil, fmt.Errorf("read %s: %w", pragma, err)
+		}
+	}
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&info.Migration); err != nil {
+		return nil, fmt.Errorf("read migration version: %w", err)
+	}
+
+	rows, err := db.Query(`SELECT name FROM sqlite_master
+		WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("list tables: %w", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var t string
+		if err := rows.Scan(&t); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		tables = append(tables, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for _, t := range tables {
+		tc := TableCount{Name: t}
+		if err := db.QueryRow(fmt.Sprintf(`SELECT COUNT(*) FROM "%s"`, t)).Scan(&tc.Rows); err != nil {
+			return nil, fmt.Errorf("count %s: %w", t, err)
+		}
+		info.Tables = append(info.Tables, tc)
+	}
+	return info, nil
+}
+
+// Vacuum rebuilds the database file to release free pages and truncates
+// the write-ahead log. It needs as much free disk space as the database
+// takes and fails while another connection is writing.
+func Vacuum(db *sql.DB) error {
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	if _, err := db.Exec("VACUUM"); err != nil {
+		return fmt.Errorf("vacuum: %w", err)
+	}
+	// In WAL mode VACUUM writes the new pages to the log; fold them back.
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		return fmt.Errorf("checkpoint: %w", err)
+	}
+	return nil
+}
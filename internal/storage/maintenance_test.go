@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDBInfo(t *testing.T) {
+	db := testDB(t)
+	if _, err := db.Exec(`INSERT INTO snapshots (rev, profile, tab_count) VALUES (1, 'default', 5)`); err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := DBInfo(db)
+	if err != nil {
+		t.Fatalf("DBInfo: %v", err)
+	}
+	if !strings.HasSuffix(info.Path, "test.db") {
+		t.Errorf("Path = %q", info.Path)
+	}
+	if info.Size == 0 || info.PageSize == 0 || info.Pages == 0 {
+		t.Errorf("sizes not filled in: %+v", info)
+	}
+	if info.Migration != info.LatestMigration || info.Migration != migrations[len(migrations)-1].Version {
+		t.Errorf("Migration = %d, LatestMigration = %d", info.Migration, info.LatestMigration)
+	}
+	rows := map[string]int{}
+	for _, tc := range info.Tables {
+		rows[tc.Name] = tc.Rows
+	}
+	if rows["snapshots"] != 1 {
+		t.Errorf("snapshots rows = %d, want 1 (tables: %v)", rows["snapshots"], info.Tables)
+	}
+	if _, ok := rows["schema_migrations"]; !ok {
+		t.Error("schema_migrations missing from table counts")
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	if _, _, err := SchemaVersion(path); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("missing file: err = %v, want ErrNotExist", err)
+	}
+	if _, err := os.Stat(path); err == nil {
+		t.Fatal("SchemaVersion created the database file")
+	}
+
+	db, err := OpenDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	applied, latest, err := SchemaVersion(path)
+	if err != nil {
+		t.Fatalf("SchemaVersion: %v", err)
+	}
+	if applied != latest || latest != migrations[len(migrations)-1].Version {
+		t.Errorf("applied = %d, latest = %d", applied, latest)
+	}
+
+	if _, err := db.Exec("INSERT INTO schema_migrations (version, description) VALUES (?, ?)", latest+1, "from a newer build"); err != nil {
+		t.Fatal(err)
+	}
+	if applied, _, _ := SchemaVersion(path); applied != latest+1 {
+		t.Errorf("applied = %d after a newer migration, want %d", applied, latest+1)
+	}
+}
+
+func TestVacuum(t *testing.T) {
+	db := testDB(t)
+	for i := range 500 {
+		if _, err := db.Exec(`INSERT INTO snapshots (rev, profile, tab_count, name) VALUES (?, 'default', 1, ?)`,
+			i+1, fmt.Sprintf("%0500d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := db.Exec(`DELETE FROM snapshots`); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := DBInfo(db)
+	if before.FreePages == 0 {
+		t.Fatal("expected free pages after deleting rows")
+	}
+
+	if err := Vacuum(db); err != nil {
+		t.Fatalf("Vacuum: %v", err)
+	}
+	after, err := DBInfo(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.FreePages != 0 {
+		t.Errorf("FreePages after vacuum = %d, want 0", after.FreePages)
+	}
+	if after.Pages >= before.Pages {
+		t.Errorf("Pages %d -> %d, expected the file to shrink", before.Pages, after.Pages)
+	}
+	if after.WALSize != 0 {
+		t.Errorf("WALSize after vacuum = %d, want 0", after.WALSize)
+	}
+}
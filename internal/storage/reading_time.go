@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// UpsertWordCount records the word count of a page's readable content.
+func UpsertWordCount(db *sql.DB, url string, words int) error {
+	_, err := db.Exec(
+		`INSERT INTO reading_times (url, word_count, measured_at)
+		 VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(url) DO UPDATE SET
+		   word_count = excluded.word_count,
+		   measured_at = CURRENT_TIMESTAMP`,
+		url, words,
+	)
+	if err != nil {
+		return fmt.Errorf("upsert word count: %w", err)
+	}
+	return nil
+}
+
+// WordCounts returns all recorded word counts keyed by URL.
+func WordCounts(db *sql.DB) (map[string]int, error) {
+	rows, err := db.Query(`SELECT url, word_count FROM reading_times`)
+	if err != nil {
+		return nil, fmt.Errorf("query word counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for rows.Next() {
+		var url string
+		var words int
+		if err := rows.Scan(&url, &words); err != nil {
+			return nil, fmt.Errorf("scan word count: %w", err)
+		}
+		counts[url] = words
+	}
+	return counts, rows.Err()
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// SignalEntity is the GitHub, Bugzilla or GitLab entity a signal refers
+// to, as recorded by the "signal_seen" events of ExtractGitHubFromSignals,
+// ExtractBugzillaFromSignals and ExtractGitLabFromSignals.
+type SignalEntity struct {
+	Source   string // "github", "bugzilla" or "gitlab"
+	EntityID int64
+	Kind     string // "pull"/"issue"/"discussion" for GitHub, "merge_request"/"issue" for GitLab
+	Ref      string // "owner/repo#42", "host#123" or "group/project!7"
+	Title    string
+	State    string
+	URL      string
+}
+
+// Key identifies the entity across sources, for grouping signals.
+func (e SignalEntity) Key() string {
+	return fmt.Sprintf("%s:%d", e.Source, e.EntityID)
+}
+
+// SignalEntities maps signal IDs to the entity each signal refers to. A
+// signal linked to several entities maps to the one it was linked to first,
+// by event time; events from the same second fall back to their event ID,
+// which only orders events within one source.
+func SignalEntities(db *sql.DB) (map[int64]SignalEntity, error) {
+	rows, err := db.Query(`
+		SELECT e.signal_id, 'github', g.id, g.owner, g.repo, g.number, g.kind, g.title, g.state,
+		       e.created_at AS seen_at, e.id AS event_id
+		FROM github_entity_events e JOIN github_entities g ON g.id = e.entity_id
+		WHERE e.event_type = 'signal_seen' AND e.signal_id IS NOT NULL
+		UNION ALL
+		SELECT e.signal_id, 'bugzilla', b.id, b.host, '', b.bug_id, '', b.title,
+		       TRIM(b.status || ' ' || b.resolution), e.created_at, e.id
+		FROM bugzilla_entity_events e JOIN bugzilla_entities b ON b.id = e.entity_id
+		WHERE e.event_type = 'signal_seen' AND e.signal_id IS NOT NULL
+		UNION ALL
+		SELECT e.signal_id, 'gitlab', l.id, l.host, l.project, l.number, l.kind, l.title, l.state, e.created_at, e.id
+		FROM gitlab_entity_events e JOIN gitlab_entities l ON l.id = e.entity_id
+		WHERE e.event_type = 'signal_seen' AND e.signal_id IS NOT NULL
+		ORDER BY seen_at, event_id`)
+	if err != nil {
+		return nil, fmt.Errorf("query signal entities: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[int64]SignalEntity)
+	for rows.Next() {
+		var signalID, eventID int64
+		var e SignalEntity
+		var a, b string
+		var number int
+		var title, state, seenAt sql.NullString
+		if err := rows.Scan(&signalID, &e.Source, &e.EntityID, &a, &b, &number, &e.Kind, &title, &state, &seenAt, &eventID); err != nil {
+			return nil, fmt.Errorf("scan signal entity: %w", err)
+		}
+		if _, ok := result[signalID]; ok {
+			continue
+		}
+		e.Title, e.State = title.String, state.String
+		switch e.Source {
+		case "github":
+			e.Ref = fmt.Sprintf("%s/%s#%d", a, b, number)
+			e.URL = fmt.Sprintf("https://github.com/%s/%s/%s/%d", a, b, entityURLPath(e.Kind), number)
+		case "bugzilla":
+			e.Ref = fmt.Sprintf("%s#%d", a, number)
+			e.URL = fmt.Sprintf("https://%s/show_bug.cgi?id=%d", a, number)
+		case "gitlab":
+			e.Ref = GitLabRef(b, e.Kind, number)
+			e.URL = GitLabURL(a, b, e.Kind, number)
+		}
+		result[signalID] = e
+	}
+	return result, rows.Err()
+}
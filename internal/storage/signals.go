@@ -4,10 +4,12 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/signal"
 )
 
 // SignalRecord represents a single signal item stored in the database.
@@ -25,20 +27,13 @@ type SignalRecord struct {
 	Pinned        bool
 	Urgency       *string  // "urgent", "review", "fyi", or nil (unclassified)
 	UrgencySource *string  // "heuristic", "llm", or nil
+	SnoozedUntil  *time.Time
 }
 
-// ClassifyByKind returns urgency for signals with a known kind.
-func ClassifyByKind(kind string) (urgency string, ok bool) {
-	switch kind {
-	case "dm":
-		return "urgent", true
-	case "mention":
-		return "review", true
-	case "channel":
-		return "fyi", true
-	default:
-		return "", false
-	}
+// ClassifyByKind returns urgency for signals with a known kind, using the
+// source's urgency mapping when it defines one.
+func ClassifyByKind(source, kind string) (urgency string, ok bool) {
+	return signal.UrgencyForKind(source, kind)
 }
 
 // InsertSignal inserts a signal, silently ignoring duplicates (same source+title+source_ts).
@@ -59,9 +54,25 @@ func InsertSignal(db *sql.DB, sig SignalRecord) error {
 
 // ListSignals returns signals. If source is non-empty, filters by source.
 // If includeCompleted is false, only returns active signals (completed_at IS NULL).
+// Active signals that are snoozed are left out until their snooze expires.
 // Results are ordered: active first (newest captured_at first), then completed (newest completed_at first).
 func ListSignals(db *sql.DB, source string, includeCompleted bool) ([]SignalRecord, error) {
-	query := `SELECT id, source, title, preview, snippet, kind, source_ts, captured_at, completed_at, auto_completed, pinned, urgency, urgency_source
+	return ListSignalsByUrgency(db, source, "", includeCompleted, false)
+}
+
+// UrgencyUnclassified selects signals with no urgency in
+// ListSignalsByUrgency.
+const UrgencyUnclassified = "unclassified"
+
+// Urgencies are the values ListSignalsByUrgency accepts, most urgent first.
+var Urgencies = []string{"urgent", "review", "fyi", UrgencyUnclassified}
+
+// ListSignalsByUrgency is ListSignals limited to one urgency: "urgent",
+// "review", "fyi", or UrgencyUnclassified for signals not classified yet.
+// An empty urgency selects all signals. includeSnoozed also lists active
+// signals that are snoozed.
+func ListSignalsByUrgency(db *sql.DB, source, urgency string, includeCompleted, includeSnoozed bool) ([]SignalRecord, error) {
+	query := `SELECT id, source, title, preview, snippet, kind, source_ts, captured_at, completed_at, auto_completed, pinned, urgency, urgency_source, snoozed_until
 		FROM signals WHERE 1=1`
 	var args []interface{}
 
@@ -69,9 +80,24 @@ func ListSignals(db *sql.DB, source string, includeCompleted bool) ([]SignalReco
 		query += " AND source = ?"
 		args = append(args, source)
 	}
+	switch urgency {
+	case "":
+	case UrgencyUnclassified:
+		query += " AND urgency IS NULL"
+	default:
+		if !slices.Contains(Urgencies, urgency) {
+			return nil, fmt.Errorf("unknown urgency %q (use %s)", urgency, strings.Join(Urgencies, ", "))
+		}
+		query += " AND urgency = ?"
+		args = append(args, urgency)
+	}
 	if !includeCompleted {
 		query += " AND completed_at IS NULL"
 	}
+	if !includeSnoozed {
+		query += " AND (completed_at IS NOT NULL OR snoozed_until IS NULL OR snoozed_until <= ?)"
+		args = append(args, time.Now().Unix())
+	}
 
 	query += ` ORDER BY
 		CASE WHEN completed_at IS NULL THEN 0 ELSE 1 END,
@@ -88,12 +114,16 @@ func ListSignals(db *sql.DB, source string, includeCompleted bool) ([]SignalReco
 		var s SignalRecord
 		var completedAt sql.NullTime
 		var urgency, urgencySource sql.NullString
+		var snoozedUntil sql.NullInt64
 		if err := rows.Scan(&s.ID, &s.Source, &s.Title, &s.Preview, &s.Snippet, &s.Kind, &s.SourceTS,
-			&s.CapturedAt, &completedAt, &s.AutoCompleted, &s.Pinned, &urgency, &urgencySource); err != nil {
+			&s.CapturedAt, &completedAt, &s.AutoCompleted, &s.Pinned, &urgency, &urgencySource, &snoozedUntil); err != nil {
 			return nil, err
 		}
 		if completedAt.Valid {
 			s.CompletedAt = &completedAt.Time
+		} else if snoozedUntil.Valid {
+			t := time.Unix(snoozedUntil.Int64, 0)
+			s.SnoozedUntil = &t
 		}
 		if urgency.Valid {
 			s.Urgency = &urgency.String
@@ -106,9 +136,18 @@ func ListSignals(db *sql.DB, source string, includeCompleted bool) ([]SignalReco
 	return result, rows.Err()
 }
 
+// CountSignals returns the total number of stored signals, active or not.
+func CountSignals(db *sql.DB) (int, error) {
+	var n int
+	err := db.QueryRow(`SELECT COUNT(*) FROM signals`).Scan(&n)
+	return n, err
+}
+
 // ActiveSignalCounts returns the number of active (non-completed) signals per source.
 func ActiveSignalCounts(db *sql.DB) (map[string]int, error) {
-	rows, err := db.Query(`SELECT source, COUNT(*) FROM signals WHERE completed_at IS NULL GROUP BY source`)
+	rows, err := db.Query(`SELECT source, COUNT(*) FROM signals
+		WHERE completed_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= ?)
+		GROUP BY source`, time.Now().Unix())
 	if err != nil {
 		return nil, err
 	}
@@ -134,7 +173,8 @@ func HighestUrgencyBySource(db *sql.DB) (map[string]string, error) {
 			WHEN SUM(CASE WHEN urgency = 'fyi' THEN 1 ELSE 0 END) > 0 THEN 'fyi'
 			ELSE ''
 		END as highest
-		FROM signals WHERE completed_at IS NULL GROUP BY source`)
+		FROM signals WHERE completed_at IS NULL AND (snoozed_until IS NULL OR snoozed_until <= ?)
+		GROUP BY source`, time.Now().Unix())
 	if err != nil {
 		return nil, err
 	}
@@ -167,6 +207,21 @@ func CompleteSignal(db *sql.DB, id int64) error {
 	return nil
 }
 
+// CompleteAllSignals marks every active signal of a source as manually
+// completed, like CompleteSignal, and returns how many it completed.
+// Snoozed signals are left alone, as they are not listed as active.
+func CompleteAllSignals(db *sql.DB, source string) (int64, error) {
+	res, err := db.Exec(
+		`UPDATE signals SET completed_at = CURRENT_TIMESTAMP, auto_completed = 0, pinned = 0
+		 WHERE source = ? AND completed_at IS NULL
+		   AND (snoozed_until IS NULL OR snoozed_until <= ?)`,
+		source, time.Now().Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // ReopenSignal reactivates a completed signal. Sets pinned=true to prevent auto-complete.
 func ReopenSignal(db *sql.DB, id int64) error {
 	res, err := db.Exec(
@@ -182,6 +237,47 @@ func ReopenSignal(db *sql.DB, id int64) error {
 	return nil
 }
 
+// SnoozeSignal hides an active signal until the given time.
+func SnoozeSignal(db *sql.DB, id int64, until time.Time) error {
+	res, err := db.Exec(
+		`UPDATE signals SET snoozed_until = ? WHERE id = ? AND completed_at IS NULL`,
+		until.Unix(), id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("active signal %d not found", id)
+	}
+	return nil
+}
+
+// UnsnoozeSignal brings a snoozed signal back before its snooze expires.
+func UnsnoozeSignal(db *sql.DB, id int64) error {
+	res, err := db.Exec(
+		`UPDATE signals SET snoozed_until = NULL WHERE id = ? AND snoozed_until IS NOT NULL`, id)
+	if err != nil {
+		return err
+	}
+	n, _ := res.RowsAffected()
+	if n == 0 {
+		return fmt.Errorf("snoozed signal %d not found", id)
+	}
+	return nil
+}
+
+// ResurfaceSnoozedSignals clears snoozes that expired at or before now and
+// returns how many signals became visible again.
+func ResurfaceSnoozedSignals(db *sql.DB, now time.Time) (int64, error) {
+	res, err := db.Exec(
+		`UPDATE signals SET snoozed_until = NULL WHERE snoozed_until IS NOT NULL AND snoozed_until <= ?`,
+		now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
 // ReconcileSignals processes a scrape result for a source in a single transaction.
 // Each unread→read→unread cycle creates a distinct "episode" signal:
 // 1. Query active signals for this source
@@ -189,6 +285,10 @@ func ReopenSignal(db *sql.DB, id int64) error {
 // 3. Auto-complete active signals missing from scrape (unless pinned)
 // No reactivation — once completed, a signal stays completed and new unreads create a new episode.
 func ReconcileSignals(db *sql.DB, source string, items []SignalRecord, capturedAt time.Time) error {
+	return retryBusy(func() error { return reconcileSignals(db, source, items, capturedAt) })
+}
+
+func reconcileSignals(db *sql.DB, source string, items []SignalRecord, capturedAt time.Time) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return err
@@ -249,7 +349,7 @@ func ReconcileSignals(db *sql.DB, source string, items []SignalRecord, capturedA
 		inserted++
 
 		// Heuristic classification for signals with known kind
-		if urgency, ok := ClassifyByKind(item.Kind); ok {
+		if urgency, ok := ClassifyByKind(source, item.Kind); ok {
 			if _, err := tx.Exec(`UPDATE signals SET urgency = ?, urgency_source = 'heuristic'
 				WHERE source = ? AND title = ? AND preview = ? AND source_ts = ? AND urgency IS NULL`,
 				urgency, source, item.Title, item.Preview, sourceTS); err != nil {
@@ -368,6 +468,9 @@ func FormatSignalsMarkdown(signals []SignalRecord) string {
 			if s.CompletedAt != nil {
 				prefix += " ✓"
 			}
+			if s.SnoozedUntil != nil && s.SnoozedUntil.After(time.Now()) {
+				prefix += " (snoozed until " + s.SnoozedUntil.Format("Jan 2 15:04") + ")"
+			}
 			urgencyTag := "[pending] "
 			if s.Urgency != nil {
 				switch *s.Urgency {
@@ -424,6 +527,7 @@ type SignalJSONOutput struct {
 	Active        bool   `json:"active"`
 	Urgency       string `json:"urgency,omitempty"`
 	UrgencySource string `json:"urgency_source,omitempty"`
+	SnoozedUntil  string `json:"snoozed_until,omitempty"`
 }
 
 // FormatSignalsJSON formats signals grouped by source as JSON.
@@ -445,6 +549,9 @@ func FormatSignalsJSON(signals []SignalRecord) (string, error) {
 		if s.UrgencySource != nil {
 			out.UrgencySource = *s.UrgencySource
 		}
+		if s.SnoozedUntil != nil {
+			out.SnoozedUntil = s.SnoozedUntil.Format(time.RFC3339)
+		}
 		grouped[s.Source] = append(grouped[s.Source], out)
 	}
 	data, err := json.MarshalIndent(grouped, "", "  ")
@@ -453,3 +560,90 @@ func FormatSignalsJSON(signals []SignalRecord) (string, error) {
 	}
 	return string(data) + "\n", nil
 }
+
+// FormatSignalsICS formats signals as an iCalendar file with one VTODO per
+// signal. Completed signals are exported as COMPLETED tasks and urgency is
+// mapped to PRIORITY (urgent=1, review=5, fyi=9).
+func FormatSignalsICS(signals []SignalRecord) string {
+	const stampFmt = "20060102T150405Z"
+	now := time.Now().UTC().Format(stampFmt)
+
+	var b strings.Builder
+	line := func(s string) {
+		b.WriteString(foldICSLine(s))
+		b.WriteString("\r\n")
+	}
+
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//tabsordnung//signals//EN")
+	for _, s := range signals {
+		summary := s.Title
+		if s.Preview != "" {
+			summary += " — " + s.Preview
+		}
+		line("BEGIN:VTODO")
+		line(fmt.Sprintf("UID:signal-%d@tabsordnung", s.ID))
+		line("DTSTAMP:" + now)
+		line("CREATED:" + s.CapturedAt.UTC().Format(stampFmt))
+		line("SUMMARY:" + escapeICSText(summary))
+		if s.Snippet != "" {
+			line("DESCRIPTION:" + escapeICSText(s.Snippet))
+		}
+		line("CATEGORIES:" + escapeICSText(s.Source))
+		if s.Urgency != nil {
+			switch *s.Urgency {
+			case "urgent":
+				line("PRIORITY:1")
+			case "review":
+				line("PRIORITY:5")
+			case "fyi":
+				line("PRIORITY:9")
+			}
+		}
+		if s.CompletedAt != nil {
+			line("STATUS:COMPLETED")
+			line("COMPLETED:" + s.CompletedAt.UTC().Format(stampFmt))
+		} else {
+			line("STATUS:NEEDS-ACTION")
+		}
+		line("END:VTODO")
+	}
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// escapeICSText escapes a value for an iCalendar TEXT property (RFC 5545 3.3.11).
+func escapeICSText(s string) string {
+	r := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return r.Replace(s)
+}
+
+// foldICSLine splits content lines longer than 75 octets, continuing them
+// on lines that start with a space. It never splits a UTF-8 sequence.
+func foldICSLine(s string) string {
+	const limit = 75
+	if len(s) <= limit {
+		return s
+	}
+	var b strings.Builder
+	n := 0
+	width := limit
+	for _, r := range s {
+		size := len(string(r))
+		if n+size > width {
+			b.WriteString("\r\n ")
+			n = 0
+			width = limit - 1
+		}
+		b.WriteRune(r)
+		n += size
+	}
+	return b.String()
+}
@@ -75,6 +75,66 @@ func TestListSignals(t *testing.T) {
 	}
 }
 
+func TestListSignalsByUrgency(t *testing.T) {
+	db := testDB(t)
+
+	now := time.Now()
+	InsertSignal(db, SignalRecord{Source: "gmail", Title: "Alice", Preview: "outage", CapturedAt: now})
+	InsertSignal(db, SignalRecord{Source: "slack", Title: "#ops", Preview: "deploy", CapturedAt: now})
+	InsertSignal(db, SignalRecord{Source: "slack", Title: "#random", Preview: "lunch", CapturedAt: now})
+	InsertSignal(db, SignalRecord{Source: "matrix", Title: "Bob", Preview: "hi", CapturedAt: now})
+	all, _ := ListSignals(db, "", false)
+	ids := map[string]int64{}
+	for _, s := range all {
+		ids[s.Title] = s.ID
+	}
+	UpdateUrgency(db, ids["Alice"], "urgent", "llm")
+	UpdateUrgency(db, ids["#ops"], "urgent", "heuristic")
+	UpdateUrgency(db, ids["#random"], "fyi", "heuristic")
+	CompleteSignal(db, ids["#ops"])
+
+	titles := func(sigs []SignalRecord) map[string]bool {
+		m := map[string]bool{}
+		for _, s := range sigs {
+			m[s.Title] = true
+		}
+		return m
+	}
+	tests := []struct {
+		source, urgency string
+		completed       bool
+		want            []string
+	}{
+		{urgency: "urgent", want: []string{"Alice"}},
+		{urgency: "urgent", completed: true, want: []string{"Alice", "#ops"}},
+		{source: "slack", urgency: "urgent", completed: true, want: []string{"#ops"}},
+		{urgency: "fyi", want: []string{"#random"}},
+		{urgency: "review", want: nil},
+		{urgency: UrgencyUnclassified, want: []string{"Bob"}},
+		{urgency: "", want: []string{"Alice", "#random", "Bob"}},
+	}
+	for _, tt := range tests {
+		sigs, err := ListSignalsByUrgency(db, tt.source, tt.urgency, tt.completed, false)
+		if err != nil {
+			t.Fatalf("ListSignalsByUrgency(%q, %q): %v", tt.source, tt.urgency, err)
+		}
+		got := titles(sigs)
+		if len(got) != len(tt.want) {
+			t.Errorf("ListSignalsByUrgency(%q, %q, %v) = %v, want %v", tt.source, tt.urgency, tt.completed, got, tt.want)
+			continue
+		}
+		for _, w := range tt.want {
+			if !got[w] {
+				t.Errorf("ListSignalsByUrgency(%q, %q, %v) = %v, want %v", tt.source, tt.urgency, tt.completed, got, tt.want)
+			}
+		}
+	}
+
+	if _, err := ListSignalsByUrgency(db, "", "critical", false, false); err == nil {
+		t.Error("expected an error for an unknown urgency")
+	}
+}
+
 func TestCompleteAndReopenSignal(t *testing.T) {
 	db := testDB(t)
 
@@ -391,3 +451,152 @@ func TestFormatSignalsJSONWithSnippet(t *testing.T) {
 		t.Errorf("expected snippet value in JSON, got:\n%s", out)
 	}
 }
+
+func TestFormatSignalsICS(t *testing.T) {
+	captured := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	completed := captured.Add(2 * time.Hour)
+	urgent := "urgent"
+	fyi := "fyi"
+	sigs := []SignalRecord{
+		{ID: 1, Source: "gmail", Title: "Alice", Preview: "Budget; Q2, draft", CapturedAt: captured, Urgency: &urgent},
+		{ID: 2, Source: "slack", Title: "#general", Preview: strings.Repeat("long ", 30), CapturedAt: captured, CompletedAt: &completed, Urgency: &fyi},
+	}
+	out := FormatSignalsICS(sigs)
+
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"UID:signal-1@tabsordnung\r\n",
+		"CREATED:20240301T093000Z\r\n",
+		`SUMMARY:Alice — Budget\; Q2\, draft` + "\r\n",
+		"PRIORITY:1\r\n",
+		"STATUS:NEEDS-ACTION\r\n",
+		"PRIORITY:9\r\n",
+		"STATUS:COMPLETED\r\nCOMPLETED:20240301T113000Z\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if n := strings.Count(out, "BEGIN:VTODO"); n != 2 {
+		t.Errorf("expected 2 VTODOs, got %d", n)
+	}
+	for _, l := range strings.Split(out, "\r\n") {
+		if len(l) > 75 {
+			t.Errorf("line longer than 75 octets: %q", l)
+		}
+	}
+}
+
+func TestCountSignals(t *testing.T) {
+	db := testDB(t)
+	if n, err := CountSignals(db); err != nil || n != 0 {
+		t.Fatalf("CountSignals on empty DB = %d, %v", n, err)
+	}
+	now := time.Now()
+	InsertSignal(db, SignalRecord{Source: "gmail", Title: "Alice", CapturedAt: now})
+	InsertSignal(db, SignalRecord{Source: "slack", Title: "Bob", CapturedAt: now})
+	all, _ := ListSignals(db, "slack", false)
+	CompleteSignal(db, all[0].ID)
+	if n, err := CountSignals(db); err != nil || n != 2 {
+		t.Fatalf("CountSignals = %d, %v, want 2", n, err)
+	}
+}
+
+func TestSignalEntities(t *testing.T) {
+	db := testDB(t)
+
+	now := time.Now()
+	for _, sig := range []SignalRecord{
+		{Source: "gmail", Title: "alice", Preview: "Review requested", Snippet: "https://github.com/org/repo/pull/42", CapturedAt: now},
+		{Source: "slack", Title: "#team", Preview: "can someone look at https://github.com/org/repo/pull/42", CapturedAt: now},
+		{Source: "gmail", Title: "bugzilla-daemon", Preview: "Bug updated", Snippet: "See https://bugzilla.mozilla.org/show_bug.cgi?id=1900001", CapturedAt: now},
+		{Source: "slack", Title: "#general", Preview: "Hello world", CapturedAt: now},
+	} {
+		if err := InsertSignal(db, sig); err != nil {
+			t.Fatalf("InsertSignal: %v", err)
+		}
+	}
+	signals, _ := ListSignals(db, "", false)
+	if _, err := ExtractGitHubFromSignals(db, signals); err != nil {
+		t.Fatalf("ExtractGitHubFromSignals: %v", err)
+	}
+	if _, err := ExtractBugzillaFromSignals(db, signals); err != nil {
+		t.Fatalf("ExtractBugzillaFromSignals: %v", err)
+	}
+
+	entities, err := SignalEntities(db)
+	if err != nil {
+		t.Fatalf("SignalEntities: %v", err)
+	}
+	if len(entities) != 3 {
+		t.Fatalf("expected 3 signals with entities, got %d: %v", len(entities), entities)
+	}
+
+	byTitle := make(map[string]SignalEntity)
+	for _, s := range signals {
+		if e, ok := entities[s.ID]; ok {
+			byTitle[s.Title] = e
+		}
+	}
+	pr := byTitle["alice"]
+	if pr.Ref != "org/repo#42" || pr.URL != "https://github.com/org/repo/pull/42" {
+		t.Errorf("github entity = %+v", pr)
+	}
+	if byTitle["#team"].Key() != pr.Key() {
+		t.Errorf("signals about the same PR have different keys: %q, %q", byTitle["#team"].Key(), pr.Key())
+	}
+	bug := byTitle["bugzilla-daemon"]
+	if bug.Source != "bugzilla" || bug.Ref != "bugzilla.mozilla.org#1900001" ||
+		bug.URL != "https://bugzilla.mozilla.org/show_bug.cgi?id=1900001" {
+		t.Errorf("bugzilla entity = %+v", bug)
+	}
+	if _, ok := byTitle["#general"]; ok {
+		t.Error("signal without a reference should have no entity")
+	}
+}
+
+func TestCompleteAllSignals(t *testing.T) {
+	db := testDB(t)
+
+	now := time.Now()
+	for _, sig := range []SignalRecord{
+		{Source: "slack", Title: "#general", Preview: "one", CapturedAt: now},
+		{Source: "slack", Title: "#random", Preview: "two", CapturedAt: now},
+		{Source: "slack", Title: "#team", Preview: "snoozed", CapturedAt: now},
+		{Source: "gmail", Title: "alice", Preview: "hello", CapturedAt: now},
+	} {
+		if err := InsertSignal(db, sig); err != nil {
+			t.Fatalf("InsertSignal: %v", err)
+		}
+	}
+	all, _ := ListSignals(db, "slack", false)
+	for _, s := range all {
+		if s.Title == "#team" {
+			if err := SnoozeSignal(db, s.ID, now.Add(time.Hour)); err != nil {
+				t.Fatalf("SnoozeSignal: %v", err)
+			}
+		}
+	}
+
+	n, err := CompleteAllSignals(db, "slack")
+	if err != nil {
+		t.Fatalf("CompleteAllSignals: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("completed %d signals, want 2", n)
+	}
+
+	if active, _ := ListSignals(db, "slack", false); len(active) != 0 {
+		t.Errorf("expected no active slack signals, got %d", len(active))
+	}
+	slack, _ := ListSignals(db, "slack", true)
+	for _, s := range slack {
+		if s.CompletedAt != nil && s.AutoCompleted {
+			t.Errorf("%s: expected auto_completed=false for a manual completion", s.Title)
+		}
+	}
+	if gmail, _ := ListSignals(db, "gmail", false); len(gmail) != 1 {
+		t.Errorf("expected gmail signal to stay active, got %d", len(gmail))
+	}
+}
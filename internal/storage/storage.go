@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite"
@@ -35,6 +36,12 @@ type SnapshotTab struct {
 	GroupIndex *int // index into groups slice; nil = ungrouped
 	Pinned     bool
 	GroupName  string // populated by GetSnapshot
+
+	// Analyzer verdicts at the time the snapshot was taken.
+	IsStale      bool
+	IsDead       bool
+	IsDuplicate  bool
+	GitHubStatus string // "open", "closed", "merged", "" (unknown or not GitHub)
 }
 
 // SnapshotFull is a snapshot with its groups and tabs.
@@ -328,6 +335,109 @@ CREATE INDEX idx_tab_visits_started ON tab_visits(started_at);`,
 		Description: "dedupe tab visits with unique index",
 		SQL:         `CREATE UNIQUE INDEX idx_tab_visits_unique ON tab_visits(tab_id, url, started_at, ended_at);`,
 	},
+	{
+		Version:     13,
+		Description: "add snoozed_until to signals",
+		SQL:         `ALTER TABLE signals ADD COLUMN snoozed_until INTEGER;`,
+	},
+	{
+		Version:     14,
+		Description: "create reading_times table",
+		SQL: `
+CREATE TABLE reading_times (
+    url         TEXT PRIMARY KEY,
+    word_count  INTEGER NOT NULL,
+    measured_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`,
+	},
+	{
+		Version:     15,
+		Description: "create ui_state table",
+		SQL: `
+CREATE TABLE ui_state (
+    profile    TEXT PRIMARY KEY,
+    state      TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`,
+	},
+	{
+		Version:     16,
+		Description: "add priority and severity to bugzilla_entities",
+		SQL: `
+ALTER TABLE bugzilla_entities ADD COLUMN priority TEXT NOT NULL DEFAULT '';
+ALTER TABLE bugzilla_entities ADD COLUMN severity TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     17,
+		Description: "add restricted flag to bugzilla_entities",
+		SQL:         `ALTER TABLE bugzilla_entities ADD COLUMN restricted INTEGER NOT NULL DEFAULT 0;`,
+	},
+	{
+		Version:     18,
+		Description: "create bugzilla_github_links table",
+		SQL: `
+CREATE TABLE bugzilla_github_links (
+    bugzilla_id INTEGER NOT NULL REFERENCES bugzilla_entities(id) ON DELETE CASCADE,
+    github_id   INTEGER NOT NULL REFERENCES github_entities(id) ON DELETE CASCADE,
+    created_at  DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (bugzilla_id, github_id)
+);
+CREATE INDEX idx_bugzilla_github_links_github ON bugzilla_github_links(github_id);`,
+	},
+	{
+		Version:     19,
+		Description: "create settings table",
+		SQL: `
+CREATE TABLE settings (
+    key        TEXT PRIMARY KEY,
+    value      TEXT NOT NULL,
+    updated_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);`,
+	},
+	{
+		Version:     20,
+		Description: "add analyzer flags to snapshot_tabs",
+		SQL: `
+ALTER TABLE snapshot_tabs ADD COLUMN is_stale INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_tabs ADD COLUMN is_dead INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_tabs ADD COLUMN is_duplicate INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE snapshot_tabs ADD COLUMN github_status TEXT NOT NULL DEFAULT '';`,
+	},
+	{
+		Version:     21,
+		Description: "create gitlab_entities and gitlab_entity_events tables",
+		SQL: `
+CREATE TABLE gitlab_entities (
+    id                INTEGER PRIMARY KEY,
+    host              TEXT NOT NULL,
+    project           TEXT NOT NULL,
+    number            INTEGER NOT NULL,
+    kind              TEXT NOT NULL,
+    title             TEXT NOT NULL DEFAULT '',
+    state             TEXT NOT NULL DEFAULT '',
+    author            TEXT NOT NULL DEFAULT '',
+    assignees         TEXT NOT NULL DEFAULT '',
+    first_seen_at     DATETIME DEFAULT CURRENT_TIMESTAMP,
+    first_seen_source TEXT NOT NULL DEFAULT '',
+    last_refreshed_at DATETIME,
+    UNIQUE(host, project, kind, number)
+);
+CREATE TABLE gitlab_entity_events (
+    id          INTEGER PRIMARY KEY,
+    entity_id   INTEGER NOT NULL REFERENCES gitlab_entities(id) ON DELETE CASCADE,
+    event_type  TEXT NOT NULL,
+    signal_id   INTEGER REFERENCES signals(id),
+    snapshot_id INTEGER REFERENCES snapshots(id),
+    detail      TEXT NOT NULL DEFAULT '',
+    created_at  DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX idx_gitlab_events_signal
+    ON gitlab_entity_events(entity_id, event_type, signal_id)
+    WHERE signal_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_gitlab_events_snapshot
+    ON gitlab_entity_events(entity_id, event_type, snapshot_id)
+    WHERE snapshot_id IS NOT NULL;`,
+	},
 }
 
 // OpenDB opens (or creates) a SQLite database at the given path.
@@ -340,23 +450,34 @@ func OpenDB(path string) (*sql.DB, error) {
 		return nil, fmt.Errorf("create directory %s: %w", dir, err)
 	}
 
-	db, err := sql.Open("sqlite", path)
+	// Foreign keys and the busy timeout are per-connection settings, so
+	// they go in the DSN to apply to every connection in the pool. The TUI
+	// and a cron "snapshot" often have the database open at the same time:
+	// writers wait up to busyTimeout for each other instead of failing
+	// with "database is locked", and transactions take the write lock when
+	// they begin, so a read-then-write transaction cannot deadlock with
+	// another writer.
+	dsn := fmt.Sprintf("%s?_pragma=busy_timeout(%d)&_pragma=foreign_keys(1)&_txlock=immediate",
+		path, busyTimeout.Milliseconds())
+	db, err := sql.Open("sqlite", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	// Enable foreign keys.
-	if _, err := db.Exec("PRAGMA foreign_keys = ON"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
-	}
-
 	// Enable WAL mode for better concurrency.
 	if _, err := db.Exec("PRAGMA journal_mode = WAL"); err != nil {
 		db.Close()
+		if isCorrupt(err) {
+			return nil, &CorruptError{Path: path, Problems: []string{err.Error()}}
+		}
 		return nil, fmt.Errorf("enable WAL mode: %w", err)
 	}
 
+	if err := checkIntegrity(db, path); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	// Run migrations.
 	if err := runMigrations(db); err != nil {
 		db.Close()
@@ -397,23 +518,8 @@ func runMigrations(db *sql.DB) error {
 
 	// Apply pending migrations in order.
 	for _, m := range migrations {
-		var exists int
-		err := db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.Version).Scan(&exists)
-		if err != nil {
-			return fmt.Errorf("check migration %d: %w", m.Version, err)
-		}
-		if exists > 0 {
-			continue
-		}
-
-		if _, err := db.Exec(m.SQL); err != nil {
-			return fmt.Errorf("apply migration %d (%s): %w", m.Version, m.Description, err)
-		}
-		if _, err := db.Exec(
-			"INSERT INTO schema_migrations (version, description) VALUES (?, ?)",
-			m.Version, m.Description,
-		); err != nil {
-			return fmt.Errorf("record migration %d: %w", m.Version, err)
+		if err := applyMigration(db, m); err != nil {
+			return err
 		}
 	}
 
@@ -428,10 +534,45 @@ func runMigrations(db *sql.DB) error {
 		// Table exists but is empty — backfill from existing data
 		BackfillBugzillaEntities(db)
 	}
+	var glCount int
+	if err := db.QueryRow("SELECT COUNT(*) FROM gitlab_entities").Scan(&glCount); err == nil && glCount == 0 {
+		// Table exists but is empty — backfill from existing data
+		BackfillGitLabEntities(db)
+	}
 
 	return nil
 }
 
+// applyMigration runs m and records it in one transaction, so a migration
+// that fails half way (or a process killed during one) leaves the schema as
+// it was, to be retried on the next start. It does nothing if m was already
+// applied, also by another process that got there first.
+func applyMigration(db *sql.DB, m migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin migration %d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", m.Version).Scan(&exists); err != nil {
+		return fmt.Errorf("check migration %d: %w", m.Version, err)
+	}
+	if exists > 0 {
+		return nil
+	}
+	if _, err := tx.Exec(m.SQL); err != nil {
+		return fmt.Errorf("apply migration %d (%s): %w", m.Version, m.Description, err)
+	}
+	if _, err := tx.Exec(
+		"INSERT INTO schema_migrations (version, description) VALUES (?, ?)",
+		m.Version, m.Description,
+	); err != nil {
+		return fmt.Errorf("record migration %d: %w", m.Version, err)
+	}
+	return tx.Commit()
+}
+
 // DefaultDBPath returns the default database file path:
 // ~/.local/share/tabsordnung/tabsordnung.db
 func DefaultDBPath() (string, error) {
@@ -442,10 +583,56 @@ func DefaultDBPath() (string, error) {
 	return filepath.Join(home, ".local", "share", "tabsordnung", "tabsordnung.db"), nil
 }
 
+// DBPathEnv names the environment variable that overrides DefaultDBPath.
+const DBPathEnv = "TABSORDNUNG_DB"
+
+// ResolveDBPath returns path if set, else $TABSORDNUNG_DB, else
+// DefaultDBPath. A leading "~/" is expanded to the home directory.
+func ResolveDBPath(path string) (string, error) {
+	if path == "" {
+		path = os.Getenv(DBPathEnv)
+	}
+	if path == "" {
+		return DefaultDBPath()
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("get home directory: %w", err)
+		}
+		path = filepath.Join(home, rest)
+	}
+	return path, nil
+}
+
+// CheckWritable verifies that the database accepts writes by creating a
+// table in a transaction that is rolled back.
+func CheckWritable(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec("CREATE TABLE write_check (x INTEGER)"); err != nil {
+		return fmt.Errorf("write to database: %w", err)
+	}
+	return nil
+}
+
 // CreateSnapshot inserts a new snapshot with its groups and tabs in a single
 // transaction. The rev number is auto-assigned per profile. Label is optional
 // (empty string = no label). Returns the assigned rev number.
 func CreateSnapshot(db *sql.DB, profile string, groups []SnapshotGroup, tabs []SnapshotTab, label string) (int, error) {
+	var rev int
+	err := retryBusy(func() error {
+		var err error
+		rev, err = createSnapshot(db, profile, groups, tabs, label)
+		return err
+	})
+	return rev, err
+}
+
+func createSnapshot(db *sql.DB, profile string, groups []SnapshotGroup, tabs []SnapshotTab, label string) (int, error) {
 	tx, err := db.Begin()
 	if err != nil {
 		return 0, fmt.Errorf("begin transaction: %w", err)
@@ -507,8 +694,9 @@ func CreateSnapshot(db *sql.DB, profile string, groups []SnapshotGroup, tabs []S
 			groupID = &gid
 		}
 		_, err := tx.Exec(
-			"INSERT INTO snapshot_tabs (snapshot_id, group_id, url, title, pinned) VALUES (?, ?, ?, ?, ?)",
-			snapID, groupID, tab.URL, tab.Title, tab.Pinned,
+			`INSERT INTO snapshot_tabs (snapshot_id, group_id, url, title, pinned, is_stale, is_dead, is_duplicate, github_status)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			snapID, groupID, tab.URL, tab.Title, tab.Pinned, tab.IsStale, tab.IsDead, tab.IsDuplicate, tab.GitHubStatus,
 		)
 		if err != nil {
 			return 0, fmt.Errorf("insert tab %q: %w", tab.URL, err)
@@ -607,11 +795,13 @@ func GetSnapshot(db *sql.DB, profile string, rev int) (*SnapshotFull, error) {
 	defer groupRows.Close()
 
 	groupNameByID := make(map[int64]string)
+	groupIndexByID := make(map[int64]int)
 	for groupRows.Next() {
 		var g SnapshotGroup
 		if err := groupRows.Scan(&g.ID, &g.FirefoxID, &g.Name, &g.Color); err != nil {
 			return nil, fmt.Errorf("scan group: %w", err)
 		}
+		groupIndexByID[g.ID] = len(snap.Groups)
 		snap.Groups = append(snap.Groups, g)
 		groupNameByID[g.ID] = g.Name
 	}
@@ -621,7 +811,8 @@ func GetSnapshot(db *sql.DB, profile string, rev int) (*SnapshotFull, error) {
 
 	// Load tabs.
 	tabRows, err := db.Query(
-		"SELECT url, title, group_id, pinned FROM snapshot_tabs WHERE snapshot_id = ?",
+		`SELECT url, title, group_id, pinned, is_stale, is_dead, is_duplicate, github_status
+		 FROM snapshot_tabs WHERE snapshot_id = ?`,
 		snap.ID,
 	)
 	if err != nil {
@@ -632,13 +823,17 @@ func GetSnapshot(db *sql.DB, profile string, rev int) (*SnapshotFull, error) {
 	for tabRows.Next() {
 		var tab SnapshotTab
 		var groupID *int64
-		if err := tabRows.Scan(&tab.URL, &tab.Title, &groupID, &tab.Pinned); err != nil {
+		if err := tabRows.Scan(&tab.URL, &tab.Title, &groupID, &tab.Pinned,
+			&tab.IsStale, &tab.IsDead, &tab.IsDuplicate, &tab.GitHubStatus); err != nil {
 			return nil, fmt.Errorf("scan tab: %w", err)
 		}
 		if groupID != nil {
 			if gName, ok := groupNameByID[*groupID]; ok {
 				tab.GroupName = gName
 			}
+			if idx, ok := groupIndexByID[*groupID]; ok {
+				tab.GroupIndex = &idx
+			}
 		}
 		snap.Tabs = append(snap.Tabs, tab)
 	}
@@ -80,6 +80,33 @@ func TestOpenDB(t *testing.T) {
 	}
 }
 
+func TestCheckWritable(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "tabsordnung.db")
+	db, err := OpenDB(dbPath)
+	if err != nil {
+		t.Fatalf("OpenDB failed: %v", err)
+	}
+	defer db.Close()
+	if err := CheckWritable(db); err != nil {
+		t.Fatalf("CheckWritable: %v", err)
+	}
+	// The check leaves nothing behind.
+	var n int
+	db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'write_check'`).Scan(&n)
+	if n != 0 {
+		t.Error("write_check table was not rolled back")
+	}
+
+	ro, err := sql.Open("sqlite", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		t.Fatalf("open read-only: %v", err)
+	}
+	defer ro.Close()
+	if err := CheckWritable(ro); err == nil {
+		t.Error("CheckWritable succeeded on a read-only database")
+	}
+}
+
 func TestOpenDB_MigratesOldSchema(t *testing.T) {
 	dir := t.TempDir()
 	dbPath := filepath.Join(dir, "migrate.db")
@@ -265,6 +292,27 @@ func TestDefaultDBPath(t *testing.T) {
 	}
 }
 
+func TestResolveDBPath(t *testing.T) {
+	t.Setenv(DBPathEnv, "")
+	def, _ := DefaultDBPath()
+	if p, err := ResolveDBPath(""); err != nil || p != def {
+		t.Errorf("ResolveDBPath(\"\") = %q, %v; want default %q", p, err, def)
+	}
+
+	t.Setenv(DBPathEnv, "/tmp/env.db")
+	if p, _ := ResolveDBPath(""); p != "/tmp/env.db" {
+		t.Errorf("with %s set: got %q", DBPathEnv, p)
+	}
+	if p, _ := ResolveDBPath("/tmp/flag.db"); p != "/tmp/flag.db" {
+		t.Errorf("explicit path should win over %s: got %q", DBPathEnv, p)
+	}
+
+	home, _ := os.UserHomeDir()
+	if p, _ := ResolveDBPath("~/work.db"); p != filepath.Join(home, "work.db") {
+		t.Errorf("~ not expanded: got %q", p)
+	}
+}
+
 func TestCreateAndListSnapshots(t *testing.T) {
 	db := testDB(t)
 
@@ -577,6 +625,105 @@ func TestReconcileSignals_HeuristicUrgency(t *testing.T) {
 	}
 }
 
+func TestReconcileSignals_MatrixHeuristicUrgency(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+
+	items := []SignalRecord{
+		{Title: "carol", Preview: "2 unread", Kind: "dm"},
+		{Title: "#releng", Preview: "5 unread", Kind: "mention"},
+		{Title: "#firefox", Preview: "unread", Kind: "channel"},
+	}
+	if err := ReconcileSignals(db, "matrix", items, now); err != nil {
+		t.Fatalf("ReconcileSignals matrix: %v", err)
+	}
+
+	sigs, _ := ListSignals(db, "matrix", false)
+	if len(sigs) != 3 {
+		t.Fatalf("expected 3 matrix signals, got %d", len(sigs))
+	}
+
+	urgencies := make(map[string]*string)
+	for _, s := range sigs {
+		urgencies[s.Title] = s.Urgency
+		if s.UrgencySource == nil || *s.UrgencySource != "heuristic" {
+			t.Errorf("expected %s urgency_source=heuristic, got %v", s.Title, s.UrgencySource)
+		}
+	}
+
+	// DM -> urgent
+	if urgencies["carol"] == nil || *urgencies["carol"] != "urgent" {
+		t.Errorf("expected carol=urgent, got %v", urgencies["carol"])
+	}
+	// room mention -> review
+	if urgencies["#releng"] == nil || *urgencies["#releng"] != "review" {
+		t.Errorf("expected #releng=review, got %v", urgencies["#releng"])
+	}
+	// plain unread room -> fyi
+	if urgencies["#firefox"] == nil || *urgencies["#firefox"] != "fyi" {
+		t.Errorf("expected #firefox=fyi, got %v", urgencies["#firefox"])
+	}
+}
+
+func TestSnoozeSignal(t *testing.T) {
+	db := testDB(t)
+	now := time.Now()
+
+	if err := InsertSignal(db, SignalRecord{Source: "gmail", Title: "Alice", Preview: "Lunch?", CapturedAt: now}); err != nil {
+		t.Fatalf("InsertSignal: %v", err)
+	}
+	sigs, _ := ListSignals(db, "gmail", false)
+	if len(sigs) != 1 {
+		t.Fatalf("expected 1 signal, got %d", len(sigs))
+	}
+	id := sigs[0].ID
+
+	if err := SnoozeSignal(db, id, now.Add(time.Hour)); err != nil {
+		t.Fatalf("SnoozeSignal: %v", err)
+	}
+	if sigs, _ := ListSignals(db, "gmail", true); len(sigs) != 0 {
+		t.Errorf("expected snoozed signal to be hidden, got %d", len(sigs))
+	}
+	if counts, _ := ActiveSignalCounts(db); counts["gmail"] != 0 {
+		t.Errorf("expected snoozed signal excluded from counts, got %d", counts["gmail"])
+	}
+	// Listing everything includes it, marked as snoozed.
+	if sigs, _ := ListSignalsByUrgency(db, "gmail", "", true, true); len(sigs) != 1 || sigs[0].SnoozedUntil == nil {
+		t.Errorf("expected the snoozed signal when listing all, got %+v", sigs)
+	}
+
+	// Not yet expired: nothing resurfaces.
+	if n, err := ResurfaceSnoozedSignals(db, now); err != nil || n != 0 {
+		t.Errorf("ResurfaceSnoozedSignals(now) = %d, %v; want 0", n, err)
+	}
+	if n, err := ResurfaceSnoozedSignals(db, now.Add(2*time.Hour)); err != nil || n != 1 {
+		t.Errorf("ResurfaceSnoozedSignals(later) = %d, %v; want 1", n, err)
+	}
+	if sigs, _ := ListSignals(db, "gmail", false); len(sigs) != 1 {
+		t.Errorf("expected signal back after snooze expired, got %d", len(sigs))
+	}
+
+	// Unsnoozing brings a signal back before its snooze expires.
+	if err := SnoozeSignal(db, id, now.Add(time.Hour)); err != nil {
+		t.Fatalf("SnoozeSignal: %v", err)
+	}
+	if err := UnsnoozeSignal(db, id); err != nil {
+		t.Fatalf("UnsnoozeSignal: %v", err)
+	}
+	if sigs, _ := ListSignals(db, "gmail", false); len(sigs) != 1 || sigs[0].SnoozedUntil != nil {
+		t.Errorf("expected signal back after unsnoozing, got %+v", sigs)
+	}
+	if err := UnsnoozeSignal(db, id); err == nil {
+		t.Error("expected error unsnoozing a signal that isn't snoozed")
+	}
+
+	// Completed signals can't be snoozed.
+	CompleteSignal(db, id)
+	if err := SnoozeSignal(db, id, now.Add(time.Hour)); err == nil {
+		t.Error("expected error snoozing a completed signal")
+	}
+}
+
 func TestGitHubTablesExist(t *testing.T) {
 	db := testDB(t)
 
@@ -683,3 +830,120 @@ func TestTabVisitsTableExists(t *testing.T) {
 		t.Fatalf("insert into tab_visits: %v", err)
 	}
 }
+
+func TestWordCounts(t *testing.T) {
+	db := testDB(t)
+
+	if err := UpsertWordCount(db, "https://example.com/a", 1200); err != nil {
+		t.Fatalf("UpsertWordCount: %v", err)
+	}
+	if err := UpsertWordCount(db, "https://example.com/b", 300); err != nil {
+		t.Fatalf("UpsertWordCount: %v", err)
+	}
+	if err := UpsertWordCount(db, "https://example.com/a", 1500); err != nil {
+		t.Fatalf("UpsertWordCount (update): %v", err)
+	}
+
+	counts, err := WordCounts(db)
+	if err != nil {
+		t.Fatalf("WordCounts: %v", err)
+	}
+	if len(counts) != 2 {
+		t.Fatalf("got %d counts, want 2", len(counts))
+	}
+	if counts["https://example.com/a"] != 1500 {
+		t.Errorf("a = %d, want 1500", counts["https://example.com/a"])
+	}
+	if counts["https://example.com/b"] != 300 {
+		t.Errorf("b = %d, want 300", counts["https://example.com/b"])
+	}
+}
+
+func TestUIState(t *testing.T) {
+	db := testDB(t)
+
+	if _, ok, err := LoadUIState(db, "default"); err != nil || ok {
+		t.Fatalf("LoadUIState on empty db: ok=%v err=%v", ok, err)
+	}
+
+	first := UIState{View: 2, Filter: 1, Cursor: 7, Expanded: map[string]bool{"g1": true, "g2": false}}
+	if err := SaveUIState(db, "default", first); err != nil {
+		t.Fatalf("SaveUIState: %v", err)
+	}
+	second := UIState{View: 0, Filter: 12, Container: "Work", Cursor: 3, Offset: 1, Expanded: map[string]bool{"g1": false}}
+	if err := SaveUIState(db, "default", second); err != nil {
+		t.Fatalf("SaveUIState (update): %v", err)
+	}
+	if err := SaveUIState(db, "work", first); err != nil {
+		t.Fatalf("SaveUIState (other profile): %v", err)
+	}
+
+	got, ok, err := LoadUIState(db, "default")
+	if err != nil || !ok {
+		t.Fatalf("LoadUIState: ok=%v err=%v", ok, err)
+	}
+	if got.Filter != 12 || got.Container != "Work" || got.Cursor != 3 || got.Offset != 1 {
+		t.Errorf("got %+v, want %+v", got, second)
+	}
+	if len(got.Expanded) != 1 || got.Expanded["g1"] {
+		t.Errorf("expanded = %v, want map[g1:false]", got.Expanded)
+	}
+
+	other, _, _ := LoadUIState(db, "work")
+	if other.View != 2 || other.Cursor != 7 {
+		t.Errorf("work profile state = %+v, want %+v", other, first)
+	}
+}
+
+func TestLastProfile(t *testing.T) {
+	db := testDB(t)
+	if name, err := LastProfile(db); err != nil || name != "" {
+		t.Fatalf("LastProfile on empty db = %q, %v", name, err)
+	}
+	for _, want := range []string{"work", "live"} {
+		if err := SaveLastProfile(db, want); err != nil {
+			t.Fatalf("SaveLastProfile: %v", err)
+		}
+		if got, err := LastProfile(db); err != nil || got != want {
+			t.Errorf("LastProfile = %q, %v; want %q", got, err, want)
+		}
+	}
+}
+
+func TestListRecentEvents(t *testing.T) {
+	db := testDB(t)
+	if _, err := CreateSnapshot(db, "default", nil, []SnapshotTab{{URL: "https://example.com"}}, "before trip"); err != nil {
+		t.Fatalf("CreateSnapshot: %v", err)
+	}
+	ghID, _, _ := UpsertGitHubEntity(db, "mozilla", "gecko-dev", 42, "pull", "tab")
+	RecordGitHubEvent(db, ghID, "status_changed", nil, nil, "open -> merged")
+	bzID, _, _ := UpsertBugzillaEntity(db, "bugzilla.mozilla.org", 123, "tab")
+	RecordBugzillaEvent(db, bzID, "status_changed", nil, nil, "NEW -> RESOLVED")
+
+	db.Exec(`UPDATE snapshots SET created_at = '2026-03-10 09:00:00'`)
+	db.Exec(`UPDATE github_entity_events SET created_at = '2026-03-10 10:00:00'`)
+	db.Exec(`UPDATE bugzilla_entity_events SET created_at = '2026-03-10 11:00:00'`)
+
+	events, err := ListRecentEvents(db, 10)
+	if err != nil {
+		t.Fatalf("ListRecentEvents: %v", err)
+	}
+	want := []string{
+		"bug bugzilla.mozilla.org#123 NEW -> RESOLVED",
+		"PR mozilla/gecko-dev#42 open -> merged",
+		"created snapshot #1 (default, 1 tabs) before trip",
+	}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, w := range want {
+		if got := events[i].Describe(); got != w {
+			t.Errorf("event %d = %q, want %q", i, got, w)
+		}
+	}
+
+	events, _ = ListRecentEvents(db, 2)
+	if len(events) != 2 || events[1].Source != "github" {
+		t.Errorf("limit 2: unexpected events %+v", events)
+	}
+}
@@ -0,0 +1,92 @@
+package storage
+
+import "database/sql"
+
+// OpenTab is the part of a currently open tab that entity extraction looks
+// at. Unlike the snapshot extractors, it works on the live session, so tabs
+// opened since the last snapshot are tracked right away.
+type OpenTab struct {
+	URL   string
+	Title string
+}
+
+// TabEntities lists the IDs of the entities open tabs refer to, each once.
+type TabEntities struct {
+	GitHub   []int64
+	Bugzilla []int64
+	GitLab   []int64
+	// New counts the entities that were not tracked before.
+	New int
+}
+
+// ExtractEntitiesFromTabs upserts the GitHub issues and PRs, Bugzilla bugs
+// and GitLab merge requests and issues that tabs link to. A tab's URL names
+// at most one entity; a "Bug NNNN" in its title is tracked as well, so a
+// GitHub PR titled after the bug it fixes yields both. An entity seen for
+// the first time gets a "tab_seen" event; later sightings are left to
+// snapshots, which record them against the snapshot.
+func ExtractEntitiesFromTabs(db *sql.DB, tabs []OpenTab) (*TabEntities, error) {
+	out := &TabEntities{}
+	seenGitHub := make(map[int64]bool)
+	seenBugzilla := make(map[int64]bool)
+	seenGitLab := make(map[int64]bool)
+
+	for _, tab := range tabs {
+		forge := false // the URL is a GitHub or GitLab item, not a bug
+		if ref := extractGitHubRef(tab.URL); ref != nil {
+			forge = true
+			id, isNew, err := UpsertGitHubEntity(db, ref.owner, ref.repo, ref.number, ref.kind, "tab")
+			if err != nil {
+				return out, err
+			}
+			if !seenGitHub[id] {
+				seenGitHub[id] = true
+				out.GitHub = append(out.GitHub, id)
+			}
+			if isNew {
+				out.New++
+				_ = RecordGitHubEvent(db, id, "tab_seen", nil, nil, "")
+			}
+		} else if ref := extractGitLabFromURL(tab.URL); ref != nil {
+			forge = true
+			id, isNew, err := UpsertGitLabEntity(db, ref.host, ref.project, ref.number, ref.kind, "tab")
+			if err != nil {
+				return out, err
+			}
+			if !seenGitLab[id] {
+				seenGitLab[id] = true
+				out.GitLab = append(out.GitLab, id)
+			}
+			if isNew {
+				out.New++
+				_ = RecordGitLabEvent(db, id, "tab_seen", nil, nil, "")
+			}
+		}
+		var ref *bugzillaRef
+		if !forge {
+			ref = extractBugzillaFromURL(tab.URL)
+		}
+		if ref == nil {
+			ref = extractBugzillaRefFromText(tab.Title)
+		}
+		if ref == nil {
+			continue
+		}
+		id, isNew, err := UpsertBugzillaEntity(db, ref.host, ref.bugID, "tab")
+		if err != nil {
+			return out, err
+		}
+		if !seenBugzilla[id] {
+			seenBugzilla[id] = true
+			out.Bugzilla = append(out.Bugzilla, id)
+		}
+		if isNew {
+			out.New++
+			if cleaned := CleanBugzillaTabTitle(tab.Title); cleaned != "" && !forge {
+				db.Exec(`UPDATE bugzilla_entities SET title=? WHERE id=? AND title=''`, cleaned, id)
+			}
+			_ = RecordBugzillaEvent(db, id, "tab_seen", nil, nil, "")
+		}
+	}
+	return out, nil
+}
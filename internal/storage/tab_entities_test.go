@@ -0,0 +1,61 @@
+package storage
+
+import "testing"
+
+func TestExtractEntitiesFromTabs(t *testing.T) {
+	db := testDB(t)
+	tabs := []OpenTab{
+		{URL: "https://github.com/org/repo/pull/1", Title: "Fix it"},
+		{URL: "https://github.com/org/repo/pull/1#discussion", Title: "Fix it (again)"},
+		{URL: "https://gitlab.com/gitlab-org/gitlab/-/merge_requests/123", Title: "MR"},
+		{URL: "https://bugzilla.mozilla.org/show_bug.cgi?id=123456", Title: "123456 - Crash"},
+		{URL: "https://example.com", Title: "Example"},
+	}
+
+	got, err := ExtractEntitiesFromTabs(db, tabs)
+	if err != nil {
+		t.Fatalf("ExtractEntitiesFromTabs: %v", err)
+	}
+	if len(got.GitHub) != 1 || len(got.GitLab) != 1 || len(got.Bugzilla) != 1 {
+		t.Fatalf("got %d GitHub, %d GitLab, %d Bugzilla, want one each", len(got.GitHub), len(got.GitLab), len(got.Bugzilla))
+	}
+	if got.New != 3 {
+		t.Errorf("New = %d, want 3", got.New)
+	}
+	events, _ := ListGitHubEntityEvents(db, got.GitHub[0])
+	if len(events) != 1 || events[0].EventType != "tab_seen" {
+		t.Errorf("events = %+v, want one tab_seen", events)
+	}
+
+	// A second pass finds the same entities, but nothing new, and doesn't
+	// record another sighting.
+	again, err := ExtractEntitiesFromTabs(db, tabs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.New != 0 || len(again.GitHub) != 1 || again.GitHub[0] != got.GitHub[0] {
+		t.Errorf("second pass = %+v, want the same entities and none new", again)
+	}
+	if events, _ := ListGitHubEntityEvents(db, got.GitHub[0]); len(events) != 1 {
+		t.Errorf("got %d events after the second pass, want 1", len(events))
+	}
+}
+
+func TestExtractEntitiesFromTabsBugInForgeTitle(t *testing.T) {
+	db := testDB(t)
+	got, err := ExtractEntitiesFromTabs(db, []OpenTab{
+		{URL: "https://github.com/mozilla/gecko-dev/pull/42", Title: "Bug 1890123 - Fix the crash on startup"},
+	})
+	if err != nil {
+		t.Fatalf("ExtractEntitiesFromTabs: %v", err)
+	}
+	if len(got.GitHub) != 1 || len(got.Bugzilla) != 1 || got.New != 2 {
+		t.Fatalf("got %+v, want the PR and the bug", got)
+	}
+	// The PR's title is not the bug's.
+	var title string
+	db.QueryRow(`SELECT title FROM bugzilla_entities WHERE id = ?`, got.Bugzilla[0]).Scan(&title)
+	if title != "" {
+		t.Errorf("bug title = %q, want it left for the Bugzilla refresh", title)
+	}
+}
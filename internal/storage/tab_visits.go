@@ -48,6 +48,10 @@ func InsertTabVisits(db *sql.DB, visits []TabVisit) error {
 	if len(visits) == 0 {
 		return nil
 	}
+	return retryBusy(func() error { return insertTabVisits(db, visits) })
+}
+
+func insertTabVisits(db *sql.DB, visits []TabVisit) error {
 	tx, err := db.Begin()
 	if err != nil {
 		return fmt.Errorf("begin: %w", err)
@@ -0,0 +1,181 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TimelineEvent is one entry of the combined activity feed: a GitHub,
+// Bugzilla or GitLab entity event, or a snapshot being created.
+type TimelineEvent struct {
+	Source    string // "github", "bugzilla", "gitlab" or "snapshot"
+	EntityID  int64  // entity or snapshot ID
+	Ref       string // "owner/repo#42", "host#123", "host/group/project!7" or the snapshot profile
+	Kind      string // "pull"/"issue"/"discussion" for GitHub, "merge_request"/"issue" for GitLab, "" otherwise
+	Title     string // entity title or snapshot name
+	EventType string // entity event type, "created" for snapshots
+	Detail    string
+	Rev       int // snapshot revision
+	TabCount  int // snapshot tab count
+	CreatedAt time.Time
+}
+
+// Describe returns a one-line summary like "saw PR mozilla/gecko-dev#42 in
+// tab" or "bug bugzilla.mozilla.org#123 NEW -> RESOLVED".
+func (e TimelineEvent) Describe() string {
+	if e.Source == "snapshot" {
+		s := fmt.Sprintf("created snapshot #%d (%s, %d tabs)", e.Rev, e.Ref, e.TabCount)
+		if e.Title != "" {
+			s += " " + e.Title
+		}
+		return s
+	}
+	noun := "bug"
+	switch {
+	case e.Source == "github" && e.Kind == "pull":
+		noun = "PR"
+	case e.Source == "github" && e.Kind == "discussion":
+		noun = "discussion"
+	case e.Source == "github":
+		noun = "issue"
+	case e.Source == "gitlab" && e.Kind == "merge_request":
+		noun = "MR"
+	case e.Source == "gitlab":
+		noun = "issue"
+	}
+	subject := noun + " " + e.Ref
+	switch e.EventType {
+	case "tab_seen":
+		return "saw " + subject + " in tab"
+	case "signal_seen":
+		return "saw " + subject + " in signal"
+	case "status_changed":
+		return subject + " " + e.Detail
+	case "priority_changed":
+		return subject + " priority " + e.Detail
+	case "linked":
+		return subject + " linked to " + e.Detail
+	}
+	if e.Detail != "" {
+		return subject + " " + e.EventType + ": " + e.Detail
+	}
+	return subject + " " + e.EventType
+}
+
+// ListRecentEvents returns the newest limit events across GitHub, Bugzilla
+// and GitLab entities and snapshots, newest first.
+func ListRecentEvents(db *sql.DB, limit int) ([]TimelineEvent, error) {
+	var events []TimelineEvent
+
+	rows, err := db.Query(
+		`SELECT g.id, g.owner, g.repo, g.number, g.kind, g.title, e.event_type, e.detail, e.created_at
+		 FROM github_entity_events e JOIN github_entities g ON g.id = e.entity_id
+		 ORDER BY e.created_at DESC, e.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query github timeline: %w", err)
+	}
+	for rows.Next() {
+		ev := TimelineEvent{Source: "github"}
+		var owner, repo string
+		var number int
+		var title, detail sql.NullString
+		if err := rows.Scan(&ev.EntityID, &owner, &repo, &number, &ev.Kind, &title, &ev.EventType, &detail, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan github timeline: %w", err)
+		}
+		ev.Ref = fmt.Sprintf("%s/%s#%d", owner, repo, number)
+		ev.Title, ev.Detail = title.String, detail.String
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate github timeline: %w", err)
+	}
+
+	rows, err = db.Query(
+		`SELECT b.id, b.host, b.bug_id, b.title, e.event_type, e.detail, e.created_at
+		 FROM bugzilla_entity_events e JOIN bugzilla_entities b ON b.id = e.entity_id
+		 ORDER BY e.created_at DESC, e.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query bugzilla timeline: %w", err)
+	}
+	for rows.Next() {
+		ev := TimelineEvent{Source: "bugzilla"}
+		var host string
+		var bugID int
+		var detail sql.NullString
+		if err := rows.Scan(&ev.EntityID, &host, &bugID, &ev.Title, &ev.EventType, &detail, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan bugzilla timeline: %w", err)
+		}
+		ev.Ref = fmt.Sprintf("%s#%d", host, bugID)
+		ev.Detail = detail.String
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate bugzilla timeline: %w", err)
+	}
+
+	rows, err = db.Query(
+		`SELECT g.id, g.host, g.project, g.number, g.kind, g.title, e.event_type, e.detail, e.created_at
+		 FROM gitlab_entity_events e JOIN gitlab_entities g ON g.id = e.entity_id
+		 ORDER BY e.created_at DESC, e.id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query gitlab timeline: %w", err)
+	}
+	for rows.Next() {
+		ev := TimelineEvent{Source: "gitlab"}
+		var host, project string
+		var number int
+		if err := rows.Scan(&ev.EntityID, &host, &project, &number, &ev.Kind, &ev.Title, &ev.EventType, &ev.Detail, &ev.CreatedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan gitlab timeline: %w", err)
+		}
+		ev.Ref = host + "/" + GitLabRef(project, ev.Kind, number)
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate gitlab timeline: %w", err)
+	}
+
+	rows, err = db.Query(
+		`SELECT id, rev, name, profile, created_at, tab_count FROM snapshots
+		 ORDER BY created_at DESC, id DESC LIMIT ?`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("query snapshot timeline: %w", err)
+	}
+	for rows.Next() {
+		ev := TimelineEvent{Source: "snapshot", EventType: "created"}
+		var name sql.NullString
+		if err := rows.Scan(&ev.EntityID, &ev.Rev, &name, &ev.Ref, &ev.CreatedAt, &ev.TabCount); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("scan snapshot timeline: %w", err)
+		}
+		ev.Title = name.String
+		events = append(events, ev)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterate snapshot timeline: %w", err)
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+	if len(events) > limit {
+		events = events[:limit]
+	}
+	return events, nil
+}
@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// UIState is the TUI layout remembered between sessions for one profile:
+// which groups are expanded, where the cursor was, the active filter and
+// the active view.
+type UIState struct {
+	View          int             `json:"view"`
+	Filter        int             `json:"filter"`
+	Container     string          `json:"container,omitempty"`
+	Cursor        int             `json:"cursor"`
+	Offset        int             `json:"offset"`
+	Expanded      map[string]bool `json:"expanded,omitempty"`
+	SavedExpanded map[string]bool `json:"saved_expanded,omitempty"`
+}
+
+// SaveUIState stores the UI state for a profile, replacing any previous one.
+func SaveUIState(db *sql.DB, profile string, state UIState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("marshal ui state: %w", err)
+	}
+	_, err = db.Exec(
+		`INSERT INTO ui_state (profile, state, updated_at)
+		 VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(profile) DO UPDATE SET
+		   state = excluded.state,
+		   updated_at = CURRENT_TIMESTAMP`,
+		profile, string(data),
+	)
+	if err != nil {
+		return fmt.Errorf("save ui state: %w", err)
+	}
+	return nil
+}
+
+// LoadUIState returns the saved UI state for a profile. ok is false when
+// nothing has been saved for it yet.
+func LoadUIState(db *sql.DB, profile string) (state UIState, ok bool, err error) {
+	var data string
+	err = db.QueryRow(`SELECT state FROM ui_state WHERE profile = ?`, profile).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return UIState{}, false, nil
+	}
+	if err != nil {
+		return UIState{}, false, fmt.Errorf("load ui state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), &state); err != nil {
+		return UIState{}, false, fmt.Errorf("parse ui state: %w", err)
+	}
+	return state, true, nil
+}
+
+// lastProfileKey is the settings key holding the last selected source.
+const lastProfileKey = "last_profile"
+
+// SaveLastProfile remembers the most recently selected profile name, or
+// "live" for live mode.
+func SaveLastProfile(db *sql.DB, name string) error {
+	_, err := db.Exec(
+		`INSERT INTO settings (key, value, updated_at)
+		 VALUES (?, ?, CURRENT_TIMESTAMP)
+		 ON CONFLICT(key) DO UPDATE SET
+		   value = excluded.value,
+		   updated_at = CURRENT_TIMESTAMP`,
+		lastProfileKey, name,
+	)
+	if err != nil {
+		return fmt.Errorf("save last profile: %w", err)
+	}
+	return nil
+}
+
+// LastProfile returns the name saved by SaveLastProfile, or "" if no
+// source has been selected yet.
+func LastProfile(db *sql.DB) (string, error) {
+	var name string
+	err := db.QueryRow(`SELECT value FROM settings WHERE key = ?`, lastProfileKey).Scan(&name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("load last profile: %w", err)
+	}
+	return name, nil
+}
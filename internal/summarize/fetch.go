@@ -1,8 +1,14 @@
 package summarize
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"regexp"
 	"strings"
 	"time"
 
@@ -11,36 +17,126 @@ import (
 
 var skipPrefixes = []string{"about:", "moz-extension:", "file:", "chrome:", "resource:", "data:"}
 
+// DefaultUserAgent is sent when fetching pages unless TABSORDNUNG_USER_AGENT
+// is set; some sites serve bots an empty shell.
+const DefaultUserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+
+// minReadableLen is the shortest extracted text worth summarizing.
+const minReadableLen = 50
+
+// maxMetaRefresh bounds how many <meta http-equiv="refresh"> hops are followed.
+const maxMetaRefresh = 3
+
+var (
+	// ErrBlocked is returned when the site refuses the request (401, 403, 429).
+	ErrBlocked = errors.New("blocked by site")
+	// ErrEmptyContent is returned when the page loads but no readable text
+	// is left after parsing, typically because it is rendered by JavaScript.
+	ErrEmptyContent = errors.New("empty after parse")
+)
+
+var (
+	metaTagRe     = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	httpEquivRe   = regexp.MustCompile(`(?i)http-equiv\s*=\s*["']?refresh`)
+	metaContentRe = regexp.MustCompile(`(?i)content\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+	refreshURLRe  = regexp.MustCompile(`(?i)url\s*=\s*['"]?([^'"]+)`)
+)
+
+func userAgent() string {
+	if ua := os.Getenv("TABSORDNUNG_USER_AGENT"); ua != "" {
+		return ua
+	}
+	return DefaultUserAgent
+}
+
 // FetchReadable fetches a URL and extracts readable text content.
 // Returns the article title and extracted text.
-// Returns an error for non-HTTP URLs or if extraction fails.
-func FetchReadable(url string) (title, text string, err error) {
+// Returns an error for non-HTTP URLs or if extraction fails. Pages that
+// redirect with a meta refresh are followed; a page the site refuses to
+// serve returns ErrBlocked and one without readable text ErrEmptyContent.
+func FetchReadable(rawURL string) (title, text string, err error) {
 	for _, prefix := range skipPrefixes {
-		if strings.HasPrefix(url, prefix) {
-			return "", "", fmt.Errorf("skipping non-HTTP URL: %s", url)
+		if strings.HasPrefix(rawURL, prefix) {
+			return "", "", fmt.Errorf("skipping non-HTTP URL: %s", rawURL)
 		}
 	}
 
 	client := &http.Client{Timeout: 15 * time.Second}
-	req, err := http.NewRequest("GET", url, nil)
+	for hop := 0; ; hop++ {
+		page, body, err := fetchPage(client, rawURL)
+		if err != nil {
+			return "", "", err
+		}
+
+		article, parseErr := readability.FromReader(bytes.NewReader(body), page)
+		if parseErr == nil && len(strings.TrimSpace(article.TextContent)) >= minReadableLen {
+			return article.Title, article.TextContent, nil
+		}
+
+		if hop < maxMetaRefresh {
+			if target := metaRefreshURL(body, page); target != "" {
+				rawURL = target
+				continue
+			}
+		}
+		if parseErr != nil {
+			return "", "", fmt.Errorf("extract readable content from %s: %w", rawURL, parseErr)
+		}
+		return article.Title, article.TextContent, fmt.Errorf("fetch %s: %w", rawURL, ErrEmptyContent)
+	}
+}
+
+// fetchPage GETs a page with browser-like headers and returns its final
+// URL (after HTTP redirects) and body.
+func fetchPage(client *http.Client, rawURL string) (*url.URL, []byte, error) {
+	req, err := http.NewRequest("GET", rawURL, nil)
 	if err != nil {
-		return "", "", fmt.Errorf("fetch %s: %w", url, err)
+		return nil, nil, fmt.Errorf("fetch %s: %w", rawURL, err)
 	}
-	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("User-Agent", userAgent())
+	req.Header.Set("Accept", "text/html,application/xhtml+xml;q=0.9,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", "", fmt.Errorf("fetch %s: %w", url, err)
+		return nil, nil, fmt.Errorf("fetch %s: %w", rawURL, err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return "", "", fmt.Errorf("fetch %s: HTTP %d", url, resp.StatusCode)
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests:
+		return nil, nil, fmt.Errorf("fetch %s: %w (HTTP %d)", rawURL, ErrBlocked, resp.StatusCode)
+	case resp.StatusCode >= 400:
+		return nil, nil, fmt.Errorf("fetch %s: HTTP %d", rawURL, resp.StatusCode)
 	}
 
-	article, err := readability.FromReader(resp.Body, nil)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
 	if err != nil {
-		return "", "", fmt.Errorf("extract readable content from %s: %w", url, err)
+		return nil, nil, fmt.Errorf("fetch %s: %w", rawURL, err)
 	}
+	return resp.Request.URL, body, nil
+}
 
-	return article.Title, article.TextContent, nil
+// metaRefreshURL returns the absolute target of a <meta http-equiv="refresh">
+// tag in body, or "" if there is none.
+func metaRefreshURL(body []byte, base *url.URL) string {
+	for _, tag := range metaTagRe.FindAll(body, -1) {
+		if !httpEquivRe.Match(tag) {
+			continue
+		}
+		content := metaContentRe.FindSubmatch(tag)
+		if content == nil {
+			continue
+		}
+		// Only one of the double- and single-quoted groups matches.
+		m := refreshURLRe.FindStringSubmatch(string(content[1]) + string(content[2]))
+		if m == nil {
+			continue
+		}
+		target, err := base.Parse(strings.TrimSpace(m[1]))
+		if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+			continue
+		}
+		return target.String()
+	}
+	return ""
 }
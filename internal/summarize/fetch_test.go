@@ -1,6 +1,7 @@
 package summarize
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -76,3 +77,69 @@ func TestFetchReadable_HTTPError(t *testing.T) {
 		t.Error("expected error for 500 response")
 	}
 }
+
+const readablePage = `<!DOCTYPE html>
+<html><head><title>Target</title></head>
+<body><article><h1>Target</h1>
+<p>This is the main content of the article. It has enough text to be considered readable content by the readability algorithm. The quick brown fox jumps over the lazy dog.</p>
+</article></body></html>`
+
+func TestFetchReadable_FollowsMetaRefresh(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		if r.URL.Path == "/target" {
+			w.Write([]byte(readablePage))
+			return
+		}
+		w.Write([]byte(`<html><head><meta content="0; URL='/target'" http-equiv="Refresh"></head><body></body></html>`))
+	}))
+	defer srv.Close()
+
+	title, _, err := FetchReadable(srv.URL + "/start")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if title != "Target" {
+		t.Errorf("title = %q, want Target", title)
+	}
+}
+
+func TestFetchReadable_Blocked(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	_, _, err := FetchReadable(srv.URL)
+	if !errors.Is(err, ErrBlocked) {
+		t.Errorf("expected ErrBlocked, got %v", err)
+	}
+}
+
+func TestFetchReadable_EmptyAfterParse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>App</title></head><body><div id="root"></div><script src="app.js"></script></body></html>`))
+	}))
+	defer srv.Close()
+
+	_, _, err := FetchReadable(srv.URL)
+	if !errors.Is(err, ErrEmptyContent) {
+		t.Errorf("expected ErrEmptyContent, got %v", err)
+	}
+}
+
+func TestFetchReadable_UserAgentFromEnv(t *testing.T) {
+	var gotUA string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotUA = r.Header.Get("User-Agent")
+		w.Write([]byte(readablePage))
+	}))
+	defer srv.Close()
+
+	t.Setenv("TABSORDNUNG_USER_AGENT", "tabsordnung-test/1.0")
+	FetchReadable(srv.URL)
+	if gotUA != "tabsordnung-test/1.0" {
+		t.Errorf("User-Agent = %q", gotUA)
+	}
+}
@@ -0,0 +1,102 @@
+package summarize
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// maxGroupTextLen bounds the combined content of all tabs in a group
+// summary prompt; each tab gets an equal share.
+const maxGroupTextLen = 24000
+
+const groupPromptTemplate = `The following articles were collected together as one research topic. Explain what the topic is about, summarize the key points across the articles, and note where they agree or differ. Be concise.
+
+---
+
+%s`
+
+// GroupSummaryPath returns the file path for a whole-group summary.
+func GroupSummaryPath(outDir, groupName string) string {
+	return filepath.Join(outDir, "groups", sanitizeFilename(groupName)+".md")
+}
+
+// FailedTab is a tab whose content could not be fetched for a group summary.
+type FailedTab struct {
+	Tab *types.Tab
+	Err error
+}
+
+// SummarizeGroup fetches readable content for every tab in the group,
+// summarizes it with a single Ollama prompt and writes the result to
+// GroupSummaryPath. Tabs that fail to fetch are listed in the file and
+// returned; it is an error only if no tab could be fetched.
+func SummarizeGroup(ctx context.Context, model, host, outDir string, group *types.TabGroup) (string, []FailedTab, error) {
+	type article struct {
+		tab   *types.Tab
+		title string
+		text  string
+	}
+	var articles []article
+	var failed []FailedTab
+	for _, tab := range group.Tabs {
+		title, text, err := FetchReadable(tab.URL)
+		if err != nil {
+			applog.Error("summarize.group.fetch", err, "url", tab.URL)
+			failed = append(failed, FailedTab{Tab: tab, Err: err})
+			continue
+		}
+		if title == "" {
+			title = tab.Title
+		}
+		articles = append(articles, article{tab: tab, title: title, text: text})
+	}
+	if len(articles) == 0 {
+		return "", failed, fmt.Errorf("no readable content in group %q", group.Name)
+	}
+
+	perTab := maxGroupTextLen / len(articles)
+	var b strings.Builder
+	for _, a := range articles {
+		text := a.text
+		if len(text) > perTab {
+			text = text[:perTab]
+		}
+		fmt.Fprintf(&b, "## %s\n%s\n\n%s\n\n", a.title, a.tab.URL, text)
+	}
+
+	summary, err := ollamaGenerate(ctx, model, host, fmt.Sprintf(groupPromptTemplate, b.String()), nil)
+	if err != nil {
+		return "", failed, err
+	}
+
+	var md strings.Builder
+	fmt.Fprintf(&md, "# %s\n\n**Group:** %d tabs\n**Summarized:** %s\n\n", group.Name, len(group.Tabs), time.Now().Format("2006-01-02"))
+	md.WriteString("### Sources\n\n")
+	for _, a := range articles {
+		fmt.Fprintf(&md, "- [%s](%s)\n", a.title, a.tab.URL)
+	}
+	if len(failed) > 0 {
+		md.WriteString("\n### Not fetched\n\n")
+		for _, f := range failed {
+			fmt.Fprintf(&md, "- %s — %v\n", f.Tab.URL, f.Err)
+		}
+	}
+	fmt.Fprintf(&md, "\n## Summary\n\n%s\n", summary)
+
+	outPath := GroupSummaryPath(outDir, group.Name)
+	if err := os.MkdirAll(filepath.Dir(outPath), 0o755); err != nil {
+		return "", failed, err
+	}
+	if err := os.WriteFile(outPath, []byte(md.String()), 0o644); err != nil {
+		return "", failed, err
+	}
+	applog.Info("summarize.group", "group", group.Name, "tabs", len(articles), "failed", len(failed))
+	return outPath, failed, nil
+}
@@ -0,0 +1,86 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestSummarizeGroup(t *testing.T) {
+	pages := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!DOCTYPE html>
+<html><head><title>Article</title></head>
+<body><article><h1>Article</h1>
+<p>This is the main content of the article. It has enough text to be considered readable content by the readability algorithm. The quick brown fox jumps over the lazy dog.</p>
+<p>Second paragraph with more meaningful content that helps the readability parser understand this is a real article and not just navigation or boilerplate.</p>
+</article></body></html>`))
+	}))
+	defer pages.Close()
+
+	var prompt string
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(ollamaResponse{Response: "Combined summary."})
+	}))
+	defer ollama.Close()
+
+	group := &types.TabGroup{
+		Name: "Research: Go",
+		Tabs: []*types.Tab{
+			{Title: "One", URL: pages.URL + "/one"},
+			{Title: "Gone", URL: pages.URL + "/missing"},
+			{Title: "Two", URL: pages.URL + "/two"},
+		},
+	}
+
+	outDir := t.TempDir()
+	path, failed, err := SummarizeGroup(context.Background(), "llama3.2", ollama.URL, outDir, group)
+	if err != nil {
+		t.Fatalf("SummarizeGroup: %v", err)
+	}
+	if path != GroupSummaryPath(outDir, group.Name) {
+		t.Errorf("path = %s", path)
+	}
+	if len(failed) != 1 || failed[0].Tab.Title != "Gone" {
+		t.Errorf("expected the missing page to fail, got %+v", failed)
+	}
+	if strings.Count(prompt, pages.URL) != 2 {
+		t.Errorf("prompt should include both fetched tabs, got:\n%s", prompt)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := string(data)
+	if !strings.Contains(content, "### Not fetched") || !strings.Contains(content, pages.URL+"/missing") {
+		t.Errorf("file should list the failed tab, got:\n%s", content)
+	}
+	if got, _ := ReadSummary(path); strings.TrimSpace(got) != "Combined summary." {
+		t.Errorf("ReadSummary = %q", got)
+	}
+}
+
+func TestSummarizeGroupNothingFetched(t *testing.T) {
+	group := &types.TabGroup{Name: "Local", Tabs: []*types.Tab{{URL: "about:config"}}}
+	_, failed, err := SummarizeGroup(context.Background(), "m", "http://127.0.0.1:0", t.TempDir(), group)
+	if err == nil {
+		t.Fatal("expected an error when no tab could be fetched")
+	}
+	if len(failed) != 1 {
+		t.Errorf("failed = %d, want 1", len(failed))
+	}
+}
@@ -5,16 +5,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"strings"
 )
 
 const maxTextLen = 8000
 
-const promptTemplate = `Summarize the following article. Provide a concise summary with key points.
-
----
-
-%s`
+// Defaults used when neither a flag nor TABSORDNUNG_MODEL / OLLAMA_HOST
+// choose the model and the Ollama instance.
+const (
+	DefaultModel      = "llama3.2"
+	DefaultOllamaHost = "http://localhost:11434"
+)
 
 type ollamaRequest struct {
 	Model  string `json:"model"`
@@ -24,6 +27,8 @@ type ollamaRequest struct {
 
 type ollamaResponse struct {
 	Response string `json:"response"`
+	Done     bool   `json:"done"`
+	Error    string `json:"error"`
 }
 
 const threadPromptTemplate = `Summarize this Slack conversation thread. Identify the main topic, key decisions or conclusions, and any open questions or action items. Be concise.
@@ -37,11 +42,49 @@ func OllamaThreadSummarize(ctx context.Context, model, host, text string) (strin
 	if len(text) > maxTextLen {
 		text = text[:maxTextLen]
 	}
+	return ollamaGenerate(ctx, model, host, fmt.Sprintf(threadPromptTemplate, text), nil)
+}
 
+// OllamaSummarize sends text to an Ollama instance and returns the summary.
+func OllamaSummarize(ctx context.Context, model, host, text string) (string, error) {
+	return OllamaSummarizeWithPrompt(ctx, model, host, DefaultPrompt(), "", "", text)
+}
+
+// OllamaSummarizeWithPrompt is OllamaSummarize with a custom prompt
+// template, rendered with the page title, URL and text.
+func OllamaSummarizeWithPrompt(ctx context.Context, model, host string, prompt *Prompt, title, url, text string) (string, error) {
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+	rendered, err := prompt.Render(title, url, text)
+	if err != nil {
+		return "", err
+	}
+	return ollamaGenerate(ctx, model, host, rendered, nil)
+}
+
+// OllamaSummarizeStream is OllamaSummarizeWithPrompt with streaming: onToken
+// is called with each piece of the response as Ollama generates it. The
+// full summary is returned once generation is done.
+func OllamaSummarizeStream(ctx context.Context, model, host string, prompt *Prompt, title, url, text string, onToken func(string)) (string, error) {
+	if prompt == nil {
+		prompt = DefaultPrompt()
+	}
+	rendered, err := prompt.Render(title, url, text)
+	if err != nil {
+		return "", err
+	}
+	return ollamaGenerate(ctx, model, host, rendered, onToken)
+}
+
+// ollamaGenerate sends a prompt to Ollama's generate endpoint and returns
+// the full response text. With a non-nil onToken the response is streamed
+// and onToken is called for each chunk.
+func ollamaGenerate(ctx context.Context, model, host, prompt string, onToken func(string)) (string, error) {
 	reqBody := ollamaRequest{
 		Model:  model,
-		Prompt: fmt.Sprintf(threadPromptTemplate, text),
-		Stream: false,
+		Prompt: prompt,
+		Stream: onToken != nil,
 	}
 
 	body, err := json.Marshal(reqBody)
@@ -65,51 +108,75 @@ func OllamaThreadSummarize(ctx context.Context, model, host, text string) (strin
 		return "", fmt.Errorf("ollama returned HTTP %d", resp.StatusCode)
 	}
 
-	var result ollamaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("decode ollama response: %w", err)
+	if onToken == nil {
+		var result ollamaResponse
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+			return "", fmt.Errorf("decode ollama response: %w", err)
+		}
+		return result.Response, nil
 	}
 
-	return result.Response, nil
-}
-
-// OllamaSummarize sends text to an Ollama instance and returns the summary.
-func OllamaSummarize(ctx context.Context, model, host, text string) (string, error) {
-	if len(text) > maxTextLen {
-		text = text[:maxTextLen]
-	}
-
-	reqBody := ollamaRequest{
-		Model:  model,
-		Prompt: fmt.Sprintf(promptTemplate, text),
-		Stream: false,
-	}
-
-	body, err := json.Marshal(reqBody)
-	if err != nil {
-		return "", fmt.Errorf("marshal request: %w", err)
+	// Streaming responses are newline-delimited JSON objects, the last
+	// one with done set.
+	var full strings.Builder
+	dec := json.NewDecoder(resp.Body)
+	for {
+		var chunk ollamaResponse
+		if err := dec.Decode(&chunk); err != nil {
+			if err == io.EOF {
+				return "", fmt.Errorf("ollama stream ended early")
+			}
+			return "", fmt.Errorf("decode ollama response: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", fmt.Errorf("ollama: %s", chunk.Error)
+		}
+		if chunk.Response != "" {
+			full.WriteString(chunk.Response)
+			onToken(chunk.Response)
+		}
+		if chunk.Done {
+			return full.String(), nil
+		}
 	}
+}
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, host+"/api/generate", bytes.NewReader(body))
+// OllamaModels lists the models pulled on the Ollama instance at host.
+func OllamaModels(ctx context.Context, host string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+"/api/tags", nil)
 	if err != nil {
-		return "", fmt.Errorf("create request: %w", err)
+		return nil, fmt.Errorf("create request: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
-
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("ollama request: %w", err)
+		return nil, fmt.Errorf("ollama request: %w", err)
 	}
 	defer resp.Body.Close()
-
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("ollama returned HTTP %d", resp.StatusCode)
+		return nil, fmt.Errorf("ollama returned HTTP %d", resp.StatusCode)
+	}
+	var result struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
 	}
-
-	var result ollamaResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return "", fmt.Errorf("decode ollama response: %w", err)
+		return nil, fmt.Errorf("decode ollama response: %w", err)
 	}
+	names := make([]string, len(result.Models))
+	for i, m := range result.Models {
+		names[i] = m.Name
+	}
+	return names, nil
+}
 
-	return result.Response, nil
+// HasModel reports whether model is among names, as returned by
+// OllamaModels. A model without a tag matches its ":latest" variant.
+func HasModel(names []string, model string) bool {
+	for _, n := range names {
+		if n == model || (!strings.Contains(model, ":") && n == model+":latest") {
+			return true
+		}
+	}
+	return false
 }
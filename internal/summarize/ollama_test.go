@@ -68,3 +68,84 @@ func TestOllamaSummarize_Cancelled(t *testing.T) {
 		t.Error("expected error for cancelled context")
 	}
 }
+
+func TestOllamaSummarizeStream(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("expected stream=true")
+		}
+		enc := json.NewEncoder(w)
+		enc.Encode(ollamaResponse{Response: "Key "})
+		enc.Encode(ollamaResponse{Response: "points."})
+		enc.Encode(ollamaResponse{Done: true})
+	}))
+	defer srv.Close()
+
+	var tokens []string
+	result, err := OllamaSummarizeStream(context.Background(), "llama3.2", srv.URL, nil, "", "", "text", func(tok string) {
+		tokens = append(tokens, tok)
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result != "Key points." {
+		t.Errorf("result = %q", result)
+	}
+	if len(tokens) != 2 || tokens[0] != "Key " {
+		t.Errorf("tokens = %q", tokens)
+	}
+}
+
+func TestOllamaSummarizeStream_EndsEarly(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ollamaResponse{Response: "partial"})
+	}))
+	defer srv.Close()
+
+	_, err := OllamaSummarizeStream(context.Background(), "llama3.2", srv.URL, nil, "", "", "text", func(string) {})
+	if err == nil {
+		t.Error("expected error for a stream without done")
+	}
+}
+
+func TestOllamaModels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/api/tags" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"models":[{"name":"llama3.2:latest"},{"name":"qwen2.5:7b"}]}`))
+	}))
+	defer srv.Close()
+
+	names, err := OllamaModels(context.Background(), srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(names) != 2 {
+		t.Fatalf("got %v", names)
+	}
+	for model, want := range map[string]bool{
+		"llama3.2":        true,
+		"llama3.2:latest": true,
+		"qwen2.5:7b":      true,
+		"qwen2.5":         false,
+		"mistral":         false,
+	} {
+		if got := HasModel(names, model); got != want {
+			t.Errorf("HasModel(%q) = %v, want %v", model, got, want)
+		}
+	}
+}
+
+func TestOllamaModels_Error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if _, err := OllamaModels(context.Background(), srv.URL); err == nil {
+		t.Error("expected error for HTTP 500")
+	}
+}
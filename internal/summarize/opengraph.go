@@ -0,0 +1,118 @@
+package summarize
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// OpenGraph is the preview a page describes itself with in its Open Graph
+// meta tags, falling back to the <title> and description meta tags.
+type OpenGraph struct {
+	Title       string
+	Description string
+	Image       string // absolute URL of og:image
+	SiteName    string
+}
+
+// IsEmpty reports whether the page described nothing worth showing.
+func (og OpenGraph) IsEmpty() bool {
+	return og.Title == "" && og.Description == "" && og.Image == ""
+}
+
+var (
+	metaKeyRe = regexp.MustCompile(`(?i)\s(?:property|name)\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+	titleRe   = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+)
+
+// ErrPrivateHost is returned for previews of pages on loopback, private or
+// link-local addresses, which a tab's URL must not be able to probe.
+var ErrPrivateHost = errors.New("refusing to fetch a private network address")
+
+// openGraphClient only connects to public addresses. The check runs on the
+// dialed address, so it also covers redirects and host names that resolve
+// to a private address; for the same reason no proxy is used.
+var openGraphClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+					return fmt.Errorf("%w: %s", ErrPrivateHost, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	},
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() && !ip.IsMulticast()
+}
+
+// FetchOpenGraph fetches a page and reads its Open Graph preview. It is
+// much cheaper than FetchReadable: nothing but the meta tags is parsed.
+// Only http and https pages on public addresses are fetched.
+func FetchOpenGraph(rawURL string) (*OpenGraph, error) {
+	if u, err := url.Parse(rawURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil, fmt.Errorf("skipping non-HTTP URL: %s", rawURL)
+	}
+
+	page, body, err := fetchPage(openGraphClient, rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := make(map[string]string)
+	for _, tag := range metaTagRe.FindAll(body, -1) {
+		key := metaKeyRe.FindSubmatch(tag)
+		content := metaContentRe.FindSubmatch(tag)
+		if key == nil || content == nil {
+			continue
+		}
+		// Only one of the alternative groups matches.
+		name := strings.ToLower(string(key[1]) + string(key[2]) + string(key[3]))
+		if _, ok := meta[name]; !ok {
+			meta[name] = strings.TrimSpace(html.UnescapeString(string(content[1]) + string(content[2])))
+		}
+	}
+	first := func(keys ...string) string {
+		for _, k := range keys {
+			if v := meta[k]; v != "" {
+				return v
+			}
+		}
+		return ""
+	}
+
+	og := &OpenGraph{
+		Title:       first("og:title", "twitter:title"),
+		Description: first("og:description", "twitter:description", "description"),
+		SiteName:    first("og:site_name"),
+	}
+	if og.Title == "" {
+		if m := titleRe.FindSubmatch(body); m != nil {
+			og.Title = strings.TrimSpace(html.UnescapeString(string(m[1])))
+		}
+	}
+	if img := first("og:image", "og:image:url", "twitter:image"); img != "" {
+		if u, err := page.Parse(img); err == nil {
+			og.Image = u.String()
+		}
+	}
+	return og, nil
+}
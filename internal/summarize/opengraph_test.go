@@ -0,0 +1,88 @@
+package summarize
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// withLocalClient lets FetchOpenGraph reach srv, which listens on loopback.
+func withLocalClient(t *testing.T, srv *httptest.Server) {
+	t.Helper()
+	orig := openGraphClient
+	openGraphClient = srv.Client()
+	t.Cleanup(func() { openGraphClient = orig })
+}
+
+func TestFetchOpenGraph(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<!DOCTYPE html>
+<html><head>
+<title>Fallback title</title>
+<meta property="og:title" content="Tabs &amp; Groups">
+<meta content='What to do with 500 open tabs' property='og:description'>
+<meta name="description" content="plain description">
+<meta property="og:image" content="/img/cover.png">
+<meta property="og:site_name" content="Example">
+</head><body></body></html>`))
+	}))
+	defer srv.Close()
+	withLocalClient(t, srv)
+
+	og, err := FetchOpenGraph(srv.URL + "/post")
+	if err != nil {
+		t.Fatalf("FetchOpenGraph: %v", err)
+	}
+	want := OpenGraph{
+		Title:       "Tabs & Groups",
+		Description: "What to do with 500 open tabs",
+		Image:       srv.URL + "/img/cover.png",
+		SiteName:    "Example",
+	}
+	if *og != want {
+		t.Errorf("got %+v, want %+v", *og, want)
+	}
+}
+
+func TestFetchOpenGraph_Fallbacks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title> Plain page </title>
+<meta name="description" content="Described the old way"></head></html>`))
+	}))
+	defer srv.Close()
+	withLocalClient(t, srv)
+
+	og, err := FetchOpenGraph(srv.URL)
+	if err != nil {
+		t.Fatalf("FetchOpenGraph: %v", err)
+	}
+	if og.Title != "Plain page" || og.Description != "Described the old way" || og.Image != "" {
+		t.Errorf("got %+v", *og)
+	}
+	if og.IsEmpty() {
+		t.Error("expected a non-empty preview")
+	}
+}
+
+func TestFetchOpenGraph_SkipsNonHTTP(t *testing.T) {
+	for _, u := range []string{"about:blank", "file:///etc/passwd", "ftp://example.com/"} {
+		if _, err := FetchOpenGraph(u); err == nil {
+			t.Errorf("expected an error for %s", u)
+		}
+	}
+}
+
+func TestFetchOpenGraph_SkipsPrivateHosts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("fetched a loopback address")
+	}))
+	defer srv.Close()
+
+	for _, u := range []string{srv.URL, "http://localhost:1/", "http://10.0.0.1:1/", "http://[::1]:1/"} {
+		if _, err := FetchOpenGraph(u); !errors.Is(err, ErrPrivateHost) {
+			t.Errorf("FetchOpenGraph(%s) = %v, want ErrPrivateHost", u, err)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package summarize
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// DefaultPromptTemplate is the built-in tab summarization prompt.
+const DefaultPromptTemplate = `Summarize the following article. Provide a concise summary with key points.
+
+---
+
+{{.Content}}`
+
+// PromptData is the data a prompt template is rendered with.
+type PromptData struct {
+	Title   string
+	URL     string
+	Content string
+}
+
+// Prompt is a parsed summarization prompt template.
+type Prompt struct {
+	Name   string // "default" or the file the template was loaded from
+	Source string
+	tmpl   *template.Template
+}
+
+// DefaultPrompt returns the built-in prompt.
+func DefaultPrompt() *Prompt {
+	p, _ := ParsePrompt("default", DefaultPromptTemplate)
+	return p
+}
+
+// ParsePrompt parses a text/template prompt with {{.Title}}, {{.URL}} and
+// {{.Content}} placeholders.
+func ParsePrompt(name, src string) (*Prompt, error) {
+	tmpl, err := template.New(name).Parse(src)
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt template %s: %w", name, err)
+	}
+	if !strings.Contains(src, ".Content") {
+		return nil, fmt.Errorf("prompt template %s does not use {{.Content}}", name)
+	}
+	return &Prompt{Name: name, Source: src, tmpl: tmpl}, nil
+}
+
+// LoadPrompt reads a prompt template from a file. An empty path returns
+// the default prompt.
+func LoadPrompt(path string) (*Prompt, error) {
+	if path == "" {
+		return DefaultPrompt(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read prompt file: %w", err)
+	}
+	return ParsePrompt(path, string(data))
+}
+
+// Render fills in the template. Content is truncated to the length Ollama
+// prompts are limited to.
+func (p *Prompt) Render(title, url, content string) (string, error) {
+	if len(content) > maxTextLen {
+		content = content[:maxTextLen]
+	}
+	var b strings.Builder
+	if err := p.tmpl.Execute(&b, PromptData{Title: title, URL: url, Content: content}); err != nil {
+		return "", fmt.Errorf("render prompt %s: %w", p.Name, err)
+	}
+	return b.String(), nil
+}
+
+// FormatSummary returns the markdown file content for a tab summary. The
+// front matter records which prompt produced the summary.
+func FormatSummary(title, url string, prompt *Prompt, summary string) string {
+	var b strings.Builder
+	b.WriteString("---\n")
+	if prompt == nil || prompt.Name == "default" {
+		b.WriteString("prompt: default\n")
+	} else {
+		fmt.Fprintf(&b, "prompt: %q\n", prompt.Name)
+		b.WriteString("prompt_template: |\n")
+		for _, line := range strings.Split(strings.TrimRight(prompt.Source, "\n"), "\n") {
+			b.WriteString("  " + line + "\n")
+		}
+	}
+	b.WriteString("---\n\n")
+	fmt.Fprintf(&b, "# %s\n\n**Source:** %s\n**Summarized:** %s\n\n## Summary\n\n%s\n", title, url, time.Now().Format("2006-01-02"), summary)
+	return b.String()
+}
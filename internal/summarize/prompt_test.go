@@ -0,0 +1,83 @@
+package summarize
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDefaultPromptMatchesBuiltin(t *testing.T) {
+	got, err := DefaultPrompt().Render("T", "https://example.com", "article text")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Summarize the following article. Provide a concise summary with key points.\n\n---\n\narticle text"
+	if got != want {
+		t.Errorf("Render() = %q, want %q", got, want)
+	}
+}
+
+func TestLoadPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prompt.txt")
+	os.WriteFile(path, []byte("Extract action items from {{.Title}} ({{.URL}}):\n{{.Content}}\n"), 0o644)
+
+	p, err := LoadPrompt(path)
+	if err != nil {
+		t.Fatalf("LoadPrompt: %v", err)
+	}
+	got, _ := p.Render("Page", "https://example.com", "body")
+	if got != "Extract action items from Page (https://example.com):\nbody\n" {
+		t.Errorf("Render() = %q", got)
+	}
+
+	os.WriteFile(path, []byte("Summarize {{.Title}}"), 0o644)
+	if _, err := LoadPrompt(path); err == nil {
+		t.Error("expected an error for a template without {{.Content}}")
+	}
+	os.WriteFile(path, []byte("{{.Content"), 0o644)
+	if _, err := LoadPrompt(path); err == nil {
+		t.Error("expected a parse error")
+	}
+}
+
+func TestOllamaSummarizeWithPrompt(t *testing.T) {
+	var gotPrompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		gotPrompt = req.Prompt
+		json.NewEncoder(w).Encode(ollamaResponse{Response: "ok"})
+	}))
+	defer srv.Close()
+
+	p, _ := ParsePrompt("custom", "For auditors: {{.URL}}\n{{.Content}}")
+	if _, err := OllamaSummarizeWithPrompt(context.Background(), "m", srv.URL, p, "T", "https://example.com", "text"); err != nil {
+		t.Fatal(err)
+	}
+	if gotPrompt != "For auditors: https://example.com\ntext" {
+		t.Errorf("prompt = %q", gotPrompt)
+	}
+}
+
+func TestFormatSummaryFrontMatter(t *testing.T) {
+	p, _ := ParsePrompt("/home/me/audit.txt", "For auditors:\n{{.Content}}")
+	content := FormatSummary("Title", "https://example.com", p, "The summary.")
+	if !strings.HasPrefix(content, "---\nprompt: \"/home/me/audit.txt\"\nprompt_template: |\n  For auditors:\n  {{.Content}}\n---\n") {
+		t.Errorf("unexpected front matter:\n%s", content)
+	}
+
+	path := filepath.Join(t.TempDir(), "s.md")
+	os.WriteFile(path, []byte(content), 0o644)
+	if got, _ := ReadSummary(path); got != "The summary.\n" {
+		t.Errorf("ReadSummary() = %q", got)
+	}
+
+	if !strings.HasPrefix(FormatSummary("T", "u", nil, "s"), "---\nprompt: default\n---\n") {
+		t.Error("nil prompt should be recorded as default")
+	}
+}
@@ -0,0 +1,30 @@
+package summarize
+
+import "strings"
+
+// wordsPerMinute is the average adult reading speed used for estimates.
+const wordsPerMinute = 230
+
+// WordCount returns the number of whitespace-separated words in text.
+func WordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// ReadingMinutes estimates how long it takes to read the given number of
+// words, rounded up to a whole minute. It returns 0 for no words.
+func ReadingMinutes(words int) int {
+	if words <= 0 {
+		return 0
+	}
+	return (words + wordsPerMinute - 1) / wordsPerMinute
+}
+
+// FetchWordCount fetches a page's readable content and counts its words,
+// without summarizing it.
+func FetchWordCount(url string) (int, error) {
+	_, text, err := FetchReadable(url)
+	if err != nil {
+		return 0, err
+	}
+	return WordCount(text), nil
+}
@@ -0,0 +1,29 @@
+package summarize
+
+import "testing"
+
+func TestWordCount(t *testing.T) {
+	if got := WordCount("  one two\nthree\tfour  "); got != 4 {
+		t.Errorf("WordCount = %d, want 4", got)
+	}
+	if got := WordCount(""); got != 0 {
+		t.Errorf("WordCount(\"\") = %d, want 0", got)
+	}
+}
+
+func TestReadingMinutes(t *testing.T) {
+	tests := []struct {
+		words, want int
+	}{
+		{0, 0},
+		{1, 1},
+		{230, 1},
+		{231, 2},
+		{6900, 30},
+	}
+	for _, tt := range tests {
+		if got := ReadingMinutes(tt.words); got != tt.want {
+			t.Errorf("ReadingMinutes(%d) = %d, want %d", tt.words, got, tt.want)
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
-	"time"
 
 	"github.com/lotas/tabsordnung/internal/applog"
 	"github.com/lotas/tabsordnung/internal/types"
@@ -21,6 +20,7 @@ type Config struct {
 	OllamaHost string
 	GroupName  string
 	Session    *types.SessionData
+	Prompt     *Prompt // nil uses DefaultPrompt
 }
 
 var nonAlphanumeric = regexp.MustCompile(`[^a-z0-9]+`)
@@ -133,12 +133,6 @@ func Run(cfg Config) error {
 		}
 		fmt.Fprintf(os.Stderr, " ok\n")
 
-		if len(strings.TrimSpace(text)) < 50 {
-			fmt.Fprintf(os.Stderr, "        ✗ not enough readable content\n")
-			errCount++
-			continue
-		}
-
 		// Use fetched title if available, fall back to tab title.
 		if title == "" {
 			title = tab.Title
@@ -146,7 +140,7 @@ func Run(cfg Config) error {
 
 		// Summarize via Ollama.
 		fmt.Fprintf(os.Stderr, "        summarizing...")
-		summary, err := OllamaSummarize(ctx, cfg.Model, cfg.OllamaHost, text)
+		summary, err := OllamaSummarizeWithPrompt(ctx, cfg.Model, cfg.OllamaHost, cfg.Prompt, title, tab.URL, text)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, " ✗ ollama: %v\n", err)
 			errCount++
@@ -155,8 +149,7 @@ func Run(cfg Config) error {
 		fmt.Fprintf(os.Stderr, " ok\n")
 
 		// Write markdown file.
-		content := fmt.Sprintf("# %s\n\n**Source:** %s\n**Summarized:** %s\n\n## Summary\n\n%s\n",
-			title, tab.URL, time.Now().Format("2006-01-02"), summary)
+		content := FormatSummary(title, tab.URL, cfg.Prompt, summary)
 
 		if err := os.WriteFile(outPath, []byte(content), 0o644); err != nil {
 			fmt.Fprintf(os.Stderr, "        ✗ write: %v\n", err)
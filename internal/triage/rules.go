@@ -0,0 +1,146 @@
+package triage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// Rule routes matching GitHub tabs to a tab group. Empty or nil conditions
+// match anything; a tab goes to the group of the first rule it matches.
+type Rule struct {
+	State           string `json:"state,omitempty"` // "open", "merged" or "closed"
+	Kind            string `json:"kind,omitempty"`  // "pr", "issue" or "discussion"
+	Repo            string `json:"repo,omitempty"`  // owner/repo glob, e.g. "mozilla/*"
+	Assigned        *bool  `json:"assigned,omitempty"`
+	ReviewRequested *bool  `json:"reviewRequested,omitempty"`
+	Authored        *bool  `json:"authored,omitempty"`
+	NewActivity     *bool  `json:"newActivity,omitempty"` // updated on GitHub since the tab was last visited
+	Group           string `json:"group"`
+	Color           string `json:"color,omitempty"`
+}
+
+func yes() *bool { b := true; return &b }
+
+// DefaultRules is the built-in classification, used when no rules file
+// exists.
+var DefaultRules = []Rule{
+	{State: "open", Kind: "pr", ReviewRequested: yes(), Group: string(CatReviewRequested), Color: "purple"},
+	{State: "open", Kind: "pr", Authored: yes(), Group: string(CatMyPRs), Color: "green"},
+	{State: "open", ReviewRequested: yes(), Group: string(CatNeedsAttention), Color: "red"},
+	{State: "open", Assigned: yes(), Group: string(CatNeedsAttention), Color: "red"},
+	{State: "open", NewActivity: yes(), Group: string(CatNeedsAttention), Color: "red"},
+	{State: "open", Kind: "pr", Group: string(CatOpenPRs), Color: "blue"},
+	{State: "open", Kind: "issue", Group: string(CatOpenIssues), Color: "cyan"},
+	{State: "closed", Group: string(CatClosedMerged), Color: "grey"},
+	{State: "merged", Group: string(CatClosedMerged), Color: "grey"},
+}
+
+// defaultColor is used for rules that do not name a group color.
+const defaultColor = "blue"
+
+// validColors are the tab group colors Firefox supports.
+var validColors = map[string]bool{
+	"blue": true, "turquoise": true, "green": true, "yellow": true, "orange": true,
+	"red": true, "pink": true, "purple": true, "cyan": true, "grey": true,
+}
+
+// RulesFilePath returns the path to the custom triage rules file.
+func RulesFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tabsordnung", "triage.json")
+}
+
+// LoadRules reads a JSON array of rules from p. A missing file returns
+// DefaultRules.
+func LoadRules(p string) ([]Rule, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return DefaultRules, nil
+		}
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", p, err)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("parse %s: no rules", p)
+	}
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return nil, fmt.Errorf("parse %s: rule %d: %w", p, i+1, err)
+		}
+	}
+	return rules, nil
+}
+
+func (rule Rule) validate() error {
+	if rule.Group == "" {
+		return fmt.Errorf("group is required")
+	}
+	switch rule.State {
+	case "", "open", "merged", "closed":
+	default:
+		return fmt.Errorf("invalid state %q", rule.State)
+	}
+	switch rule.Kind {
+	case "", "pr", "issue", "discussion":
+	default:
+		return fmt.Errorf("invalid kind %q", rule.Kind)
+	}
+	if _, err := path.Match(rule.Repo, ""); err != nil {
+		return fmt.Errorf("invalid repo pattern %q", rule.Repo)
+	}
+	if rule.Color != "" && !validColors[rule.Color] {
+		return fmt.Errorf("invalid color %q", rule.Color)
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a tab with GitHubTriage info.
+func (rule Rule) matches(tab *types.Tab) bool {
+	info := tab.GitHubTriage
+	if rule.State != "" && rule.State != tab.GitHubStatus {
+		return false
+	}
+	if rule.Kind != "" && rule.Kind != parseKind(tab.URL) {
+		return false
+	}
+	if rule.Repo != "" {
+		ok, _ := path.Match(strings.ToLower(rule.Repo), strings.ToLower(parseRepo(tab.URL)))
+		if !ok {
+			return false
+		}
+	}
+	return flagMatches(rule.Assigned, info.Assigned) &&
+		flagMatches(rule.ReviewRequested, info.ReviewRequested) &&
+		flagMatches(rule.Authored, info.Authored) &&
+		flagMatches(rule.NewActivity, hasNewActivity(tab))
+}
+
+func flagMatches(want *bool, got bool) bool {
+	return want == nil || *want == got
+}
+
+// usesFlags reports whether the rule matches on the user's involvement
+// rather than just state, kind and repo.
+func (rule Rule) usesFlags() bool {
+	return rule.Assigned != nil || rule.ReviewRequested != nil || rule.Authored != nil || rule.NewActivity != nil
+}
+
+func (rule Rule) color() string {
+	if rule.Color == "" {
+		return defaultColor
+	}
+	return rule.Color
+}
@@ -2,8 +2,8 @@ package triage
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/http"
 	"regexp"
 	"strings"
 	"time"
@@ -16,10 +16,12 @@ import (
 type Category string
 
 const (
-	CatNeedsAttention Category = "Needs Attention"
-	CatOpenPRs        Category = "Open PRs"
-	CatOpenIssues     Category = "Open Issues"
-	CatClosedMerged   Category = "Closed / Merged"
+	CatReviewRequested Category = "Review Requested"
+	CatMyPRs           Category = "My PRs Awaiting Review"
+	CatNeedsAttention  Category = "Needs Attention"
+	CatOpenPRs         Category = "Open PRs"
+	CatOpenIssues      Category = "Open Issues"
+	CatClosedMerged    Category = "Closed / Merged"
 )
 
 // Move represents a proposed tab-to-category assignment.
@@ -29,97 +31,146 @@ type Move struct {
 	Reason   string
 }
 
+// Bucket is a target tab group and the tabs proposed to move into it.
+type Bucket struct {
+	Name  Category
+	Color string
+	Moves []*Move
+}
+
 // Result holds the triage classification output.
 type Result struct {
-	NeedsAttention []*Move
-	OpenPRs        []*Move
-	OpenIssues     []*Move
-	ClosedMerged   []*Move
-	Skipped        int
+	// Buckets are ordered by the first rule naming each group; groups no
+	// tab matched are left out.
+	Buckets   []*Bucket
+	Skipped   int // non-GitHub tabs
+	Unmatched int // GitHub tabs that matched no rule
 }
 
-var githubURLPattern = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/(issues|pull)/(\d+)`)
+// Moves returns the moves into the named group.
+func (r *Result) Moves(name Category) []*Move {
+	for _, b := range r.Buckets {
+		if b.Name == name {
+			return b.Moves
+		}
+	}
+	return nil
+}
 
-// parseKind determines "pr" or "issue" from a GitHub URL.
+// Total returns the number of classified tabs.
+func (r *Result) Total() int {
+	n := 0
+	for _, b := range r.Buckets {
+		n += len(b.Moves)
+	}
+	return n
+}
+
+var githubURLPattern = regexp.MustCompile(`^https?://github\.com/([^/]+)/([^/]+)/(issues|pull|discussions)/(\d+)`)
+
+// parseKind determines "pr", "issue" or "discussion" from a GitHub URL.
 func parseKind(rawURL string) string {
 	matches := githubURLPattern.FindStringSubmatch(rawURL)
 	if matches == nil {
 		return ""
 	}
-	if matches[3] == "pull" {
+	switch matches[3] {
+	case "pull":
 		return "pr"
+	case "discussions":
+		return "discussion"
 	}
 	return "issue"
 }
 
-// needsAttention returns true if the tab requires the user's attention.
-func needsAttention(tab *types.Tab) bool {
-	if tab.GitHubStatus != "open" {
-		return false
+// parseRepo returns "owner/repo" from a GitHub issue, PR or discussion URL.
+func parseRepo(rawURL string) string {
+	matches := githubURLPattern.FindStringSubmatch(rawURL)
+	if matches == nil {
+		return ""
 	}
+	return matches[1] + "/" + matches[2]
+}
+
+// hasNewActivity reports whether the issue/PR was updated since the tab was
+// last visited.
+func hasNewActivity(tab *types.Tab) bool {
 	info := tab.GitHubTriage
-	if info.ReviewRequested {
-		return true
-	}
-	if info.Assigned {
-		return true
+	return !info.UpdatedAt.IsZero() && !tab.LastAccessed.IsZero() && info.UpdatedAt.After(tab.LastAccessed)
+}
+
+// Classify assigns each tab with GitHubTriage info to the group of the
+// first matching rule. Nil rules use DefaultRules.
+func Classify(tabs []*types.Tab, rules []Rule) *Result {
+	if rules == nil {
+		rules = DefaultRules
 	}
-	// New activity since last access
-	if !info.UpdatedAt.IsZero() && !tab.LastAccessed.IsZero() && info.UpdatedAt.After(tab.LastAccessed) {
-		return true
+
+	// Buckets are laid out in rule order up front so the output order does
+	// not depend on tab order.
+	buckets := make(map[string]*Bucket)
+	var order []*Bucket
+	for _, rule := range rules {
+		if _, ok := buckets[rule.Group]; !ok {
+			b := &Bucket{Name: Category(rule.Group), Color: rule.color()}
+			buckets[rule.Group] = b
+			order = append(order, b)
+		}
 	}
-	return false
-}
 
-// Classify assigns each tab with GitHubTriage info to a triage category.
-func Classify(tabs []*types.Tab) *Result {
 	r := &Result{}
-
 	for _, tab := range tabs {
 		if tab.GitHubTriage == nil {
 			r.Skipped++
 			continue
 		}
-
-		if needsAttention(tab) {
-			reason := buildReason(tab)
-			r.NeedsAttention = append(r.NeedsAttention, &Move{
+		matched := false
+		for _, rule := range rules {
+			if !rule.matches(tab) {
+				continue
+			}
+			b := buckets[rule.Group]
+			b.Moves = append(b.Moves, &Move{
 				Tab:      tab,
-				Category: CatNeedsAttention,
-				Reason:   reason,
+				Category: b.Name,
+				Reason:   moveReason(rule, tab),
 			})
-			continue
+			matched = true
+			break
 		}
-
-		status := tab.GitHubStatus
-		if status == "closed" || status == "merged" {
-			r.ClosedMerged = append(r.ClosedMerged, &Move{
-				Tab:      tab,
-				Category: CatClosedMerged,
-				Reason:   status,
-			})
-			continue
+		if !matched {
+			r.Unmatched++
 		}
+	}
 
-		kind := parseKind(tab.URL)
-		if kind == "pr" {
-			r.OpenPRs = append(r.OpenPRs, &Move{
-				Tab:      tab,
-				Category: CatOpenPRs,
-				Reason:   "open PR",
-			})
-		} else {
-			r.OpenIssues = append(r.OpenIssues, &Move{
-				Tab:      tab,
-				Category: CatOpenIssues,
-				Reason:   "open issue",
-			})
+	for _, b := range order {
+		if len(b.Moves) > 0 {
+			r.Buckets = append(r.Buckets, b)
 		}
 	}
-
 	return r
 }
 
+// moveReason describes why a tab matched a rule.
+func moveReason(rule Rule, tab *types.Tab) string {
+	if rule.usesFlags() {
+		if reason := buildReason(tab); reason != "" {
+			return reason
+		}
+	}
+	status := tab.GitHubStatus
+	if status == "closed" || status == "merged" {
+		return status
+	}
+	switch parseKind(tab.URL) {
+	case "pr":
+		return "open PR"
+	case "discussion":
+		return "open discussion"
+	}
+	return "open issue"
+}
+
 // buildReason constructs a human-readable reason for why a tab needs attention.
 func buildReason(tab *types.Tab) string {
 	var reasons []string
@@ -127,10 +178,13 @@ func buildReason(tab *types.Tab) string {
 	if info.ReviewRequested {
 		reasons = append(reasons, "review requested")
 	}
+	if info.Authored {
+		reasons = append(reasons, "awaiting review")
+	}
 	if info.Assigned {
 		reasons = append(reasons, "assigned")
 	}
-	if !info.UpdatedAt.IsZero() && !tab.LastAccessed.IsZero() && info.UpdatedAt.After(tab.LastAccessed) {
+	if hasNewActivity(tab) {
 		reasons = append(reasons, "new activity")
 	}
 	return strings.Join(reasons, ", ")
@@ -140,50 +194,73 @@ func buildReason(tab *types.Tab) string {
 func FormatDryRun(r *Result) string {
 	var b strings.Builder
 
-	sections := []struct {
-		name  string
-		moves []*Move
-	}{
-		{string(CatNeedsAttention), r.NeedsAttention},
-		{string(CatOpenPRs), r.OpenPRs},
-		{string(CatOpenIssues), r.OpenIssues},
-		{string(CatClosedMerged), r.ClosedMerged},
-	}
-
-	for _, sec := range sections {
-		if len(sec.moves) == 0 {
+	for _, sec := range r.Buckets {
+		if len(sec.Moves) == 0 {
 			continue
 		}
-		b.WriteString(fmt.Sprintf("\n%s (%d):\n", sec.name, len(sec.moves)))
-		for _, m := range sec.moves {
+		b.WriteString(fmt.Sprintf("\n%s (%d):\n", sec.Name, len(sec.Moves)))
+		for _, m := range sec.Moves {
 			b.WriteString(fmt.Sprintf("  - %s (%s)\n", m.Tab.Title, m.Reason))
 		}
 	}
 
+	if r.Skipped > 0 || r.Unmatched > 0 {
+		b.WriteString("\n")
+	}
 	if r.Skipped > 0 {
-		b.WriteString(fmt.Sprintf("\nSkipped: %d non-GitHub tabs\n", r.Skipped))
+		b.WriteString(fmt.Sprintf("Skipped: %d non-GitHub tabs\n", r.Skipped))
+	}
+	if r.Unmatched > 0 {
+		b.WriteString(fmt.Sprintf("Unmatched: %d GitHub tabs matched no rule\n", r.Unmatched))
 	}
 
 	return b.String()
 }
 
-// Apply executes triage moves via the live mode WebSocket extension.
-func Apply(r *Result, port int) error {
-	srv := server.New(port)
+type jsonDryRun struct {
+	Moves     []jsonMove `json:"moves"`
+	Skipped   int        `json:"skipped"`
+	Unmatched int        `json:"unmatched"`
+}
+
+type jsonMove struct {
+	URL    string `json:"url"`
+	Title  string `json:"title"`
+	Group  string `json:"group"`
+	Color  string `json:"color"`
+	Reason string `json:"reason"`
+}
 
+// DryRunJSON returns the proposed triage moves as a JSON document, one
+// entry per classified tab with its target group.
+func DryRunJSON(r *Result) (string, error) {
+	out := jsonDryRun{Moves: make([]jsonMove, 0, r.Total()), Skipped: r.Skipped, Unmatched: r.Unmatched}
+	for _, sec := range r.Buckets {
+		for _, m := range sec.Moves {
+			out.Moves = append(out.Moves, jsonMove{
+				URL:    m.Tab.URL,
+				Title:  m.Tab.Title,
+				Group:  string(sec.Name),
+				Color:  sec.Color,
+				Reason: m.Reason,
+			})
+		}
+	}
+	b, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// Apply executes triage moves via the live mode WebSocket extension,
+// serving srv until the moves are done.
+func Apply(r *Result, srv *server.Server) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	go func() {
-		mux := http.NewServeMux()
-		mux.Handle("/", srv.Handler())
-		httpSrv := &http.Server{Addr: fmt.Sprintf("127.0.0.1:%d", port), Handler: mux}
-		go func() {
-			<-ctx.Done()
-			httpSrv.Close()
-		}()
-		httpSrv.ListenAndServe()
-	}()
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(ctx) }()
 
 	// Wait for extension to connect and send initial snapshot.
 	fmt.Println("Waiting for extension connection...")
@@ -193,6 +270,8 @@ func Apply(r *Result, port int) error {
 		if snapshot.Type != "snapshot" {
 			return fmt.Errorf("expected initial snapshot message, got %q", snapshot.Type)
 		}
+	case err := <-errc:
+		return err
 	case <-time.After(60 * time.Second):
 		return fmt.Errorf("timed out waiting for extension connection")
 	}
@@ -208,25 +287,14 @@ func Apply(r *Result, port int) error {
 		urlToBrowserID[t.URL] = t.BrowserID
 	}
 
-	categories := []struct {
-		name  Category
-		color string
-		moves []*Move
-	}{
-		{CatNeedsAttention, "red", r.NeedsAttention},
-		{CatOpenPRs, "blue", r.OpenPRs},
-		{CatOpenIssues, "cyan", r.OpenIssues},
-		{CatClosedMerged, "grey", r.ClosedMerged},
-	}
-
-	for _, cat := range categories {
-		if len(cat.moves) == 0 {
+	for _, cat := range r.Buckets {
+		if len(cat.Moves) == 0 {
 			continue
 		}
 
 		// Resolve live browser tab IDs by URL
 		var tabIDs []int
-		for _, m := range cat.moves {
+		for _, m := range cat.Moves {
 			if id, ok := urlToBrowserID[m.Tab.URL]; ok {
 				tabIDs = append(tabIDs, id)
 			}
@@ -237,12 +305,12 @@ func Apply(r *Result, port int) error {
 		err := srv.Send(server.OutgoingMsg{
 			ID:     groupID,
 			Action: "create-group",
-			Name:   string(cat.name),
-			Color:  cat.color,
+			Name:   string(cat.Name),
+			Color:  cat.Color,
 			TabIDs: tabIDs,
 		})
 		if err != nil {
-			return fmt.Errorf("failed to create group %s: %w", cat.name, err)
+			return fmt.Errorf("failed to create group %s: %w", cat.Name, err)
 		}
 
 		// Wait for group creation response
@@ -253,16 +321,16 @@ func Apply(r *Result, port int) error {
 			case msg := <-srv.Messages():
 				if msg.ID == groupID {
 					if msg.OK != nil && !*msg.OK {
-						return fmt.Errorf("failed to create group %s: %s", cat.name, msg.Error)
+						return fmt.Errorf("failed to create group %s: %s", cat.Name, msg.Error)
 					}
 					break waitGroup
 				}
 			case <-respTimeout:
-				return fmt.Errorf("timed out waiting for group creation: %s", cat.name)
+				return fmt.Errorf("timed out waiting for group creation: %s", cat.Name)
 			}
 		}
 
-		fmt.Printf("  %s: %d tabs grouped\n", cat.name, len(cat.moves))
+		fmt.Printf("  %s: %d tabs grouped\n", cat.Name, len(cat.Moves))
 	}
 
 	return nil
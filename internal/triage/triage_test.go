@@ -1,6 +1,9 @@
 package triage
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,8 +13,12 @@ import (
 func TestClassify(t *testing.T) {
 	now := time.Now()
 	tabs := []*types.Tab{
-		// Needs attention: review requested
+		// Review requested from me
 		{URL: "https://github.com/org/repo/pull/1", Title: "PR1", GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{ReviewRequested: true}},
+		// My own PR awaiting review, even with new activity
+		{URL: "https://github.com/org/repo/pull/8", Title: "PR8", GitHubStatus: "open", LastAccessed: now.Add(-24 * time.Hour), GitHubTriage: &types.GitHubTriageInfo{Authored: true, UpdatedAt: now.Add(-1 * time.Hour)}},
+		// My own merged PR
+		{URL: "https://github.com/org/repo/pull/9", Title: "PR9", GitHubStatus: "merged", GitHubTriage: &types.GitHubTriageInfo{Authored: true}},
 		// Needs attention: assigned + new activity
 		{URL: "https://github.com/org/repo/issues/2", Title: "Issue2", GitHubStatus: "open", LastAccessed: now.Add(-48 * time.Hour), GitHubTriage: &types.GitHubTriageInfo{Assigned: true, UpdatedAt: now.Add(-1 * time.Hour)}},
 		// Needs attention: new activity since last access
@@ -27,20 +34,135 @@ func TestClassify(t *testing.T) {
 		// Non-GitHub tab
 		{URL: "https://google.com", Title: "Google"},
 	}
-	result := Classify(tabs)
-	if len(result.NeedsAttention) != 3 {
-		t.Errorf("NeedsAttention: got %d, want 3", len(result.NeedsAttention))
+	result := Classify(tabs, nil)
+	if len(result.Moves(CatReviewRequested)) != 1 {
+		t.Errorf("ReviewRequested: got %d, want 1", len(result.Moves(CatReviewRequested)))
 	}
-	if len(result.OpenPRs) != 1 {
-		t.Errorf("OpenPRs: got %d, want 1", len(result.OpenPRs))
+	if len(result.Moves(CatMyPRs)) != 1 {
+		t.Errorf("MyPRs: got %d, want 1", len(result.Moves(CatMyPRs)))
+	} else if result.Moves(CatMyPRs)[0].Reason != "awaiting review, new activity" {
+		t.Errorf("MyPRs reason = %q", result.Moves(CatMyPRs)[0].Reason)
 	}
-	if len(result.OpenIssues) != 1 {
-		t.Errorf("OpenIssues: got %d, want 1", len(result.OpenIssues))
+	if len(result.Moves(CatNeedsAttention)) != 2 {
+		t.Errorf("NeedsAttention: got %d, want 2", len(result.Moves(CatNeedsAttention)))
 	}
-	if len(result.ClosedMerged) != 2 {
-		t.Errorf("ClosedMerged: got %d, want 2", len(result.ClosedMerged))
+	if len(result.Moves(CatOpenPRs)) != 1 {
+		t.Errorf("OpenPRs: got %d, want 1", len(result.Moves(CatOpenPRs)))
+	}
+	if len(result.Moves(CatOpenIssues)) != 1 {
+		t.Errorf("OpenIssues: got %d, want 1", len(result.Moves(CatOpenIssues)))
+	}
+	if len(result.Moves(CatClosedMerged)) != 3 {
+		t.Errorf("ClosedMerged: got %d, want 3", len(result.Moves(CatClosedMerged)))
+	}
+	if result.Total() != 9 {
+		t.Errorf("Total: got %d, want 9", result.Total())
 	}
 	if result.Skipped != 1 {
 		t.Errorf("Skipped: got %d, want 1", result.Skipped)
 	}
 }
+
+func TestDryRunJSON(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "https://github.com/org/repo/pull/1", Title: "PR1", GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{ReviewRequested: true}},
+		{URL: "https://github.com/org/repo/issues/2", Title: "Issue2", GitHubStatus: "closed", GitHubTriage: &types.GitHubTriageInfo{}},
+		{URL: "https://example.com", Title: "Example"},
+	}
+	out, err := DryRunJSON(Classify(tabs, nil))
+	if err != nil {
+		t.Fatalf("DryRunJSON: %v", err)
+	}
+
+	var got struct {
+		Moves []struct {
+			URL    string `json:"url"`
+			Group  string `json:"group"`
+			Reason string `json:"reason"`
+		} `json:"moves"`
+		Skipped int `json:"skipped"`
+	}
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(got.Moves) != 2 {
+		t.Fatalf("got %d moves, want 2", len(got.Moves))
+	}
+	if got.Moves[0].URL != "https://github.com/org/repo/pull/1" || got.Moves[0].Group != string(CatReviewRequested) {
+		t.Errorf("moves[0] = %+v", got.Moves[0])
+	}
+	if got.Moves[1].Group != string(CatClosedMerged) || got.Moves[1].Reason != "closed" {
+		t.Errorf("moves[1] = %+v", got.Moves[1])
+	}
+	if got.Skipped != 1 {
+		t.Errorf("skipped = %d, want 1", got.Skipped)
+	}
+}
+
+func TestClassifyCustomRules(t *testing.T) {
+	tabs := []*types.Tab{
+		{URL: "https://github.com/mozilla/gecko/pull/1", Title: "Gecko PR", GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{}},
+		{URL: "https://github.com/Mozilla/fxa/pull/2", Title: "FxA PR", GitHubStatus: "merged", GitHubTriage: &types.GitHubTriageInfo{}},
+		{URL: "https://github.com/other/repo/pull/3", Title: "Other PR", GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{Assigned: true}},
+		{URL: "https://github.com/other/repo/issues/4", Title: "Other issue", GitHubStatus: "open", GitHubTriage: &types.GitHubTriageInfo{}},
+	}
+	rules := []Rule{
+		{Repo: "mozilla/*", Kind: "pr", Group: "Mozilla PRs", Color: "orange"},
+		{Assigned: yes(), Group: "Mine"},
+		{State: "open", Kind: "pr", Group: "Other PRs"},
+	}
+	result := Classify(tabs, rules)
+
+	if got := result.Moves("Mozilla PRs"); len(got) != 2 {
+		t.Errorf("Mozilla PRs: got %d, want 2", len(got))
+	}
+	mine := result.Moves("Mine")
+	if len(mine) != 1 || mine[0].Reason != "assigned" {
+		t.Errorf("Mine: got %+v", mine)
+	}
+	if got := result.Moves("Other PRs"); len(got) != 0 {
+		t.Errorf("Other PRs: got %d, want 0", len(got))
+	}
+	if result.Unmatched != 1 {
+		t.Errorf("Unmatched: got %d, want 1", result.Unmatched)
+	}
+	if len(result.Buckets) != 2 || result.Buckets[0].Color != "orange" || result.Buckets[1].Color != defaultColor {
+		t.Errorf("unexpected buckets: %+v", result.Buckets)
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+
+	rules, err := LoadRules(filepath.Join(dir, "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadRules(missing): %v", err)
+	}
+	if len(rules) != len(DefaultRules) {
+		t.Errorf("missing file: got %d rules, want defaults", len(rules))
+	}
+
+	p := filepath.Join(dir, "triage.json")
+	os.WriteFile(p, []byte(`[{"repo": "mozilla/*", "state": "open", "reviewRequested": true, "group": "Mozilla reviews", "color": "orange"}]`), 0o644)
+	rules, err = LoadRules(p)
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if len(rules) != 1 || rules[0].ReviewRequested == nil || !*rules[0].ReviewRequested || rules[0].Group != "Mozilla reviews" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	for _, bad := range []string{
+		`[{"state": "open"}]`,
+		`[{"state": "draft", "group": "x"}]`,
+		`[{"kind": "commit", "group": "x"}]`,
+		`[{"repo": "[", "group": "x"}]`,
+		`[{"color": "magenta", "group": "x"}]`,
+		`[]`,
+	} {
+		os.WriteFile(p, []byte(bad), 0o644)
+		if _, err := LoadRules(p); err == nil {
+			t.Errorf("expected error for %s", bad)
+		}
+	}
+}
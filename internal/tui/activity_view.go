@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -296,8 +297,8 @@ func (v ActivityView) ViewList() string {
 	}
 
 	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
-	modeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
-	countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	modeStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	countStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	var b strings.Builder
 	b.WriteString(modeStyle.Render(fmt.Sprintf("Mode: %s", activityKindLabel(v.kind))))
@@ -337,8 +338,8 @@ func (v ActivityView) ViewDetail() string {
 		return "Select a period to inspect activity."
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	var totalMs int64
 	var totalVisits int
@@ -395,7 +396,7 @@ func (v ActivityView) ViewDetail() string {
 	}
 	scrollStyle := dimStyle
 	if v.focusDetail {
-		scrollStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("62"))
+		scrollStyle = lipgloss.NewStyle().Foreground(theme.Accent)
 	}
 	status := "Scroll 0/0"
 	if maxScroll > 0 {
@@ -411,13 +412,13 @@ func (v ActivityView) ViewDetail() string {
 }
 
 func truncateString(s string, maxLen int) string {
-	if maxLen <= 0 || len(s) <= maxLen {
+	if maxLen <= 0 || utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
 	if maxLen == 1 {
 		return "…"
 	}
-	return s[:maxLen-1] + "…"
+	return string([]rune(s)[:maxLen-1]) + "…"
 }
 
 func activityKindLabel(kind storage.ActivityPeriodKind) string {
@@ -6,7 +6,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"sync/atomic"
@@ -18,10 +22,15 @@ import (
 	"github.com/lotas/tabsordnung/internal/applog"
 	"github.com/lotas/tabsordnung/internal/bugzilla"
 	"github.com/lotas/tabsordnung/internal/classify"
+	"github.com/lotas/tabsordnung/internal/clipboard"
+	"github.com/lotas/tabsordnung/internal/export"
 	"github.com/lotas/tabsordnung/internal/firefox"
 	"github.com/lotas/tabsordnung/internal/github"
+	"github.com/lotas/tabsordnung/internal/gitlab"
+	"github.com/lotas/tabsordnung/internal/metrics"
 	"github.com/lotas/tabsordnung/internal/server"
 	"github.com/lotas/tabsordnung/internal/signal"
+	"github.com/lotas/tabsordnung/internal/snapshot"
 	"github.com/lotas/tabsordnung/internal/storage"
 	"github.com/lotas/tabsordnung/internal/summarize"
 	"github.com/lotas/tabsordnung/internal/types"
@@ -29,20 +38,91 @@ import (
 
 // --- Messages ---
 
+// entityCountsMsg carries the navbar counts of the GitHub, Bugzilla and
+// GitLab views, which live in the database.
+type entityCountsMsg struct {
+	github, bugzilla, gitlab int
+}
+
+// tabEntitiesMsg reports the entities found in the open tabs by R.
+type tabEntitiesMsg struct {
+	found *storage.TabEntities
+	err   error
+}
+
+// tabEntitiesRefreshedMsg reports that the entities of one kind found by R
+// were refreshed; status describes the outcome ("GitHub: Refreshed 3 of 4").
+type tabEntitiesRefreshedMsg struct {
+	status string
+	err    error
+}
+
 type sessionLoadedMsg struct {
 	data *types.SessionData
 	err  error
 }
 
-type analysisCompleteMsg struct{}
-type githubAnalysisCompleteMsg struct{}
+// analysisCompleteMsg and githubAnalysisCompleteMsg report that the checks
+// started by startChecks finished; cancelled is set when they were aborted,
+// in which case newer checks may still be running.
+type analysisCompleteMsg struct{ cancelled bool }
+type githubAnalysisCompleteMsg struct {
+	err       error
+	cancelled bool
+}
 
 type summarizeCompleteMsg struct {
 	url     string
 	summary string
+	words   int // word count of the summarized content
+	err     error
+}
+
+type wordCountMsg struct {
+	url   string
+	words int
+	err   error
+}
+
+type previewMsg struct {
+	url     string
+	preview *summarize.OpenGraph
+	err     error
+}
+
+// toastMsg shows a short notice in the tabs view bottom bar.
+type toastMsg struct{ text string }
+
+// toastExpiredMsg clears the toast with the given sequence number, unless
+// a newer one has replaced it.
+type toastExpiredMsg struct{ seq int }
+
+// toastDuration is how long a toast stays in the bottom bar.
+const toastDuration = 2 * time.Second
+
+// snapshotCreatedMsg reports the outcome of taking a snapshot from the
+// Tabs view.
+type snapshotCreatedMsg struct {
+	rev     int
+	created bool
+	diff    *snapshot.DiffResult
 	err     error
 }
 
+// summarizeChunkMsg carries the next piece of a streaming summary.
+type summarizeChunkMsg struct {
+	url    string
+	text   string
+	chunks <-chan string
+}
+
+type groupSummarizeCompleteMsg struct {
+	group  string // group name
+	path   string
+	failed int // tabs that could not be fetched
+	err    error
+}
+
 type signalCompleteMsg struct {
 	source string
 	err    error
@@ -62,6 +142,9 @@ type signalNavigateMsg struct {
 
 type rebuildTickMsg struct{}
 
+// liveTickMsg refreshes the live mode connection status in the navbar.
+type liveTickMsg struct{}
+
 // SourceMode distinguishes live vs offline.
 type SourceMode int
 
@@ -72,6 +155,10 @@ const (
 
 // Messages from the WebSocket server
 type wsDisconnectedMsg struct{}
+
+// wsServerErrorMsg reports that the live mode server could not listen, for
+// example because the port is in use.
+type wsServerErrorMsg struct{ err error }
 type wsSnapshotMsg struct {
 	data *types.SessionData
 }
@@ -84,6 +171,7 @@ type wsCmdResponseMsg struct {
 	error   string
 	content string
 	items   string
+	tabIDs  []int
 }
 type wsVisitsBatchMsg struct {
 	id  string
@@ -160,6 +248,7 @@ type SummarizeJob struct {
 	Tab            *types.Tab
 	ContentID      string // non-empty = waiting for browser content (live mode)
 	PopupRequestID string // non-empty = send summary back to extension popup when done
+	Partial        string // summary text streamed so far
 }
 
 // SignalJob tracks a single in-flight signal capture.
@@ -177,6 +266,18 @@ type Model struct {
 	profile   types.Profile
 	session   *types.SessionData
 	staleDays int
+	githubTTL time.Duration // reuse cached GitHub status younger than this
+	// triageCache keeps the GitHub badge details across reloads for
+	// githubTTL.
+	triageCache *analyzer.TriageCache
+	// checksCancel aborts the running dead-link and GitHub checks.
+	checksCancel context.CancelFunc
+	// exactDuplicates compares raw URLs instead of normalized ones when
+	// looking for duplicate tabs.
+	exactDuplicates bool
+	// sessionFile, when set, is read instead of the profile's own session
+	// files in offline mode.
+	sessionFile string
 
 	// UI state
 	picker     SourcePicker
@@ -191,16 +292,27 @@ type Model struct {
 	server           *server.Server
 	port             int
 	connected        bool
-	cancel           context.CancelFunc
+	serverCtx        context.Context    // scope of the server Init starts
+	cancel           context.CancelFunc // stops the current server run
+	serverErr        error              // why the server could not listen
 	groupPicker      GroupPicker
 	showGroupPicker  bool
 	filterPicker     FilterPicker
 	showFilterPicker bool
+	palette          CommandPalette
+	showPalette      bool
+	// disconnectedAt is when the extension connection dropped; zero while
+	// connected and before the first connection.
+	disconnectedAt time.Time
+	// liveTicking is set while liveTick keeps the navbar status current.
+	liveTicking bool
 
 	// Summarization config (needed for WS-triggered summarize)
 	summaryDir  string
 	ollamaModel string
 	ollamaHost  string
+	// summaryPrompt is the tab summarization prompt; nil uses the default.
+	summaryPrompt *summarize.Prompt
 
 	// Database
 	db *sql.DB
@@ -211,37 +323,95 @@ type Model struct {
 	signalsView   SignalsView
 	githubView    GitHubView
 	bugzillaView  BugzillaView
+	gitlabView    GitLabView
 	activityView  ActivityView
 	snapshotsView SnapshotsView
+	timelineView  TimelineView
 
 	// Thread summarization
 	threadSummarizeJobs map[string]*ThreadSummarizeJob // key: channelID/threadTS
 
-	// Debounced rebuild
-	rebuildDirty     bool
-	rebuildScheduled bool
+	// Snapshot restore: "open" command ID -> group to create for the opened tabs
+	restoreJobs map[string]*storage.SnapshotGroup
+
+	// Background urgency classification in flight
+	classifying bool
+
+	// Debounced rebuild: rebuildDirty is set while a rebuild tick is
+	// pending; rebuildFirst/rebuildLast bracket the pending changes.
+	rebuildDirty   bool
+	rebuildPending int
+	rebuildFirst   time.Time
+	rebuildLast    time.Time
+
+	// Incremental stats: changedTabs are tabs added or updated since the
+	// last rebuild, recounted by statsTracker instead of a full recompute.
+	statsTracker *analyzer.StatsTracker
+	changedTabs  []*types.Tab
+	// metrics, if set, receives the stats and signal counts as they change.
+	metrics *metrics.Exporter
+
+	// rememberUI saves the tree layout and view on quit and restores it on
+	// the next launch; restoredUIKey is the profile last restored.
+	rememberUI    bool
+	restoredUIKey string
+
+	// entityCounts are the navbar counts of the entity views. They are
+	// reloaded by loadEntityCounts whenever entities may have changed
+	// rather than queried on every render.
+	entityCounts entityCountsMsg
+}
+
+// Options holds the configuration of the TUI.
+type Options struct {
+	Profiles        []types.Profile
+	StaleDays       int
+	Live            bool // start in live mode instead of reading a profile
+	Server          *server.Server
+	SummaryDir      string
+	OllamaModel     string
+	OllamaHost      string
+	SummaryPrompt   *summarize.Prompt
+	DB              *sql.DB
+	GitHubTTL       time.Duration // reuse cached GitHub status younger than this
+	ExactDuplicates bool          // compare raw URLs when finding duplicates
+	SessionFile     string        // read this session file instead of the profile's
+	RememberUI      bool          // restore and save the view, filter and cursor
 }
 
-func NewModel(profiles []types.Profile, staleDays int, liveMode bool, srv *server.Server, summaryDir, ollamaModel, ollamaHost string, db *sql.DB) Model {
+func NewModel(opts Options) Model {
+	profiles, db, srv := opts.Profiles, opts.DB, opts.Server
 	m := Model{
-		profiles:    profiles,
-		staleDays:   staleDays,
-		server:      srv,
-		port:        srv.Port(),
-		summaryDir:  summaryDir,
-		ollamaModel: ollamaModel,
-		ollamaHost:  ollamaHost,
-		db:          db,
+		profiles:        profiles,
+		staleDays:       opts.StaleDays,
+		githubTTL:       opts.GitHubTTL,
+		triageCache:     analyzer.NewTriageCache(),
+		exactDuplicates: opts.ExactDuplicates,
+		sessionFile:     opts.SessionFile,
+		server:          srv,
+		port:            srv.Port(),
+		summaryDir:      opts.SummaryDir,
+		ollamaModel:     opts.OllamaModel,
+		ollamaHost:      opts.OllamaHost,
+		summaryPrompt:   opts.SummaryPrompt,
+		db:              db,
+		rememberUI:      opts.RememberUI,
 	}
 	m.threadSummarizeJobs = make(map[string]*ThreadSummarizeJob)
-	m.tabsView = NewTabsView(srv, db, summaryDir, ollamaModel, ollamaHost)
-	m.tabsView.staleDays = staleDays
+	m.restoreJobs = make(map[string]*storage.SnapshotGroup)
+	m.tabsView = NewTabsView(srv, db, opts.SummaryDir, opts.OllamaModel, opts.OllamaHost)
+	m.tabsView.staleDays = opts.StaleDays
+	m.tabsView.exactDuplicates = opts.ExactDuplicates
+	m.tabsView.summaryPrompt = opts.SummaryPrompt
 	m.signalsView = NewSignalsView(db)
 	m.githubView = NewGitHubView(db)
 	m.bugzillaView = NewBugzillaView(db)
+	m.gitlabView = NewGitLabView(db)
 	m.activityView = NewActivityView(db)
 	m.snapshotsView = NewSnapshotsView(db)
-	if liveMode {
+	m.timelineView = NewTimelineView(db)
+	m.serverCtx, m.cancel = context.WithCancel(context.Background())
+	if opts.Live {
 		m.mode = ModeLive
 		m.loading = true
 	} else if len(profiles) == 1 {
@@ -250,24 +420,65 @@ func NewModel(profiles []types.Profile, staleDays int, liveMode bool, srv *serve
 	} else {
 		m.showPicker = true
 		m.picker = NewSourcePicker(profiles)
+		if db != nil {
+			if last, err := storage.LastProfile(db); err == nil {
+				m.picker.SelectName(last)
+			}
+		}
 	}
 	return m
 }
 
+// OpenLastSource skips the source picker and opens the profile (or live
+// mode) selected last time. It does nothing if the picker is not shown or
+// the saved source no longer exists; the picker stays available via p.
+func (m *Model) OpenLastSource() {
+	if !m.showPicker || m.db == nil {
+		return
+	}
+	last, err := storage.LastProfile(m.db)
+	if err != nil || last == "" {
+		return
+	}
+	for _, src := range m.picker.Sources {
+		switch {
+		case src.IsLive && last == "live":
+			m.showPicker = false
+			m.mode = ModeLive
+			m.loading = true
+			return
+		case src.Profile != nil && src.Profile.Name == last:
+			m.showPicker = false
+			m.mode = ModeOffline
+			m.profile = *src.Profile
+			m.loading = true
+			return
+		}
+	}
+}
+
 func (m Model) Init() tea.Cmd {
 	if m.mode == ModeLive {
 		return tea.Batch(
 			listenWebSocket(m.server),
-			startWSServerCtx(context.Background(), m.server),
+			startWSServerCtx(m.serverCtx, m.server),
 		)
 	}
 	if len(m.profiles) == 1 {
-		return loadSession(m.profiles[0])
+		return loadSession(m.profiles[0], m.sessionFile)
+	}
+	if m.mode == ModeOffline && m.profile.Name != "" {
+		return loadSession(m.profile, m.sessionFile)
+	}
+	if m.showPicker {
+		return loadSourceCounts(m.db, m.profiles)
 	}
 	return nil
 }
 
 func (m *Model) startLiveMode() tea.Cmd {
+	m.stopServer()
+	m.serverErr = nil
 	ctx, cancel := context.WithCancel(context.Background())
 	m.cancel = cancel
 	return tea.Batch(
@@ -276,16 +487,35 @@ func (m *Model) startLiveMode() tea.Cmd {
 	)
 }
 
+// stopServer shuts down the live mode server, if one is running, releasing
+// its port and closing the extension's connection.
+func (m *Model) stopServer() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
 func startWSServerCtx(ctx context.Context, srv *server.Server) tea.Cmd {
 	return func() tea.Msg {
-		srv.ListenAndServe(ctx)
+		if err := srv.ListenAndServe(ctx); err != nil {
+			return wsServerErrorMsg{err: err}
+		}
 		return wsDisconnectedMsg{}
 	}
 }
 
-func loadSession(profile types.Profile) tea.Cmd {
+func loadSession(profile types.Profile, sessionFile string) tea.Cmd {
 	return func() tea.Msg {
-		data, err := firefox.ReadSessionFile(profile.Path)
+		var data *types.SessionData
+		var err error
+		if len(profile.Members) > 0 {
+			data, err = firefox.ReadProfiles(profile.Members)
+		} else if sessionFile != "" {
+			data, err = firefox.ReadSession(sessionFile, profile.Path)
+		} else {
+			data, err = firefox.ReadSessionFile(profile.Path)
+		}
 		if err != nil {
 			return sessionLoadedMsg{err: err}
 		}
@@ -294,69 +524,265 @@ func loadSession(profile types.Profile) tea.Cmd {
 	}
 }
 
-func runDeadLinkChecks(tabs []*types.Tab) tea.Cmd {
+// startChecks cancels the dead-link and GitHub checks of the previous
+// session, if still running, and starts them for the current one.
+func (m *Model) startChecks() tea.Cmd {
+	m.cancelChecks()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.checksCancel = cancel
+	m.tabsView.deadChecking = true
+	m.tabsView.githubChecking = true
+	return tea.Batch(
+		runDeadLinkChecks(ctx, m.session.AllTabs),
+		runGitHubChecks(ctx, m.db, m.session.AllTabs, m.githubTTL, m.triageCache),
+	)
+}
+
+// cancelChecks aborts in-flight dead-link and GitHub requests.
+func (m *Model) cancelChecks() {
+	if m.checksCancel != nil {
+		m.checksCancel()
+		m.checksCancel = nil
+	}
+}
+
+func runDeadLinkChecks(ctx context.Context, tabs []*types.Tab) tea.Cmd {
 	return func() tea.Msg {
 		results := make(chan analyzer.DeadLinkResult, len(tabs))
 		go func() {
-			analyzer.AnalyzeDeadLinks(tabs, results)
+			analyzer.AnalyzeDeadLinks(ctx, tabs, results)
 			close(results)
 		}()
 		for range results {
 		}
-		return analysisCompleteMsg{}
+		return analysisCompleteMsg{cancelled: ctx.Err() != nil}
 	}
 }
 
-func runGitHubChecks(tabs []*types.Tab) tea.Cmd {
+func runGitHubChecks(ctx context.Context, db *sql.DB, tabs []*types.Tab, ttl time.Duration, cache *analyzer.TriageCache) tea.Cmd {
 	return func() tea.Msg {
-		analyzer.AnalyzeGitHub(tabs)
-		return githubAnalysisCompleteMsg{}
+		err := analyzer.AnalyzeGitHubCached(ctx, db, tabs, ttl)
+		if err == nil {
+			err = runGitHubTriage(ctx, tabs, cache, ttl)
+		}
+		if ctx.Err() != nil {
+			return githubAnalysisCompleteMsg{cancelled: true}
+		}
+		if err != nil {
+			applog.Error("github.analyze", err)
+		}
+		return githubAnalysisCompleteMsg{err: err}
 	}
 }
 
-func runSummarizeTab(tab *types.Tab, outDir, model, host string) tea.Cmd {
-	return func() tea.Msg {
+// runGitHubTriage fetches review-request, assignee and CI check details for
+// open GitHub tabs, which the tree shows as badges. Details fetched within
+// ttl are taken from cache instead.
+func runGitHubTriage(ctx context.Context, tabs []*types.Tab, cache *analyzer.TriageCache, ttl time.Duration) error {
+	var open []*types.Tab
+	for _, tab := range tabs {
+		if tab.GitHubStatus == "open" {
+			open = append(open, tab)
+		}
+	}
+	open = cache.Apply(open, ttl)
+	if len(open) == 0 {
+		return nil
+	}
+	token := analyzer.ResolveGitHubToken()
+	if token == "" {
+		return nil
+	}
+	username, err := analyzer.ResolveGitHubUser(token)
+	if err != nil {
+		return err
+	}
+	err = analyzer.AnalyzeGitHubTriage(ctx, open, username)
+	cache.Store(open)
+	return err
+}
+
+// runSummarizeTab fetches a tab's readable content and summarizes it,
+// streaming the summary into the detail pane as it is generated.
+func runSummarizeTab(tab *types.Tab, outDir, model, host string, prompt *summarize.Prompt) tea.Cmd {
+	chunks := make(chan string, 64)
+	work := func() tea.Msg {
+		defer close(chunks)
 		title, text, err := summarize.FetchReadable(tab.URL)
 		if err != nil {
 			return summarizeCompleteMsg{url: tab.URL, err: err}
 		}
-		if len(strings.TrimSpace(text)) < 50 {
-			return summarizeCompleteMsg{url: tab.URL, err: fmt.Errorf("not enough readable content")}
-		}
 		if title == "" {
 			title = tab.Title
 		}
-		ctx := context.Background()
-		sum, err := summarize.OllamaSummarize(ctx, model, host, text)
-		if err != nil {
-			return summarizeCompleteMsg{url: tab.URL, err: err}
+		return summarizeAndSave(tab, title, text, outDir, model, host, prompt, chunks)
+	}
+	return tea.Batch(work, listenSummaryChunks(tab.URL, chunks))
+}
+
+// runSummarizeWithContent summarizes content the extension read from the
+// tab (live mode).
+func runSummarizeWithContent(tab *types.Tab, content, outDir, model, host string, prompt *summarize.Prompt) tea.Cmd {
+	chunks := make(chan string, 64)
+	work := func() tea.Msg {
+		defer close(chunks)
+		return summarizeAndSave(tab, tab.Title, content, outDir, model, host, prompt, chunks)
+	}
+	return tea.Batch(work, listenSummaryChunks(tab.URL, chunks))
+}
+
+func summarizeAndSave(tab *types.Tab, title, text, outDir, model, host string, prompt *summarize.Prompt, chunks chan<- string) summarizeCompleteMsg {
+	sum, err := summarize.OllamaSummarizeStream(context.Background(), model, host, prompt, title, tab.URL, text, func(tok string) {
+		chunks <- tok
+	})
+	if err != nil {
+		return summarizeCompleteMsg{url: tab.URL, err: err}
+	}
+	outPath := summarize.SummaryPath(outDir, tab.URL, tab.Title)
+	os.MkdirAll(filepath.Dir(outPath), 0o755)
+	md := summarize.FormatSummary(title, tab.URL, prompt, sum)
+	if err := os.WriteFile(outPath, []byte(md), 0o644); err != nil {
+		return summarizeCompleteMsg{url: tab.URL, err: err}
+	}
+	return summarizeCompleteMsg{url: tab.URL, summary: sum, words: summarize.WordCount(text)}
+}
+
+// runOpenGraph fetches a page's Open Graph preview.
+func runOpenGraph(url string) tea.Cmd {
+	return func() tea.Msg {
+		og, err := summarize.FetchOpenGraph(url)
+		return previewMsg{url: url, preview: og, err: err}
+	}
+}
+
+// runWordCount fetches a tab's readable content to estimate its reading
+// time, without summarizing it.
+func runWordCount(url string) tea.Cmd {
+	return func() tea.Msg {
+		words, err := summarize.FetchWordCount(url)
+		return wordCountMsg{url: url, words: words, err: err}
+	}
+}
+
+// copyToClipboard copies text to the system clipboard and reports the
+// outcome as a toast; what names the copied thing in the toast.
+func copyToClipboard(text, what string) tea.Cmd {
+	return func() tea.Msg {
+		if err := clipboard.Copy(text); err != nil {
+			applog.Error("tui.clipboard", err)
+			return toastMsg{text: "Copy failed: " + err.Error()}
 		}
-		outPath := summarize.SummaryPath(outDir, tab.URL, tab.Title)
-		os.MkdirAll(filepath.Dir(outPath), 0o755)
-		content := fmt.Sprintf("# %s\n\n**Source:** %s\n**Summarized:** %s\n\n## Summary\n\n%s\n",
-			title, tab.URL, time.Now().Format("2006-01-02"), sum)
-		if err := os.WriteFile(outPath, []byte(content), 0o644); err != nil {
-			return summarizeCompleteMsg{url: tab.URL, err: err}
+		return toastMsg{text: "Copied " + what}
+	}
+}
+
+// exportView writes the filtered view to path, as JSON if it ends in
+// .json and markdown otherwise. An empty path copies the markdown to the
+// clipboard.
+func exportView(data *types.SessionData, path string) tea.Cmd {
+	return func() tea.Msg {
+		n := len(data.AllTabs)
+		if path == "" {
+			if err := clipboard.Copy(export.Markdown(data)); err != nil {
+				applog.Error("tui.export", err)
+				return toastMsg{text: "Copy failed: " + err.Error()}
+			}
+			return toastMsg{text: fmt.Sprintf("Copied %d tab(s) as markdown", n)}
 		}
-		return summarizeCompleteMsg{url: tab.URL, summary: sum}
+		if rest, ok := strings.CutPrefix(path, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, rest)
+			}
+		}
+		out := export.Markdown(data)
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			var err error
+			if out, err = export.JSON(data); err != nil {
+				applog.Error("tui.export", err, "path", path)
+				return toastMsg{text: "Export failed: " + err.Error()}
+			}
+		}
+		if err := os.WriteFile(path, []byte(out), 0o644); err != nil {
+			applog.Error("tui.export", err, "path", path)
+			return toastMsg{text: "Export failed: " + err.Error()}
+		}
+		applog.Info("tui.export", "path", path, "tabs", n)
+		return toastMsg{text: fmt.Sprintf("Exported %d tab(s) to %s", n, path)}
 	}
 }
 
-func runSummarizeWithContent(tab *types.Tab, content, outDir, model, host string) tea.Cmd {
+// createSnapshot saves data as a new snapshot, unless its tabs are the same
+// as the latest one's. Live sessions have no profile and are saved as
+// "live". The tabs are copied first so live updates can't race the write.
+func createSnapshot(db *sql.DB, data *types.SessionData) tea.Cmd {
+	sd := &types.SessionData{Profile: data.Profile, Groups: data.Groups}
+	if sd.Profile.Name == "" {
+		sd.Profile.Name = "live"
+	}
+	sd.AllTabs = make([]*types.Tab, len(data.AllTabs))
+	for i, t := range data.AllTabs {
+		tab := *t
+		sd.AllTabs[i] = &tab
+	}
 	return func() tea.Msg {
-		ctx := context.Background()
-		sum, err := summarize.OllamaSummarize(ctx, model, host, content)
+		rev, created, diff, err := snapshot.Create(db, sd, "")
 		if err != nil {
-			return summarizeCompleteMsg{url: tab.URL, err: err}
+			applog.Error("tui.snapshot.create", err)
 		}
-		outPath := summarize.SummaryPath(outDir, tab.URL, tab.Title)
-		os.MkdirAll(filepath.Dir(outPath), 0o755)
-		md := fmt.Sprintf("# %s\n\n**Source:** %s\n**Summarized:** %s\n\n## Summary\n\n%s\n",
-			tab.Title, tab.URL, time.Now().Format("2006-01-02"), sum)
-		if err := os.WriteFile(outPath, []byte(md), 0o644); err != nil {
-			return summarizeCompleteMsg{url: tab.URL, err: err}
+		return snapshotCreatedMsg{rev: rev, created: created, diff: diff, err: err}
+	}
+}
+
+// openTabInBrowser opens url in the system browser. Browser-internal
+// pages can't be opened from outside Firefox and are reported as a toast.
+func openTabInBrowser(url string) tea.Cmd {
+	return func() tea.Msg {
+		if url == "" || strings.HasPrefix(url, "about:") {
+			return toastMsg{text: "Can't open this page outside Firefox"}
 		}
-		return summarizeCompleteMsg{url: tab.URL, summary: sum}
+		if err := openURL(url); err != nil {
+			applog.Error("tui.open", err, "url", url)
+			return toastMsg{text: "Open failed: " + err.Error()}
+		}
+		return nil
+	}
+}
+
+// openURL opens url in the system browser without waiting for it; the
+// opener is reaped in the background.
+func openURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go cmd.Wait()
+	return nil
+}
+
+// listenSummaryChunks delivers streamed summary text one chunk at a time.
+// It is re-issued after every chunk until the channel is closed.
+func listenSummaryChunks(url string, chunks <-chan string) tea.Cmd {
+	return func() tea.Msg {
+		text, ok := <-chunks
+		if !ok {
+			return nil
+		}
+		return summarizeChunkMsg{url: url, text: text, chunks: chunks}
+	}
+}
+
+func runSummarizeGroup(group *types.TabGroup, outDir, model, host string) tea.Cmd {
+	return func() tea.Msg {
+		path, failed, err := summarize.SummarizeGroup(context.Background(), model, host, outDir, group)
+		return groupSummarizeCompleteMsg{group: group.Name, path: path, failed: len(failed), err: err}
 	}
 }
 
@@ -396,55 +822,76 @@ func signalPollTick() tea.Cmd {
 
 type classifyTickMsg struct{}
 type classifyDoneMsg struct {
-	id      int64
-	urgency string
-	err     error
+	classified int
+	err        error
 }
 
 const classifyInterval = 30 * time.Second
 
+// classifyBatchSize caps the number of Ollama calls per classify tick so a
+// backlog drains steadily without keeping the model busy for minutes.
+const classifyBatchSize = 5
+
 func classifyTick() tea.Cmd {
 	return tea.Tick(classifyInterval, func(time.Time) tea.Msg {
 		return classifyTickMsg{}
 	})
 }
 
-func runClassifyOne(db *sql.DB, model, host string) tea.Cmd {
+// runClassifyBatch assigns urgency to unclassified signals. Heuristics are
+// tried first; the rest go to Ollama, up to classifyBatchSize per run. The
+// run stops at the first Ollama error, since the next one would most likely
+// fail the same way.
+func runClassifyBatch(db *sql.DB, model, host string) tea.Cmd {
 	return func() tea.Msg {
 		sigs, err := storage.ListUnclassifiedSignals(db)
 		if err != nil || len(sigs) == 0 {
 			return classifyDoneMsg{err: err}
 		}
-		sig := sigs[0]
 
-		// Heuristic classification for signals with kind
-		if urgency, ok := storage.ClassifyByKind(sig.Kind); ok {
-			err := storage.UpdateUrgency(db, sig.ID, urgency, "heuristic")
-			return classifyDoneMsg{id: sig.ID, urgency: urgency, err: err}
+		classified, llmCalls := 0, 0
+		for _, sig := range sigs {
+			urgency, source, ok := classifyHeuristic(sig)
+			if !ok {
+				if llmCalls >= classifyBatchSize {
+					continue
+				}
+				llmCalls++
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				urgency, err = classify.ClassifySignal(ctx, model, host, sig.Title, sig.Preview, sig.Snippet)
+				cancel()
+				if err != nil {
+					return classifyDoneMsg{classified: classified, err: fmt.Errorf("signal %d: %w", sig.ID, err)}
+				}
+				source = "llm"
+			}
+			if err := storage.UpdateUrgency(db, sig.ID, urgency, source); err != nil {
+				return classifyDoneMsg{classified: classified, err: err}
+			}
+			applog.Info("classify.signal", "id", sig.ID, "urgency", urgency, "by", source)
+			classified++
 		}
+		return classifyDoneMsg{classified: classified}
+	}
+}
 
-		// Slack/Matrix without kind: default to fyi (heuristic only, no LLM)
-		if sig.Source == "slack" || sig.Source == "matrix" {
-			err := storage.UpdateUrgency(db, sig.ID, "fyi", "heuristic")
-			return classifyDoneMsg{id: sig.ID, urgency: "fyi", err: err}
-		}
+// classifyHeuristic returns an urgency for signals that don't need the LLM.
+func classifyHeuristic(sig storage.SignalRecord) (urgency, source string, ok bool) {
+	// Heuristic classification for signals with kind
+	if urgency, ok := storage.ClassifyByKind(sig.Source, sig.Kind); ok {
+		return urgency, "heuristic", true
+	}
 
-		// Gmail sender/content heuristics (skip LLM for bots, digests, resolved bugs)
-		if urgency, ok := classify.ClassifyGmailHeuristic(sig.Title, sig.Preview, sig.Snippet); ok {
-			err := storage.UpdateUrgency(db, sig.ID, urgency, "heuristic")
-			return classifyDoneMsg{id: sig.ID, urgency: urgency, err: err}
-		}
+	// Slack/Matrix without kind: default to fyi (heuristic only, no LLM)
+	if sig.Source == "slack" || sig.Source == "matrix" {
+		return "fyi", "heuristic", true
+	}
 
-		// LLM classification for Gmail
-		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-		defer cancel()
-		urgency, err := classify.ClassifySignal(ctx, model, host, sig.Title, sig.Preview, sig.Snippet)
-		if err != nil {
-			return classifyDoneMsg{id: sig.ID, err: err}
-		}
-		err = storage.UpdateUrgency(db, sig.ID, urgency, "llm")
-		return classifyDoneMsg{id: sig.ID, urgency: urgency, err: err}
+	// Gmail sender/content heuristics (skip LLM for bots, digests, resolved bugs)
+	if urgency, ok := classify.ClassifyGmailHeuristic(sig.Title, sig.Preview, sig.Snippet); ok {
+		return urgency, "heuristic", true
 	}
+	return "", "", false
 }
 
 func runReconcileSignals(db *sql.DB, source string, items []signal.SignalItem, capturedAt time.Time) tea.Cmd {
@@ -476,6 +923,27 @@ func completeSignalCmd(db *sql.DB, id int64, source string) tea.Cmd {
 	}
 }
 
+// completeSignalsCmd completes several signals, e.g. every signal about
+// one entity, stopping at the first error.
+func completeSignalsCmd(db *sql.DB, ids []int64, source string) tea.Cmd {
+	return func() tea.Msg {
+		for _, id := range ids {
+			if err := storage.CompleteSignal(db, id); err != nil {
+				return signalActionMsg{source: source, err: err}
+			}
+		}
+		return signalActionMsg{source: source}
+	}
+}
+
+// completeAllSignalsCmd completes every active signal of a source.
+func completeAllSignalsCmd(db *sql.DB, source string) tea.Cmd {
+	return func() tea.Msg {
+		_, err := storage.CompleteAllSignals(db, source)
+		return signalActionMsg{source: source, err: err}
+	}
+}
+
 func reopenSignalCmd(db *sql.DB, id int64, source string) tea.Cmd {
 	return func() tea.Msg {
 		err := storage.ReopenSignal(db, id)
@@ -483,6 +951,30 @@ func reopenSignalCmd(db *sql.DB, id int64, source string) tea.Cmd {
 	}
 }
 
+func snoozeSignalCmd(db *sql.DB, id int64, until time.Time, source string) tea.Cmd {
+	return func() tea.Msg {
+		err := storage.SnoozeSignal(db, id, until)
+		return signalActionMsg{source: source, err: err}
+	}
+}
+
+func resurfaceSnoozedCmd(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		n, err := storage.ResurfaceSnoozedSignals(db, time.Now())
+		if err != nil {
+			applog.Error("signal.resurface", err)
+			return nil
+		}
+		if n == 0 {
+			return nil
+		}
+		applog.Info("signal.resurface", "count", n)
+		// Handled like any other signal change: the counts, the tree and
+		// the Signals view are reloaded.
+		return signalActionMsg{}
+	}
+}
+
 func setUrgencyCmd(db *sql.DB, id int64, urgency string, source string) tea.Cmd {
 	return func() tea.Msg {
 		err := storage.UpdateUrgency(db, id, urgency, "manual")
@@ -490,6 +982,22 @@ func setUrgencyCmd(db *sql.DB, id int64, urgency string, source string) tea.Cmd
 	}
 }
 
+// loadEntityCounts reloads the navbar counts of the entity views.
+func loadEntityCounts(db *sql.DB) tea.Cmd {
+	return func() tea.Msg { return countEntities(db) }
+}
+
+func countEntities(db *sql.DB) entityCountsMsg {
+	var msg entityCountsMsg
+	if db == nil {
+		return msg
+	}
+	msg.github, _ = storage.OpenGitHubEntityCount(db)
+	msg.bugzilla, _ = storage.BugzillaEntityCount(db)
+	msg.gitlab, _ = storage.GitLabEntityCount(db)
+	return msg
+}
+
 func extractGitHubFromRecentSignals(db *sql.DB, source string) tea.Cmd {
 	return func() tea.Msg {
 		signals, err := storage.ListSignals(db, source, false)
@@ -497,7 +1005,7 @@ func extractGitHubFromRecentSignals(db *sql.DB, source string) tea.Cmd {
 			return nil
 		}
 		storage.ExtractGitHubFromSignals(db, signals)
-		return nil
+		return countEntities(db)
 	}
 }
 
@@ -508,14 +1016,25 @@ func extractBugzillaFromRecentSignals(db *sql.DB, source string) tea.Cmd {
 			return nil
 		}
 		storage.ExtractBugzillaFromSignals(db, signals)
-		return nil
+		return countEntities(db)
+	}
+}
+
+func extractGitLabFromRecentSignals(db *sql.DB, source string) tea.Cmd {
+	return func() tea.Msg {
+		signals, err := storage.ListSignals(db, source, false)
+		if err != nil {
+			return nil
+		}
+		storage.ExtractGitLabFromSignals(db, signals)
+		return countEntities(db)
 	}
 }
 
 // refreshGitHubEntitiesCmd triggers a background gh refresh (respects cooldown).
 func refreshGitHubEntitiesCmd(db *sql.DB) tea.Cmd {
 	return func() tea.Msg {
-		token := resolveGHToken()
+		token := analyzer.ResolveGitHubToken()
 		if token == "" {
 			return nil
 		}
@@ -523,8 +1042,8 @@ func refreshGitHubEntitiesCmd(db *sql.DB) tea.Cmd {
 		if err != nil || len(entities) == 0 {
 			return nil
 		}
-		github.RefreshEntities(db, entities, token, false)
-		return githubRefreshDoneMsg{}
+		n, _ := github.RefreshEntities(context.Background(), db, entities, token, false)
+		return githubRefreshDoneMsg{status: github.RefreshStatus(n, len(entities))}
 	}
 }
 
@@ -540,6 +1059,98 @@ func refreshBugzillaEntitiesCmd(db *sql.DB) tea.Cmd {
 	}
 }
 
+// refreshGitLabEntitiesCmd triggers a background GitLab REST refresh (respects cooldown).
+func refreshGitLabEntitiesCmd(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		entities, err := storage.ListGitLabEntities(db)
+		if err != nil || len(entities) == 0 {
+			return nil
+		}
+		gitlab.RefreshEntities(db, entities, false)
+		return gitlabRefreshDoneMsg{}
+	}
+}
+
+// extractTabEntitiesCmd tracks the GitHub, Bugzilla and GitLab entities the
+// open tabs link to, without waiting for the next snapshot.
+func extractTabEntitiesCmd(db *sql.DB, tabs []*types.Tab) tea.Cmd {
+	open := make([]storage.OpenTab, len(tabs))
+	for i, t := range tabs {
+		open[i] = storage.OpenTab{URL: t.URL, Title: t.Title}
+	}
+	return func() tea.Msg {
+		found, err := storage.ExtractEntitiesFromTabs(db, open)
+		return tabEntitiesMsg{found: found, err: err}
+	}
+}
+
+// refreshTabEntitiesCmds refreshes the entities in found, one command per
+// kind. Like r in the entity views, recently refreshed entities are skipped.
+func refreshTabEntitiesCmds(db *sql.DB, found *storage.TabEntities) []tea.Cmd {
+	var cmds []tea.Cmd
+	if ids := idSet(found.GitHub); len(ids) > 0 {
+		cmds = append(cmds, func() tea.Msg {
+			token := analyzer.ResolveGitHubToken()
+			if token == "" {
+				return tabEntitiesRefreshedMsg{status: "GitHub: no token (run gh auth login)"}
+			}
+			all, err := storage.ListGitHubEntities(db, storage.GitHubFilter{})
+			if err != nil {
+				return tabEntitiesRefreshedMsg{status: "GitHub", err: err}
+			}
+			var entities []storage.GitHubEntity
+			for _, e := range all {
+				if ids[e.ID] {
+					entities = append(entities, e)
+				}
+			}
+			n, err := github.RefreshEntities(context.Background(), db, entities, token, false)
+			return tabEntitiesRefreshedMsg{status: "GitHub: " + github.RefreshStatus(n, len(entities)), err: err}
+		})
+	}
+	if ids := idSet(found.Bugzilla); len(ids) > 0 {
+		cmds = append(cmds, func() tea.Msg {
+			all, err := storage.ListBugzillaEntities(db)
+			if err != nil {
+				return tabEntitiesRefreshedMsg{status: "Bugzilla", err: err}
+			}
+			var entities []storage.BugzillaEntity
+			for _, e := range all {
+				if ids[e.ID] {
+					entities = append(entities, e)
+				}
+			}
+			err = bugzilla.RefreshEntities(db, entities, false)
+			return tabEntitiesRefreshedMsg{status: fmt.Sprintf("Bugzilla: %d bug(s)", len(entities)), err: err}
+		})
+	}
+	if ids := idSet(found.GitLab); len(ids) > 0 {
+		cmds = append(cmds, func() tea.Msg {
+			all, err := storage.ListGitLabEntities(db)
+			if err != nil {
+				return tabEntitiesRefreshedMsg{status: "GitLab", err: err}
+			}
+			var entities []storage.GitLabEntity
+			for _, e := range all {
+				if ids[e.ID] {
+					entities = append(entities, e)
+				}
+			}
+			err = gitlab.RefreshEntities(db, entities, false)
+			return tabEntitiesRefreshedMsg{status: fmt.Sprintf("GitLab: %d item(s)", len(entities)), err: err}
+		})
+	}
+	return cmds
+}
+
+func idSet(ids []int64) map[int64]bool {
+	set := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
 func listenWebSocket(srv *server.Server) tea.Cmd {
 	return func() tea.Msg {
 		for {
@@ -547,7 +1158,10 @@ func listenWebSocket(srv *server.Server) tea.Cmd {
 			if !ok {
 				return wsDisconnectedMsg{}
 			}
+			applog.Debug("tui.ws.msg", "type", msg.Type, "id", msg.ID, "tabId", msg.TabID)
 			switch msg.Type {
+			case server.TypeDisconnected:
+				return wsDisconnectedMsg{}
 			case "snapshot":
 				data, err := server.ParseSnapshot(msg)
 				if err != nil {
@@ -557,6 +1171,7 @@ func listenWebSocket(srv *server.Server) tea.Cmd {
 			case "tab.created":
 				tab, err := server.ParseTab(msg.Tab)
 				if err != nil {
+					applog.Error("tui.ws.parse", err, "type", msg.Type, "tab", string(msg.Tab))
 					continue
 				}
 				return wsTabCreatedMsg{tab: tab}
@@ -565,6 +1180,7 @@ func listenWebSocket(srv *server.Server) tea.Cmd {
 			case "tab.updated", "tab.moved":
 				tab, err := server.ParseTab(msg.Tab)
 				if err != nil {
+					applog.Error("tui.ws.parse", err, "type", msg.Type, "tab", string(msg.Tab))
 					continue
 				}
 				return wsTabUpdatedMsg{tab: tab}
@@ -584,8 +1200,9 @@ func listenWebSocket(srv *server.Server) tea.Cmd {
 				return wsSummarizeThreadMsg{id: msg.ID, tabID: msg.TabID, channelID: msg.ChannelID, threadTS: msg.ThreadTS}
 			default:
 				if msg.ID != "" && msg.OK != nil {
-					return wsCmdResponseMsg{id: msg.ID, ok: *msg.OK, error: msg.Error, content: msg.Content, items: msg.Items}
+					return wsCmdResponseMsg{id: msg.ID, ok: *msg.OK, error: msg.Error, content: msg.Content, items: msg.Items, tabIDs: msg.TabIDs}
 				}
+				applog.Debug("tui.ws.ignored", "type", msg.Type, "id", msg.ID)
 			}
 		}
 	}
@@ -600,33 +1217,161 @@ func navigateSignalCmd(srv *server.Server, tabID int, source, title string) tea.
 	})
 }
 
-// --- Debounce helpers ---
-
-func rebuildTick() tea.Cmd {
-	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
-		return rebuildTickMsg{}
-	})
+// --- Reconnect helpers ---
+
+// Extension reconnect backoff (see RECONNECT_BASE_MS/RECONNECT_MAX_MS in
+// extension/background.js).
+const (
+	reconnectBase = time.Second
+	reconnectMax  = 30 * time.Second
+)
+
+func liveTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return liveTickMsg{}
+	})
+}
+
+// nextReconnect estimates how long until the extension's next reconnect
+// attempt, given how long the connection has been down. The extension
+// retries after 1s, then doubles the delay up to 30s.
+func nextReconnect(down time.Duration) time.Duration {
+	delay, at := reconnectBase, time.Duration(0)
+	for {
+		at += delay
+		if at > down {
+			return at - down
+		}
+		delay = min(delay*2, reconnectMax)
+	}
+}
+
+// --- Debounce helpers ---
+
+// Tab changes from the extension are coalesced: the tree is rebuilt once
+// changes have been quiet for rebuildQuiet, so a single update shows up
+// almost immediately and a burst (closing 50 tabs) costs one rebuild.
+// rebuildMaxWait bounds the delay while changes keep streaming in.
+const (
+	rebuildQuiet   = 30 * time.Millisecond
+	rebuildMaxWait = 250 * time.Millisecond
+)
+
+func rebuildTick(d time.Duration) tea.Cmd {
+	return tea.Tick(d, func(time.Time) tea.Msg {
+		return rebuildTickMsg{}
+	})
+}
+
+func (m *Model) scheduleRebuild() tea.Cmd {
+	now := time.Now()
+	m.rebuildLast = now
+	m.rebuildPending++
+	if m.rebuildDirty {
+		return nil // a tick is already pending
+	}
+	m.rebuildDirty = true
+	m.rebuildFirst = now
+	return rebuildTick(rebuildQuiet)
+}
+
+// rebuildWait returns how much longer a pending rebuild should wait for
+// more changes; zero or less means rebuild now.
+func (m *Model) rebuildWait(now time.Time) time.Duration {
+	quiet := m.rebuildLast.Add(rebuildQuiet).Sub(now)
+	deadline := m.rebuildFirst.Add(rebuildMaxWait).Sub(now)
+	return min(quiet, deadline)
+}
+
+func (m *Model) doRebuild() {
+	if !m.rebuildDirty {
+		return
+	}
+	pending := m.rebuildPending
+	m.rebuildDirty = false
+	m.rebuildPending = 0
+	if m.session == nil {
+		return
+	}
+	start := time.Now()
+	changed := m.changedTabs
+	m.changedTabs = nil
+	changed = append(changed, analyzer.AnalyzeStale(m.session.AllTabs, m.staleDays)...)
+	changed = append(changed, analyzer.AnalyzePlaceholders(m.session.AllTabs)...)
+	changed = append(changed, analyzer.AnalyzeDuplicates(m.session.AllTabs, m.exactDuplicates)...)
+	if m.statsTracker == nil {
+		m.resetStats()
+	} else {
+		for _, tab := range changed {
+			m.statsTracker.Update(tab)
+		}
+		m.statsTracker.SetGroups(len(m.session.Groups))
+		m.tabsView.stats = m.statsTracker.Stats()
+	}
+	m.tabsView.RebuildTree()
+	m.publishMetrics()
+	if pending > 1 {
+		applog.Info("tui.rebuild", "changes", pending, "tabs", len(m.session.AllTabs), "took", time.Since(start).String())
+	}
+}
+
+// switchView makes target the active view and returns the command that
+// loads its data, if any.
+func (m *Model) switchView(target ViewType) tea.Cmd {
+	if target == m.activeView || target < ViewTabs || target > ViewGitLab {
+		return nil
+	}
+	m.activeView = target
+	switch target {
+	case ViewTabs:
+		m.tabsView.focusDetail = false
+		m.tabsView.detail.Scroll = 0
+	case ViewSignals:
+		return m.signalsView.Reload()
+	case ViewGitHub:
+		return m.githubView.Reload()
+	case ViewBugzilla:
+		return m.bugzillaView.Reload()
+	case ViewActivity:
+		if !m.activityView.loaded {
+			return m.activityView.LoadPeriods()
+		}
+	case ViewSnapshots:
+		m.snapshotsView.SetCurrent(m.session)
+		if !m.snapshotsView.loaded {
+			return m.snapshotsView.LoadAll()
+		}
+	case ViewTimeline:
+		return m.timelineView.Reload()
+	case ViewGitLab:
+		return m.gitlabView.Reload()
+	}
+	return nil
+}
+
+// resetStats recounts the stats from scratch. It is used whenever the
+// whole session is replaced or tabs were changed outside the live update
+// path (dead link and GitHub checks).
+func (m *Model) resetStats() {
+	m.statsTracker = analyzer.NewStatsTracker(m.session)
+	m.changedTabs = nil
+	m.tabsView.stats = m.statsTracker.Stats()
+	m.publishMetrics()
 }
 
-func (m *Model) scheduleRebuild() tea.Cmd {
-	m.rebuildDirty = true
-	if m.rebuildScheduled {
-		return nil
-	}
-	m.rebuildScheduled = true
-	return rebuildTick()
+// SetMetrics makes the model publish its stats and signal counts to e.
+func (m *Model) SetMetrics(e *metrics.Exporter) {
+	m.metrics = e
 }
 
-func (m *Model) doRebuild() {
-	if !m.rebuildDirty || m.session == nil {
+// publishMetrics hands the current stats and signal counts to the metrics
+// exporter, if any.
+func (m *Model) publishMetrics() {
+	if m.metrics == nil {
 		return
 	}
-	analyzer.AnalyzeStale(m.session.AllTabs, m.staleDays)
-	analyzer.AnalyzeDuplicates(m.session.AllTabs)
-	m.tabsView.stats = analyzer.ComputeStats(m.session)
-	m.tabsView.RebuildTree()
-	m.rebuildDirty = false
-	m.rebuildScheduled = false
+	m.metrics.SetStats(m.tabsView.stats)
+	m.metrics.SetSignalCounts(m.tabsView.tree.SignalCounts)
 }
 
 // --- Update ---
@@ -646,57 +1391,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.bugzillaView.SetSize(m.width, paneHeight)
 		m.activityView.SetSize(m.width, paneHeight)
 		m.snapshotsView.SetSize(m.width, paneHeight)
+		m.timelineView.SetSize(m.width, paneHeight)
+		m.gitlabView.SetSize(m.width, paneHeight)
 		return m, nil
 
 	case tea.KeyMsg:
+		// Text prompts take every key
+		if m.activeView == ViewSignals && m.signalsView.Prompting() {
+			v, cmd := m.signalsView.Update(msg)
+			m.signalsView = v
+			return m, cmd
+		}
+		if m.activeView == ViewTabs && m.tabsView.Prompting() {
+			v, cmd := m.tabsView.Update(msg)
+			m.tabsView = v
+			return m, cmd
+		}
+
 		// View switching and global keys (when no modal)
-		if !m.showPicker && !m.showGroupPicker && !m.showFilterPicker {
+		if !m.showPicker && !m.showGroupPicker && !m.showFilterPicker && !m.showPalette {
 			switch msg.String() {
-			case "1":
-				if m.activeView != ViewTabs {
-					m.activeView = ViewTabs
-					m.tabsView.focusDetail = false
-					m.tabsView.detail.Scroll = 0
-				}
-				return m, nil
-			case "2":
-				if m.activeView != ViewSignals {
-					m.activeView = ViewSignals
-					return m, m.signalsView.Reload()
-				}
-				return m, nil
-			case "3":
-				if m.activeView != ViewGitHub {
-					m.activeView = ViewGitHub
-					return m, m.githubView.Reload()
-				}
-				return m, nil
-			case "4":
-				if m.activeView != ViewBugzilla {
-					m.activeView = ViewBugzilla
-					return m, m.bugzillaView.Reload()
-				}
-				return m, nil
-			case "5":
-				if m.activeView != ViewActivity {
-					m.activeView = ViewActivity
-					if !m.activityView.loaded {
-						return m, m.activityView.LoadPeriods()
-					}
-				}
-				return m, nil
-			case "6":
-				if m.activeView != ViewSnapshots {
-					m.activeView = ViewSnapshots
-					if !m.snapshotsView.loaded {
-						return m, m.snapshotsView.LoadAll()
-					}
-				}
+			case "1", "2", "3", "4", "5", "6", "7", "8":
+				return m, m.switchView(ViewType(msg.String()[0] - '1'))
+			case "ctrl+p":
+				m.showPalette = true
+				m.palette = NewCommandPalette(&m)
+				m.palette.Width = m.width
+				m.palette.Height = m.height
 				return m, nil
 			}
 		}
 
 		// Modal handling
+		if m.showPalette {
+			return m.updatePalette(msg)
+		}
 		if m.showGroupPicker {
 			return m.updateGroupPicker(msg)
 		}
@@ -710,124 +1439,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Global keys handled before view delegation
 		switch msg.String() {
 		case "q", "ctrl+c":
-			if m.cancel != nil {
-				m.cancel()
-			}
-			return m, tea.Quit
+			return m, m.quit()
 		case "p":
 			m.showPicker = true
 			m.picker = NewSourcePicker(m.profiles)
 			m.picker.Width = m.width
 			m.picker.Height = m.height
-			return m, nil
+			return m, loadSourceCounts(m.db, m.profiles)
 		}
 
-		// Delegate to active view
-		switch m.activeView {
-		case ViewTabs:
-			v, cmd := m.tabsView.Update(msg)
-			m.tabsView = v
-			return m, cmd
-
-		case ViewSignals:
-			v, cmd := m.signalsView.Update(msg)
-			m.signalsView = v
-			return m, cmd
-
-		case ViewGitHub:
-			v, cmd := m.githubView.Update(msg)
-			m.githubView = v
-			return m, cmd
-
-		case ViewBugzilla:
-			v, cmd := m.bugzillaView.Update(msg)
-			m.bugzillaView = v
-			return m, cmd
-
-		case ViewActivity:
-			v, cmd := m.activityView.Update(msg)
-			m.activityView = v
-			return m, cmd
-
-		case ViewSnapshots:
-			v, cmd := m.snapshotsView.Update(msg)
-			m.snapshotsView = v
-			return m, cmd
-		}
-		return m, nil
+		return m.updateActiveView(msg)
 
 	case tea.MouseMsg:
-		if m.showPicker || m.showGroupPicker || m.showFilterPicker {
+		if m.showPicker || m.showGroupPicker || m.showFilterPicker || m.showPalette {
 			return m, nil
 		}
 		// Navbar click — switch views
 		if msg.Y == 0 && msg.Button == tea.MouseButtonLeft {
-			var counts [6]int
-			counts[ViewTabs] = m.tabsView.stats.TotalTabs
-			for _, c := range m.tabsView.tree.SignalCounts {
-				counts[ViewSignals] += c
+			if idx := navbarHitTest(msg.X, m.navCounts()); idx >= 0 {
+				return m, m.switchView(ViewType(idx))
 			}
-			ghC, _ := storage.OpenGitHubEntityCount(m.db)
-			counts[ViewGitHub] = ghC
-			bzC, _ := storage.BugzillaEntityCount(m.db)
-			counts[ViewBugzilla] = bzC
-			counts[ViewActivity] = len(m.activityView.periods)
-			counts[ViewSnapshots] = len(m.snapshotsView.snapshots)
-
-			if idx := navbarHitTest(msg.X, counts); idx >= 0 {
-				target := ViewType(idx)
-				if target != m.activeView {
-					m.activeView = target
-					switch target {
-					case ViewTabs:
-						m.tabsView.focusDetail = false
-						m.tabsView.detail.Scroll = 0
-					case ViewSignals:
-						return m, m.signalsView.Reload()
-					case ViewGitHub:
-						return m, m.githubView.Reload()
-					case ViewBugzilla:
-						return m, m.bugzillaView.Reload()
-					case ViewActivity:
-						if !m.activityView.loaded {
-							return m, m.activityView.LoadPeriods()
-						}
-					case ViewSnapshots:
-						if !m.snapshotsView.loaded {
-							return m, m.snapshotsView.LoadAll()
-						}
-					}
-				}
-				return m, nil
-			}
-		}
-		switch m.activeView {
-		case ViewTabs:
-			v, cmd := m.tabsView.Update(msg)
-			m.tabsView = v
-			return m, cmd
-		case ViewSignals:
-			v, cmd := m.signalsView.Update(msg)
-			m.signalsView = v
-			return m, cmd
-		case ViewGitHub:
-			v, cmd := m.githubView.Update(msg)
-			m.githubView = v
-			return m, cmd
-		case ViewBugzilla:
-			v, cmd := m.bugzillaView.Update(msg)
-			m.bugzillaView = v
-			return m, cmd
-		case ViewActivity:
-			v, cmd := m.activityView.Update(msg)
-			m.activityView = v
-			return m, cmd
-		case ViewSnapshots:
-			v, cmd := m.snapshotsView.Update(msg)
-			m.snapshotsView = v
-			return m, cmd
 		}
-		return m, nil
+		return m.updateActiveView(msg)
 
 	// --- Custom messages from TabsView ---
 	case showGroupPickerMsg:
@@ -837,16 +1470,23 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.groupPicker.Height = m.height
 		return m, nil
 
+	case showJumpPickerMsg:
+		m.showGroupPicker = true
+		m.groupPicker = NewJumpPicker(m.tabsView.tree.Groups)
+		m.groupPicker.Width = m.width
+		m.groupPicker.Height = m.height
+		return m, nil
+
 	case showFilterPickerMsg:
 		m.showFilterPicker = true
-		m.filterPicker = NewFilterPicker(m.tabsView.tree.Filter)
+		m.filterPicker = NewFilterPicker(m.tabsView.tree.Filter, m.tabsView.tree.ContainerFilter, sessionContainers(m.session))
 		m.filterPicker.Width = m.width
 		m.filterPicker.Height = m.height
 		return m, nil
 
 	case reloadSessionMsg:
 		m.loading = true
-		return m, loadSession(m.profile)
+		return m, loadSession(m.profile, m.sessionFile)
 
 	// --- Async results ---
 	case sessionLoadedMsg:
@@ -861,34 +1501,58 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.tabsView.session = m.session
 		m.tabsView.mode = m.mode
 		m.tabsView.connected = m.connected
+		if counts, err := storage.WordCounts(m.db); err == nil {
+			m.tabsView.wordCounts = counts
+		}
 
 		analyzer.AnalyzeStale(m.session.AllTabs, m.staleDays)
-		analyzer.AnalyzeDuplicates(m.session.AllTabs)
-		m.tabsView.stats = analyzer.ComputeStats(m.session)
+		analyzer.AnalyzePlaceholders(m.session.AllTabs)
+		analyzer.AnalyzeDuplicates(m.session.AllTabs, m.exactDuplicates)
+		m.resetStats()
 		m.tabsView.RebuildTree()
+		m.publishMetrics()
+		restoreCmd := m.restoreUIState()
+		var warnCmd tea.Cmd
+		for _, w := range m.session.Warnings {
+			applog.Info("session.warning", "warning", w)
+		}
+		if len(m.session.Warnings) > 0 {
+			text := strings.Join(m.session.Warnings, "; ")
+			warnCmd = func() tea.Msg { return toastMsg{text: text} }
+		}
 
 		activityCmd := m.activityView.LoadPeriods()
+		m.snapshotsView.SetCurrent(m.session)
 		snapshotsCmd := m.snapshotsView.LoadAll()
 
-		m.tabsView.deadChecking = true
-		m.tabsView.githubChecking = true
 		return m, tea.Batch(
-			runDeadLinkChecks(m.session.AllTabs),
-			runGitHubChecks(m.session.AllTabs),
+			m.startChecks(),
 			activityCmd,
 			snapshotsCmd,
 			classifyTick(),
+			restoreCmd,
+			loadEntityCounts(m.db),
+			warnCmd,
 		)
 
 	case analysisCompleteMsg:
+		if msg.cancelled {
+			return m, nil
+		}
 		m.tabsView.deadChecking = false
-		m.tabsView.stats = analyzer.ComputeStats(m.session)
+		m.tabsView.tree.Invalidate()
+		m.resetStats()
 		return m, nil
 
 	case githubAnalysisCompleteMsg:
+		if msg.cancelled {
+			return m, nil
+		}
 		m.tabsView.githubChecking = false
-		m.tabsView.stats = analyzer.ComputeStats(m.session)
-		return m, nil
+		m.tabsView.githubRateLimited = github.IsRateLimited(msg.err)
+		m.tabsView.tree.Invalidate()
+		m.resetStats()
+		return m, loadEntityCounts(m.db)
 
 	case summarizeCompleteMsg:
 		job := m.tabsView.summarizeJobs[msg.url]
@@ -897,6 +1561,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			popupID = job.PopupRequestID
 		}
 		delete(m.tabsView.summarizeJobs, msg.url)
+		m.tabsView.tree.Invalidate()
 		if msg.err != nil {
 			m.tabsView.summarizeErrors[msg.url] = msg.err.Error()
 			if popupID != "" {
@@ -908,6 +1573,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 		} else {
 			delete(m.tabsView.summarizeErrors, msg.url)
+			m.tabsView.setWordCount(msg.url, msg.words)
 			if popupID != "" {
 				m.server.Send(server.OutgoingMsg{
 					ID:      popupID,
@@ -919,6 +1585,71 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case previewMsg:
+		if msg.err != nil {
+			applog.Error("tui.preview", msg.err, "url", msg.url)
+		}
+		m.tabsView.setPreview(msg.url, msg.preview)
+		return m, nil
+
+	case wordCountMsg:
+		delete(m.tabsView.wordCounting, msg.url)
+		if msg.err != nil {
+			applog.Error("tui.wordcount", msg.err, "url", msg.url)
+			return m, nil
+		}
+		m.tabsView.setWordCount(msg.url, msg.words)
+		return m, nil
+
+	case toastMsg:
+		m.tabsView.toastSeq++
+		m.tabsView.toast = msg.text
+		seq := m.tabsView.toastSeq
+		return m, tea.Tick(toastDuration, func(time.Time) tea.Msg {
+			return toastExpiredMsg{seq: seq}
+		})
+
+	case snapshotCreatedMsg:
+		var text string
+		switch {
+		case msg.err != nil:
+			text = "Snapshot failed: " + msg.err.Error()
+		case !msg.created:
+			text = fmt.Sprintf("No changes since snapshot #%d", msg.rev)
+		case msg.diff != nil:
+			text = fmt.Sprintf("Snapshot #%d created (+%d -%d)", msg.rev, len(msg.diff.Added), len(msg.diff.Removed))
+		default:
+			text = fmt.Sprintf("Snapshot #%d created", msg.rev)
+		}
+		toast := func() tea.Msg { return toastMsg{text: text} }
+		if !msg.created {
+			return m, toast
+		}
+		return m, tea.Batch(toast, m.snapshotsView.LoadAll(), loadEntityCounts(m.db))
+
+	case toastExpiredMsg:
+		if msg.seq == m.tabsView.toastSeq {
+			m.tabsView.toast = ""
+		}
+		return m, nil
+
+	case summarizeChunkMsg:
+		if job := m.tabsView.summarizeJobs[msg.url]; job != nil {
+			job.Partial += msg.text
+		}
+		return m, listenSummaryChunks(msg.url, msg.chunks)
+
+	case groupSummarizeCompleteMsg:
+		delete(m.tabsView.groupSummarizing, msg.group)
+		if msg.err != nil {
+			m.tabsView.groupSummaryErrors[msg.group] = msg.err.Error()
+			applog.Error("tui.summarize.group", msg.err, "group", msg.group)
+		} else {
+			delete(m.tabsView.groupSummaryErrors, msg.group)
+			applog.Info("tui.summarize.group", "group", msg.group, "path", msg.path, "failed", msg.failed)
+		}
+		return m, nil
+
 	case signalCompleteMsg:
 		if msg.err != nil {
 			applog.Error("tui.signal", msg.err, "source", msg.source)
@@ -927,11 +1658,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			applog.Info("tui.signal", "source", msg.source)
 			delete(m.tabsView.signalErrors, msg.source)
 		}
-		if m.tabsView.signalSource != "" {
-			m.tabsView.signals, _ = storage.ListSignals(m.db, m.tabsView.signalSource, true)
-		}
-		m.tabsView.tree.SignalCounts, _ = storage.ActiveSignalCounts(m.db)
-		m.tabsView.tree.SignalUrgency, _ = storage.HighestUrgencyBySource(m.db)
+		m.tabsView.reloadSignalCounts()
+		m.publishMetrics()
 		var cmds []tea.Cmd
 		cmds = append(cmds, m.tabsView.processNextSignal())
 		if m.activeView == ViewSignals {
@@ -940,25 +1668,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Extract GitHub entities from recently reconciled signals and refresh
 		cmds = append(cmds, extractGitHubFromRecentSignals(m.db, msg.source))
 		cmds = append(cmds, extractBugzillaFromRecentSignals(m.db, msg.source))
+		cmds = append(cmds, extractGitLabFromRecentSignals(m.db, msg.source))
 		cmds = append(cmds, refreshGitHubEntitiesCmd(m.db))
 		cmds = append(cmds, refreshBugzillaEntitiesCmd(m.db))
+		cmds = append(cmds, refreshGitLabEntitiesCmd(m.db))
 		return m, tea.Batch(cmds...)
 
 	case signalActionMsg:
 		if msg.err != nil {
 			m.tabsView.signalErrors[msg.source] = msg.err.Error()
 		}
-		if m.tabsView.signalSource != "" {
-			m.tabsView.signals, _ = storage.ListSignals(m.db, m.tabsView.signalSource, true)
-			if m.tabsView.signalCursor >= len(m.tabsView.signals) {
-				m.tabsView.signalCursor = len(m.tabsView.signals) - 1
-			}
-			if m.tabsView.signalCursor < 0 {
-				m.tabsView.signalCursor = 0
-			}
-		}
-		m.tabsView.tree.SignalCounts, _ = storage.ActiveSignalCounts(m.db)
-		m.tabsView.tree.SignalUrgency, _ = storage.HighestUrgencyBySource(m.db)
+		m.tabsView.reloadSignalCounts()
+		m.publishMetrics()
 		if m.activeView == ViewSignals {
 			v, cmd := m.signalsView.Update(msg)
 			m.signalsView = v
@@ -979,33 +1700,69 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, m.tabsView.queueSignalPoll()
 
 	case classifyTickMsg:
+		if m.classifying {
+			return m, tea.Batch(resurfaceSnoozedCmd(m.db), classifyTick())
+		}
+		m.classifying = true
 		return m, tea.Batch(
-			runClassifyOne(m.db, m.ollamaModel, m.ollamaHost),
+			runClassifyBatch(m.db, m.ollamaModel, m.ollamaHost),
+			resurfaceSnoozedCmd(m.db),
 			classifyTick(),
 		)
 
 	case classifyDoneMsg:
+		m.classifying = false
 		if msg.err != nil {
-			applog.Error("classify.done", msg.err, "id", msg.id)
-		} else if msg.urgency != "" {
-			applog.Info("classify.done", "id", msg.id, "urgency", msg.urgency)
+			applog.Error("classify.done", msg.err, "classified", msg.classified)
+		} else if msg.classified > 0 {
+			applog.Info("classify.done", "classified", msg.classified)
 		}
-		// Refresh signal counts and urgency
-		m.tabsView.tree.SignalCounts, _ = storage.ActiveSignalCounts(m.db)
-		m.tabsView.tree.SignalUrgency, _ = storage.HighestUrgencyBySource(m.db)
+		m.tabsView.reloadSignalCounts()
+		m.publishMetrics()
 		if m.activeView == ViewSignals {
 			return m, m.signalsView.Reload()
 		}
 		return m, nil
 
+	case snapshotRestoreMsg:
+		if m.mode != ModeLive || !m.connected {
+			m.snapshotsView.status = "Restore requires live mode (connected extension)"
+			return m, nil
+		}
+		snap := snapshot.FilterTabs(msg.snap, snapshot.InGroup(msg.group))
+		var cmds []tea.Cmd
+		for _, batch := range snapshot.PlanRestore(snap) {
+			id, cmd := sendCmdWithID(m.server, server.OutgoingMsg{
+				Action: "open",
+				Tabs:   batch.Tabs,
+			})
+			m.restoreJobs[id] = batch.Group
+			cmds = append(cmds, cmd)
+		}
+		applog.Info("tui.snapshot.restore", "rev", snap.Rev, "group", msg.group, "tabs", len(snap.Tabs))
+		m.snapshotsView.status = fmt.Sprintf("Restoring %d tabs from %q", len(snap.Tabs), msg.group)
+		return m, tea.Batch(cmds...)
+
 	case rebuildTickMsg:
+		if wait := m.rebuildWait(time.Now()); wait > 0 {
+			return m, rebuildTick(wait)
+		}
 		m.doRebuild()
 		return m, nil
 
+	case liveTickMsg:
+		if m.mode != ModeLive {
+			m.liveTicking = false
+			return m, nil
+		}
+		return m, liveTick()
+
 	// --- WebSocket messages ---
 	case wsSnapshotMsg:
 		m.loading = false
 		m.connected = true
+		reconnected := !m.disconnectedAt.IsZero()
+		m.disconnectedAt = time.Time{}
 		m.session = msg.data
 		m.tabsView.session = m.session
 		m.tabsView.mode = m.mode
@@ -1013,36 +1770,58 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		applog.Info("tui.snapshot", "tabs", len(msg.data.AllTabs), "groups", len(msg.data.Groups))
 
 		analyzer.AnalyzeStale(m.session.AllTabs, m.staleDays)
-		analyzer.AnalyzeDuplicates(m.session.AllTabs)
-		m.tabsView.stats = analyzer.ComputeStats(m.session)
+		analyzer.AnalyzePlaceholders(m.session.AllTabs)
+		analyzer.AnalyzeDuplicates(m.session.AllTabs, m.exactDuplicates)
+		m.resetStats()
 		m.tabsView.RebuildTree()
+		m.publishMetrics()
+		restoreCmd := m.restoreUIState()
+
+		var tickCmd tea.Cmd
+		if !m.liveTicking {
+			m.liveTicking = true
+			tickCmd = liveTick()
+		}
+
+		var resumeCmd tea.Cmd
+		if reconnected {
+			applog.Info("tui.reconnected", "queuedSignals", len(m.tabsView.signalQueue))
+			resumeCmd = m.tabsView.resumeSignals()
+		}
 
-		m.tabsView.deadChecking = true
-		m.tabsView.githubChecking = true
 		return m, tea.Batch(
-			runDeadLinkChecks(m.session.AllTabs),
-			runGitHubChecks(m.session.AllTabs),
+			m.startChecks(),
 			m.activityView.RefreshPeriods(),
 			listenWebSocket(m.server),
+			resumeCmd,
+			restoreCmd,
+			tickCmd,
 			signalPollTick(),
 			classifyTick(),
 			refreshGitHubEntitiesCmd(m.db),
 			refreshBugzillaEntitiesCmd(m.db),
+			refreshGitLabEntitiesCmd(m.db),
 		)
 
+	case wsServerErrorMsg:
+		m.serverErr = msg.err
+		return m, nil
+
 	case wsDisconnectedMsg:
+		wasConnected := m.connected
 		m.connected = false
 		m.tabsView.connected = false
-		if m.tabsView.signalActive != nil {
-			m.tabsView.signalErrors[m.tabsView.signalActive.Source] = "disconnected"
-			m.tabsView.signalActive = nil
-		}
-		m.tabsView.signalQueue = nil
+		// Signal captures wait for the extension to come back.
+		m.tabsView.requeueSignals()
 		var cmds []tea.Cmd
+		if wasConnected && m.mode == ModeLive {
+			applog.Info("tui.disconnected")
+			m.disconnectedAt = time.Now()
+		}
 		for _, job := range m.tabsView.summarizeJobs {
 			if job.ContentID != "" {
 				job.ContentID = ""
-				cmds = append(cmds, runSummarizeTab(job.Tab, m.summaryDir, m.ollamaModel, m.ollamaHost))
+				cmds = append(cmds, runSummarizeTab(job.Tab, m.summaryDir, m.ollamaModel, m.ollamaHost, m.summaryPrompt))
 			}
 		}
 		if m.mode == ModeLive && m.server != nil {
@@ -1056,6 +1835,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case wsTabRemovedMsg:
 		if m.session != nil {
 			m.removeTab(msg.tabID)
+			m.tabsView.tree.Invalidate()
 			return m, tea.Batch(listenWebSocket(m.server), m.scheduleRebuild())
 		}
 		return m, listenWebSocket(m.server)
@@ -1096,6 +1876,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case wsTabCreatedMsg:
 		if m.session != nil {
 			m.addTab(msg.tab)
+			m.tabsView.tree.Invalidate()
 			return m, tea.Batch(listenWebSocket(m.server), m.scheduleRebuild())
 		}
 		return m, listenWebSocket(m.server)
@@ -1103,6 +1884,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case wsTabUpdatedMsg:
 		if m.session != nil {
 			m.updateTab(msg.tab)
+			m.tabsView.tree.Invalidate()
 			return m, tea.Batch(listenWebSocket(m.server), m.scheduleRebuild())
 		}
 		return m, listenWebSocket(m.server)
@@ -1126,24 +1908,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			return m, listenWebSocket(m.server)
 		}
-		if existing, ok := m.tabsView.summarizeJobs[tab.URL]; ok {
-			existing.PopupRequestID = msg.id
-			return m, listenWebSocket(m.server)
-		}
-		job := &SummarizeJob{Tab: tab, PopupRequestID: msg.id}
-		m.tabsView.summarizeJobs[tab.URL] = job
-		if m.mode == ModeLive && m.connected {
-			id, cmd := sendCmdWithID(m.server, server.OutgoingMsg{
-				Action: "get-content",
-				TabID:  tab.BrowserID,
-			})
-			job.ContentID = id
-			return m, tea.Batch(listenWebSocket(m.server), cmd)
-		}
-		return m, tea.Batch(
-			listenWebSocket(m.server),
-			runSummarizeTab(tab, m.summaryDir, m.ollamaModel, m.ollamaHost),
-		)
+		return m, tea.Batch(listenWebSocket(m.server), m.tabsView.startSummarize(tab, msg.id))
 
 	case wsAutoSummarizeMsg:
 		// Check if summary already exists on disk
@@ -1169,24 +1934,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			})
 			return m, listenWebSocket(m.server)
 		}
-		if existing, ok := m.tabsView.summarizeJobs[tab.URL]; ok {
-			existing.PopupRequestID = msg.id
-			return m, listenWebSocket(m.server)
-		}
-		job := &SummarizeJob{Tab: tab, PopupRequestID: msg.id}
-		m.tabsView.summarizeJobs[tab.URL] = job
-		if m.mode == ModeLive && m.connected {
-			id, cmd := sendCmdWithID(m.server, server.OutgoingMsg{
-				Action: "get-content",
-				TabID:  tab.BrowserID,
-			})
-			job.ContentID = id
-			return m, tea.Batch(listenWebSocket(m.server), cmd)
-		}
-		return m, tea.Batch(
-			listenWebSocket(m.server),
-			runSummarizeTab(tab, m.summaryDir, m.ollamaModel, m.ollamaHost),
-		)
+		return m, tea.Batch(listenWebSocket(m.server), m.tabsView.startSummarize(tab, msg.id))
 
 	case wsGetThreadSummaryMsg:
 		summary := ""
@@ -1255,6 +2003,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case wsCmdResponseMsg:
 		applog.Info("tui.cmdResponse", "id", msg.id, "ok", msg.ok)
+		if group, ok := m.restoreJobs[msg.id]; ok {
+			delete(m.restoreJobs, msg.id)
+			if !msg.ok {
+				m.snapshotsView.status = "Restore failed: " + msg.error
+				return m, listenWebSocket(m.server)
+			}
+			if group != nil && len(msg.tabIDs) > 0 {
+				return m, tea.Batch(listenWebSocket(m.server), sendCmd(m.server, server.OutgoingMsg{
+					Action: "create-group",
+					TabIDs: msg.tabIDs,
+					Name:   group.Name,
+					Color:  group.Color,
+				}))
+			}
+			return m, listenWebSocket(m.server)
+		}
 		if m.tabsView.signalActive != nil && m.tabsView.signalActive.ContentID == msg.id {
 			source := m.tabsView.signalActive.Source
 			m.tabsView.signalActive = nil
@@ -1285,12 +2049,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				if msg.ok && len(content) >= 50 {
 					return m, tea.Batch(
 						listenWebSocket(m.server),
-						runSummarizeWithContent(tab, content, m.summaryDir, m.ollamaModel, m.ollamaHost),
+						runSummarizeWithContent(tab, content, m.summaryDir, m.ollamaModel, m.ollamaHost, m.summaryPrompt),
 					)
 				}
 				return m, tea.Batch(
 					listenWebSocket(m.server),
-					runSummarizeTab(tab, m.summaryDir, m.ollamaModel, m.ollamaHost),
+					runSummarizeTab(tab, m.summaryDir, m.ollamaModel, m.ollamaHost, m.summaryPrompt),
 				)
 			}
 		}
@@ -1320,22 +2084,32 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case githubViewLoadedMsg:
 		v, cmd := m.githubView.Update(msg)
 		m.githubView = v
-		return m, cmd
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
 
 	case githubRefreshDoneMsg:
 		v, cmd := m.githubView.Update(msg)
 		m.githubView = v
-		return m, cmd
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
 
 	case bugzillaRefreshDoneMsg:
 		v, cmd := m.bugzillaView.Update(msg)
 		m.bugzillaView = v
-		return m, cmd
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
 
 	case bugzillaViewLoadedMsg:
 		v, cmd := m.bugzillaView.Update(msg)
 		m.bugzillaView = v
-		return m, cmd
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
+
+	case gitlabRefreshDoneMsg:
+		v, cmd := m.gitlabView.Update(msg)
+		m.gitlabView = v
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
+
+	case gitlabViewLoadedMsg:
+		v, cmd := m.gitlabView.Update(msg)
+		m.gitlabView = v
+		return m, tea.Batch(cmd, loadEntityCounts(m.db))
 
 	case signalsViewLoadedMsg:
 		v, cmd := m.signalsView.Update(msg)
@@ -1361,6 +2135,61 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		v, cmd := m.snapshotsView.Update(msg)
 		m.snapshotsView = v
 		return m, cmd
+
+	case tabEntitiesMsg:
+		v := &m.tabsView
+		if msg.err != nil {
+			applog.Error("tui.track_entities", msg.err)
+			v.entityRefresh = ""
+			return m, func() tea.Msg { return toastMsg{text: "Tracking failed: " + msg.err.Error()} }
+		}
+		cmds := refreshTabEntitiesCmds(m.db, msg.found)
+		if len(cmds) == 0 {
+			v.entityRefresh = ""
+			return m, func() tea.Msg { return toastMsg{text: "No GitHub, Bugzilla or GitLab links in the open tabs"} }
+		}
+		v.entityRefreshPending = len(cmds)
+		v.entityRefreshResults = []string{fmt.Sprintf("%d new", msg.found.New)}
+		v.entityRefresh = fmt.Sprintf("refreshing %d tracked item(s)...",
+			len(msg.found.GitHub)+len(msg.found.Bugzilla)+len(msg.found.GitLab))
+		return m, tea.Batch(append(cmds, loadEntityCounts(m.db))...)
+
+	case tabEntitiesRefreshedMsg:
+		v := &m.tabsView
+		if v.entityRefreshPending == 0 {
+			return m, nil
+		}
+		v.entityRefreshPending--
+		if msg.err != nil {
+			applog.Error("tui.track_entities", msg.err)
+			v.entityRefreshResults = append(v.entityRefreshResults, msg.status+" ("+msg.err.Error()+")")
+		} else {
+			v.entityRefreshResults = append(v.entityRefreshResults, msg.status)
+		}
+		if v.entityRefreshPending > 0 {
+			return m, nil
+		}
+		text := "Tracked: " + strings.Join(v.entityRefreshResults, " \u00b7 ")
+		v.entityRefresh, v.entityRefreshResults = "", nil
+		return m, tea.Batch(
+			func() tea.Msg { return toastMsg{text: text} },
+			m.githubView.Reload(),
+			m.bugzillaView.Reload(),
+			m.gitlabView.Reload(),
+		)
+
+	case entityCountsMsg:
+		m.entityCounts = msg
+		return m, nil
+
+	case sourceCountsMsg:
+		m.picker.SetCounts(msg)
+		return m, nil
+
+	case timelineLoadedMsg:
+		v, cmd := m.timelineView.Update(msg)
+		m.timelineView = v
+		return m, cmd
 	}
 
 	return m, nil
@@ -1368,7 +2197,35 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // --- Modal handlers ---
 
+// updateActiveView hands a key or mouse event to the active view. Keys and
+// mouse events share it so that every view sees both the same way.
+func (m Model) updateActiveView(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	switch m.activeView {
+	case ViewTabs:
+		m.tabsView, cmd = m.tabsView.Update(msg)
+	case ViewSignals:
+		m.signalsView, cmd = m.signalsView.Update(msg)
+	case ViewGitHub:
+		m.githubView, cmd = m.githubView.Update(msg)
+	case ViewBugzilla:
+		m.bugzillaView, cmd = m.bugzillaView.Update(msg)
+	case ViewActivity:
+		m.activityView, cmd = m.activityView.Update(msg)
+	case ViewSnapshots:
+		m.snapshotsView, cmd = m.snapshotsView.Update(msg)
+	case ViewTimeline:
+		m.timelineView, cmd = m.timelineView.Update(msg)
+	case ViewGitLab:
+		m.gitlabView, cmd = m.gitlabView.Update(msg)
+	}
+	return m, cmd
+}
+
 func (m Model) updateGroupPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.groupPicker.Jump {
+		return m.updateJumpPicker(msg)
+	}
 	switch msg.String() {
 	case "up", "k":
 		m.groupPicker.MoveUp()
@@ -1390,11 +2247,55 @@ func (m Model) updateGroupPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "esc":
 		m.showGroupPicker = false
 	case "q", "ctrl+c":
-		return m, tea.Quit
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+// updateJumpPicker handles the group picker opened to jump to a group:
+// keys type into its query rather than acting as shortcuts.
+func (m Model) updateJumpPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp, tea.KeyShiftTab:
+		m.groupPicker.MoveUp()
+	case tea.KeyDown, tea.KeyTab:
+		m.groupPicker.MoveDown()
+	case tea.KeyEnter:
+		if group := m.groupPicker.Selected(); group != nil {
+			m.showGroupPicker = false
+			m.tabsView.tree.JumpToGroup(group.ID)
+			m.tabsView.refreshSignals()
+		}
+	case tea.KeyEsc:
+		m.showGroupPicker = false
+	case tea.KeyCtrlC:
+		return m, m.quit()
+	case tea.KeyBackspace:
+		m.groupPicker.Backspace()
+	case tea.KeyRunes, tea.KeySpace:
+		m.groupPicker.Type(string(msg.Runes))
 	}
 	return m, nil
 }
 
+// sessionContainers returns the sorted names of the containers used by the
+// session's tabs.
+func sessionContainers(session *types.SessionData) []string {
+	if session == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for _, tab := range session.AllTabs {
+		if tab.Container != "" && !seen[tab.Container] {
+			seen[tab.Container] = true
+			names = append(names, tab.Container)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
 func (m Model) updateFilterPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
 	case "up", "k":
@@ -1402,12 +2303,43 @@ func (m Model) updateFilterPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "down", "j":
 		m.filterPicker.MoveDown()
 	case "enter":
-		m.tabsView.tree.SetFilter(m.filterPicker.Selected().Mode)
+		opt := m.filterPicker.Selected()
+		m.tabsView.tree.ContainerFilter = opt.Container
+		m.tabsView.tree.SetFilter(opt.Mode)
 		m.showFilterPicker = false
 	case "esc":
 		m.showFilterPicker = false
 	case "q", "ctrl+c":
-		return m, tea.Quit
+		return m, m.quit()
+	}
+	return m, nil
+}
+
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyUp:
+		m.palette.MoveUp()
+	case tea.KeyDown:
+		m.palette.MoveDown()
+	case tea.KeyBackspace:
+		m.palette.Backspace()
+	case tea.KeyRunes, tea.KeySpace:
+		m.palette.Type(string(msg.Runes))
+	case tea.KeyEsc, tea.KeyCtrlP:
+		m.showPalette = false
+	case tea.KeyCtrlC:
+		return m, m.quit()
+	case tea.KeyEnter:
+		m.showPalette = false
+		cmd, ok := m.palette.Selected()
+		if !ok {
+			break
+		}
+		applog.Info("tui.palette", "command", cmd.Label)
+		if cmd.Run != nil {
+			return m, cmd.Run(&m)
+		}
+		return m.Update(paletteKeyMsg(cmd.Key))
 	}
 	return m, nil
 }
@@ -1419,59 +2351,102 @@ func (m Model) updateSourcePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "down", "j":
 		m.picker.MoveDown()
 	case "enter":
-		src := m.picker.Selected()
-		m.showPicker = false
-		m.loading = true
-		if src.IsLive {
-			m.mode = ModeLive
-			return m, m.startLiveMode()
-		}
-		m.mode = ModeOffline
-		m.profile = *src.Profile
-		return m, loadSession(m.profile)
+		return m, m.selectSource(m.picker.Selected())
 	case "esc":
 		if m.session != nil {
 			m.showPicker = false
 		}
 	case "q", "ctrl+c":
-		return m, tea.Quit
+		return m, m.quit()
 	case "1", "2", "3", "4", "5", "6", "7", "8", "9":
 		n := int(msg.String()[0] - '0')
 		if m.picker.SelectByNumber(n) {
-			src := m.picker.Selected()
-			m.showPicker = false
-			m.loading = true
-			if src.IsLive {
-				m.mode = ModeLive
-				return m, m.startLiveMode()
-			}
-			m.mode = ModeOffline
-			m.profile = *src.Profile
-			return m, loadSession(m.profile)
+			return m, m.selectSource(m.picker.Selected())
 		}
 	}
 	return m, nil
 }
 
+// selectSource switches to the source picked in the source picker and
+// remembers it as the default for the next start.
+func (m *Model) selectSource(src Source) tea.Cmd {
+	m.saveUIState()
+	m.cancelChecks()
+	m.showPicker = false
+	m.loading = true
+	name := "live"
+	if !src.IsLive {
+		name = src.Profile.Name
+	}
+	if m.db != nil {
+		if err := storage.SaveLastProfile(m.db, name); err != nil {
+			applog.Error("tui.lastprofile.save", err, "profile", name)
+		}
+	}
+	if src.IsLive {
+		m.mode = ModeLive
+		return m.startLiveMode()
+	}
+	m.stopServer()
+	m.mode = ModeOffline
+	m.profile = *src.Profile
+	return loadSession(m.profile, m.sessionFile)
+}
+
 // --- View ---
 
+// navCounts returns the count shown next to each view in the navbar: tabs,
+// active signals, open GitHub issues and PRs, bugs, and so on.
+func (m Model) navCounts() [8]int {
+	var counts [8]int
+	counts[ViewTabs] = m.tabsView.stats.TotalTabs
+	for _, c := range m.tabsView.tree.SignalCounts {
+		counts[ViewSignals] += c
+	}
+	counts[ViewGitHub] = m.entityCounts.github
+	counts[ViewBugzilla] = m.entityCounts.bugzilla
+	counts[ViewActivity] = len(m.activityView.periods)
+	counts[ViewSnapshots] = len(m.snapshotsView.snapshots)
+	counts[ViewTimeline] = len(m.timelineView.events)
+	counts[ViewGitLab] = m.entityCounts.gitlab
+	return counts
+}
+
 func (m Model) View() string {
+	// The source picker can be opened from the loading and failure
+	// screens, so it is drawn before them.
+	if m.showPicker {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.picker.View())
+	}
 	if m.loading {
+		if m.mode == ModeLive && m.serverErr != nil {
+			return fmt.Sprintf("\n  Live mode failed: %v\n\n  Press p to pick another source or q to quit.\n", m.serverErr)
+		}
 		if m.mode == ModeLive {
-			return fmt.Sprintf("\n  Waiting for extension connection on :%d...\n", m.port)
+			addr := fmt.Sprintf(":%d", m.port)
+			if m.server != nil {
+				addr = m.server.Addr()
+				if !server.IsLoopback(m.server.Bind()) {
+					addr += " (reachable from other machines)"
+				}
+				if m.server.TokenRequired() {
+					addr += " (token required, see 'tabsordnung ws-token')"
+				}
+			}
+			return fmt.Sprintf("\n  Waiting for extension connection on %s...\n", addr)
 		}
 		return "\n  Loading session data...\n"
 	}
 
-	if m.showPicker {
-		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.picker.View())
-	}
 	if m.showGroupPicker {
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.groupPicker.View())
 	}
 	if m.showFilterPicker {
 		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.filterPicker.View())
 	}
+	if m.showPalette {
+		return lipgloss.Place(m.width, m.height, lipgloss.Center, lipgloss.Center, m.palette.View())
+	}
 
 	if m.err != nil {
 		return fmt.Sprintf("\n  Error: %v\n\n  Press 1-9 to switch source, 'q' to quit.\n", m.err)
@@ -1486,6 +2461,13 @@ func (m Model) View() string {
 	if m.mode == ModeLive {
 		if m.connected {
 			profileName = "Live \u25cf connected"
+			if seen := m.server.LastSeen(); !seen.IsZero() {
+				profileName += fmt.Sprintf(" (last seen %s ago)", time.Since(seen).Round(time.Second))
+			}
+		} else if !m.disconnectedAt.IsZero() {
+			down := time.Since(m.disconnectedAt)
+			profileName = fmt.Sprintf("Live \u25cc reconnecting (down %s, retry in ~%s)",
+				down.Round(time.Second), nextReconnect(down).Round(time.Second))
 		} else {
 			profileName = "Live \u25cb waiting..."
 		}
@@ -1497,19 +2479,8 @@ func (m Model) View() string {
 	if m.activeView == ViewTabs && m.session != nil {
 		statsStr = m.tabsView.StatsString()
 	}
-	var viewCounts [6]int
-	viewCounts[ViewTabs] = m.tabsView.stats.TotalTabs
-	for _, c := range m.tabsView.tree.SignalCounts {
-		viewCounts[ViewSignals] += c
-	}
-	ghCount, _ := storage.OpenGitHubEntityCount(m.db)
-	viewCounts[ViewGitHub] = ghCount
-	bzCount, _ := storage.BugzillaEntityCount(m.db)
-	viewCounts[ViewBugzilla] = bzCount
-	viewCounts[ViewActivity] = len(m.activityView.periods)
-	viewCounts[ViewSnapshots] = len(m.snapshotsView.snapshots)
 	navbar := lipgloss.NewStyle().MaxWidth(m.width).Render(
-		renderNavbar(m.activeView, profileName, viewCounts, statsStr, m.width))
+		renderNavbar(m.activeView, profileName, m.navCounts(), statsStr, m.width))
 
 	// Pane content
 	treeWidth := m.width * TreeWidthPct / 100
@@ -1549,26 +2520,36 @@ func (m Model) View() string {
 		isFocusDetail = m.snapshotsView.FocusDetail()
 		leftContent = m.snapshotsView.ViewList()
 		rightContent = m.snapshotsView.ViewDetail()
+
+	case ViewTimeline:
+		isFocusDetail = m.timelineView.FocusDetail()
+		leftContent = m.timelineView.ViewList()
+		rightContent = m.timelineView.ViewDetail()
+
+	case ViewGitLab:
+		isFocusDetail = m.gitlabView.FocusDetail()
+		leftContent = m.gitlabView.ViewList()
+		rightContent = m.gitlabView.ViewDetail()
 	}
 
 	// Pane borders
-	treeBorderColor := "62"
-	detailBorderColor := "240"
+	treeBorderColor := theme.Accent
+	detailBorderColor := theme.Dim
 	if isFocusDetail {
-		treeBorderColor = "240"
-		detailBorderColor = "62"
+		treeBorderColor = theme.Dim
+		detailBorderColor = theme.Accent
 	}
 
 	treeBorder := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(treeBorderColor)).
+		Border(paneBorder(!isFocusDetail)).
+		BorderForeground(treeBorderColor).
 		Width(treeWidth).
 		Height(paneHeight).
 		MaxHeight(paneHeight + 2)
 
 	detailBorder := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color(detailBorderColor)).
+		Border(paneBorder(isFocusDetail)).
+		BorderForeground(detailBorderColor).
 		Width(detailWidth).
 		Height(paneHeight).
 		MaxHeight(paneHeight + 2)
@@ -1578,21 +2559,29 @@ func (m Model) View() string {
 	panes := lipgloss.JoinHorizontal(lipgloss.Top, left, right)
 
 	// Bottom bar
-	bottomBarStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1)
+	bottomBarStyle := lipgloss.NewStyle().Foreground(theme.Dim).Padding(0, 1)
 	var bottomText string
 	switch m.activeView {
 	case ViewTabs:
 		bottomText = m.tabsView.BottomBar()
 	case ViewSignals:
-		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 open \u00b7 tab focus \u00b7 x complete \u00b7 u reopen \u00b7 [/] urgency \u00b7 1-6 view \u00b7 p source \u00b7 q quit"
+		bottomText = m.signalsView.BottomBar()
 	case ViewGitHub:
-		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 tab focus \u00b7 t tree \u00b7 f filter \u00b7 r refresh \u00b7 o browser \u00b7 1-6 view \u00b7 q quit"
+		bottomText = m.githubView.BottomBar()
 	case ViewBugzilla:
-		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 tab focus \u00b7 t tree \u00b7 f filter \u00b7 r reload \u00b7 o browser \u00b7 1-6 view \u00b7 q quit"
+		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 tab focus \u00b7 t tree \u00b7 f filter \u00b7 P priority \u00b7 r reload \u00b7 o browser \u00b7 1-8 view \u00b7 q quit"
 	case ViewActivity:
-		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 [/] day-week-month \u00b7 1-6 view \u00b7 p source \u00b7 q quit"
+		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 [/] day-week-month \u00b7 1-8 view \u00b7 p source \u00b7 q quit"
 	case ViewSnapshots:
-		bottomText = "\u2191\u2193/jk navigate \u00b7 tab focus \u00b7 1-6 view \u00b7 p source \u00b7 q quit"
+		if m.snapshotsView.FocusDetail() {
+			bottomText = "\u2191\u2193/jk group \u00b7 \u21b5 expand \u00b7 r restore group \u00b7 esc back \u00b7 1-8 view \u00b7 q quit"
+		} else {
+			bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 groups \u00b7 m mark \u00b7 d diff \u00b7 1-8 view \u00b7 p source \u00b7 q quit"
+		}
+	case ViewTimeline:
+		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 o browser \u00b7 r reload \u00b7 1-8 view \u00b7 p source \u00b7 q quit"
+	case ViewGitLab:
+		bottomText = "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 tab focus \u00b7 t tree \u00b7 f filter \u00b7 r refresh \u00b7 o browser \u00b7 1-8 view \u00b7 q quit"
 	}
 	bottomBar := bottomBarStyle.Render(bottomText)
 
@@ -1613,6 +2602,10 @@ func (m *Model) removeTab(browserID int) {
 	for i, t := range m.session.AllTabs {
 		if t.BrowserID == browserID {
 			m.session.AllTabs = append(m.session.AllTabs[:i], m.session.AllTabs[i+1:]...)
+			if m.statsTracker != nil {
+				m.statsTracker.Remove(t)
+			}
+			m.changedTabs = slices.DeleteFunc(m.changedTabs, func(c *types.Tab) bool { return c == t })
 			break
 		}
 	}
@@ -1621,6 +2614,7 @@ func (m *Model) removeTab(browserID int) {
 
 func (m *Model) addTab(tab *types.Tab) {
 	m.session.AllTabs = append(m.session.AllTabs, tab)
+	m.changedTabs = append(m.changedTabs, tab)
 	placed := false
 	if tab.GroupID != "" {
 		for _, g := range m.session.Groups {
@@ -1655,9 +2649,12 @@ func (m *Model) updateTab(tab *types.Tab) {
 			t.LastAccessed = tab.LastAccessed
 			t.Favicon = tab.Favicon
 			t.TabIndex = tab.TabIndex
+			t.Pinned = tab.Pinned
 			if t.GroupID != tab.GroupID {
 				m.removeTab(tab.BrowserID)
 				m.addTab(tab)
+			} else {
+				m.changedTabs = append(m.changedTabs, t)
 			}
 			return
 		}
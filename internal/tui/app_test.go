@@ -0,0 +1,108 @@
+package tui
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/server"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestNavCountsUseCachedEntityCounts(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	if _, _, err := storage.UpsertGitHubEntity(m.db, "lotas", "tabsordnung", 1, "pull", "tab"); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := storage.UpsertBugzillaEntity(m.db, "bugzilla.mozilla.org", 123, "tab"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The navbar doesn't query the database while rendering.
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+	m.View()
+	if got := m.navCounts()[ViewGitHub]; got != 0 {
+		t.Fatalf("GitHub count = %d before reloading, want the cached 0", got)
+	}
+
+	next, _ = m.Update(loadEntityCounts(m.db)())
+	m = next.(Model)
+	counts := m.navCounts()
+	if counts[ViewTabs] != 1 || counts[ViewGitHub] != 1 || counts[ViewBugzilla] != 1 || counts[ViewGitLab] != 0 {
+		t.Errorf("counts = %v, want 1 tab, 1 PR, 1 bug", counts)
+	}
+}
+
+func TestPickerFromLiveModeFailure(t *testing.T) {
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m := NewModel(Options{
+		Profiles:    []types.Profile{{Name: "default"}},
+		StaleDays:   7,
+		Live:        true,
+		Server:      server.New(0),
+		SummaryDir:  t.TempDir(),
+		OllamaModel: "model",
+		OllamaHost:  "http://127.0.0.1:0",
+		DB:          db,
+	})
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+	next, _ = m.Update(wsServerErrorMsg{err: errors.New("address already in use")})
+	m = next.(Model)
+	if !strings.Contains(m.View(), "Live mode failed") {
+		t.Fatalf("View() = %q, want the failure screen", m.View())
+	}
+
+	next, _ = m.Update(key("p"))
+	m = next.(Model)
+	if !m.showPicker {
+		t.Fatal("p did not open the source picker")
+	}
+	if v := m.View(); strings.Contains(v, "Live mode failed") || !strings.Contains(v, "default") {
+		t.Errorf("View() = %q, want the source picker", v)
+	}
+}
+
+func TestResurfacedSignalsRefreshCounts(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://mail.google.com/mail/u/0/", Title: "Inbox", LastAccessed: time.Now()})
+	if err := storage.InsertSignal(m.db, storage.SignalRecord{Source: "gmail", Title: "Alice", CapturedAt: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+	sigs, _ := storage.ListSignals(m.db, "gmail", false)
+	if err := storage.SnoozeSignal(m.db, sigs[0].ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := m.Update(signalActionMsg{})
+	m = next.(Model)
+	if got := m.tabsView.tree.SignalCounts["gmail"]; got != 0 {
+		t.Fatalf("gmail count = %d while snoozed, want 0", got)
+	}
+	m.tabsView.tree.VisibleNodes()
+
+	// The snooze expires.
+	if _, err := m.db.Exec(`UPDATE signals SET snoozed_until = ?`, time.Now().Add(-time.Minute).Unix()); err != nil {
+		t.Fatal(err)
+	}
+	msg := resurfaceSnoozedCmd(m.db)()
+	if msg == nil {
+		t.Fatal("nothing resurfaced")
+	}
+	next, _ = m.Update(msg)
+	m = next.(Model)
+	if got := m.tabsView.tree.SignalCounts["gmail"]; got != 1 {
+		t.Errorf("gmail count = %d after resurfacing, want 1", got)
+	}
+	if m.tabsView.tree.nodes.valid {
+		t.Error("resurfacing kept the cached tree rows")
+	}
+}
@@ -3,12 +3,12 @@ package tui
 import (
 	"database/sql"
 	"fmt"
-	"os/exec"
-	"runtime"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/applog"
 	"github.com/lotas/tabsordnung/internal/bugzilla"
 	"github.com/lotas/tabsordnung/internal/storage"
 )
@@ -44,6 +44,8 @@ type BugzillaView struct {
 	focusDetail    bool
 	filter         string
 	discoveredHosts []string
+	priorityFilter string
+	discoveredPriorities []string
 }
 
 func NewBugzillaView(db *sql.DB) BugzillaView {
@@ -108,11 +110,26 @@ func (v *BugzillaView) buildNodes() {
 		v.discoveredHosts = append(v.discoveredHosts, host)
 	}
 
+	prioritySeen := make(map[string]bool)
+	for _, e := range v.entities {
+		if e.Priority != "" && e.Priority != "--" {
+			prioritySeen[e.Priority] = true
+		}
+	}
+	v.discoveredPriorities = v.discoveredPriorities[:0]
+	for p := range prioritySeen {
+		v.discoveredPriorities = append(v.discoveredPriorities, p)
+	}
+	sort.Strings(v.discoveredPriorities)
+
 	var filtered []storage.BugzillaEntity
 	for _, e := range v.entities {
 		if v.filter != "" && e.Host != v.filter {
 			continue
 		}
+		if v.priorityFilter != "" && e.Priority != v.priorityFilter {
+			continue
+		}
 		filtered = append(filtered, e)
 	}
 
@@ -301,12 +318,24 @@ func (v BugzillaView) Update(msg tea.Msg) (BugzillaView, tea.Cmd) {
 				v.filter = next
 			}
 			v.buildNodes()
-			if v.cursor >= len(v.nodes) {
-				v.cursor = len(v.nodes) - 1
-			}
-			if v.cursor < 0 {
-				v.cursor = 0
+			v.clampCursor()
+		case "P":
+			// Cycle priority filter through known priorities + none.
+			next := ""
+			if v.priorityFilter == "" {
+				if len(v.discoveredPriorities) > 0 {
+					next = v.discoveredPriorities[0]
+				}
+			} else {
+				for i, p := range v.discoveredPriorities {
+					if p == v.priorityFilter && i+1 < len(v.discoveredPriorities) {
+						next = v.discoveredPriorities[i+1]
+					}
+				}
 			}
+			v.priorityFilter = next
+			v.buildNodes()
+			v.clampCursor()
 		case "o":
 			e := v.selectedEntity()
 			if e != nil {
@@ -319,6 +348,15 @@ func (v BugzillaView) Update(msg tea.Msg) (BugzillaView, tea.Cmd) {
 	return v, nil
 }
 
+func (v *BugzillaView) clampCursor() {
+	if v.cursor >= len(v.nodes) {
+		v.cursor = len(v.nodes) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
 func (v *BugzillaView) adjustOffset() {
 	if v.cursor < v.offset {
 		v.offset = v.cursor
@@ -340,8 +378,8 @@ func (v BugzillaView) ViewList() string {
 		return fmt.Sprintf("Error: %v", v.err)
 	}
 	if len(v.nodes) == 0 {
-		if v.filter != "" {
-			return fmt.Sprintf("No Bugzilla issues matching filter: %s", v.filter)
+		if label := v.filterLabel(); label != "" {
+			return fmt.Sprintf("No Bugzilla issues matching filter: %s", label)
 		}
 		return "No Bugzilla issues yet.\n\n  Bugzilla links are auto-detected\n  from tabs and signals."
 	}
@@ -349,17 +387,18 @@ func (v BugzillaView) ViewList() string {
 	treeWidth := v.width * TreeWidthPct / 100
 	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
 	headerStyle := lipgloss.NewStyle().Bold(true)
-	idStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("44"))
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	filterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	idStyle := lipgloss.NewStyle().Foreground(theme.Ref)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	filterStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
 
 	var b strings.Builder
-	if v.filter != "" {
-		b.WriteString(filterStyle.Render(fmt.Sprintf("  Filter: %s", v.filter)) + "\n")
+	filterLabel := v.filterLabel()
+	if filterLabel != "" {
+		b.WriteString(filterStyle.Render(fmt.Sprintf("  Filter: %s", filterLabel)) + "\n")
 	}
 
 	end := v.offset + v.height
-	if v.filter != "" {
+	if filterLabel != "" {
 		end--
 	}
 	if end > len(v.nodes) {
@@ -381,7 +420,10 @@ func (v BugzillaView) ViewList() string {
 			// In tree mode, status is implied by the group header.
 			statusStr := ""
 			statusLen := 0
-			if !v.treeMode && e.Status != "" {
+			if e.Restricted {
+				statusLen = len(" [restricted]")
+				statusStr = " " + dimStyle.Render("[restricted]")
+			} else if !v.treeMode && e.Status != "" {
 				statusTag := " [" + e.Status + "]"
 				statusLen = len(statusTag)
 				switch e.Status {
@@ -423,15 +465,27 @@ func (v BugzillaView) ViewList() string {
 	return b.String()
 }
 
+// filterLabel describes the active host and priority filters.
+func (v BugzillaView) filterLabel() string {
+	var parts []string
+	if v.filter != "" {
+		parts = append(parts, v.filter)
+	}
+	if v.priorityFilter != "" {
+		parts = append(parts, v.priorityFilter)
+	}
+	return strings.Join(parts, " · ")
+}
+
 func (v BugzillaView) ViewDetail() string {
 	e := v.selectedEntity()
 	if e == nil {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	valueStyle := lipgloss.NewStyle()
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 	headerBoldStyle := lipgloss.NewStyle().Bold(true)
 
 	var b strings.Builder
@@ -447,6 +501,11 @@ func (v BugzillaView) ViewDetail() string {
 	b.WriteString(labelStyle.Render("URL") + "\n")
 	b.WriteString(valueStyle.Render(url) + "\n\n")
 
+	if e.Restricted {
+		b.WriteString(labelStyle.Render("Restricted") + "\n")
+		b.WriteString(dimStyle.Render("Not authorized to view this bug. Set "+bugzilla.HostKeyEnv(e.Host)+" to an API key.") + "\n\n")
+	}
+
 	if e.Status != "" {
 		b.WriteString(labelStyle.Render("Status") + "\n")
 		statusText := e.Status
@@ -456,6 +515,11 @@ func (v BugzillaView) ViewDetail() string {
 		b.WriteString(valueStyle.Render(statusText) + "\n\n")
 	}
 
+	if rank := e.Rank(); rank != "" {
+		b.WriteString(labelStyle.Render("Priority / Severity") + "\n")
+		b.WriteString(valueStyle.Render(rank) + "\n\n")
+	}
+
 	if e.Assignee != "" {
 		b.WriteString(labelStyle.Render("Assignee") + "\n")
 		b.WriteString(valueStyle.Render(e.Assignee) + "\n\n")
@@ -471,6 +535,22 @@ func (v BugzillaView) ViewDetail() string {
 	}
 
 	if v.db != nil {
+		linked, err := storage.ListLinkedGitHubEntities(v.db, e.ID)
+		if err == nil && len(linked) > 0 {
+			b.WriteString(labelStyle.Render("Linked GitHub") + "\n")
+			for _, g := range linked {
+				line := fmt.Sprintf("%s/%s#%d", g.Owner, g.Repo, g.Number)
+				if g.State != "" {
+					line += " [" + g.State + "]"
+				}
+				if g.Title != "" {
+					line += " " + g.Title
+				}
+				b.WriteString(valueStyle.Render(line) + "\n")
+			}
+			b.WriteString("\n")
+		}
+
 		events, err := storage.ListBugzillaEntityEvents(v.db, e.ID)
 		if err == nil && len(events) > 0 {
 			b.WriteString(labelStyle.Render("Timeline") + "\n")
@@ -495,16 +575,10 @@ func (v BugzillaView) FocusDetail() bool { return v.focusDetail }
 func openBugzillaInBrowser(e *storage.BugzillaEntity) tea.Cmd {
 	return func() tea.Msg {
 		url := fmt.Sprintf("https://%s/show_bug.cgi?id=%d", e.Host, e.BugID)
-		var cmd *exec.Cmd
-		switch runtime.GOOS {
-		case "darwin":
-			cmd = exec.Command("open", url)
-		case "linux":
-			cmd = exec.Command("xdg-open", url)
-		default:
-			cmd = exec.Command("open", url)
+		if err := openURL(url); err != nil {
+			applog.Error("tui.open", err, "url", url)
+			return toastMsg{text: "Open failed: " + err.Error()}
 		}
-		_ = cmd.Start()
 		return nil
 	}
 }
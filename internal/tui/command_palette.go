@@ -0,0 +1,284 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// Command is an action offered by the command palette. Most commands replay
+// the key they are bound to; palette-only commands have no key and a Run
+// function instead.
+type Command struct {
+	Label   string
+	Key     string                 // key the action is bound to ("space" for " ")
+	Views   []ViewType             // views the command applies to; nil means all
+	Enabled func(m *Model) bool    // nil means always available
+	Run     func(m *Model) tea.Cmd // palette-only action
+}
+
+func liveOnly(m *Model) bool    { return m.mode == ModeLive && m.connected }
+func offlineOnly(m *Model) bool { return m.mode != ModeLive }
+
+var tabsOnly = []ViewType{ViewTabs}
+
+// commands lists every palette action, grouped by view.
+var commands = []Command{
+	{Label: "Go to Tabs", Key: "1"},
+	{Label: "Go to Signals", Key: "2"},
+	{Label: "Go to GitHub", Key: "3"},
+	{Label: "Go to Bugzilla", Key: "4"},
+	{Label: "Go to Activity", Key: "5"},
+	{Label: "Go to Snapshots", Key: "6"},
+	{Label: "Go to Timeline", Key: "7"},
+	{Label: "Go to GitLab", Key: "8"},
+	{Label: "Switch profile / source", Key: "p"},
+	{Label: "Quit", Key: "q"},
+
+	{Label: "Summarize tab", Key: "s", Views: tabsOnly},
+	{Label: "Summarize group", Key: "S", Views: tabsOnly},
+	{Label: "Estimate reading time", Key: "e", Views: tabsOnly},
+	{Label: "Fetch page preview", Key: "i", Views: tabsOnly},
+	{Label: "Copy URL", Key: "y", Views: tabsOnly},
+	{Label: "Copy markdown link", Key: "Y", Views: tabsOnly},
+	{Label: "Export filtered view", Key: "E", Views: tabsOnly},
+	{Label: "Create snapshot", Key: "C", Views: tabsOnly},
+	{Label: "Capture signals from tab", Key: "c", Views: tabsOnly, Enabled: liveOnly},
+	{Label: "Capture signals from all sources", Key: "A", Views: tabsOnly, Enabled: liveOnly},
+	{Label: "Track and refresh GitHub, Bugzilla and GitLab items of open tabs", Key: "R", Views: tabsOnly},
+	{Label: "Cycle display mode (URL / title / both)", Key: "t", Views: tabsOnly},
+	{Label: "Toggle GitHub badges", Key: "b", Views: tabsOnly},
+	{Label: "Cycle sort order", Key: "O", Views: tabsOnly},
+	{Label: "Open tab in system browser", Key: "o", Views: tabsOnly},
+	{Label: "Toggle grouping by window", Key: "w", Views: tabsOnly},
+	{Label: "Expand all groups", Key: "*", Views: tabsOnly},
+	{Label: "Collapse all groups", Key: "-", Views: tabsOnly},
+	{Label: "Search tabs", Key: "/", Views: tabsOnly},
+	{Label: "Jump to group", Key: "G", Views: tabsOnly},
+	{Label: "Jump to next flagged tab", Key: "n", Views: tabsOnly},
+	{Label: "Jump to previous flagged tab", Key: "N", Views: tabsOnly},
+	{Label: "Filter tabs", Key: "f", Views: tabsOnly},
+	{Label: "Clear filter", Views: tabsOnly, Run: func(m *Model) tea.Cmd {
+		m.tabsView.tree.ContainerFilter = ""
+		m.tabsView.tree.SetFilter(types.FilterAll)
+		return nil
+	}},
+	{Label: "Reload session", Key: "r", Views: tabsOnly, Enabled: offlineOnly},
+	{Label: "Focus detail pane", Key: "tab", Views: tabsOnly},
+	{Label: "Select tab", Key: "space", Views: tabsOnly, Enabled: liveOnly},
+	{Label: "Close tab(s)", Key: "x", Views: tabsOnly, Enabled: liveOnly},
+	{Label: "Close duplicate tabs", Key: "D", Views: tabsOnly, Enabled: liveOnly},
+	{Label: "Move tab(s) to group", Key: "g", Views: tabsOnly, Enabled: liveOnly},
+
+	{Label: "Complete signal", Key: "x", Views: []ViewType{ViewSignals}},
+	{Label: "Complete all signals of source", Key: "X", Views: []ViewType{ViewSignals}},
+	{Label: "Reopen signal", Key: "u", Views: []ViewType{ViewSignals}},
+	{Label: "Snooze signal", Key: "s", Views: []ViewType{ViewSignals}},
+	{Label: "Raise signal urgency", Key: "]", Views: []ViewType{ViewSignals}},
+	{Label: "Lower signal urgency", Key: "[", Views: []ViewType{ViewSignals}},
+	{Label: "Group signals by source / urgency", Key: "g", Views: []ViewType{ViewSignals}},
+	{Label: "Collapse signals by entity", Key: "e", Views: []ViewType{ViewSignals}},
+	{Label: "Open signal's GitHub/Bugzilla/GitLab entity", Key: "o", Views: []ViewType{ViewSignals}},
+
+	{Label: "Toggle tree / list", Key: "t", Views: []ViewType{ViewGitHub, ViewBugzilla, ViewGitLab}},
+	{Label: "Cycle filter", Key: "f", Views: []ViewType{ViewGitHub, ViewBugzilla, ViewGitLab}},
+	{Label: "Open in browser", Key: "o", Views: []ViewType{ViewGitHub, ViewBugzilla, ViewTimeline, ViewGitLab}},
+	{Label: "Refresh", Key: "r", Views: []ViewType{ViewGitHub, ViewBugzilla, ViewGitLab}},
+	{Label: "Refresh all (ignore freshness)", Key: "R", Views: []ViewType{ViewGitHub}},
+	{Label: "Cycle priority filter", Key: "P", Views: []ViewType{ViewBugzilla}},
+
+	{Label: "Previous period kind (day/week/month)", Key: "[", Views: []ViewType{ViewActivity}},
+	{Label: "Next period kind (day/week/month)", Key: "]", Views: []ViewType{ViewActivity}},
+
+	{Label: "Mark snapshot as diff base", Key: "m", Views: []ViewType{ViewSnapshots}},
+	{Label: "Diff snapshot (against mark or previous)", Key: "d", Views: []ViewType{ViewSnapshots}},
+
+	{Label: "Reload timeline", Key: "r", Views: []ViewType{ViewTimeline}},
+}
+
+// paletteKeyMsg builds the key message a command's key replays.
+func paletteKeyMsg(key string) tea.KeyMsg {
+	switch key {
+	case "tab":
+		return tea.KeyMsg{Type: tea.KeyTab}
+	case "enter":
+		return tea.KeyMsg{Type: tea.KeyEnter}
+	case "space":
+		return tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+}
+
+// CommandPalette is the ctrl+p fuzzy action launcher.
+type CommandPalette struct {
+	Commands []Command // available in the active view
+	Matches  []Command // Commands matching Query, best first
+	Query    string
+	Cursor   int
+	Width    int
+	Height   int
+}
+
+// NewCommandPalette lists the commands available in the model's active view.
+func NewCommandPalette(m *Model) CommandPalette {
+	var cmds []Command
+	for _, c := range commands {
+		if c.Views != nil && !viewIn(m.activeView, c.Views) {
+			continue
+		}
+		if c.Enabled != nil && !c.Enabled(m) {
+			continue
+		}
+		cmds = append(cmds, c)
+	}
+	p := CommandPalette{Commands: cmds}
+	p.filter()
+	return p
+}
+
+func viewIn(v ViewType, views []ViewType) bool {
+	for _, w := range views {
+		if w == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Type appends text to the query.
+func (p *CommandPalette) Type(s string) {
+	p.Query += s
+	p.filter()
+}
+
+// Backspace removes the last character of the query.
+func (p *CommandPalette) Backspace() {
+	if p.Query == "" {
+		return
+	}
+	r := []rune(p.Query)
+	p.Query = string(r[:len(r)-1])
+	p.filter()
+}
+
+func (p *CommandPalette) filter() {
+	type scored struct {
+		cmd   Command
+		score int
+	}
+	var matches []scored
+	for _, c := range p.Commands {
+		if s, ok := fuzzyScore(p.Query, c.Label); ok {
+			matches = append(matches, scored{c, s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	p.Matches = p.Matches[:0]
+	for _, s := range matches {
+		p.Matches = append(p.Matches, s.cmd)
+	}
+	p.Cursor = 0
+}
+
+// fuzzyScore reports whether the characters of query appear in order in s,
+// ignoring case. Lower scores are better: a match at a word start beats one
+// inside a word, and tightly packed matches beat scattered ones.
+func fuzzyScore(query, s string) (int, bool) {
+	q := []rune(strings.ToLower(query))
+	if len(q) == 0 {
+		return 0, true
+	}
+	r := []rune(strings.ToLower(s))
+	score, qi, last := 0, 0, -1
+	for i := 0; i < len(r) && qi < len(q); i++ {
+		if r[i] != q[qi] {
+			continue
+		}
+		if last >= 0 {
+			score += i - last - 1
+		} else {
+			score += i
+		}
+		if i > 0 && r[i-1] != ' ' && r[i-1] != '/' && last != i-1 {
+			score += 5
+		}
+		last = i
+		qi++
+	}
+	return score, qi == len(q)
+}
+
+func (p *CommandPalette) MoveUp() {
+	if p.Cursor > 0 {
+		p.Cursor--
+	}
+}
+
+func (p *CommandPalette) MoveDown() {
+	if p.Cursor < len(p.Matches)-1 {
+		p.Cursor++
+	}
+}
+
+// Selected returns the highlighted command, if any command matches.
+func (p CommandPalette) Selected() (Command, bool) {
+	if p.Cursor < 0 || p.Cursor >= len(p.Matches) {
+		return Command{}, false
+	}
+	return p.Matches[p.Cursor], true
+}
+
+func (p CommandPalette) View() string {
+	titleStyle := lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	selectedStyle := lipgloss.NewStyle().Bold(true).Reverse(true).Padding(0, 1)
+	normalStyle := lipgloss.NewStyle().Padding(0, 1)
+	keyStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	boxStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(theme.Accent).
+		Padding(1, 2)
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render("> "+p.Query+"\u2588") + "\n\n")
+
+	labelWidth := 0
+	for _, c := range p.Commands {
+		labelWidth = max(labelWidth, len(c.Label))
+	}
+
+	// Keep the list inside the screen, scrolling with the cursor.
+	rows := len(p.Matches)
+	if p.Height > 0 {
+		rows = min(rows, max(p.Height-10, 3))
+	}
+	start := 0
+	if p.Cursor >= rows {
+		start = p.Cursor - rows + 1
+	}
+
+	if len(p.Matches) == 0 {
+		b.WriteString(normalStyle.Render("  No matching commands") + "\n")
+	}
+	for i := start; i < len(p.Matches) && i < start+rows; i++ {
+		c := p.Matches[i]
+		key := c.Key
+		if key == "" {
+			key = "-"
+		}
+		line := fmt.Sprintf("%-*s  %s", labelWidth, c.Label, keyStyle.Render(key))
+		if i == p.Cursor {
+			line = selectedStyle.Render(fmt.Sprintf("%-*s  %s", labelWidth, c.Label, key))
+		} else {
+			line = normalStyle.Render("  " + line)
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n" + normalStyle.Render("type to filter \u00b7 \u2191\u2193 navigate \u00b7 enter run \u00b7 esc cancel"))
+
+	return boxStyle.Render(b.String())
+}
@@ -0,0 +1,47 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestPaletteRunsSelectedCommand(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = next.(Model)
+	if !m.showPalette {
+		t.Fatal("ctrl+p did not open the palette")
+	}
+	for _, c := range m.palette.Commands {
+		if c.Enabled != nil && c.Label == "Close tab(s)" {
+			t.Errorf("offline palette offers the live-only %q", c.Label)
+		}
+	}
+	for _, r := range "display mode" {
+		next, _ = m.Update(key(string(r)))
+		m = next.(Model)
+	}
+	if v := m.View(); !strings.Contains(v, "Cycle display mode") {
+		t.Fatalf("View() = %q, want the matching command", v)
+	}
+	// Typed keys go to the query, not to the tabs view.
+	if m.tabsView.tree.DisplayMode != types.TabDisplayTitle {
+		t.Fatalf("DisplayMode = %v while typing, want it unchanged", m.tabsView.tree.DisplayMode)
+	}
+
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.showPalette {
+		t.Error("enter left the palette open")
+	}
+	if m.tabsView.tree.DisplayMode == types.TabDisplayTitle {
+		t.Error("enter did not run the selected command")
+	}
+}
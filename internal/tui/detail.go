@@ -6,7 +6,10 @@ import (
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/analyzer"
+	"github.com/lotas/tabsordnung/internal/github"
 	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/summarize"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
@@ -46,10 +49,11 @@ func (m DetailModel) ViewTab(tab *types.Tab) string {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	valueStyle := lipgloss.NewStyle()
-	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	staleWarnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	warnStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	staleWarnStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+	placeholderWarnStyle := lipgloss.NewStyle().Foreground(theme.Dim).Bold(true)
 
 	var b strings.Builder
 
@@ -69,21 +73,53 @@ func (m DetailModel) ViewTab(tab *types.Tab) string {
 	}
 	b.WriteString(valueStyle.Render(url) + "\n\n")
 
-	b.WriteString(labelStyle.Render("Last Visited") + "\n")
-	age := time.Since(tab.LastAccessed)
-	days := int(age.Hours() / 24)
-	var ageStr string
-	if days == 0 {
-		hours := int(age.Hours())
-		if hours == 0 {
-			ageStr = "just now"
+	if tab.Favicon != "" {
+		b.WriteString(labelStyle.Render("Favicon") + "\n")
+		fav := faviconLabel(tab.Favicon)
+		for len(fav) > m.Width-2 {
+			b.WriteString(valueStyle.Render(fav[:m.Width-2]) + "\n")
+			fav = fav[m.Width-2:]
+		}
+		b.WriteString(valueStyle.Render(fav) + "\n\n")
+	}
+
+	if !tab.ClosedAt.IsZero() {
+		b.WriteString(labelStyle.Render("Closed") + "\n")
+		b.WriteString(valueStyle.Render(tab.ClosedAt.Format("2006-01-02 15:04")+" ("+formatSignalAge(tab.ClosedAt)+")") + "\n\n")
+	}
+
+	if !tab.LastAccessed.IsZero() {
+		b.WriteString(labelStyle.Render("Last Visited") + "\n")
+		age := time.Since(tab.LastAccessed)
+		days := int(age.Hours() / 24)
+		var ageStr string
+		if days == 0 {
+			hours := int(age.Hours())
+			if hours == 0 {
+				ageStr = "just now"
+			} else {
+				ageStr = fmt.Sprintf("%d hours ago", hours)
+			}
 		} else {
-			ageStr = fmt.Sprintf("%d hours ago", hours)
+			ageStr = fmt.Sprintf("%d days ago", days)
 		}
-	} else {
-		ageStr = fmt.Sprintf("%d days ago", days)
+		b.WriteString(valueStyle.Render(ageStr) + "\n\n")
+	}
+
+	if tab.WordCount > 0 {
+		b.WriteString(labelStyle.Render("Reading Time") + "\n")
+		b.WriteString(valueStyle.Render(fmt.Sprintf("~%d min (%d words)", summarize.ReadingMinutes(tab.WordCount), tab.WordCount)) + "\n\n")
+	}
+
+	if tab.Profile != "" {
+		b.WriteString(labelStyle.Render("Profile") + "\n")
+		b.WriteString(valueStyle.Render(tab.Profile) + "\n\n")
+	}
+
+	if tab.Container != "" {
+		b.WriteString(labelStyle.Render("Container") + "\n")
+		b.WriteString(containerStyle().Render(tab.Container) + "\n\n")
 	}
-	b.WriteString(valueStyle.Render(ageStr) + "\n\n")
 
 	// Status section
 	var statuses []string
@@ -93,19 +129,58 @@ func (m DetailModel) ViewTab(tab *types.Tab) string {
 	if tab.IsStale {
 		statuses = append(statuses, staleWarnStyle.Render(fmt.Sprintf("Stale (%d days)", tab.StaleDays)))
 	}
+	if tab.IsPlaceholder {
+		statuses = append(statuses, placeholderWarnStyle.Render("Blank or stuck loading"))
+	}
 	if tab.IsDuplicate {
+		where := "in same group"
+		color := theme.Info
+		switch {
+		case tab.DuplicateInGroup && tab.DuplicateAcrossGroups:
+			where = "in same and other groups"
+		case tab.DuplicateAcrossGroups:
+			where = "across groups"
+			color = theme.InfoMuted
+		case tab.DuplicateAcrossProfiles:
+			where = "in another profile"
+			color = theme.InfoMuted
+		}
 		statuses = append(statuses, lipgloss.NewStyle().
-			Foreground(lipgloss.Color("33")).Bold(true).
-			Render(fmt.Sprintf("Duplicate (%d copies)", len(tab.DuplicateOf)+1)))
+			Foreground(color).Bold(true).
+			Render(fmt.Sprintf("Duplicate (%d copies, %s)", len(tab.DuplicateOf)+1, where)))
 	}
 	if tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged" {
 		statuses = append(statuses, lipgloss.NewStyle().
-			Foreground(lipgloss.Color("42")).Bold(true).
+			Foreground(theme.OK).Bold(true).
 			Render(fmt.Sprintf("GitHub: %s", tab.GitHubStatus)))
 	} else if tab.GitHubStatus == "open" {
 		statuses = append(statuses, lipgloss.NewStyle().
-			Foreground(lipgloss.Color("135")).Bold(true).
+			Foreground(theme.Open).Bold(true).
 			Render("GitHub: open"))
+		if info := tab.GitHubTriage; info != nil {
+			if info.ChecksStatus != "" {
+				statuses = append(statuses, "Checks: "+info.ChecksStatus)
+			}
+			if info.ReviewRequested {
+				statuses = append(statuses, "Review requested from you")
+			}
+			if info.Assigned {
+				statuses = append(statuses, "Assigned to you")
+			}
+		}
+	} else if tab.GitHubStatus == types.GitHubNotFound {
+		statuses = append(statuses, lipgloss.NewStyle().
+			Foreground(theme.Error).Bold(true).
+			Render("GitHub: not found (deleted, renamed or private)"))
+	} else if tab.GitHubStatus == types.GitHubNoAccess {
+		statuses = append(statuses, lipgloss.NewStyle().
+			Foreground(theme.Error).Bold(true).
+			Render("GitHub: no access"))
+	} else if kind := github.ClassifyURL(tab.URL); kind == "commit" || kind == "gist" {
+		// Commits and gists have no state to track.
+		statuses = append(statuses, lipgloss.NewStyle().
+			Foreground(theme.Dim).
+			Render("GitHub "+kind))
 	}
 
 	if len(statuses) > 0 {
@@ -118,16 +193,34 @@ func (m DetailModel) ViewTab(tab *types.Tab) string {
 	return b.String()
 }
 
-// ViewTabWithSummary renders tab info with optional summary content.
-func (m *DetailModel) ViewTabWithSummary(tab *types.Tab, summary string, summarizing bool, summarizeErr string) string {
+// faviconLabel describes a favicon for the detail pane: its URL, or the
+// media type and size of an inline data: URI rather than its contents.
+func faviconLabel(fav string) string {
+	rest, ok := strings.CutPrefix(fav, "data:")
+	if !ok {
+		return fav
+	}
+	mediaType, data, _ := strings.Cut(rest, ",")
+	mediaType, _, _ = strings.Cut(mediaType, ";")
+	if mediaType == "" {
+		mediaType = "data"
+	}
+	return fmt.Sprintf("inline %s (%d bytes)", mediaType, len(data))
+}
+
+// ViewTabWithSummary renders tab info with optional summary content. Tabs
+// without a summary show the page's Open Graph preview, if known.
+func (m *DetailModel) ViewTabWithSummary(tab *types.Tab, summary string, summarizing bool, summarizeErr string, preview *summarize.OpenGraph) string {
 	base := m.ViewTab(tab)
 
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
-	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
-	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	activeStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Error)
 
-	if summarizing {
+	if summarizing && summary != "" {
+		base += "\n" + activeStyle.Render("Summarizing...") + "\n" + summary
+	} else if summarizing {
 		base += "\n" + activeStyle.Render("Summarizing... (fetching & processing)")
 	} else if summary != "" {
 		base += "\n" + labelStyle.Render("Summary") + "\n" + summary
@@ -136,7 +229,61 @@ func (m *DetailModel) ViewTabWithSummary(tab *types.Tab, summary string, summari
 		base += "\n" + errStyle.Render("Summarize failed: "+summarizeErr)
 		base += "\n" + dimStyle.Render("  Press 's' to retry")
 	} else {
+		if preview != nil {
+			base += "\n" + m.viewPreview(preview)
+		}
 		base += "\n" + dimStyle.Render("  Press 's' to summarize")
+		if preview == nil {
+			base += "\n" + dimStyle.Render("  Press 'i' to fetch a preview")
+		}
+	}
+
+	return base
+}
+
+// viewPreview renders a page's Open Graph preview.
+func (m *DetailModel) viewPreview(og *summarize.OpenGraph) string {
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	textStyle := lipgloss.NewStyle().Width(m.Width - 2)
+
+	var b strings.Builder
+	label := "Preview"
+	if og.SiteName != "" {
+		label += " · " + og.SiteName
+	}
+	b.WriteString(labelStyle.Render(label) + "\n")
+	if og.Title != "" {
+		b.WriteString(textStyle.Bold(true).Render(og.Title) + "\n")
+	}
+	if og.Description != "" {
+		b.WriteString(textStyle.Render(og.Description) + "\n")
+	}
+	if og.Image != "" {
+		b.WriteString(dimStyle.Render("Image: "+og.Image) + "\n")
+	}
+	return b.String()
+}
+
+// ViewGroupWithSummary renders group info with its whole-group summary.
+func (m *DetailModel) ViewGroupWithSummary(group *types.TabGroup, summary string, summarizing bool, summarizeErr string) string {
+	base := m.ViewGroup(group)
+
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	activeStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Error)
+
+	if summarizing {
+		base += "\n" + activeStyle.Render(fmt.Sprintf("Summarizing group... (fetching %d tabs)", len(group.Tabs)))
+	} else if summary != "" {
+		base += "\n" + labelStyle.Render("Group summary") + "\n" + summary
+		base += "\n" + dimStyle.Render("  Press 'S' to re-summarize the group")
+	} else if summarizeErr != "" {
+		base += "\n" + errStyle.Render("Summarize failed: "+summarizeErr)
+		base += "\n" + dimStyle.Render("  Press 'S' to retry")
+	} else {
+		base += "\n" + dimStyle.Render("  Press 'S' to summarize the whole group")
 	}
 
 	return base
@@ -146,16 +293,16 @@ func (m *DetailModel) ViewTabWithSummary(tab *types.Tab, summary string, summari
 func (m *DetailModel) ViewTabWithSignal(tab *types.Tab, signals []storage.SignalRecord, signalCursor int, capturing bool, signalErr string) string {
 	base := m.ViewTab(tab)
 
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
-	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
-	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-	completedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	cursorStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62"))
-	urgentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	reviewStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	fyiStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	unclassifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	activeStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+	errStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	completedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	cursorStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).Reverse(colorDisabled)
+	urgentStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	reviewStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	fyiStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	unclassifiedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	if capturing {
 		base += "\n" + activeStyle.Render("Capturing signal...")
@@ -269,12 +416,40 @@ func (m *DetailModel) ViewScrolled(content string) string {
 	return strings.Join(lines[m.Scroll:end], "\n")
 }
 
+func (m DetailModel) ViewWindow(w *WindowNode) string {
+	if w == nil {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	valueStyle := lipgloss.NewStyle()
+
+	var b strings.Builder
+
+	b.WriteString(labelStyle.Render("Window") + "\n")
+	b.WriteString(valueStyle.Render(w.Label) + "\n\n")
+
+	tabs := 0
+	for _, g := range w.Groups {
+		tabs += len(g.Tabs)
+	}
+	b.WriteString(labelStyle.Render("Tabs") + "\n")
+	b.WriteString(valueStyle.Render(fmt.Sprintf("%d", tabs)) + "\n\n")
+
+	b.WriteString(labelStyle.Render("Groups") + "\n")
+	for _, g := range w.Groups {
+		b.WriteString(valueStyle.Render(fmt.Sprintf("%s (%d tabs)", g.Name, len(g.Tabs))) + "\n")
+	}
+
+	return b.String()
+}
+
 func (m DetailModel) ViewGroup(group *types.TabGroup) string {
 	if group == nil {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	valueStyle := lipgloss.NewStyle()
 
 	var b strings.Builder
@@ -309,6 +484,16 @@ func (m DetailModel) ViewGroup(group *types.TabGroup) string {
 		}
 	}
 
+	if len(group.Tabs) > 0 {
+		b.WriteString("\n" + labelStyle.Render("Last accessed") + "\n")
+		hist := analyzer.AgeHistogram(group.Tabs)
+		for _, bucket := range analyzer.AgeBuckets {
+			n := hist[bucket]
+			bar := strings.Repeat("\u2588", (n*20+len(group.Tabs)-1)/len(group.Tabs))
+			b.WriteString(fmt.Sprintf("  %-7s %4d %s\n", bucket, n, bar))
+		}
+	}
+
 	if stale+dead+dup > 0 {
 		b.WriteString("\n" + labelStyle.Render("Issues") + "\n")
 		if dead > 0 {
@@ -8,8 +8,9 @@ import (
 )
 
 type FilterOption struct {
-	Label string
-	Mode  types.FilterMode
+	Label     string
+	Mode      types.FilterMode
+	Container string // for FilterContainer
 }
 
 type FilterPicker struct {
@@ -19,22 +20,31 @@ type FilterPicker struct {
 	Height  int
 }
 
-func NewFilterPicker(current types.FilterMode) FilterPicker {
+// NewFilterPicker lists the fixed filters plus one entry per container in
+// containers. currentContainer is the active container filter, if any.
+func NewFilterPicker(current types.FilterMode, currentContainer string, containers []string) FilterPicker {
 	options := []FilterOption{
-		{"All tabs", types.FilterAll},
-		{"Stale", types.FilterStale},
-		{"Dead links", types.FilterDead},
-		{"Duplicates", types.FilterDuplicate},
-		{"Older than 7 days", types.FilterAge7},
-		{"Older than 30 days", types.FilterAge30},
-		{"Older than 90 days", types.FilterAge90},
-		{"GitHub done", types.FilterGitHubDone},
-		{"Has summary", types.FilterHasSummary},
-		{"No summary", types.FilterNoSummary},
+		{Label: "All tabs", Mode: types.FilterAll},
+		{Label: "Stale", Mode: types.FilterStale},
+		{Label: "Dead links", Mode: types.FilterDead},
+		{Label: "Duplicates", Mode: types.FilterDuplicate},
+		{Label: "Duplicates in same group", Mode: types.FilterDuplicateInGroup},
+		{Label: "Blank / stuck loading", Mode: types.FilterPlaceholder},
+		{Label: "Pinned", Mode: types.FilterPinned},
+		{Label: "Older than 7 days", Mode: types.FilterAge7},
+		{Label: "Older than 30 days", Mode: types.FilterAge30},
+		{Label: "Older than 90 days", Mode: types.FilterAge90},
+		{Label: "GitHub done", Mode: types.FilterGitHubDone},
+		{Label: "GitHub waiting on me", Mode: types.FilterGitHubWaiting},
+		{Label: "Has summary", Mode: types.FilterHasSummary},
+		{Label: "No summary", Mode: types.FilterNoSummary},
+	}
+	for _, c := range containers {
+		options = append(options, FilterOption{Label: "Container: " + c, Mode: types.FilterContainer, Container: c})
 	}
 	cursor := 0
 	for i, opt := range options {
-		if opt.Mode == current {
+		if opt.Mode == current && opt.Container == currentContainer {
 			cursor = i
 			break
 		}
@@ -64,7 +74,7 @@ func (m FilterPicker) View() string {
 	normalStyle := lipgloss.NewStyle().Padding(0, 1)
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
+		BorderForeground(theme.Accent).
 		Padding(1, 2)
 
 	var b strings.Builder
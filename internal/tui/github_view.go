@@ -1,14 +1,15 @@
 package tui
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
-	"os/exec"
-	"runtime"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/analyzer"
+	"github.com/lotas/tabsordnung/internal/applog"
 	"github.com/lotas/tabsordnung/internal/github"
 	"github.com/lotas/tabsordnung/internal/storage"
 )
@@ -20,7 +21,12 @@ type githubViewLoadedMsg struct {
 	err      error
 }
 
-type githubRefreshDoneMsg struct{ err error }
+// githubRefreshDoneMsg reports a finished refresh; status is shown in the
+// bottom bar ("Refreshed 3 of 120 (rest fresh)").
+type githubRefreshDoneMsg struct {
+	status string
+	err    error
+}
 
 // --- Node type for tree/flat rendering ---
 
@@ -48,7 +54,8 @@ type GitHubView struct {
 	treeMode      bool
 	stateExpanded map[string]bool // "open", "merged", "closed"
 	focusDetail   bool
-	filter        string // "", "open", "closed", "pull", "issue"
+	filter        string // "", "open", "closed", "pull", "issue", "discussion"
+	status        string // outcome of the last refresh
 }
 
 func NewGitHubView(db *sql.DB) GitHubView {
@@ -102,6 +109,10 @@ func (v *GitHubView) buildNodes() {
 				if e.Kind != "issue" {
 					continue
 				}
+			case "discussion":
+				if e.Kind != "discussion" {
+					continue
+				}
 			}
 		}
 		filtered = append(filtered, e)
@@ -199,6 +210,9 @@ func (v GitHubView) Update(msg tea.Msg) (GitHubView, tea.Cmd) {
 		if msg.err != nil {
 			v.err = msg.err
 		}
+		if msg.status != "" {
+			v.status = msg.status
+		}
 		// Reload from DB after refresh
 		return v, v.Reload()
 
@@ -317,6 +331,8 @@ func (v GitHubView) Update(msg tea.Msg) (GitHubView, tea.Cmd) {
 			case "pull":
 				v.filter = "issue"
 			case "issue":
+				v.filter = "discussion"
+			case "discussion":
 				v.filter = ""
 			}
 			v.buildNodes()
@@ -332,7 +348,9 @@ func (v GitHubView) Update(msg tea.Msg) (GitHubView, tea.Cmd) {
 				return v, openGitHubInBrowser(e)
 			}
 		case "r":
-			return v, v.forceRefresh()
+			return v, v.refresh(false)
+		case "R":
+			return v, v.refresh(true)
 		case "tab":
 			v.focusDetail = true
 		}
@@ -370,12 +388,12 @@ func (v GitHubView) ViewList() string {
 	treeWidth := v.width * TreeWidthPct / 100
 	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
 	headerStyle := lipgloss.NewStyle().Bold(true)
-	openStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))
-	mergedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("135"))
-	closedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	ciFailStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-	ciPendingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	filterStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+	openStyle := lipgloss.NewStyle().Foreground(theme.OK)
+	mergedStyle := lipgloss.NewStyle().Foreground(theme.Open)
+	closedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	ciFailStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	ciPendingStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	filterStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
 
 	var b strings.Builder
 
@@ -478,12 +496,12 @@ func (v GitHubView) ViewDetail() string {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	valueStyle := lipgloss.NewStyle()
-	openStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
-	mergedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("135")).Bold(true)
-	closedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	openStyle := lipgloss.NewStyle().Foreground(theme.OK).Bold(true)
+	mergedStyle := lipgloss.NewStyle().Foreground(theme.Open).Bold(true)
+	closedStyle := lipgloss.NewStyle().Foreground(theme.Dim).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 	headerBoldStyle := lipgloss.NewStyle().Bold(true)
 
 	var b strings.Builder
@@ -516,8 +534,11 @@ func (v GitHubView) ViewDetail() string {
 	// Type
 	b.WriteString(labelStyle.Render("Type") + "\n")
 	kindLabel := "Issue"
-	if e.Kind == "pull" {
+	switch e.Kind {
+	case "pull":
 		kindLabel = "Pull Request"
+	case "discussion":
+		kindLabel = "Discussion"
 	}
 	b.WriteString(valueStyle.Render(kindLabel) + "\n\n")
 
@@ -562,8 +583,25 @@ func (v GitHubView) ViewDetail() string {
 		b.WriteString(valueStyle.Render(e.GHUpdatedAt.Local().Format("2006-01-02 15:04")) + "\n\n")
 	}
 
-	// Timeline
 	if v.db != nil {
+		// Linked bugs
+		linked, err := storage.ListLinkedBugzillaEntities(v.db, e.ID)
+		if err == nil && len(linked) > 0 {
+			b.WriteString(labelStyle.Render("Linked Bugs") + "\n")
+			for _, bug := range linked {
+				line := fmt.Sprintf("%s#%d", bug.Host, bug.BugID)
+				if bug.Status != "" {
+					line += " [" + bug.Status + "]"
+				}
+				if bug.Title != "" {
+					line += " " + bug.Title
+				}
+				b.WriteString(valueStyle.Render(line) + "\n")
+			}
+			b.WriteString("\n")
+		}
+
+		// Timeline
 		events, err := storage.ListGitHubEntityEvents(v.db, e.ID)
 		if err == nil && len(events) > 0 {
 			b.WriteString(labelStyle.Render("Timeline") + "\n")
@@ -590,40 +628,36 @@ func (v GitHubView) FocusDetail() bool { return v.focusDetail }
 
 func openGitHubInBrowser(e *storage.GitHubEntity) tea.Cmd {
 	return func() tea.Msg {
-		url := fmt.Sprintf("https://github.com/%s/%s/", e.Owner, e.Repo)
-		if e.Kind == "pull" {
-			url += fmt.Sprintf("pull/%d", e.Number)
-		} else {
-			url += fmt.Sprintf("issues/%d", e.Number)
-		}
-		var cmd *exec.Cmd
-		switch runtime.GOOS {
-		case "darwin":
-			cmd = exec.Command("open", url)
-		case "linux":
-			cmd = exec.Command("xdg-open", url)
-		default:
-			cmd = exec.Command("open", url)
+		url := fmt.Sprintf("https://github.com/%s/%s/%s/%d", e.Owner, e.Repo, github.KindPath(e.Kind), e.Number)
+		if err := openURL(url); err != nil {
+			applog.Error("tui.open", err, "url", url)
+			return toastMsg{text: "Open failed: " + err.Error()}
 		}
-		_ = cmd.Start()
 		return nil
 	}
 }
 
-func resolveGHToken() string {
-	out, err := exec.Command("gh", "auth", "token").Output()
-	if err != nil {
-		return ""
-	}
-	return strings.TrimSpace(string(out))
-}
-
-func (v *GitHubView) forceRefresh() tea.Cmd {
+// refresh queries GitHub for the view's entities, skipping those refreshed
+// within github.RefreshSince unless force is set.
+func (v *GitHubView) refresh(force bool) tea.Cmd {
 	db := v.db
 	entities := v.entities
+	v.status = "Refreshing..."
 	return func() tea.Msg {
-		token := resolveGHToken()
-		err := github.RefreshEntities(db, entities, token, true)
-		return githubRefreshDoneMsg{err: err}
+		token := analyzer.ResolveGitHubToken()
+		if token == "" {
+			return githubRefreshDoneMsg{status: "No GitHub token (run gh auth login)"}
+		}
+		n, err := github.RefreshEntities(context.Background(), db, entities, token, force)
+		return githubRefreshDoneMsg{status: github.RefreshStatus(n, len(entities)), err: err}
+	}
+}
+
+// BottomBar returns the key hints, preceded by the last refresh outcome.
+func (v GitHubView) BottomBar() string {
+	s := "\u2191\u2193/jk navigate \u00b7 \u21b5 detail \u00b7 tab focus \u00b7 t tree \u00b7 f filter \u00b7 r refresh stale \u00b7 R refresh all \u00b7 o browser \u00b7 1-8 view \u00b7 q quit"
+	if v.status != "" {
+		s = v.status + " \u00b7 " + s
 	}
+	return s
 }
@@ -0,0 +1,492 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/gitlab"
+	"github.com/lotas/tabsordnung/internal/storage"
+)
+
+type gitlabViewLoadedMsg struct {
+	entities []storage.GitLabEntity
+	err      error
+}
+
+type gitlabRefreshDoneMsg struct{ err error }
+
+type gitlabNode struct {
+	IsHeader bool
+	Header   string
+	Entity   *storage.GitLabEntity
+	Group    string // state bucket key used for tree expand/collapse
+}
+
+// GitLabView lists tracked GitLab merge requests and issues.
+type GitLabView struct {
+	db       *sql.DB
+	entities []storage.GitLabEntity
+	nodes    []gitlabNode
+	cursor   int
+	offset   int
+	detail   DetailModel
+	width    int
+	height   int
+	loading  bool
+	err      error
+
+	treeMode           bool
+	groupExpanded      map[string]bool
+	focusDetail        bool
+	filter             string // project path, or "" for all
+	discoveredProjects []string
+}
+
+func NewGitLabView(db *sql.DB) GitLabView {
+	return GitLabView{
+		db:            db,
+		groupExpanded: map[string]bool{},
+	}
+}
+
+func (v *GitLabView) Reload() tea.Cmd {
+	v.loading = true
+	db := v.db
+	return func() tea.Msg {
+		entities, err := storage.ListGitLabEntities(db)
+		return gitlabViewLoadedMsg{entities: entities, err: err}
+	}
+}
+
+func (v *GitLabView) forceRefresh() tea.Cmd {
+	db := v.db
+	entities := v.entities
+	return func() tea.Msg {
+		err := gitlab.RefreshEntities(db, entities, true)
+		return gitlabRefreshDoneMsg{err: err}
+	}
+}
+
+func (v *GitLabView) SetSize(w, h int) {
+	v.width = w
+	v.height = h
+	v.detail.Width = w - (w * TreeWidthPct / 100) - 4
+	v.detail.Height = h
+}
+
+// gitlabStateBucket maps a GitLab state to a display group. Entities that
+// were never refreshed count as open.
+func gitlabStateBucket(state string) string {
+	switch state {
+	case "merged":
+		return "merged"
+	case "closed", "locked":
+		return "closed"
+	default:
+		return "open"
+	}
+}
+
+var gitlabStateOrder = []string{"open", "merged", "closed"}
+
+var gitlabStateLabels = map[string]string{
+	"open":   "Open",
+	"merged": "Merged",
+	"closed": "Closed",
+}
+
+func (v *GitLabView) buildNodes() {
+	v.nodes = nil
+
+	// Track discovered projects for filter cycling.
+	projectSeen := make(map[string]bool)
+	for _, e := range v.entities {
+		projectSeen[e.Project] = true
+	}
+	v.discoveredProjects = v.discoveredProjects[:0]
+	for p := range projectSeen {
+		v.discoveredProjects = append(v.discoveredProjects, p)
+	}
+	sort.Strings(v.discoveredProjects)
+
+	var filtered []storage.GitLabEntity
+	for _, e := range v.entities {
+		if v.filter != "" && e.Project != v.filter {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	if !v.treeMode {
+		for i := range filtered {
+			v.nodes = append(v.nodes, gitlabNode{Entity: &filtered[i]})
+		}
+		return
+	}
+
+	// Tree mode: group by state bucket.
+	buckets := make(map[string][]*storage.GitLabEntity)
+	for i := range filtered {
+		e := &filtered[i]
+		bucket := gitlabStateBucket(e.State)
+		buckets[bucket] = append(buckets[bucket], e)
+	}
+
+	for _, key := range gitlabStateOrder {
+		list := buckets[key]
+		if len(list) == 0 {
+			continue
+		}
+		if _, ok := v.groupExpanded[key]; !ok {
+			v.groupExpanded[key] = true
+		}
+		icon := "▸"
+		if v.groupExpanded[key] {
+			icon = "▼"
+		}
+		v.nodes = append(v.nodes, gitlabNode{
+			IsHeader: true,
+			Header:   fmt.Sprintf("%s %s (%d)", icon, gitlabStateLabels[key], len(list)),
+			Group:    key,
+		})
+		if v.groupExpanded[key] {
+			for _, e := range list {
+				v.nodes = append(v.nodes, gitlabNode{Entity: e, Group: key})
+			}
+		}
+	}
+}
+
+func (v *GitLabView) selectedEntity() *storage.GitLabEntity {
+	if v.cursor >= 0 && v.cursor < len(v.nodes) {
+		return v.nodes[v.cursor].Entity
+	}
+	return nil
+}
+
+func (v GitLabView) Update(msg tea.Msg) (GitLabView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case gitlabViewLoadedMsg:
+		v.loading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.err = nil
+		v.entities = msg.entities
+		v.buildNodes()
+		v.clampCursor()
+		return v, nil
+
+	case gitlabRefreshDoneMsg:
+		if msg.err != nil {
+			v.err = msg.err
+		}
+		return v, v.Reload()
+
+	case tea.MouseMsg:
+		treeWidth := v.width * TreeWidthPct / 100
+		onDetail := msg.X > treeWidth+1
+		switch msg.Button {
+		case tea.MouseButtonLeft:
+			v.focusDetail = onDetail
+		case tea.MouseButtonWheelUp:
+			if onDetail {
+				v.detail.ScrollUp()
+			} else if v.cursor > 0 {
+				v.cursor--
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case tea.MouseButtonWheelDown:
+			if onDetail {
+				v.detail.ScrollDown()
+			} else if v.cursor < len(v.nodes)-1 {
+				v.cursor++
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.focusDetail {
+			switch msg.String() {
+			case "esc":
+				v.focusDetail = false
+				v.detail.Scroll = 0
+			case "j", "down":
+				v.detail.ScrollDown()
+			case "k", "up":
+				v.detail.ScrollUp()
+			}
+			return v, nil
+		}
+
+		switch msg.String() {
+		case "j", "down":
+			if v.cursor < len(v.nodes)-1 {
+				v.cursor++
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case "k", "up":
+			if v.cursor > 0 {
+				v.cursor--
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case "h":
+			if v.cursor >= 0 && v.cursor < len(v.nodes) {
+				node := v.nodes[v.cursor]
+				if node.IsHeader {
+					v.groupExpanded[node.Group] = false
+					v.buildNodes()
+				} else {
+					for i := v.cursor - 1; i >= 0; i-- {
+						if v.nodes[i].IsHeader {
+							v.cursor = i
+							v.adjustOffset()
+							break
+						}
+					}
+				}
+			}
+		case "l":
+			if v.cursor >= 0 && v.cursor < len(v.nodes) {
+				node := v.nodes[v.cursor]
+				if node.IsHeader && !v.groupExpanded[node.Group] {
+					v.groupExpanded[node.Group] = true
+					v.buildNodes()
+				} else if v.cursor < len(v.nodes)-1 {
+					v.cursor++
+					v.adjustOffset()
+					v.detail.Scroll = 0
+				}
+			}
+		case "enter", " ":
+			if v.cursor >= 0 && v.cursor < len(v.nodes) && v.nodes[v.cursor].IsHeader {
+				node := v.nodes[v.cursor]
+				v.groupExpanded[node.Group] = !v.groupExpanded[node.Group]
+				v.buildNodes()
+			} else if v.selectedEntity() != nil {
+				v.focusDetail = true
+			}
+		case "tab":
+			v.focusDetail = true
+		case "t":
+			v.treeMode = !v.treeMode
+			v.buildNodes()
+			v.clampCursor()
+		case "f":
+			// Cycle filter through known projects + none.
+			next := ""
+			if v.filter == "" {
+				if len(v.discoveredProjects) > 0 {
+					next = v.discoveredProjects[0]
+				}
+			} else {
+				for i, p := range v.discoveredProjects {
+					if p == v.filter && i+1 < len(v.discoveredProjects) {
+						next = v.discoveredProjects[i+1]
+					}
+				}
+			}
+			v.filter = next
+			v.buildNodes()
+			v.clampCursor()
+		case "o":
+			if e := v.selectedEntity(); e != nil {
+				return v, openTabInBrowser(e.URL())
+			}
+		case "r":
+			return v, v.forceRefresh()
+		}
+	}
+	return v, nil
+}
+
+func (v *GitLabView) clampCursor() {
+	if v.cursor >= len(v.nodes) {
+		v.cursor = len(v.nodes) - 1
+	}
+	if v.cursor < 0 {
+		v.cursor = 0
+	}
+}
+
+func (v *GitLabView) adjustOffset() {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	visible := v.height - 2
+	if visible < 1 {
+		visible = 1
+	}
+	if v.cursor >= v.offset+visible {
+		v.offset = v.cursor - visible + 1
+	}
+}
+
+func (v GitLabView) ViewList() string {
+	if v.loading {
+		return "Loading GitLab merge requests and issues..."
+	}
+	if v.err != nil {
+		return fmt.Sprintf("Error: %v", v.err)
+	}
+	if len(v.nodes) == 0 {
+		if v.filter != "" {
+			return fmt.Sprintf("No GitLab entities matching filter: %s", v.filter)
+		}
+		return "No GitLab merge requests or issues yet.\n\n  GitLab links are auto-detected\n  from tabs and signals."
+	}
+
+	treeWidth := v.width * TreeWidthPct / 100
+	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	idStyle := lipgloss.NewStyle().Foreground(theme.Ref)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	filterStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+
+	var b strings.Builder
+	if v.filter != "" {
+		b.WriteString(filterStyle.Render(fmt.Sprintf("  Filter: %s", v.filter)) + "\n")
+	}
+
+	end := v.offset + v.height
+	if v.filter != "" {
+		end--
+	}
+	if end > len(v.nodes) {
+		end = len(v.nodes)
+	}
+
+	for i := v.offset; i < end; i++ {
+		node := v.nodes[i]
+		var line string
+		if node.IsHeader {
+			line = headerStyle.Render(node.Header)
+		} else {
+			e := node.Entity
+			indent := "  "
+			if v.treeMode {
+				indent = "    "
+			}
+			ref := e.Ref()
+			// In tree mode, state is implied by the group header.
+			stateStr := ""
+			stateLen := 0
+			if !v.treeMode && e.State != "" {
+				stateLen = len(" [" + e.State + "]")
+				if gitlabStateBucket(e.State) == "open" {
+					stateStr = " " + idStyle.Render("["+e.State+"]")
+				} else {
+					stateStr = " " + dimStyle.Render("["+e.State+"]")
+				}
+			}
+			titleStr := ""
+			if e.Title != "" {
+				// indent(2-4) + "● "(2) + ref + "  " + title + state must fit treeWidth
+				maxTitle := treeWidth - len(indent) - 2 - len(ref) - 2 - stateLen
+				if maxTitle > 0 {
+					titleStr = "  " + truncateString(e.Title, maxTitle)
+				}
+			}
+			line = indent + idStyle.Render("●") + " " + idStyle.Render(ref) + titleStr + stateStr
+		}
+
+		if i == v.cursor {
+			for lipgloss.Width(line) < treeWidth {
+				line += " "
+			}
+			line = cursorStyle.Render(line)
+		}
+
+		b.WriteString(line)
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (v GitLabView) ViewDetail() string {
+	e := v.selectedEntity()
+	if e == nil {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	valueStyle := lipgloss.NewStyle()
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	headerBoldStyle := lipgloss.NewStyle().Bold(true)
+
+	var b strings.Builder
+	b.WriteString(headerBoldStyle.Render(e.Host+"/"+e.Ref()) + "\n\n")
+
+	if e.Title != "" {
+		b.WriteString(labelStyle.Render("Title") + "\n")
+		b.WriteString(valueStyle.Render(e.Title) + "\n\n")
+	}
+
+	b.WriteString(labelStyle.Render("URL") + "\n")
+	b.WriteString(valueStyle.Render(e.URL()) + "\n\n")
+
+	b.WriteString(labelStyle.Render("Type") + "\n")
+	kindLabel := "Issue"
+	if e.Kind == "merge_request" {
+		kindLabel = "Merge Request"
+	}
+	b.WriteString(valueStyle.Render(kindLabel) + "\n\n")
+
+	if e.State != "" {
+		b.WriteString(labelStyle.Render("State") + "\n")
+		b.WriteString(valueStyle.Render(e.State) + "\n\n")
+	} else if e.LastRefreshedAt == nil {
+		b.WriteString(labelStyle.Render("State") + "\n")
+		b.WriteString(dimStyle.Render("Not fetched yet. Private projects need "+gitlab.TokenEnv+".") + "\n\n")
+	}
+
+	if e.Author != "" {
+		b.WriteString(labelStyle.Render("Author") + "\n")
+		b.WriteString(valueStyle.Render(e.Author) + "\n\n")
+	}
+
+	if e.Assignees != "" {
+		b.WriteString(labelStyle.Render("Assignees") + "\n")
+		b.WriteString(valueStyle.Render(strings.ReplaceAll(e.Assignees, ",", ", ")) + "\n\n")
+	}
+
+	b.WriteString(labelStyle.Render("First Seen") + "\n")
+	b.WriteString(valueStyle.Render(e.FirstSeenAt.Local().Format("2006-01-02 15:04")) + "\n")
+	b.WriteString(dimStyle.Render("Source: "+e.FirstSeenSource) + "\n\n")
+
+	if e.LastRefreshedAt != nil {
+		b.WriteString(labelStyle.Render("Last Refreshed") + "\n")
+		b.WriteString(valueStyle.Render(e.LastRefreshedAt.Local().Format("2006-01-02 15:04")) + "\n\n")
+	}
+
+	if v.db != nil {
+		events, err := storage.ListGitLabEntityEvents(v.db, e.ID)
+		if err == nil && len(events) > 0 {
+			b.WriteString(labelStyle.Render("Timeline") + "\n")
+			for _, ev := range events {
+				ts := ev.CreatedAt.Local().Format("2006-01-02 15:04")
+				detail := ev.EventType
+				if ev.Detail != "" {
+					detail += ": " + ev.Detail
+				}
+				b.WriteString(dimStyle.Render(ts+" "+detail) + "\n")
+			}
+		}
+	}
+
+	return v.detail.ViewScrolled(b.String())
+}
+
+func (v GitLabView) FocusDetail() bool { return v.focusDetail }
@@ -0,0 +1,51 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestGitLabViewGroupsAndFilters(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	mr, _, err := storage.UpsertGitLabEntity(m.db, "gitlab.com", "group/app", 7, "merge_request", "tab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.UpdateGitLabEntityStatus(m.db, mr, storage.GitLabStatusUpdate{Title: "Add login", State: "merged"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := storage.UpsertGitLabEntity(m.db, "gitlab.com", "group/docs", 3, "issue", "tab"); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+	next, _ = m.Update(key("8"))
+	m = next.(Model)
+	if m.activeView != ViewGitLab {
+		t.Fatalf("activeView = %v, want the GitLab view", m.activeView)
+	}
+	next, _ = m.Update(m.gitlabView.Reload()())
+	m = next.(Model)
+	if v := m.View(); !strings.Contains(v, "group/app!7") || !strings.Contains(v, "group/docs#3") {
+		t.Fatalf("View() = %q, want both entities", v)
+	}
+
+	next, _ = m.Update(key("t"))
+	m = next.(Model)
+	list := m.gitlabView.ViewList()
+	if !strings.Contains(list, "Open (1)") || !strings.Contains(list, "Merged (1)") {
+		t.Errorf("tree mode = %q, want Open and Merged groups", list)
+	}
+
+	next, _ = m.Update(key("f"))
+	m = next.(Model)
+	list = m.gitlabView.ViewList()
+	if !strings.Contains(list, "group/app!7") || strings.Contains(list, "group/docs#3") {
+		t.Errorf("filtered to %q: %q, want only its merge request", m.gitlabView.filter, list)
+	}
+}
@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
@@ -13,12 +14,23 @@ type GroupPicker struct {
 	Cursor int
 	Width  int
 	Height int
+
+	// Jump pickers navigate to a group instead of moving tabs into it;
+	// typing narrows Groups to the fuzzy matches of Query among all.
+	Jump  bool
+	Query string
+	all   []*types.TabGroup
 }
 
 func NewGroupPicker(groups []*types.TabGroup) GroupPicker {
 	return GroupPicker{Groups: groups}
 }
 
+// NewJumpPicker returns a picker for jumping to one of groups.
+func NewJumpPicker(groups []*types.TabGroup) GroupPicker {
+	return GroupPicker{Groups: groups, Jump: true, all: groups}
+}
+
 func (m *GroupPicker) MoveUp() {
 	if m.Cursor > 0 {
 		m.Cursor--
@@ -31,6 +43,42 @@ func (m *GroupPicker) MoveDown() {
 	}
 }
 
+// Type appends text to the query.
+func (m *GroupPicker) Type(s string) {
+	m.Query += s
+	m.filter()
+}
+
+// Backspace removes the last character of the query.
+func (m *GroupPicker) Backspace() {
+	if m.Query == "" {
+		return
+	}
+	r := []rune(m.Query)
+	m.Query = string(r[:len(r)-1])
+	m.filter()
+}
+
+// filter narrows Groups to the groups whose names match Query, best first.
+func (m *GroupPicker) filter() {
+	type scored struct {
+		group *types.TabGroup
+		score int
+	}
+	var matches []scored
+	for _, g := range m.all {
+		if s, ok := fuzzyScore(m.Query, g.Name); ok {
+			matches = append(matches, scored{g, s})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score < matches[j].score })
+	m.Groups = nil
+	for _, s := range matches {
+		m.Groups = append(m.Groups, s.group)
+	}
+	m.Cursor = 0
+}
+
 func (m GroupPicker) Selected() *types.TabGroup {
 	if m.Cursor >= 0 && m.Cursor < len(m.Groups) {
 		return m.Groups[m.Cursor]
@@ -44,11 +92,15 @@ func (m GroupPicker) View() string {
 	normalStyle := lipgloss.NewStyle().Padding(0, 1)
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
+		BorderForeground(theme.Accent).
 		Padding(1, 2)
 
 	var b strings.Builder
-	b.WriteString(titleStyle.Render("Move to group:") + "\n\n")
+	if m.Jump {
+		b.WriteString(titleStyle.Render("Jump to group: "+m.Query+"_") + "\n\n")
+	} else {
+		b.WriteString(titleStyle.Render("Move to group:") + "\n\n")
+	}
 
 	for i, g := range m.Groups {
 		label := fmt.Sprintf("%s (%d tabs)", g.Name, len(g.Tabs))
@@ -59,8 +111,15 @@ func (m GroupPicker) View() string {
 		}
 		b.WriteString(label + "\n")
 	}
+	if m.Jump && len(m.Groups) == 0 {
+		b.WriteString(normalStyle.Render("  no matching groups") + "\n")
+	}
 
-	b.WriteString("\n" + normalStyle.Render("\u2191\u2193 navigate \u00b7 enter confirm \u00b7 esc cancel"))
+	if m.Jump {
+		b.WriteString("\n" + normalStyle.Render("type to filter \u00b7 \u2191\u2193 navigate \u00b7 enter jump \u00b7 esc cancel"))
+	} else {
+		b.WriteString("\n" + normalStyle.Render("\u2191\u2193 navigate \u00b7 enter confirm \u00b7 esc cancel"))
+	}
 
 	return boxStyle.Render(b.String())
 }
@@ -15,19 +15,21 @@ const (
 	ViewBugzilla
 	ViewActivity
 	ViewSnapshots
+	ViewTimeline
+	ViewGitLab
 )
 
 // TreeWidthPct is the percentage of terminal width used for the left (tree/list) pane.
 const TreeWidthPct = 50
 
-var viewNames = []string{"Tabs", "Signals", "GitHub", "Bugzilla", "Activity", "Snapshots"}
+var viewNames = []string{"Tabs", "Signals", "GitHub", "Bugzilla", "Activity", "Snapshots", "Timeline", "GitLab"}
 
-func renderNavbar(active ViewType, profileName string, counts [6]int, stats string, width int) string {
-	activeStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("62")).Underline(true)
-	inactiveStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	countStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	profileStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
-	statsStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+func renderNavbar(active ViewType, profileName string, counts [8]int, stats string, width int) string {
+	activeStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Accent).Underline(true)
+	inactiveStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	countStyle := lipgloss.NewStyle().Foreground(theme.Label)
+	profileStyle := lipgloss.NewStyle().Foreground(theme.Label)
+	statsStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	var tabs string
 	for i, name := range viewNames {
@@ -68,7 +70,7 @@ func renderNavbar(active ViewType, profileName string, counts [6]int, stats stri
 
 // navbarHitTest returns which view was clicked given an X coordinate on the navbar row.
 // Returns -1 if the click didn't land on any tab.
-func navbarHitTest(x int, counts [6]int) int {
+func navbarHitTest(x int, counts [8]int) int {
 	pos := 1 // leading space
 	for i, name := range viewNames {
 		if i > 0 {
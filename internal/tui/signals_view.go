@@ -3,7 +3,10 @@ package tui
 import (
 	"database/sql"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -11,8 +14,9 @@ import (
 )
 
 type signalsViewLoadedMsg struct {
-	signals []storage.SignalRecord
-	err     error
+	signals  []storage.SignalRecord
+	entities map[int64]storage.SignalEntity
+	err      error
 }
 
 // signalNode represents a row in the signals tree.
@@ -23,6 +27,13 @@ type signalNode struct {
 	Source         string  // source name (set on headers and their children)
 	IsCompleted    bool    // true for the "Completed" section header
 	HighestUrgency *string // for headers: most urgent signal in this source
+	// Urgency is the section ("urgent", ..., "unclassified") of headers
+	// and their children when grouped by urgency.
+	Urgency string
+	// Entity and Members are set on rows that collapse every signal about
+	// one GitHub/Bugzilla/GitLab entity; Signal is the newest member.
+	Entity  *storage.SignalEntity
+	Members []*storage.SignalRecord
 }
 
 type SignalsView struct {
@@ -41,12 +52,33 @@ type SignalsView struct {
 	sourceExpanded    map[string]bool
 	completedExpanded bool
 	focusDetail       bool
+
+	// byUrgency groups active signals into Urgent / Review / FYI /
+	// Unclassified sections across sources instead of by source.
+	byUrgency       bool
+	urgencyExpanded map[string]bool
+
+	// byEntity collapses signals in a section that refer to the same
+	// entity into one row.
+	byEntity bool
+	// entities maps signal IDs to the entity each signal refers to.
+	entities map[int64]storage.SignalEntity
+
+	// Snooze prompt
+	snoozing    bool
+	snoozeInput string
+	snoozeErr   string
+
+	// completeAllPending is the source whose active signals await
+	// confirmation before all being completed; "" when no prompt is shown.
+	completeAllPending string
 }
 
 func NewSignalsView(db *sql.DB) SignalsView {
 	return SignalsView{
-		db:             db,
-		sourceExpanded: make(map[string]bool),
+		db:              db,
+		sourceExpanded:  make(map[string]bool),
+		urgencyExpanded: make(map[string]bool),
 	}
 }
 
@@ -57,7 +89,11 @@ func (v *SignalsView) Reload() tea.Cmd {
 	db := v.db
 	return func() tea.Msg {
 		signals, err := storage.ListSignals(db, "", true)
-		return signalsViewLoadedMsg{signals: signals, err: err}
+		if err != nil {
+			return signalsViewLoadedMsg{err: err}
+		}
+		entities, err := storage.SignalEntities(db)
+		return signalsViewLoadedMsg{signals: signals, entities: entities, err: err}
 	}
 }
 
@@ -95,6 +131,10 @@ func highestUrgency(signals []*storage.SignalRecord) *string {
 
 func (v *SignalsView) buildNodes() {
 	v.nodes = nil
+	if v.byUrgency {
+		v.buildUrgencyNodes()
+		return
+	}
 
 	// Group active signals by source
 	type sourceGroup struct {
@@ -136,13 +176,97 @@ func (v *SignalsView) buildNodes() {
 			HighestUrgency: highest,
 		})
 		if v.sourceExpanded[src] {
-			for _, s := range sg.signals {
-				v.nodes = append(v.nodes, signalNode{Signal: s, Source: src})
-			}
+			v.appendSignals(sg.signals, "")
+		}
+	}
+
+	v.appendCompleted(completed)
+}
+
+// urgencySectionLabels are the section headers when grouped by urgency.
+var urgencySectionLabels = map[string]string{
+	"urgent":                    "Urgent",
+	"review":                    "Review",
+	"fyi":                       "FYI",
+	storage.UrgencyUnclassified: "Unclassified",
+}
+
+// buildUrgencyNodes lays out active signals in one section per urgency,
+// most urgent first, each listing signals from all sources.
+func (v *SignalsView) buildUrgencyNodes() {
+	sections := make(map[string][]*storage.SignalRecord)
+	var completed []*storage.SignalRecord
+	for i := range v.signals {
+		s := &v.signals[i]
+		if s.CompletedAt != nil {
+			completed = append(completed, s)
+			continue
+		}
+		u := storage.UrgencyUnclassified
+		if s.Urgency != nil {
+			u = *s.Urgency
+		}
+		sections[u] = append(sections[u], s)
+	}
+
+	for _, u := range storage.Urgencies {
+		sigs := sections[u]
+		if len(sigs) == 0 {
+			continue
+		}
+		if _, ok := v.urgencyExpanded[u]; !ok {
+			v.urgencyExpanded[u] = true
+		}
+		icon := "▸"
+		if v.urgencyExpanded[u] {
+			icon = "▼"
+		}
+		var highest *string
+		if u != storage.UrgencyUnclassified {
+			highest = &u
+		}
+		v.nodes = append(v.nodes, signalNode{
+			IsHeader:       true,
+			Header:         fmt.Sprintf("%s %s (%d)", icon, urgencySectionLabels[u], len(sigs)),
+			Urgency:        u,
+			HighestUrgency: highest,
+		})
+		if v.urgencyExpanded[u] {
+			v.appendSignals(sigs, u)
 		}
 	}
 
-	// Completed section
+	v.appendCompleted(completed)
+}
+
+// appendSignals adds a row per signal of a section. When collapsing by
+// entity, signals about an entity already listed in the section join that
+// row instead.
+func (v *SignalsView) appendSignals(sigs []*storage.SignalRecord, urgency string) {
+	rows := make(map[string]int)
+	for _, s := range sigs {
+		e, ok := v.entities[s.ID]
+		if !v.byEntity || !ok {
+			v.nodes = append(v.nodes, signalNode{Signal: s, Source: s.Source, Urgency: urgency})
+			continue
+		}
+		if i, seen := rows[e.Key()]; seen {
+			v.nodes[i].Members = append(v.nodes[i].Members, s)
+			continue
+		}
+		rows[e.Key()] = len(v.nodes)
+		v.nodes = append(v.nodes, signalNode{
+			Signal:  s,
+			Source:  s.Source,
+			Urgency: urgency,
+			Entity:  &e,
+			Members: []*storage.SignalRecord{s},
+		})
+	}
+}
+
+// appendCompleted adds the collapsible "Completed" section.
+func (v *SignalsView) appendCompleted(completed []*storage.SignalRecord) {
 	if len(completed) > 0 {
 		icon := "▸"
 		if v.completedExpanded {
@@ -161,6 +285,51 @@ func (v *SignalsView) buildNodes() {
 	}
 }
 
+// toggleGrouping switches between grouping by source and by urgency,
+// keeping the cursor on the selected signal.
+func (v *SignalsView) toggleGrouping() {
+	v.relayout(func() { v.byUrgency = !v.byUrgency })
+}
+
+// toggleEntities switches collapsing signals about the same entity on or
+// off, keeping the cursor on the selected signal.
+func (v *SignalsView) toggleEntities() {
+	v.relayout(func() { v.byEntity = !v.byEntity })
+}
+
+// relayout applies change and rebuilds the rows, moving the cursor to the
+// row holding the previously selected signal.
+func (v *SignalsView) relayout(change func()) {
+	var selectedID int64
+	if sig := v.selectedSignal(); sig != nil {
+		selectedID = sig.ID
+	}
+	change()
+	v.buildNodes()
+	v.cursor = 0
+	for i, n := range v.nodes {
+		if n.Signal != nil && n.Signal.ID == selectedID {
+			v.cursor = i
+			break
+		}
+		if slices.ContainsFunc(n.Members, func(s *storage.SignalRecord) bool { return s.ID == selectedID }) {
+			v.cursor = i
+			break
+		}
+	}
+	v.offset = 0
+	v.adjustOffset()
+	v.detail.Scroll = 0
+}
+
+// selectedNode returns the row under the cursor, or nil.
+func (v *SignalsView) selectedNode() *signalNode {
+	if v.cursor >= 0 && v.cursor < len(v.nodes) {
+		return &v.nodes[v.cursor]
+	}
+	return nil
+}
+
 func (v *SignalsView) selectedSignal() *storage.SignalRecord {
 	if v.cursor >= 0 && v.cursor < len(v.nodes) {
 		return v.nodes[v.cursor].Signal
@@ -177,6 +346,7 @@ func (v SignalsView) Update(msg tea.Msg) (SignalsView, tea.Cmd) {
 			return v, nil
 		}
 		v.signals = msg.signals
+		v.entities = msg.entities
 		v.err = nil
 		v.buildNodes()
 		if v.cursor >= len(v.nodes) {
@@ -217,6 +387,17 @@ func (v SignalsView) Update(msg tea.Msg) (SignalsView, tea.Cmd) {
 		return v, nil
 
 	case tea.KeyMsg:
+		if v.snoozing {
+			return v.updateSnoozePrompt(msg)
+		}
+		if v.completeAllPending != "" {
+			source := v.completeAllPending
+			v.completeAllPending = ""
+			if msg.String() == "y" {
+				return v, completeAllSignalsCmd(v.db, source)
+			}
+			return v, nil
+		}
 		if v.focusDetail {
 			switch msg.String() {
 			case "esc":
@@ -288,18 +469,48 @@ func (v SignalsView) Update(msg tea.Msg) (SignalsView, tea.Cmd) {
 				}
 				v.focusDetail = true
 			}
+		case "g":
+			v.toggleGrouping()
+		case "e":
+			v.toggleEntities()
+		case "o":
+			// Open the entity the signal refers to
+			if sig := v.selectedSignal(); sig != nil {
+				if e, ok := v.entities[sig.ID]; ok {
+					return v, openTabInBrowser(e.URL)
+				}
+			}
 		case "x":
-			// Complete signal
+			// Complete signal, or every signal of a collapsed entity row
+			if node := v.selectedNode(); node != nil && len(node.Members) > 1 {
+				ids := make([]int64, len(node.Members))
+				for i, s := range node.Members {
+					ids[i] = s.ID
+				}
+				return v, completeSignalsCmd(v.db, ids, node.Source)
+			}
 			sig := v.selectedSignal()
 			if sig != nil && sig.CompletedAt == nil {
 				return v, completeSignalCmd(v.db, sig.ID, sig.Source)
 			}
+		case "X":
+			// Complete every active signal of the source under the cursor
+			if node := v.selectedNode(); node != nil && node.IsHeader && node.Source != "" && v.activeCount(node.Source) > 0 {
+				v.completeAllPending = node.Source
+			}
 		case "u":
 			// Reopen signal
 			sig := v.selectedSignal()
 			if sig != nil && sig.CompletedAt != nil {
 				return v, reopenSignalCmd(v.db, sig.ID, sig.Source)
 			}
+		case "s":
+			sig := v.selectedSignal()
+			if sig != nil && sig.CompletedAt == nil {
+				v.snoozing = true
+				v.snoozeInput = ""
+				v.snoozeErr = ""
+			}
 		case "]":
 			sig := v.selectedSignal()
 			if sig != nil && sig.CompletedAt == nil {
@@ -317,9 +528,54 @@ func (v SignalsView) Update(msg tea.Msg) (SignalsView, tea.Cmd) {
 	return v, nil
 }
 
+// updateSnoozePrompt handles typing a snooze duration for the selected signal.
+func (v SignalsView) updateSnoozePrompt(msg tea.KeyMsg) (SignalsView, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.snoozing = false
+	case tea.KeyEnter:
+		d, err := parseSnoozeDuration(v.snoozeInput)
+		if err != nil {
+			v.snoozeErr = err.Error()
+			return v, nil
+		}
+		v.snoozing = false
+		if sig := v.selectedSignal(); sig != nil {
+			return v, snoozeSignalCmd(v.db, sig.ID, time.Now().Add(d), sig.Source)
+		}
+	case tea.KeyBackspace:
+		if len(v.snoozeInput) > 0 {
+			v.snoozeInput = v.snoozeInput[:len(v.snoozeInput)-1]
+		}
+	case tea.KeyRunes:
+		v.snoozeInput += string(msg.Runes)
+		v.snoozeErr = ""
+	}
+	return v, nil
+}
+
+// parseSnoozeDuration accepts Go durations ("90m", "2h") plus whole days ("1d", "3d").
+func parseSnoozeDuration(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid duration %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
 func (v *SignalsView) toggleHeader(node signalNode) {
 	if node.IsCompleted {
 		v.completedExpanded = !v.completedExpanded
+	} else if node.Urgency != "" {
+		v.urgencyExpanded[node.Urgency] = !v.urgencyExpanded[node.Urgency]
 	} else if node.Source != "" {
 		v.sourceExpanded[node.Source] = !v.sourceExpanded[node.Source]
 	}
@@ -329,6 +585,9 @@ func (v *SignalsView) isExpanded(node signalNode) bool {
 	if node.IsCompleted {
 		return v.completedExpanded
 	}
+	if node.Urgency != "" {
+		return v.urgencyExpanded[node.Urgency]
+	}
 	return v.sourceExpanded[node.Source]
 }
 
@@ -359,11 +618,11 @@ func (v SignalsView) ViewList() string {
 	treeWidth := v.width * TreeWidthPct / 100
 	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
 	groupStyle := lipgloss.NewStyle().Bold(true)
-	completedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	urgentStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
-	reviewStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
-	fyiStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
-	unclassifiedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	completedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	urgentStyle := lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
+	reviewStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	fyiStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	unclassifiedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	var b strings.Builder
 	end := v.offset + v.height
@@ -380,9 +639,9 @@ func (v SignalsView) ViewList() string {
 			if node.HighestUrgency != nil {
 				switch *node.HighestUrgency {
 				case "urgent":
-					style = style.Foreground(lipgloss.Color("196"))
+					style = style.Foreground(theme.Error)
 				case "review":
-					style = style.Foreground(lipgloss.Color("214"))
+					style = style.Foreground(theme.Warn)
 				}
 			}
 			line = style.Render(node.Header)
@@ -390,9 +649,13 @@ func (v SignalsView) ViewList() string {
 			s := node.Signal
 			age := formatSignalAge(s.CapturedAt)
 
+			urgency := s.Urgency
+			if len(node.Members) > 1 {
+				urgency = highestUrgency(node.Members)
+			}
 			urgencyPrefix := unclassifiedStyle.Render("[?] ")
-			if s.Urgency != nil {
-				switch *s.Urgency {
+			if urgency != nil {
+				switch *urgency {
 				case "urgent":
 					urgencyPrefix = urgentStyle.Render("[!] ")
 				case "review":
@@ -402,8 +665,22 @@ func (v SignalsView) ViewList() string {
 				}
 			}
 
-			text := fmt.Sprintf("  %s%s", urgencyPrefix, s.Title)
-			if s.Preview != "" {
+			title := s.Title
+			if node.Urgency != "" {
+				// Sections mix sources; say where each signal is from.
+				title = s.Source + " · " + title
+			}
+			text := fmt.Sprintf("  %s%s", urgencyPrefix, title)
+			if node.Entity != nil {
+				// One row per entity: name it rather than the newest signal.
+				text = fmt.Sprintf("  %s%s", urgencyPrefix, node.Entity.Ref)
+				if node.Entity.Title != "" {
+					text += " — " + node.Entity.Title
+				}
+				if len(node.Members) > 1 {
+					text += fmt.Sprintf(" ×%d", len(node.Members))
+				}
+			} else if s.Preview != "" {
 				text += " — " + s.Preview
 			}
 			suffix := "  " + age
@@ -443,13 +720,22 @@ func (v SignalsView) ViewDetail() string {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	valueStyle := lipgloss.NewStyle()
-	activeStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42")).Bold(true)
-	completedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	activeStyle := lipgloss.NewStyle().Foreground(theme.OK).Bold(true)
+	completedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	var b strings.Builder
 
+	if v.snoozing {
+		promptStyle := lipgloss.NewStyle().Foreground(theme.Warn).Bold(true)
+		b.WriteString(promptStyle.Render("Snooze for (e.g. 30m, 2h, 1d): "+v.snoozeInput+"_") + "\n")
+		if v.snoozeErr != "" {
+			b.WriteString(lipgloss.NewStyle().Foreground(theme.Error).Render(v.snoozeErr) + "\n")
+		}
+		b.WriteString("\n")
+	}
+
 	b.WriteString(labelStyle.Render("Source") + "\n")
 	b.WriteString(valueStyle.Render(sig.Source) + "\n\n")
 
@@ -462,7 +748,7 @@ func (v SignalsView) ViewDetail() string {
 	}
 
 	if sig.Snippet != "" {
-		snippetStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252")).Italic(true)
+		snippetStyle := lipgloss.NewStyle().Foreground(theme.Text).Italic(true)
 		b.WriteString(labelStyle.Render("Snippet") + "\n")
 		b.WriteString(snippetStyle.Render(sig.Snippet) + "\n\n")
 	}
@@ -485,11 +771,11 @@ func (v SignalsView) ViewDetail() string {
 		var uStyle lipgloss.Style
 		switch urgencyVal {
 		case "urgent":
-			uStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+			uStyle = lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
 		case "review":
-			uStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+			uStyle = lipgloss.NewStyle().Foreground(theme.Warn)
 		case "fyi":
-			uStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+			uStyle = lipgloss.NewStyle().Foreground(theme.Dim)
 		default:
 			uStyle = valueStyle
 		}
@@ -508,12 +794,54 @@ func (v SignalsView) ViewDetail() string {
 		b.WriteString(valueStyle.Render("Pinned (won't auto-complete)") + "\n")
 	}
 
+	if e, ok := v.entities[sig.ID]; ok {
+		b.WriteString("\n" + labelStyle.Render("Refers to") + "\n")
+		ref := e.Ref
+		if e.State != "" {
+			ref += " (" + e.State + ")"
+		}
+		b.WriteString(valueStyle.Render(ref) + "\n")
+		if e.Title != "" {
+			b.WriteString(valueStyle.Render(e.Title) + "\n")
+		}
+		b.WriteString(completedStyle.Render(e.URL+"  (o to open)") + "\n")
+	}
+
+	if node := v.selectedNode(); node != nil && len(node.Members) > 1 {
+		b.WriteString("\n" + labelStyle.Render(fmt.Sprintf("Signals (%d)", len(node.Members))) + "\n")
+		for _, s := range node.Members {
+			b.WriteString(valueStyle.Render(fmt.Sprintf("%s · %s — %s  %s", s.Source, s.Title, s.Preview, formatSignalAge(s.CapturedAt))) + "\n")
+		}
+	}
+
 	content := b.String()
 	return v.detail.ViewScrolled(content)
 }
 
 func (v SignalsView) FocusDetail() bool { return v.focusDetail }
 
+// Prompting reports whether the view is capturing text input, so global
+// keys (view switching, quit) should not be intercepted.
+func (v SignalsView) Prompting() bool { return v.snoozing || v.completeAllPending != "" }
+
+// activeCount returns the number of active signals listed for source.
+func (v SignalsView) activeCount(source string) int {
+	n := 0
+	for _, s := range v.signals {
+		if s.Source == source && s.CompletedAt == nil {
+			n++
+		}
+	}
+	return n
+}
+
+func (v SignalsView) BottomBar() string {
+	if v.completeAllPending != "" {
+		return fmt.Sprintf("Complete all %d active %s signal(s)? y confirm \u00b7 any other key cancels", v.activeCount(v.completeAllPending), v.completeAllPending)
+	}
+	return "\u2191\u2193/jk navigate \u00b7 \u21b5 open \u00b7 tab focus \u00b7 x complete \u00b7 X complete source \u00b7 u reopen \u00b7 s snooze \u00b7 [/] urgency \u00b7 g group \u00b7 e by entity \u00b7 o open entity \u00b7 1-8 view \u00b7 p source \u00b7 q quit"
+}
+
 // cycleUrgencyUp raises urgency: nil→fyi→review→urgent→fyi (wraps).
 func cycleUrgencyUp(current *string) string {
 	if current == nil {
@@ -0,0 +1,66 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestSignalsGroupByUrgency(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	now := time.Now()
+	for _, s := range []storage.SignalRecord{
+		{Source: "gmail", Title: "Invoice due", CapturedAt: now},
+		{Source: "slack", Title: "Lunch?", CapturedAt: now.Add(-time.Minute)},
+	} {
+		if err := storage.InsertSignal(m.db, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	sigs, _ := storage.ListSignals(m.db, "", false)
+	urgency := map[string]string{"Invoice due": "urgent", "Lunch?": "fyi"}
+	for _, s := range sigs {
+		if err := storage.UpdateUrgency(m.db, s.ID, urgency[s.Title], "manual"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+	next, _ = m.Update(key("2"))
+	m = next.(Model)
+	next, _ = m.Update(m.signalsView.Reload()())
+	m = next.(Model)
+	list := m.signalsView.ViewList()
+	if !strings.Contains(list, "gmail (1 active)") || !strings.Contains(list, "slack (1 active)") {
+		t.Fatalf("ViewList() = %q, want a section per source", list)
+	}
+	// Put the cursor on the slack signal.
+	for i := 0; i < 5 && (m.signalsView.selectedSignal() == nil || m.signalsView.selectedSignal().Title != "Lunch?"); i++ {
+		next, _ = m.Update(key("j"))
+		m = next.(Model)
+	}
+
+	next, _ = m.Update(key("g"))
+	m = next.(Model)
+	list = m.signalsView.ViewList()
+	if !strings.Contains(list, "Urgent (1)") || !strings.Contains(list, "FYI (1)") || strings.Contains(list, "active)") {
+		t.Fatalf("ViewList() = %q, want urgency sections", list)
+	}
+	if strings.Index(list, "Urgent (1)") > strings.Index(list, "FYI (1)") {
+		t.Errorf("ViewList() = %q, want Urgent before FYI", list)
+	}
+	if s := m.signalsView.selectedSignal(); s == nil || s.Title != "Lunch?" {
+		t.Errorf("selected %+v after regrouping, want the slack signal", s)
+	}
+
+	next, _ = m.Update(key("g"))
+	m = next.(Model)
+	if list := m.signalsView.ViewList(); !strings.Contains(list, "gmail (1 active)") {
+		t.Errorf("ViewList() = %q, want the source sections back", list)
+	}
+}
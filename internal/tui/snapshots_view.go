@@ -7,7 +7,9 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/snapshot"
 	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
 )
 
 type snapshotsLoadedMsg struct {
@@ -20,6 +22,18 @@ type snapshotDetailMsg struct {
 	err  error
 }
 
+type snapshotDiffMsg struct {
+	diff *snapshot.DiffResult
+	err  error
+}
+
+// snapshotRestoreMsg asks the root model to reopen a snapshot group's tabs
+// through the live extension.
+type snapshotRestoreMsg struct {
+	snap  *storage.SnapshotFull
+	group string
+}
+
 type snapshotNode struct {
 	IsHeader bool
 	Header   string
@@ -47,7 +61,26 @@ type SnapshotsView struct {
 
 	// Right pane state
 	groupExpanded map[string]bool
+	groupCursor   int
 	focusDetail   bool
+	status        string
+
+	// Diff state: mark is the "from" snapshot picked with m; diff, when
+	// set, replaces the group list in the detail pane.
+	mark *storage.SnapshotSummary
+	diff *snapshot.DiffResult
+
+	// current is the session open in the Tabs view; gone holds the URLs of
+	// the selected snapshot's tabs that are no longer open in it (nil when
+	// there is no current session).
+	current *types.SessionData
+	gone    map[string]bool
+}
+
+// snapshotGroupEntry is a group of tabs as shown in the detail pane.
+type snapshotGroupEntry struct {
+	name string
+	tabs []storage.SnapshotTab
 }
 
 func NewSnapshotsView(db *sql.DB) SnapshotsView {
@@ -85,6 +118,85 @@ func (v *SnapshotsView) loadDetail(profile string, rev int) tea.Cmd {
 	}
 }
 
+// SetCurrent sets the session the selected snapshot's tabs are checked
+// against.
+func (v *SnapshotsView) SetCurrent(session *types.SessionData) {
+	v.current = session
+	v.compareCurrent()
+	v.detail.ContentLen = v.computeDetailLineCount()
+}
+
+// compareCurrent works out which of the selected snapshot's tabs are gone
+// from the current session.
+func (v *SnapshotsView) compareCurrent() {
+	v.gone = nil
+	if v.selected == nil || v.current == nil {
+		return
+	}
+	d := snapshot.DiffWithSession(v.selected, v.current)
+	v.gone = make(map[string]bool, len(d.Removed))
+	for _, e := range d.Removed {
+		v.gone[e.URL] = true
+	}
+}
+
+// detailHeaderLines is the number of lines above the group list.
+func (v SnapshotsView) detailHeaderLines() int {
+	lines := 3
+	if v.selected.Name != "" {
+		lines++
+	}
+	if v.gone != nil {
+		lines++
+	}
+	if v.status != "" {
+		lines++
+	}
+	return lines
+}
+
+// diffSelected compares the highlighted snapshot with the marked one, or
+// with the previous revision of its profile when nothing is marked.
+func (v *SnapshotsView) diffSelected() tea.Cmd {
+	cur := v.selectedSnapshot()
+	if cur == nil {
+		return nil
+	}
+	from := v.mark
+	if from == nil || (from.Profile == cur.Profile && from.Rev == cur.Rev) {
+		from = v.previousSnapshot(cur)
+		if from == nil {
+			v.status = fmt.Sprintf("No snapshot of %s before #%d", cur.Profile, cur.Rev)
+			return nil
+		}
+	} else if from.Profile != cur.Profile {
+		v.status = fmt.Sprintf("Can't diff snapshots of different profiles (%s, %s)", from.Profile, cur.Profile)
+		return nil
+	}
+	rev1, rev2 := from.Rev, cur.Rev
+	if rev1 > rev2 {
+		rev1, rev2 = rev2, rev1
+	}
+	db, profile := v.db, cur.Profile
+	return func() tea.Msg {
+		d, err := snapshot.DiffRevisions(db, profile, rev1, rev2)
+		return snapshotDiffMsg{diff: d, err: err}
+	}
+}
+
+// previousSnapshot returns the newest snapshot of s's profile with a lower
+// revision, or nil.
+func (v *SnapshotsView) previousSnapshot(s *storage.SnapshotSummary) *storage.SnapshotSummary {
+	var prev *storage.SnapshotSummary
+	for i := range v.snapshots {
+		c := &v.snapshots[i]
+		if c.Profile == s.Profile && c.Rev < s.Rev && (prev == nil || c.Rev > prev.Rev) {
+			prev = c
+		}
+	}
+	return prev
+}
+
 func (v *SnapshotsView) SetSize(w, h int) {
 	v.width = w
 	v.height = h
@@ -157,6 +269,7 @@ func (v SnapshotsView) Update(msg tea.Msg) (SnapshotsView, tea.Cmd) {
 		}
 		v.snapshots = msg.snapshots
 		v.err = nil
+		v.mark = nil
 		v.buildNodes()
 		// Auto-select first snapshot if available
 		for i, n := range v.nodes {
@@ -173,6 +286,10 @@ func (v SnapshotsView) Update(msg tea.Msg) (SnapshotsView, tea.Cmd) {
 			return v, nil
 		}
 		v.selected = msg.snap
+		v.groupCursor = 0
+		v.status = ""
+		v.diff = nil
+		v.compareCurrent()
 		// Auto-expand all groups in detail
 		v.groupExpanded = make(map[string]bool)
 		if msg.snap != nil {
@@ -185,6 +302,17 @@ func (v SnapshotsView) Update(msg tea.Msg) (SnapshotsView, tea.Cmd) {
 		v.detail.ContentLen = v.computeDetailLineCount()
 		return v, nil
 
+	case snapshotDiffMsg:
+		if msg.err != nil {
+			v.status = fmt.Sprintf("Diff failed: %v", msg.err)
+			v.detail.ContentLen = v.computeDetailLineCount()
+			return v, nil
+		}
+		v.diff = msg.diff
+		v.detail.Scroll = 0
+		v.detail.ContentLen = v.computeDetailLineCount()
+		return v, nil
+
 	case tea.MouseMsg:
 		switch msg.Button {
 		case tea.MouseButtonLeft:
@@ -237,14 +365,35 @@ func (v SnapshotsView) Update(msg tea.Msg) (SnapshotsView, tea.Cmd) {
 
 	case tea.KeyMsg:
 		if v.focusDetail {
+			groups := v.groupedTabs()
 			switch msg.String() {
 			case "esc":
 				v.focusDetail = false
 				v.detail.Scroll = 0
 			case "j", "down":
-				v.detail.ScrollDown()
+				if v.groupCursor < len(groups)-1 {
+					v.groupCursor++
+					v.scrollToGroup()
+				}
 			case "k", "up":
-				v.detail.ScrollUp()
+				if v.groupCursor > 0 {
+					v.groupCursor--
+					v.scrollToGroup()
+				}
+			case "enter", " ":
+				if v.groupCursor < len(groups) {
+					name := groups[v.groupCursor].name
+					v.groupExpanded[name] = !v.groupExpanded[name]
+					v.detail.ContentLen = v.computeDetailLineCount()
+				}
+			case "r":
+				if v.groupCursor < len(groups) {
+					snap := v.selected
+					name := groups[v.groupCursor].name
+					return v, func() tea.Msg {
+						return snapshotRestoreMsg{snap: snap, group: name}
+					}
+				}
 			}
 			return v, nil
 		}
@@ -311,10 +460,29 @@ func (v SnapshotsView) Update(msg tea.Msg) (SnapshotsView, tea.Cmd) {
 					if v.cursor >= len(v.nodes) {
 						v.cursor = len(v.nodes) - 1
 					}
-				} else {
+				} else if v.diff == nil {
 					v.focusDetail = true
 				}
 			}
+		case "m":
+			if s := v.selectedSnapshot(); s != nil {
+				if v.mark != nil && v.mark.Profile == s.Profile && v.mark.Rev == s.Rev {
+					v.mark = nil
+					v.status = ""
+				} else {
+					v.mark = s
+					v.status = fmt.Sprintf("Marked #%d as diff base; press d on another snapshot", s.Rev)
+				}
+				v.detail.ContentLen = v.computeDetailLineCount()
+			}
+		case "d":
+			return v, v.diffSelected()
+		case "esc":
+			if v.diff != nil {
+				v.diff = nil
+				v.detail.Scroll = 0
+				v.detail.ContentLen = v.computeDetailLineCount()
+			}
 		}
 	}
 	return v, nil
@@ -337,23 +505,61 @@ func (v SnapshotsView) computeDetailLineCount() int {
 	if v.selected == nil {
 		return 0
 	}
-	lines := 3
-	if v.selected.Name != "" {
-		lines++
+	lines := v.detailHeaderLines()
+	if v.diff != nil {
+		return lines + strings.Count(snapshot.FormatDiff(v.diff), "\n")
+	}
+	for _, ge := range v.groupedTabs() {
+		lines += 2
+		if v.groupExpanded[ge.name] {
+			lines += len(ge.tabs)
+		}
 	}
-	seen := make(map[string]bool)
+	return lines
+}
+
+// groupedTabs returns the selected snapshot's tabs grouped by group name,
+// in order of first appearance.
+func (v SnapshotsView) groupedTabs() []snapshotGroupEntry {
+	if v.selected == nil {
+		return nil
+	}
+	var groups []snapshotGroupEntry
+	index := make(map[string]int)
 	for _, tab := range v.selected.Tabs {
 		gname := tab.GroupName
 		if gname == "" {
 			gname = "Ungrouped"
 		}
-		if !seen[gname] {
-			seen[gname] = true
-			lines += 2
+		i, ok := index[gname]
+		if !ok {
+			i = len(groups)
+			index[gname] = i
+			groups = append(groups, snapshotGroupEntry{name: gname})
 		}
-		lines++
+		groups[i].tabs = append(groups[i].tabs, tab)
+	}
+	return groups
+}
+
+// scrollToGroup scrolls the detail pane so the group under the cursor
+// has its header visible.
+func (v *SnapshotsView) scrollToGroup() {
+	line := v.detailHeaderLines()
+	for i, ge := range v.groupedTabs() {
+		if i == v.groupCursor {
+			break
+		}
+		line += 2
+		if v.groupExpanded[ge.name] {
+			line += len(ge.tabs)
+		}
+	}
+	if line < v.detail.Scroll {
+		v.detail.Scroll = line
+	} else if line >= v.detail.Scroll+v.detail.Height {
+		v.detail.Scroll = line - v.detail.Height + 1
 	}
-	return lines
 }
 
 func (v SnapshotsView) ViewList() string {
@@ -390,7 +596,11 @@ func (v SnapshotsView) ViewList() string {
 			if s.Name != "" {
 				label = " " + s.Name
 			}
-			line = fmt.Sprintf("    %s  %s  (%d tabs)%s", ts, s.Profile, s.TabCount, label)
+			markStr := "  "
+			if v.mark != nil && v.mark.Profile == s.Profile && v.mark.Rev == s.Rev {
+				markStr = "* "
+			}
+			line = fmt.Sprintf("  %s%s  %s  (%d tabs)%s", markStr, ts, s.Profile, s.TabCount, label)
 			if len(line) > treeWidth {
 				line = line[:treeWidth-1] + "…"
 			}
@@ -418,9 +628,12 @@ func (v SnapshotsView) ViewDetail() string {
 		return ""
 	}
 
-	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("245"))
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
 	groupStyle := lipgloss.NewStyle().Bold(true)
-	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+	dimStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	openStyle := lipgloss.NewStyle().Foreground(theme.OK)
+	goneStyle := lipgloss.NewStyle().Foreground(theme.Error)
 
 	var b strings.Builder
 
@@ -434,39 +647,69 @@ func (v SnapshotsView) ViewDetail() string {
 	if v.selected.Name != "" {
 		b.WriteString(truncateString("Label: "+v.selected.Name, v.detail.Width) + "\n")
 	}
+	if v.gone != nil {
+		open := 0
+		for _, tab := range v.selected.Tabs {
+			if !v.gone[tab.URL] {
+				open++
+			}
+		}
+		b.WriteString(truncateString(fmt.Sprintf("%d still open · %d gone", open, len(v.selected.Tabs)-open), v.detail.Width) + "\n")
+	}
+	if flagged := snapshotFlagSummary(v.selected.Tabs); flagged != "" {
+		b.WriteString(truncateString("At snapshot: "+flagged, v.detail.Width) + "\n")
+	}
+	if v.status != "" {
+		b.WriteString(dimStyle.Render(truncateString(v.status, v.detail.Width)) + "\n")
+	}
 	b.WriteString("\n")
 
-	// Group tabs by group name
-	type groupEntry struct {
-		name string
-		tabs []storage.SnapshotTab
+	if v.diff != nil {
+		addStyle := lipgloss.NewStyle().Foreground(theme.OK)
+		removeStyle := lipgloss.NewStyle().Foreground(theme.Error)
+		for _, line := range strings.Split(strings.TrimRight(snapshot.FormatDiff(v.diff), "\n"), "\n") {
+			line = truncateString(line, v.detail.Width)
+			switch {
+			case strings.HasPrefix(line, "  +"), strings.HasPrefix(line, "+ "):
+				line = addStyle.Render(line)
+			case strings.HasPrefix(line, "  -"), strings.HasPrefix(line, "- "):
+				line = removeStyle.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+		return v.detail.ViewScrolled(b.String())
 	}
-	groupMap := make(map[string]*groupEntry)
-	var groupOrder []string
 
-	for _, tab := range v.selected.Tabs {
-		gname := tab.GroupName
-		if gname == "" {
-			gname = "Ungrouped"
+	for i, ge := range v.groupedTabs() {
+		icon := "▸"
+		if v.groupExpanded[ge.name] {
+			icon = "▼"
 		}
-		if _, ok := groupMap[gname]; !ok {
-			groupMap[gname] = &groupEntry{name: gname}
-			groupOrder = append(groupOrder, gname)
+		groupHeader := truncateString(fmt.Sprintf("%s %s (%d tabs)", icon, ge.name, len(ge.tabs)), v.detail.Width)
+		if v.focusDetail && i == v.groupCursor {
+			b.WriteString(cursorStyle.Render(groupHeader) + "\n")
+		} else {
+			b.WriteString(groupStyle.Render(groupHeader) + "\n")
+		}
+		if !v.groupExpanded[ge.name] {
+			b.WriteString("\n")
+			continue
 		}
-		groupMap[gname].tabs = append(groupMap[gname].tabs, tab)
-	}
-
-	for _, gname := range groupOrder {
-		ge := groupMap[gname]
-		groupHeader := fmt.Sprintf("▼ %s (%d tabs)", ge.name, len(ge.tabs))
-		b.WriteString(groupStyle.Render(truncateString(groupHeader, v.detail.Width)) + "\n")
 		for _, tab := range ge.tabs {
 			title := tab.Title
-			maxLen := v.detail.Width - 6
+			markers := snapshotTabMarkers(tab)
+			maxLen := v.detail.Width - 6 - lipgloss.Width(markers)
 			if maxLen > 0 && len(title) > maxLen {
 				title = title[:maxLen-1] + "…"
 			}
-			b.WriteString(dimStyle.Render("    "+title) + "\n")
+			switch {
+			case v.gone == nil:
+				b.WriteString(dimStyle.Render("    "+title) + markers + "\n")
+			case v.gone[tab.URL]:
+				b.WriteString("  " + goneStyle.Render("✗") + " " + dimStyle.Render(title) + markers + "\n")
+			default:
+				b.WriteString("  " + openStyle.Render("✓") + " " + title + markers + "\n")
+			}
 		}
 		b.WriteString("\n")
 	}
@@ -476,3 +719,60 @@ func (v SnapshotsView) ViewDetail() string {
 }
 
 func (v SnapshotsView) FocusDetail() bool { return v.focusDetail }
+
+// snapshotFlagSummary counts the tabs the analyzers had flagged when the
+// snapshot was taken, e.g. "12 dead · 3 stale". Returns "" if none were.
+func snapshotFlagSummary(tabs []storage.SnapshotTab) string {
+	var dead, stale, dup, ghDone int
+	for _, tab := range tabs {
+		if tab.IsDead {
+			dead++
+		}
+		if tab.IsStale {
+			stale++
+		}
+		if tab.IsDuplicate {
+			dup++
+		}
+		if tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged" {
+			ghDone++
+		}
+	}
+	var parts []string
+	if dead > 0 {
+		parts = append(parts, fmt.Sprintf("%d dead", dead))
+	}
+	if stale > 0 {
+		parts = append(parts, fmt.Sprintf("%d stale", stale))
+	}
+	if dup > 0 {
+		parts = append(parts, fmt.Sprintf("%d duplicate", dup))
+	}
+	if ghDone > 0 {
+		parts = append(parts, fmt.Sprintf("%d GitHub done", ghDone))
+	}
+	return strings.Join(parts, " · ")
+}
+
+// snapshotTabMarkers renders the analyzer flags stored with a snapshot tab,
+// using the Tabs view's markers. GitHub state is spelled out because ✓ marks
+// tabs that are still open here.
+func snapshotTabMarkers(tab storage.SnapshotTab) string {
+	var markers []string
+	if tab.IsDead {
+		markers = append(markers, lipgloss.NewStyle().Foreground(theme.Error).Render("●"))
+	}
+	if tab.IsStale {
+		markers = append(markers, lipgloss.NewStyle().Foreground(theme.Warn).Render("◷"))
+	}
+	if tab.IsDuplicate {
+		markers = append(markers, lipgloss.NewStyle().Foreground(theme.Info).Render("⇄"))
+	}
+	if tab.GitHubStatus != "" {
+		markers = append(markers, lipgloss.NewStyle().Foreground(theme.Dim).Render(strings.ReplaceAll(tab.GitHubStatus, "_", " ")))
+	}
+	if len(markers) == 0 {
+		return ""
+	}
+	return " " + strings.Join(markers, " ")
+}
@@ -1,10 +1,14 @@
 package tui
 
 import (
+	"database/sql"
 	"fmt"
 	"strings"
 
+	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/firefox"
+	"github.com/lotas/tabsordnung/internal/storage"
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
@@ -13,6 +17,44 @@ type Source struct {
 	Label   string
 	Profile *types.Profile // nil for live mode
 	IsLive  bool
+
+	// Filled in asynchronously by sourceCountsMsg.
+	CountsLoaded bool
+	Tabs         int // -1 if the session could not be read
+	Snapshots    int
+}
+
+// sourceCountsMsg carries the tab and snapshot counts for one profile.
+type sourceCountsMsg struct {
+	profile   string
+	tabs      int
+	snapshots int
+}
+
+// loadSourceCounts reads every profile's session and snapshot count in the
+// background, one message per profile, so the picker can show up first and
+// fill them in.
+func loadSourceCounts(db *sql.DB, profiles []types.Profile) tea.Cmd {
+	var cmds []tea.Cmd
+	for _, p := range profiles {
+		cmds = append(cmds, func() tea.Msg {
+			msg := sourceCountsMsg{profile: p.Name, tabs: -1}
+			if len(p.Members) > 0 {
+				if sd, err := firefox.ReadProfiles(p.Members); err == nil {
+					msg.tabs = len(sd.AllTabs)
+				}
+			} else if sd, err := firefox.ReadSessionFile(p.Path); err == nil {
+				msg.tabs = len(sd.AllTabs)
+			}
+			if db != nil {
+				if snaps, err := storage.ListSnapshotsByProfile(db, p.Name); err == nil {
+					msg.snapshots = len(snaps)
+				}
+			}
+			return msg
+		})
+	}
+	return tea.Batch(cmds...)
 }
 
 // SourcePicker is an overlay for selecting live mode or a profile.
@@ -36,6 +78,18 @@ func NewSourcePicker(profiles []types.Profile) SourcePicker {
 	return SourcePicker{Sources: sources, Cursor: 0}
 }
 
+// SetCounts fills in the counts for the profile named in msg.
+func (m *SourcePicker) SetCounts(msg sourceCountsMsg) {
+	for i := range m.Sources {
+		src := &m.Sources[i]
+		if src.Profile != nil && src.Profile.Name == msg.profile {
+			src.CountsLoaded = true
+			src.Tabs = msg.tabs
+			src.Snapshots = msg.snapshots
+		}
+	}
+}
+
 func (m *SourcePicker) MoveUp() {
 	if m.Cursor > 0 {
 		m.Cursor--
@@ -52,6 +106,17 @@ func (m SourcePicker) Selected() Source {
 	return m.Sources[m.Cursor]
 }
 
+// SelectName moves the cursor to the profile called name, or to live mode
+// for "live". Unknown names leave the cursor where it is.
+func (m *SourcePicker) SelectName(name string) {
+	for i, src := range m.Sources {
+		if (src.IsLive && name == "live") || (src.Profile != nil && src.Profile.Name == name) {
+			m.Cursor = i
+			return
+		}
+	}
+}
+
 func (m *SourcePicker) SelectByNumber(n int) bool {
 	idx := n - 1
 	if idx >= 0 && idx < len(m.Sources) {
@@ -67,22 +132,27 @@ func (m SourcePicker) View() string {
 	normalStyle := lipgloss.NewStyle().Padding(0, 1)
 	boxStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
-		BorderForeground(lipgloss.Color("62")).
+		BorderForeground(theme.Accent).
 		Padding(1, 2)
 
+	countStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+
 	var b strings.Builder
 	b.WriteString(titleStyle.Render("Select source:") + "\n\n")
 
+	labelWidth := 0
+	for _, src := range m.Sources {
+		labelWidth = max(labelWidth, len(sourceLabel(src)))
+	}
+
 	for i, src := range m.Sources {
 		num := i + 1
-		label := fmt.Sprintf("%d  %s", num, src.Label)
-		if src.Profile != nil && src.Profile.IsDefault {
-			label += " (default)"
-		}
+		label := fmt.Sprintf("%d  %-*s", num, labelWidth, sourceLabel(src))
+		counts := sourceCounts(src)
 		if i == m.Cursor {
-			label = selectedStyle.Render(fmt.Sprintf("%d  %s", num, src.Label))
+			label = selectedStyle.Render(label + counts)
 		} else {
-			label = normalStyle.Render("  " + label)
+			label = normalStyle.Render("  " + label + countStyle.Render(counts))
 		}
 		b.WriteString(label + "\n")
 	}
@@ -91,3 +161,26 @@ func (m SourcePicker) View() string {
 
 	return boxStyle.Render(b.String())
 }
+
+func sourceLabel(src Source) string {
+	if src.Profile != nil && src.Profile.IsDefault {
+		return src.Label + " (default)"
+	}
+	return src.Label
+}
+
+// sourceCounts renders a profile's tab and snapshot counts, or an ellipsis
+// while they are loading.
+func sourceCounts(src Source) string {
+	if src.Profile == nil {
+		return ""
+	}
+	if !src.CountsLoaded {
+		return "  \u2026"
+	}
+	tabs := "? tabs"
+	if src.Tabs >= 0 {
+		tabs = fmt.Sprintf("%d tabs", src.Tabs)
+	}
+	return fmt.Sprintf("  %s \u00b7 %d snapshots", tabs, src.Snapshots)
+}
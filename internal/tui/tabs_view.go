@@ -3,9 +3,17 @@ package tui
 import (
 	"database/sql"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/glamour"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/analyzer"
+	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/filter"
+	"github.com/lotas/tabsordnung/internal/github"
 	"github.com/lotas/tabsordnung/internal/server"
 	"github.com/lotas/tabsordnung/internal/signal"
 	"github.com/lotas/tabsordnung/internal/storage"
@@ -15,6 +23,7 @@ import (
 
 // Messages returned by TabsView for root Model to handle.
 type showGroupPickerMsg struct{ ids []int }
+type showJumpPickerMsg struct{}
 type showFilterPickerMsg struct{}
 type reloadSessionMsg struct{}
 
@@ -33,15 +42,62 @@ type TabsView struct {
 	// Analysis progress
 	deadChecking   bool
 	githubChecking bool
+	// githubRateLimited is set when the last GitHub check gave up because
+	// GitHub kept throttling requests.
+	githubRateLimited bool
+
+	// closeDupsPending holds the browser IDs of duplicate tabs awaiting
+	// confirmation before they are closed; nil when no prompt is shown.
+	closeDupsPending []int
+	// exactDuplicates compares raw URLs instead of normalized ones when
+	// finding duplicates to close, matching the analyzer.
+	exactDuplicates bool
+
+	// Export of the filtered view: exporting is set while the path
+	// prompt is shown.
+	exporting   bool
+	exportInput string
+
+	// searching is set while the search query is being typed; the query
+	// itself lives in the tree.
+	searching bool
 
 	// Signal capture pipeline
 	signalQueue  []*SignalJob
 	signalActive *SignalJob
 	signalErrors map[string]string
+	// signalBatch counts the captures queued since the queue was last
+	// empty, for showing progress as it drains.
+	signalBatch int
 
 	// Summarization pipeline
 	summarizeJobs   map[string]*SummarizeJob
 	summarizeErrors map[string]string
+	// Whole-group summaries, keyed by group name
+	groupSummarizing   map[string]bool
+	groupSummaryErrors map[string]string
+	// Word counts of readable page content, keyed by URL; loaded from
+	// the database and filled in as pages are fetched.
+	wordCounts   map[string]int
+	wordCounting map[string]bool
+	// Open Graph previews of pages without a summary, keyed by URL; a nil
+	// entry means the page has none (or could not be fetched).
+	// previewPending is the URL whose preview is being waited for.
+	previews       map[string]*summarize.OpenGraph
+	previewPending string
+
+	// Tracking the GitHub, Bugzilla and GitLab items of the open tabs (R):
+	// entityRefresh is the progress shown in the stats line, empty when
+	// idle; entityRefreshPending counts the refreshes still running and
+	// entityRefreshResults collects their outcomes for the final toast.
+	entityRefresh        string
+	entityRefreshPending int
+	entityRefreshResults []string
+
+	// toast is a short notice shown in the bottom bar ("Copied URL");
+	// toastSeq identifies it so an older expiry doesn't clear a newer one.
+	toast    string
+	toastSeq int
 
 	// Dependencies (set at construction, shared by pointer)
 	server      *server.Server
@@ -49,6 +105,8 @@ type TabsView struct {
 	summaryDir  string
 	ollamaModel string
 	ollamaHost  string
+	// summaryPrompt is the tab summarization prompt; nil uses the default.
+	summaryPrompt *summarize.Prompt
 
 	// Shared state (set by root before Update/View)
 	session   *types.SessionData
@@ -62,16 +120,21 @@ type TabsView struct {
 
 func NewTabsView(srv *server.Server, db *sql.DB, summaryDir, ollamaModel, ollamaHost string) TabsView {
 	return TabsView{
-		tree:            TreeModel{DisplayMode: types.TabDisplayTitle},
-		selected:        make(map[int]bool),
-		summarizeJobs:   make(map[string]*SummarizeJob),
-		summarizeErrors: make(map[string]string),
-		signalErrors:    make(map[string]string),
-		server:          srv,
-		db:              db,
-		summaryDir:      summaryDir,
-		ollamaModel:     ollamaModel,
-		ollamaHost:      ollamaHost,
+		tree:               TreeModel{DisplayMode: types.TabDisplayTitle},
+		selected:           make(map[int]bool),
+		summarizeJobs:      make(map[string]*SummarizeJob),
+		summarizeErrors:    make(map[string]string),
+		groupSummarizing:   make(map[string]bool),
+		groupSummaryErrors: make(map[string]string),
+		wordCounts:         make(map[string]int),
+		wordCounting:       make(map[string]bool),
+		previews:           make(map[string]*summarize.OpenGraph),
+		signalErrors:       make(map[string]string),
+		server:             srv,
+		db:                 db,
+		summaryDir:         summaryDir,
+		ollamaModel:        ollamaModel,
+		ollamaHost:         ollamaHost,
 	}
 }
 
@@ -89,6 +152,9 @@ func (v *TabsView) SetSize(w, h int) {
 
 func (v TabsView) FocusDetail() bool { return v.focusDetail }
 
+// Prompting reports whether a confirmation prompt is waiting for input.
+func (v TabsView) Prompting() bool { return v.closeDupsPending != nil || v.exporting || v.searching }
+
 // --- Helper methods (moved from Model) ---
 
 func (v *TabsView) selectedOrCurrentTabIDs() []int {
@@ -113,20 +179,78 @@ func (v *TabsView) processNextSignal() tea.Cmd {
 	v.signalActive = v.signalQueue[0]
 	v.signalQueue = v.signalQueue[1:]
 
-	id, cmd := sendCmdWithID(v.server, server.OutgoingMsg{
+	msg := server.OutgoingMsg{
 		Action: "scrape-activity",
 		TabID:  v.signalActive.Tab.BrowserID,
 		Source: v.signalActive.Source,
-	})
+	}
+	if def, ok := signal.Lookup(v.signalActive.Source); ok {
+		msg.Selectors = def.Selectors
+	}
+	id, cmd := sendCmdWithID(v.server, msg)
 	v.signalActive.ContentID = id
 	return cmd
 }
 
+// requeueSignals puts an interrupted capture back at the head of the queue
+// so it is retried once the extension reconnects.
+func (v *TabsView) requeueSignals() {
+	if v.signalActive == nil {
+		return
+	}
+	v.signalActive.ContentID = ""
+	v.signalQueue = append([]*SignalJob{v.signalActive}, v.signalQueue...)
+	v.signalActive = nil
+}
+
+// resumeSignals restarts queued captures after a reconnect. Browser tab IDs
+// change when the browser restarts, so each job is pointed at the source's
+// tab in the new session; jobs whose source has no tab anymore are dropped.
+func (v *TabsView) resumeSignals() tea.Cmd {
+	queue := v.signalQueue[:0]
+	for _, j := range v.signalQueue {
+		if tab := v.findTabForSource(j.Source); tab != nil {
+			j.Tab = tab
+			queue = append(queue, j)
+		}
+	}
+	v.signalBatch -= len(v.signalQueue) - len(queue)
+	v.signalQueue = queue
+	return v.processNextSignal()
+}
+
+// enqueueSignal adds a capture job to the queue.
+func (v *TabsView) enqueueSignal(job *SignalJob) {
+	if v.signalActive == nil && len(v.signalQueue) == 0 {
+		v.signalBatch = 0
+	}
+	v.signalQueue = append(v.signalQueue, job)
+	v.signalBatch++
+}
+
+// signalProgress describes the capture batch being drained, e.g.
+// "capturing signals 2/5 (slack)", or "" when nothing is being captured.
+func (v TabsView) signalProgress() string {
+	if v.signalActive == nil {
+		return ""
+	}
+	if v.signalBatch <= 1 {
+		return "checking signals..."
+	}
+	done := v.signalBatch - len(v.signalQueue)
+	return fmt.Sprintf("capturing signals %d/%d (%s)...", done, v.signalBatch, v.signalActive.Source)
+}
+
 func (v *TabsView) queueSignalPoll() tea.Cmd {
 	if v.session == nil || !v.connected {
 		return signalPollTick()
 	}
+	return tea.Batch(v.queueAllSignals(), signalPollTick())
+}
 
+// queueAllSignals queues a capture of every signal source with an open
+// tab, one tab per source, skipping sources already queued or in progress.
+func (v *TabsView) queueAllSignals() tea.Cmd {
 	sourceTabs := make(map[string]*types.Tab)
 	for _, tab := range v.session.AllTabs {
 		src := signal.DetectSource(tab.URL)
@@ -145,11 +269,34 @@ func (v *TabsView) queueSignalPoll() tea.Cmd {
 		delete(sourceTabs, j.Source)
 	}
 
-	for src, tab := range sourceTabs {
-		v.signalQueue = append(v.signalQueue, &SignalJob{Tab: tab, Source: src})
+	sources := make([]string, 0, len(sourceTabs))
+	for src := range sourceTabs {
+		sources = append(sources, src)
 	}
+	sort.Strings(sources)
+	for _, src := range sources {
+		v.enqueueSignal(&SignalJob{Tab: sourceTabs[src], Source: src})
+	}
+
+	return v.processNextSignal()
+}
 
-	return tea.Batch(v.processNextSignal(), signalPollTick())
+// reloadSignalCounts re-reads the per-source signal counts and urgencies the
+// tree shows, and the signals of the selected source tab, after signals
+// were captured, changed, classified or resurfaced from a snooze.
+func (v *TabsView) reloadSignalCounts() {
+	if v.signalSource != "" {
+		v.signals, _ = storage.ListSignals(v.db, v.signalSource, true)
+		if v.signalCursor >= len(v.signals) {
+			v.signalCursor = len(v.signals) - 1
+		}
+		if v.signalCursor < 0 {
+			v.signalCursor = 0
+		}
+	}
+	v.tree.SignalCounts, _ = storage.ActiveSignalCounts(v.db)
+	v.tree.SignalUrgency, _ = storage.HighestUrgencyBySource(v.db)
+	v.tree.Invalidate()
 }
 
 func (v *TabsView) refreshSignals() {
@@ -200,6 +347,98 @@ func (v *TabsView) findTabForSource(source string) *types.Tab {
 	return nil
 }
 
+// closedGroupID is the tree group holding the session's recently closed tabs.
+const closedGroupID = "closed"
+
+// removeClosedTab drops a restored tab from the recently closed list.
+func (v *TabsView) removeClosedTab(tab *types.Tab) {
+	for i, t := range v.session.ClosedTabs {
+		if t == tab {
+			v.session.ClosedTabs = append(v.session.ClosedTabs[:i:i], v.session.ClosedTabs[i+1:]...)
+			break
+		}
+	}
+	v.RebuildTree()
+}
+
+// startSummarize summarizes tab, asking the browser for the page content
+// first in live mode. popupID, if set, is the extension popup request to
+// answer with the result. A tab already being summarized gets no second
+// job; the popup request is attached to the running one instead.
+func (v *TabsView) startSummarize(tab *types.Tab, popupID string) tea.Cmd {
+	if job, ok := v.summarizeJobs[tab.URL]; ok {
+		if popupID != "" {
+			job.PopupRequestID = popupID
+		}
+		return nil
+	}
+	delete(v.summarizeErrors, tab.URL)
+	job := &SummarizeJob{Tab: tab, PopupRequestID: popupID}
+	v.summarizeJobs[tab.URL] = job
+	if v.mode == ModeLive && v.connected {
+		id, cmd := sendCmdWithID(v.server, server.OutgoingMsg{
+			Action: "get-content",
+			TabID:  tab.BrowserID,
+		})
+		job.ContentID = id
+		return cmd
+	}
+	return runSummarizeTab(tab, v.summaryDir, v.ollamaModel, v.ollamaHost, v.summaryPrompt)
+}
+
+// setWordCount records a page's word count, persists it and updates the
+// tabs showing that URL.
+func (v *TabsView) setWordCount(url string, words int) {
+	if words <= 0 {
+		return
+	}
+	v.wordCounts[url] = words
+	if v.db != nil {
+		if err := storage.UpsertWordCount(v.db, url, words); err != nil {
+			applog.Error("tui.wordcount.save", err, "url", url)
+		}
+	}
+	v.applyWordCounts()
+}
+
+// previewCmd fetches the Open Graph preview of tab, unless it is cached or
+// already being fetched. Previews are only fetched on request: opening a
+// page, even just for its meta tags, is up to the user.
+func (v *TabsView) previewCmd(tab *types.Tab) tea.Cmd {
+	url := tab.URL
+	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+		return nil
+	}
+	if _, ok := v.previews[url]; ok || url == v.previewPending {
+		return nil
+	}
+	v.previewPending = url
+	return runOpenGraph(url)
+}
+
+// setPreview caches a fetched preview.
+func (v *TabsView) setPreview(url string, og *summarize.OpenGraph) {
+	if og != nil && og.IsEmpty() {
+		og = nil
+	}
+	v.previews[url] = og
+	if v.previewPending == url {
+		v.previewPending = ""
+	}
+}
+
+func (v *TabsView) applyWordCounts() {
+	if v.session == nil {
+		return
+	}
+	for _, tab := range v.session.AllTabs {
+		tab.WordCount = v.wordCounts[tab.URL]
+	}
+	for _, tab := range v.session.ClosedTabs {
+		tab.WordCount = v.wordCounts[tab.URL]
+	}
+}
+
 func (v *TabsView) RebuildTree() {
 	oldCursor := v.tree.Cursor
 	oldOffset := v.tree.Offset
@@ -207,15 +446,40 @@ func (v *TabsView) RebuildTree() {
 	oldFilter := v.tree.Filter
 	oldSavedExpanded := v.tree.SavedExpanded
 	oldDisplayMode := v.tree.DisplayMode
+	oldHideBadges := v.tree.HideGitHubBadges
+	oldSort := v.tree.Sort
+	oldContainer := v.tree.ContainerFilter
+	oldByWindow := v.tree.ByWindow
+	oldQuery := v.tree.Query
+	oldSignalCounts, oldSignalUrgency := v.tree.SignalCounts, v.tree.SignalUrgency
+
+	v.applyWordCounts()
 
-	v.tree = NewTreeModel(v.session.Groups)
+	groups := v.session.Groups
+	if len(v.session.ClosedTabs) > 0 {
+		groups = append(groups[:len(groups):len(groups)], &types.TabGroup{
+			ID:        closedGroupID,
+			Name:      "Recently closed",
+			Collapsed: true,
+			Tabs:      v.session.ClosedTabs,
+		})
+	}
+	v.tree = NewTreeModel(groups)
 	v.tree.Width = v.width * TreeWidthPct / 100
 	v.tree.Height = v.height - 4
 	v.tree.Filter = oldFilter
 	v.tree.SavedExpanded = oldSavedExpanded
 	v.tree.DisplayMode = oldDisplayMode
+	v.tree.HideGitHubBadges = oldHideBadges
+	v.tree.Sort = oldSort
+	v.tree.ContainerFilter = oldContainer
+	v.tree.ByWindow = oldByWindow
+	v.tree.Query = oldQuery
 	v.tree.SummaryDir = v.summaryDir
-	if v.db != nil {
+	// Signal counts are refreshed whenever signals change; only the first
+	// tree has to load them.
+	v.tree.SignalCounts, v.tree.SignalUrgency = oldSignalCounts, oldSignalUrgency
+	if oldSignalCounts == nil && v.db != nil {
 		v.tree.SignalCounts, _ = storage.ActiveSignalCounts(v.db)
 		v.tree.SignalUrgency, _ = storage.HighestUrgencyBySource(v.db)
 	}
@@ -233,6 +497,7 @@ func (v *TabsView) RebuildTree() {
 			}
 		}
 	}
+	v.tree.Invalidate()
 
 	nodes := v.tree.VisibleNodes()
 	if oldCursor >= len(nodes) {
@@ -276,6 +541,24 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 		return v, nil
 
 	case tea.KeyMsg:
+		if v.exporting {
+			return v.updateExportPrompt(msg)
+		}
+		if v.searching {
+			return v.updateSearchPrompt(msg)
+		}
+		if v.closeDupsPending != nil {
+			ids := v.closeDupsPending
+			v.closeDupsPending = nil
+			if msg.String() == "y" && v.mode == ModeLive && v.connected {
+				return v, sendCmd(v.server, server.OutgoingMsg{
+					Action: "close",
+					TabIDs: ids,
+				})
+			}
+			return v, nil
+		}
+
 		// Tab toggles pane focus
 		switch msg.String() {
 		case "tab", "shift+tab":
@@ -284,7 +567,7 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 				v.detail.Scroll = 0
 			} else {
 				node := v.tree.SelectedNode()
-				if node != nil && (node.Tab != nil || node.Group != nil) {
+				if node != nil && (node.Tab != nil || node.Group != nil || node.Window != nil) {
 					v.focusDetail = true
 				}
 			}
@@ -368,7 +651,15 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 		case "enter":
 			if v.mode == ModeLive && v.connected {
 				node := v.tree.SelectedNode()
-				if node != nil && node.Tab != nil {
+				if node != nil && node.Tab != nil && node.Tab.SessionID != "" {
+					cmd := sendCmd(v.server, server.OutgoingMsg{
+						Action:    "restore-tab",
+						SessionID: node.Tab.SessionID,
+					})
+					v.removeClosedTab(node.Tab)
+					return v, cmd
+				}
+				if node != nil && node.Tab != nil && node.Tab.BrowserID != 0 {
 					return v, sendCmd(v.server, server.OutgoingMsg{
 						Action: "focus",
 						TabID:  node.Tab.BrowserID,
@@ -376,7 +667,7 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 				}
 			}
 			node := v.tree.SelectedNode()
-			if node != nil && node.Group != nil {
+			if node != nil && (node.Group != nil || node.Window != nil) {
 				v.tree.Toggle()
 			} else if node != nil && node.Tab != nil {
 				v.focusDetail = true
@@ -388,23 +679,50 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 		case "s":
 			node := v.tree.SelectedNode()
 			if node != nil && node.Tab != nil {
-				url := node.Tab.URL
-				if _, exists := v.summarizeJobs[url]; exists {
-					break
-				}
-				delete(v.summarizeErrors, url)
-				job := &SummarizeJob{Tab: node.Tab}
-				v.summarizeJobs[url] = job
-				if v.mode == ModeLive && v.connected {
-					id, cmd := sendCmdWithID(v.server, server.OutgoingMsg{
-						Action: "get-content",
-						TabID:  node.Tab.BrowserID,
-					})
-					job.ContentID = id
-					return v, cmd
-				}
-				return v, runSummarizeTab(node.Tab, v.summaryDir, v.ollamaModel, v.ollamaHost)
+				return v, v.startSummarize(node.Tab, "")
+			}
+		case "S":
+			g := v.tree.SelectedGroup()
+			if g == nil || len(g.Tabs) == 0 || v.groupSummarizing[g.Name] {
+				break
+			}
+			delete(v.groupSummaryErrors, g.Name)
+			v.groupSummarizing[g.Name] = true
+			return v, runSummarizeGroup(g, v.summaryDir, v.ollamaModel, v.ollamaHost)
+		case "e":
+			node := v.tree.SelectedNode()
+			if node == nil || node.Tab == nil || v.wordCounting[node.Tab.URL] {
+				break
+			}
+			v.wordCounting[node.Tab.URL] = true
+			return v, runWordCount(node.Tab.URL)
+		case "i":
+			node := v.tree.SelectedNode()
+			if node == nil || node.Tab == nil {
+				break
+			}
+			return v, v.previewCmd(node.Tab)
+		case "C":
+			if v.session == nil || v.db == nil {
+				break
+			}
+			return v, createSnapshot(v.db, v.session)
+		case "E":
+			if v.session == nil {
+				break
+			}
+			v.exporting = true
+			v.exportInput = ""
+			return v, nil
+		case "y", "Y":
+			node := v.tree.SelectedNode()
+			if node == nil || node.Tab == nil || node.Tab.URL == "" {
+				break
+			}
+			if msg.String() == "y" {
+				return v, copyToClipboard(node.Tab.URL, "URL")
 			}
+			return v, copyToClipboard(markdownLink(node.Tab), "markdown link")
 		case "c":
 			if v.mode != ModeLive || !v.connected {
 				break
@@ -431,11 +749,50 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 				break
 			}
 			delete(v.signalErrors, source)
-			job := &SignalJob{Tab: node.Tab, Source: source}
-			v.signalQueue = append(v.signalQueue, job)
+			v.enqueueSignal(&SignalJob{Tab: node.Tab, Source: source})
 			return v, v.processNextSignal()
+		case "R":
+			if v.entityRefresh != "" || v.session == nil || v.db == nil {
+				break
+			}
+			v.entityRefresh = "finding issues and PRs in tabs..."
+			return v, extractTabEntitiesCmd(v.db, v.session.AllTabs)
+		case "A":
+			if v.mode != ModeLive || !v.connected || v.session == nil {
+				break
+			}
+			clear(v.signalErrors)
+			return v, v.queueAllSignals()
 		case "t":
 			v.tree.CycleDisplayMode()
+		case "b":
+			v.tree.HideGitHubBadges = !v.tree.HideGitHubBadges
+		case "O":
+			v.tree.CycleSort()
+		case "o":
+			node := v.tree.SelectedNode()
+			if node == nil || node.Tab == nil {
+				break
+			}
+			return v, openTabInBrowser(node.Tab.URL)
+		case "G":
+			if v.session != nil {
+				return v, func() tea.Msg { return showJumpPickerMsg{} }
+			}
+		case "*":
+			v.tree.SetAllExpanded(true)
+		case "-":
+			v.tree.SetAllExpanded(false)
+		case "w":
+			v.tree.ToggleByWindow()
+		case "n":
+			if v.tree.JumpToFlagged(1) {
+				v.refreshSignals()
+			}
+		case "N":
+			if v.tree.JumpToFlagged(-1) {
+				v.refreshSignals()
+			}
 		case "f":
 			return v, func() tea.Msg { return showFilterPickerMsg{} }
 		case "r":
@@ -470,6 +827,20 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 			}
 			v.tree.MoveDown()
 			v.refreshSignals()
+		case "D":
+			if v.mode != ModeLive || !v.connected || v.session == nil {
+				return v, nil
+			}
+			var ids []int
+			for _, tab := range analyzer.DuplicatesToClose(v.session.AllTabs, v.exactDuplicates) {
+				if tab.BrowserID != 0 {
+					ids = append(ids, tab.BrowserID)
+				}
+			}
+			if len(ids) > 0 {
+				v.closeDupsPending = ids
+			}
+			return v, nil
 		case "g":
 			if v.mode != ModeLive || !v.connected || v.session == nil {
 				return v, nil
@@ -479,7 +850,14 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 				return v, nil
 			}
 			return v, func() tea.Msg { return showGroupPickerMsg{ids: ids} }
+		case "/":
+			v.searching = true
 		case "esc":
+			if v.tree.Query != "" {
+				v.tree.SetQuery("")
+				v.refreshSignals()
+				break
+			}
 			v.selected = make(map[int]bool)
 		}
 		return v, nil
@@ -487,6 +865,45 @@ func (v TabsView) Update(msg tea.Msg) (TabsView, tea.Cmd) {
 	return v, nil
 }
 
+// updateSearchPrompt handles typing the search query. The tree follows
+// every keystroke; enter keeps the query, esc clears it.
+func (v TabsView) updateSearchPrompt(msg tea.KeyMsg) (TabsView, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.searching = false
+		v.tree.SetQuery("")
+	case tea.KeyEnter:
+		v.searching = false
+	case tea.KeyBackspace:
+		if q := []rune(v.tree.Query); len(q) > 0 {
+			v.tree.SetQuery(string(q[:len(q)-1]))
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		v.tree.SetQuery(v.tree.Query + string(msg.Runes))
+	}
+	v.refreshSignals()
+	return v, nil
+}
+
+// updateExportPrompt handles typing the path the filtered view is exported
+// to. An empty path copies the markdown to the clipboard instead.
+func (v TabsView) updateExportPrompt(msg tea.KeyMsg) (TabsView, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEsc:
+		v.exporting = false
+	case tea.KeyEnter:
+		v.exporting = false
+		return v, exportView(v.tree.FilteredSession(v.session), strings.TrimSpace(v.exportInput))
+	case tea.KeyBackspace:
+		if p := []rune(v.exportInput); len(p) > 0 {
+			v.exportInput = string(p[:len(p)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		v.exportInput += string(msg.Runes)
+	}
+	return v, nil
+}
+
 // --- View methods ---
 
 func (v TabsView) ViewList() string {
@@ -531,29 +948,57 @@ func (v TabsView) ViewDetail() string {
 			var summaryText string
 			sumPath := summarize.SummaryPath(v.summaryDir, node.Tab.URL, node.Tab.Title)
 			if raw, err := summarize.ReadSummary(sumPath); err == nil {
-				r, _ := glamour.NewTermRenderer(
-					glamour.WithStylePath("dark"),
-					glamour.WithWordWrap(detailWidth-2),
-				)
-				if rendered, err := r.Render(raw); err == nil {
-					summaryText = rendered
-				} else {
-					summaryText = raw
+				summaryText = v.renderMarkdown(raw, detailWidth)
+			}
+			job, isSummarizing := v.summarizeJobs[node.Tab.URL]
+			if isSummarizing {
+				// Show the streamed text rather than a previous summary.
+				summaryText = ""
+				if job.Partial != "" {
+					summaryText = lipgloss.NewStyle().Width(detailWidth - 2).Render(job.Partial)
 				}
 			}
-			_, isSummarizing := v.summarizeJobs[node.Tab.URL]
 			tabErr := v.summarizeErrors[node.Tab.URL]
-			detailContent = v.detail.ViewTabWithSummary(node.Tab, summaryText, isSummarizing, tabErr)
+			detailContent = v.detail.ViewTabWithSummary(node.Tab, summaryText, isSummarizing, tabErr, v.previews[node.Tab.URL])
 		}
 	} else if node.Group != nil {
-		detailContent = v.detail.ViewGroup(node.Group)
+		var summaryText string
+		if raw, err := summarize.ReadSummary(summarize.GroupSummaryPath(v.summaryDir, node.Group.Name)); err == nil {
+			summaryText = v.renderMarkdown(raw, detailWidth)
+		}
+		name := node.Group.Name
+		detailContent = v.detail.ViewGroupWithSummary(node.Group, summaryText, v.groupSummarizing[name], v.groupSummaryErrors[name])
+	} else if node.Window != nil {
+		detailContent = v.detail.ViewWindow(node.Window)
 	}
 
 	return v.detail.ViewScrolled(detailContent)
 }
 
+// renderMarkdown renders a summary for the detail pane, falling back to the
+// raw text if glamour fails.
+func (v TabsView) renderMarkdown(raw string, width int) string {
+	r, err := glamour.NewTermRenderer(
+		glamour.WithStylePath(theme.Markdown),
+		glamour.WithWordWrap(width-2),
+	)
+	if err != nil {
+		return raw
+	}
+	if rendered, err := r.Render(raw); err == nil {
+		return rendered
+	}
+	return raw
+}
+
 func (v TabsView) StatsString() string {
 	s := fmt.Sprintf("%d tabs \u00b7 %d groups", v.stats.TotalTabs, v.stats.TotalGroups)
+	if v.stats.TotalWindows > 1 {
+		s = fmt.Sprintf("%d windows \u00b7 %s", v.stats.TotalWindows, s)
+	}
+	if v.stats.PinnedTabs > 0 {
+		s += fmt.Sprintf(" \u00b7 %d pinned", v.stats.PinnedTabs)
+	}
 	if v.stats.DeadTabs > 0 {
 		s += fmt.Sprintf(" \u00b7 %d dead", v.stats.DeadTabs)
 	}
@@ -570,32 +1015,75 @@ func (v TabsView) StatsString() string {
 		s += " \u00b7 checking links..."
 	}
 	if v.githubChecking {
-		s += " \u00b7 checking github..."
+		if wait := time.Until(github.ThrottledUntil()); wait > 0 {
+			s += fmt.Sprintf(" \u00b7 github throttled, retrying in %s...", wait.Round(time.Second))
+		} else {
+			s += " \u00b7 checking github..."
+		}
+	} else if v.githubRateLimited {
+		s += " \u00b7 github rate limited"
 	}
 	if n := len(v.summarizeJobs); n == 1 {
 		s += " \u00b7 summarizing 1 tab..."
 	} else if n > 1 {
 		s += fmt.Sprintf(" \u00b7 summarizing %d tabs...", n)
 	}
-	if v.signalActive != nil {
-		s += " \u00b7 checking signals..."
+	if n := len(v.groupSummarizing); n > 0 {
+		s += fmt.Sprintf(" \u00b7 summarizing %d group(s)...", n)
+	}
+	if p := v.signalProgress(); p != "" {
+		s += " \u00b7 " + p
+	}
+	if v.entityRefresh != "" {
+		s += " \u00b7 " + v.entityRefresh
 	}
 	return s
 }
 
+// markdownLink formats a tab as a markdown link, falling back to the URL
+// when the tab has no title.
+func markdownLink(tab *types.Tab) string {
+	title := tab.Title
+	if title == "" {
+		title = tab.URL
+	}
+	return fmt.Sprintf("[%s](%s)", title, tab.URL)
+}
+
 func (v TabsView) BottomBar() string {
+	if v.exporting {
+		n := len(v.tree.FilteredSession(v.session).AllTabs)
+		return fmt.Sprintf("Export %d tab(s) to (.json for JSON, empty copies markdown): %s_ \u00b7 esc cancel", n, v.exportInput)
+	}
+	if v.searching {
+		n := len(v.tree.FilteredSession(v.session).AllTabs)
+		return fmt.Sprintf("/%s_ \u00b7 %d match(es) \u00b7 enter keep \u00b7 esc clear", v.tree.Query, n)
+	}
+	if n := len(v.closeDupsPending); n > 0 {
+		return fmt.Sprintf("Close %d duplicate tab(s), keeping the most recently used copy of each? y confirm \u00b7 any other key cancels", n)
+	}
 	var s string
+	if v.toast != "" {
+		s = v.toast + " \u00b7 "
+	}
 	if v.mode == ModeLive && v.connected {
 		selCount := len(v.selected)
 		if selCount > 0 {
 			s = fmt.Sprintf("%d selected \u00b7 x close \u00b7 g move \u00b7 esc clear \u00b7 ", selCount)
 		}
-		s += "space select \u00b7 enter focus \u00b7 "
+		s += "space select \u00b7 enter focus \u00b7 D close dups \u00b7 "
+	}
+	filterStr := fmt.Sprintf("[filter: %s]", filter.Label(v.tree.Filter))
+	if v.tree.Filter == types.FilterContainer {
+		filterStr = fmt.Sprintf("[filter: %s]", v.tree.ContainerFilter)
+	}
+	if v.tree.Query != "" {
+		filterStr += fmt.Sprintf(" [search: %s]", v.tree.Query)
 	}
-	filterNames := []string{"all", "stale", "dead", "duplicate", ">7d", ">30d", ">90d", "gh done", "summarized", "unsummarized"}
-	filterStr := fmt.Sprintf("[filter: %s]", filterNames[v.tree.Filter])
 	displayNames := []string{"URL", "Title", "Both"}
 	displayStr := fmt.Sprintf("[T: %s]", displayNames[v.tree.DisplayMode])
-	s += "\u2191\u2193/jk navigate \u00b7 tab focus \u00b7 s summarize \u00b7 c signal \u00b7 f filter \u00b7 t display \u00b7 r refresh \u00b7 1-6 view \u00b7 p source \u00b7 q quit  " + filterStr + " " + displayStr
+	sortNames := []string{"native", "title", "recent", "oldest"}
+	displayStr += fmt.Sprintf(" [O: %s]", sortNames[v.tree.Sort])
+	s += "\u2191\u2193/jk navigate \u00b7 n/N next/prev flagged \u00b7 tab focus \u00b7 s/S summarize tab/group \u00b7 e reading time \u00b7 i preview \u00b7 y/Y copy url/link \u00b7 E export view \u00b7 C snapshot \u00b7 c signal \u00b7 A all signals \u00b7 / search \u00b7 G go to group \u00b7 f filter \u00b7 t display \u00b7 o open \u00b7 O sort \u00b7 w windows \u00b7 */- expand/collapse all \u00b7 b gh badges \u00b7 R track items \u00b7 r refresh \u00b7 1-8 view \u00b7 p source \u00b7 q quit  " + filterStr + " " + displayStr
 	return s
 }
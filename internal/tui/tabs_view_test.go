@@ -0,0 +1,210 @@
+package tui
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/server"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// testModel returns a Model in offline mode with tabs loaded into one
+// group, and the tree cursor on the first tab.
+func testModel(t *testing.T, tabs ...*types.Tab) Model {
+	t.Helper()
+	db, err := storage.OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m := NewModel(Options{
+		Profiles:    []types.Profile{{Name: "test"}},
+		StaleDays:   7,
+		Server:      server.New(0),
+		SummaryDir:  t.TempDir(),
+		OllamaModel: "model",
+		OllamaHost:  "http://127.0.0.1:0",
+		DB:          db,
+	})
+	data := &types.SessionData{
+		Profile: types.Profile{Name: "test"},
+		Groups:  []*types.TabGroup{{ID: "g1", Name: "Work", Tabs: tabs}},
+		AllTabs: tabs,
+	}
+	next, _ := m.Update(sessionLoadedMsg{data: data})
+	m = next.(Model)
+	m.tabsView.tree.Expanded["g1"] = true
+	m.tabsView.tree.Invalidate()
+	for i, n := range m.tabsView.tree.VisibleNodes() {
+		if n.Tab != nil {
+			m.tabsView.tree.Cursor = i
+			break
+		}
+	}
+	return m
+}
+
+func key(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestSummarizeKeyAndPopupShareJob(t *testing.T) {
+	tab := &types.Tab{URL: "https://example.com/a", Title: "A", BrowserID: 7, LastAccessed: time.Now()}
+	m := testModel(t, tab)
+	m.tabsView.summarizeErrors[tab.URL] = "timeout"
+
+	next, cmd := m.Update(key("s"))
+	m = next.(Model)
+	if cmd == nil {
+		t.Fatal("s returned no command")
+	}
+	job := m.tabsView.summarizeJobs[tab.URL]
+	if job == nil {
+		t.Fatal("s started no summarize job")
+	}
+	if _, ok := m.tabsView.summarizeErrors[tab.URL]; ok {
+		t.Error("s kept the previous summarize error")
+	}
+
+	// The extension popup asking for the same tab joins the running job.
+	next, _ = m.Update(wsSummarizeTabMsg{id: "popup-1", tabID: 7})
+	m = next.(Model)
+	if got := m.tabsView.summarizeJobs[tab.URL]; got != job {
+		t.Fatal("popup request replaced the running job")
+	}
+	if job.PopupRequestID != "popup-1" {
+		t.Errorf("PopupRequestID = %q, want popup-1", job.PopupRequestID)
+	}
+
+	// Pressing s again doesn't drop the popup request.
+	next, _ = m.Update(key("s"))
+	m = next.(Model)
+	if job.PopupRequestID != "popup-1" {
+		t.Errorf("PopupRequestID = %q after s, want popup-1", job.PopupRequestID)
+	}
+}
+
+func TestPopupSummarizeClearsError(t *testing.T) {
+	tab := &types.Tab{URL: "https://example.com/b", Title: "B", BrowserID: 8, LastAccessed: time.Now()}
+	m := testModel(t, tab)
+	m.tabsView.summarizeErrors[tab.URL] = "timeout"
+
+	next, _ := m.Update(wsAutoSummarizeMsg{id: "popup-2", tabID: 8, url: tab.URL})
+	m = next.(Model)
+	job := m.tabsView.summarizeJobs[tab.URL]
+	if job == nil || job.PopupRequestID != "popup-2" {
+		t.Fatalf("job = %+v, want one for popup-2", job)
+	}
+	if _, ok := m.tabsView.summarizeErrors[tab.URL]; ok {
+		t.Error("popup summarize kept the previous summarize error")
+	}
+}
+
+func TestKeysReachTabsViewOnlyWhenActive(t *testing.T) {
+	tab := &types.Tab{URL: "https://example.com/c", Title: "C", BrowserID: 9, LastAccessed: time.Now()}
+	m := testModel(t, tab)
+
+	m.activeView = ViewSignals
+	next, _ := m.Update(key("s"))
+	m = next.(Model)
+	if len(m.tabsView.summarizeJobs) != 0 {
+		t.Fatal("s in the Signals view summarized a tab")
+	}
+
+	m.activeView = ViewTabs
+	next, _ = m.Update(key("/"))
+	m = next.(Model)
+	if !m.tabsView.Prompting() {
+		t.Fatal("/ did not open the search prompt")
+	}
+	// While the prompt is open, view and global keys are typed into it.
+	for _, k := range []string{"2", "q", "s"} {
+		next, _ = m.Update(key(k))
+		m = next.(Model)
+	}
+	if m.activeView != ViewTabs {
+		t.Errorf("activeView = %v, want the Tabs view", m.activeView)
+	}
+	if m.tabsView.tree.Query != "2qs" {
+		t.Errorf("Query = %q, want 2qs", m.tabsView.tree.Query)
+	}
+	if len(m.tabsView.summarizeJobs) != 0 {
+		t.Error("s typed into the search prompt summarized a tab")
+	}
+}
+
+func TestTrackTabEntities(t *testing.T) {
+	tab := &types.Tab{URL: "https://example.com/d", Title: "D", LastAccessed: time.Now()}
+	m := testModel(t, tab)
+
+	next, _ := m.Update(key("R"))
+	m = next.(Model)
+	if m.tabsView.entityRefresh == "" {
+		t.Fatal("R started no tracking")
+	}
+	if !strings.Contains(m.tabsView.StatsString(), m.tabsView.entityRefresh) {
+		t.Errorf("stats line %q lacks the progress", m.tabsView.StatsString())
+	}
+
+	// Without linked items there is nothing to refresh: tracking ends.
+	next, cmd := m.Update(extractTabEntitiesCmd(m.db, m.session.AllTabs)())
+	m = next.(Model)
+	if m.tabsView.entityRefresh != "" {
+		t.Errorf("entityRefresh = %q after finding nothing", m.tabsView.entityRefresh)
+	}
+	if msg, ok := cmd().(toastMsg); !ok || msg.text == "" {
+		t.Errorf("got %#v, want a toast", msg)
+	}
+}
+
+func TestPreviewOnlyOnRequest(t *testing.T) {
+	a := &types.Tab{URL: "https://example.com/e", Title: "E", LastAccessed: time.Now()}
+	b := &types.Tab{URL: "about:config", Title: "F", LastAccessed: time.Now()}
+	m := testModel(t, a, b)
+
+	// Resting on a tab fetches nothing.
+	next, _ := m.Update(key("j"))
+	m = next.(Model)
+	next, _ = m.Update(key("k"))
+	m = next.(Model)
+	if m.tabsView.previewPending != "" {
+		t.Fatalf("moving the cursor started a preview of %s", m.tabsView.previewPending)
+	}
+
+	next, cmd := m.Update(key("i"))
+	m = next.(Model)
+	if cmd == nil || m.tabsView.previewPending != a.URL {
+		t.Fatalf("i did not fetch the preview of %s", a.URL)
+	}
+
+	// Pages that aren't http(s) are never fetched.
+	next, _ = m.Update(key("j"))
+	m = next.(Model)
+	if node := m.tabsView.tree.SelectedNode(); node == nil || node.Tab != b {
+		t.Fatal("j did not move to the about:config tab")
+	}
+	if _, cmd = m.Update(key("i")); cmd != nil {
+		t.Error("i fetched a preview of about:config")
+	}
+}
+
+func TestExportPromptBackspaceRemovesRune(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	next, _ := m.Update(key("E"))
+	m = next.(Model)
+	if !m.tabsView.exporting {
+		t.Fatal("E did not open the export prompt")
+	}
+	next, _ = m.Update(key("/tmp/Ü"))
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	m = next.(Model)
+	if m.tabsView.exportInput != "/tmp/" {
+		t.Errorf("exportInput = %q, want /tmp/", m.tabsView.exportInput)
+	}
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds the colors the TUI draws with. Views read the active theme
+// each time they render, so changing it takes effect on the next frame.
+type Theme struct {
+	Accent    lipgloss.Color `json:"accent"`     // focused borders, active view, picker frames
+	Label     lipgloss.Color `json:"label"`      // detail pane labels, counts
+	Dim       lipgloss.Color `json:"dim"`        // secondary text, inactive borders, hints
+	Text      lipgloss.Color `json:"text"`       // emphasized body text (signal snippets)
+	Error     lipgloss.Color `json:"error"`      // dead links, failures, urgent signals
+	Warn      lipgloss.Color `json:"warn"`       // stale tabs, pending work
+	OK        lipgloss.Color `json:"ok"`         // done, merged, passing
+	Open      lipgloss.Color `json:"open"`       // open GitHub issues and PRs
+	Info      lipgloss.Color `json:"info"`       // duplicates in the same group
+	InfoMuted lipgloss.Color `json:"info_muted"` // duplicates elsewhere
+	Highlight lipgloss.Color `json:"highlight"`  // signals, badges
+	Summary   lipgloss.Color `json:"summary"`    // summary markers
+	Container lipgloss.Color `json:"container"`  // Firefox container names
+	Ref       lipgloss.Color `json:"ref"`        // bug and issue references
+
+	// Markdown is the glamour style summaries are rendered with.
+	Markdown string `json:"markdown"`
+}
+
+// themes are the built-in themes. "dark" is the original palette.
+var themes = map[string]Theme{
+	"dark": {
+		Accent: "62", Label: "245", Dim: "240", Text: "252",
+		Error: "196", Warn: "214", OK: "42", Open: "135",
+		Info: "33", InfoMuted: "67", Highlight: "220", Summary: "51",
+		Container: "73", Ref: "44", Markdown: "dark",
+	},
+	"light": {
+		Accent: "25", Label: "240", Dim: "244", Text: "236",
+		Error: "160", Warn: "166", OK: "28", Open: "90",
+		Info: "25", InfoMuted: "60", Highlight: "136", Summary: "30",
+		Container: "30", Ref: "31", Markdown: "light",
+	},
+	"high-contrast": {
+		Accent: "12", Label: "15", Dim: "250", Text: "15",
+		Error: "9", Warn: "11", OK: "10", Open: "13",
+		Info: "14", InfoMuted: "14", Highlight: "11", Summary: "14",
+		Container: "14", Ref: "14", Markdown: "dark",
+	},
+}
+
+// theme is the active theme.
+var theme = themes["dark"]
+
+// colorDisabled is set by DisableColor.
+var colorDisabled bool
+
+// DisableColor turns off all colors, for NO_COLOR and --no-color. Bold,
+// underline and reverse video are kept, so cursors and the focused pane
+// stay visible.
+func DisableColor() {
+	colorDisabled = true
+	theme = Theme{Markdown: "notty"}
+}
+
+// paneBorder is the border of a pane. Without colors the focused pane gets
+// a thick border instead of the accent color.
+func paneBorder(focused bool) lipgloss.Border {
+	if focused && colorDisabled {
+		return lipgloss.ThickBorder()
+	}
+	return lipgloss.RoundedBorder()
+}
+
+// ThemeNames lists the accepted --theme values.
+func ThemeNames() []string {
+	names := []string{"auto"}
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// ThemeFilePath returns the path of the optional theme file, a JSON object
+// with a "base" theme name and color overrides keyed like Theme's fields,
+// e.g. {"base": "light", "dim": "243"}.
+func ThemeFilePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "tabsordnung", "theme.json")
+}
+
+// SetTheme activates the named built-in theme and applies the overrides in
+// the theme file at path, if it exists. An empty name uses the file's base,
+// falling back to "auto", which picks dark or light from the terminal
+// background.
+func SetTheme(name, path string) error {
+	var data []byte
+	if path != "" {
+		var err error
+		data, err = os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if name == "" && len(data) > 0 {
+		var file struct {
+			Base string `json:"base"`
+		}
+		if err := json.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		name = file.Base
+	}
+
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "auto" {
+		name = "light"
+		if lipgloss.HasDarkBackground() {
+			name = "dark"
+		}
+	}
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (want one of %s)", name, strings.Join(ThemeNames(), ", "))
+	}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &t); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+	}
+	theme = t
+	return nil
+}
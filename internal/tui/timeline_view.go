@@ -0,0 +1,317 @@
+package tui
+
+import (
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/github"
+	"github.com/lotas/tabsordnung/internal/storage"
+)
+
+// timelineLimit bounds how many events the timeline loads.
+const timelineLimit = 500
+
+type timelineLoadedMsg struct {
+	events []storage.TimelineEvent
+	err    error
+}
+
+type timelineNode struct {
+	IsHeader bool
+	Header   string
+	Event    *storage.TimelineEvent
+}
+
+// TimelineView is a chronological feed of GitHub, Bugzilla and GitLab
+// entity events and snapshot creations, newest first, grouped by day.
+type TimelineView struct {
+	db      *sql.DB
+	events  []storage.TimelineEvent
+	nodes   []timelineNode
+	cursor  int
+	offset  int
+	detail  DetailModel
+	width   int
+	height  int
+	loading bool
+	err     error
+
+	focusDetail bool
+}
+
+func NewTimelineView(db *sql.DB) TimelineView {
+	return TimelineView{db: db}
+}
+
+func (v *TimelineView) Reload() tea.Cmd {
+	v.loading = true
+	db := v.db
+	return func() tea.Msg {
+		events, err := storage.ListRecentEvents(db, timelineLimit)
+		return timelineLoadedMsg{events: events, err: err}
+	}
+}
+
+func (v *TimelineView) SetSize(w, h int) {
+	v.width = w
+	v.height = h
+	v.detail.Width = w - (w * TreeWidthPct / 100) - 4
+	v.detail.Height = h
+}
+
+func (v *TimelineView) buildNodes() {
+	v.nodes = nil
+	day := ""
+	for i := range v.events {
+		ev := &v.events[i]
+		if d := ev.CreatedAt.Local().Format("2006-01-02"); d != day {
+			day = d
+			v.nodes = append(v.nodes, timelineNode{
+				IsHeader: true,
+				Header:   ev.CreatedAt.Local().Format("Mon 2006-01-02"),
+			})
+		}
+		v.nodes = append(v.nodes, timelineNode{Event: ev})
+	}
+}
+
+func (v *TimelineView) selectedEvent() *storage.TimelineEvent {
+	if v.cursor >= 0 && v.cursor < len(v.nodes) {
+		return v.nodes[v.cursor].Event
+	}
+	return nil
+}
+
+func (v TimelineView) FocusDetail() bool { return v.focusDetail }
+
+func (v TimelineView) Update(msg tea.Msg) (TimelineView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case timelineLoadedMsg:
+		v.loading = false
+		if msg.err != nil {
+			v.err = msg.err
+			return v, nil
+		}
+		v.err = nil
+		v.events = msg.events
+		v.buildNodes()
+		if v.cursor >= len(v.nodes) {
+			v.cursor = len(v.nodes) - 1
+		}
+		if v.cursor < 0 {
+			v.cursor = 0
+		}
+		return v, nil
+
+	case tea.MouseMsg:
+		treeWidth := v.width * TreeWidthPct / 100
+		onDetail := msg.X > treeWidth+1
+		switch msg.Button {
+		case tea.MouseButtonLeft:
+			v.focusDetail = onDetail
+		case tea.MouseButtonWheelUp:
+			if onDetail {
+				v.detail.ScrollUp()
+			} else if v.cursor > 0 {
+				v.cursor--
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case tea.MouseButtonWheelDown:
+			if onDetail {
+				v.detail.ScrollDown()
+			} else if v.cursor < len(v.nodes)-1 {
+				v.cursor++
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		}
+		return v, nil
+
+	case tea.KeyMsg:
+		if v.focusDetail {
+			switch msg.String() {
+			case "esc":
+				v.focusDetail = false
+				v.detail.Scroll = 0
+			case "j", "down":
+				v.detail.ScrollDown()
+			case "k", "up":
+				v.detail.ScrollUp()
+			}
+			return v, nil
+		}
+
+		switch msg.String() {
+		case "j", "down":
+			if v.cursor < len(v.nodes)-1 {
+				v.cursor++
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case "k", "up":
+			if v.cursor > 0 {
+				v.cursor--
+				v.adjustOffset()
+				v.detail.Scroll = 0
+			}
+		case "enter", "tab":
+			if v.selectedEvent() != nil {
+				v.focusDetail = true
+			}
+		case "o":
+			if ev := v.selectedEvent(); ev != nil {
+				if url := timelineEventURL(ev); url != "" {
+					return v, openTabInBrowser(url)
+				}
+			}
+		case "r":
+			return v, v.Reload()
+		}
+	}
+	return v, nil
+}
+
+// timelineEventURL returns the web page of the event's entity, or "" for
+// snapshots.
+func timelineEventURL(ev *storage.TimelineEvent) string {
+	switch ev.Source {
+	case "github":
+		// Ref is "owner/repo#42".
+		path, num, ok := strings.Cut(ev.Ref, "#")
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("https://github.com/%s/%s/%s", path, github.KindPath(ev.Kind), num)
+	case "bugzilla":
+		host, id, ok := strings.Cut(ev.Ref, "#")
+		if !ok {
+			return ""
+		}
+		return fmt.Sprintf("https://%s/show_bug.cgi?id=%s", host, id)
+	case "gitlab":
+		// Ref is "host/group/project!7" for MRs, "host/group/project#7" for issues.
+		host, ref, ok := strings.Cut(ev.Ref, "/")
+		if !ok {
+			return ""
+		}
+		i := strings.LastIndexAny(ref, "!#")
+		if i < 0 {
+			return ""
+		}
+		num, err := strconv.Atoi(ref[i+1:])
+		if err != nil {
+			return ""
+		}
+		return storage.GitLabURL(host, ref[:i], ev.Kind, num)
+	}
+	return ""
+}
+
+func (v *TimelineView) adjustOffset() {
+	if v.cursor < v.offset {
+		v.offset = v.cursor
+	}
+	visible := v.height - 2
+	if visible < 1 {
+		visible = 1
+	}
+	if v.cursor >= v.offset+visible {
+		v.offset = v.cursor - visible + 1
+	}
+}
+
+func (v TimelineView) ViewList() string {
+	if v.loading {
+		return "Loading timeline..."
+	}
+	if v.err != nil {
+		return fmt.Sprintf("Error: %v", v.err)
+	}
+	if len(v.nodes) == 0 {
+		return "Nothing has happened yet.\n\n  GitHub, Bugzilla and GitLab events\n  and snapshots show up here."
+	}
+
+	treeWidth := v.width * TreeWidthPct / 100
+	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
+	headerStyle := lipgloss.NewStyle().Bold(true)
+	timeStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+
+	var b strings.Builder
+	end := v.offset + v.height
+	if end > len(v.nodes) {
+		end = len(v.nodes)
+	}
+	for i := v.offset; i < end; i++ {
+		node := v.nodes[i]
+		var line string
+		if node.IsHeader {
+			line = headerStyle.Render(node.Header)
+		} else {
+			ts := node.Event.CreatedAt.Local().Format("15:04")
+			text := truncateString(node.Event.Describe(), max(treeWidth-10, 10))
+			line = "  " + timeStyle.Render(ts) + " " + text
+		}
+		if i == v.cursor {
+			for lipgloss.Width(line) < treeWidth {
+				line += " "
+			}
+			line = cursorStyle.Render(line)
+		}
+		b.WriteString(line)
+		if i < end-1 {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func (v TimelineView) ViewDetail() string {
+	ev := v.selectedEvent()
+	if ev == nil {
+		return ""
+	}
+
+	labelStyle := lipgloss.NewStyle().Bold(true).Foreground(theme.Label)
+	valueStyle := lipgloss.NewStyle()
+	headerBoldStyle := lipgloss.NewStyle().Bold(true)
+
+	var b strings.Builder
+	b.WriteString(headerBoldStyle.Render(ev.Describe()) + "\n\n")
+
+	b.WriteString(labelStyle.Render("When") + "\n")
+	b.WriteString(valueStyle.Render(ev.CreatedAt.Local().Format("2006-01-02 15:04")) + "\n\n")
+
+	if ev.Source == "snapshot" {
+		b.WriteString(labelStyle.Render("Snapshot") + "\n")
+		b.WriteString(valueStyle.Render(fmt.Sprintf("#%d · %s · %d tabs", ev.Rev, ev.Ref, ev.TabCount)) + "\n\n")
+		if ev.Title != "" {
+			b.WriteString(labelStyle.Render("Name") + "\n")
+			b.WriteString(valueStyle.Render(ev.Title) + "\n\n")
+		}
+		return v.detail.ViewScrolled(b.String())
+	}
+
+	b.WriteString(labelStyle.Render("Entity") + "\n")
+	b.WriteString(valueStyle.Render(ev.Ref) + "\n\n")
+	if ev.Title != "" {
+		b.WriteString(labelStyle.Render("Title") + "\n")
+		b.WriteString(valueStyle.Render(ev.Title) + "\n\n")
+	}
+	if url := timelineEventURL(ev); url != "" {
+		b.WriteString(labelStyle.Render("URL") + "\n")
+		b.WriteString(valueStyle.Render(url) + "\n\n")
+	}
+	b.WriteString(labelStyle.Render("Event") + "\n")
+	event := ev.EventType
+	if ev.Detail != "" {
+		event += ": " + ev.Detail
+	}
+	b.WriteString(valueStyle.Render(event) + "\n")
+
+	return v.detail.ViewScrolled(b.String())
+}
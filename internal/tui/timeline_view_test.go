@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestTimelineListsEventsByDay(t *testing.T) {
+	m := testModel(t, &types.Tab{URL: "https://example.com", Title: "Example", LastAccessed: time.Now()})
+	id, _, err := storage.UpsertGitHubEntity(m.db, "lotas", "tabsordnung", 42, "pull", "tab")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.RecordGitHubEvent(m.db, id, "tab_seen", nil, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = next.(Model)
+	next, _ = m.Update(key("7"))
+	m = next.(Model)
+	if m.activeView != ViewTimeline {
+		t.Fatalf("activeView = %v, want the Timeline view", m.activeView)
+	}
+	next, _ = m.Update(m.timelineView.Reload()())
+	m = next.(Model)
+
+	v := m.View()
+	if !strings.Contains(v, time.Now().Format("Mon 2006-01-02")) || !strings.Contains(v, "lotas/tabsordnung#42") {
+		t.Fatalf("View() = %q, want today's header and the PR event", v)
+	}
+
+	// The cursor starts on the day header, which has no detail.
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if m.timelineView.FocusDetail() {
+		t.Fatal("enter on a day header focused the detail pane")
+	}
+	next, _ = m.Update(key("j"))
+	m = next.(Model)
+	next, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = next.(Model)
+	if !m.timelineView.FocusDetail() {
+		t.Fatal("enter on an event did not focus the detail pane")
+	}
+	if d := m.timelineView.ViewDetail(); !strings.Contains(d, "https://github.com/lotas/tabsordnung/pull/42") {
+		t.Errorf("ViewDetail() = %q, want the PR URL", d)
+	}
+}
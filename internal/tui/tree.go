@@ -2,10 +2,16 @@ package tui
 
 import (
 	"fmt"
+	"hash/fnv"
+	"maps"
 	"os"
+	"slices"
+	"sort"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/filter"
 	"github.com/lotas/tabsordnung/internal/signal"
 	"github.com/lotas/tabsordnung/internal/summarize"
 	"github.com/lotas/tabsordnung/internal/types"
@@ -13,8 +19,22 @@ import (
 
 // TreeNode represents a visible row in the tree.
 type TreeNode struct {
-	Group *types.TabGroup // non-nil for group headers
-	Tab   *types.Tab      // non-nil for tab rows
+	Window *WindowNode     // non-nil for window headers (per-window layout)
+	Group  *types.TabGroup // non-nil for group headers
+	Tab    *types.Tab      // non-nil for tab rows
+}
+
+// WindowNode is a browser window in the per-window layout. Its groups are
+// copies of the session groups holding only the tabs in this window.
+type WindowNode struct {
+	ID     int
+	Label  string
+	Groups []*types.TabGroup
+}
+
+// key is the window's entry in TreeModel.Expanded.
+func (w *WindowNode) key() string {
+	return fmt.Sprintf("window:%d", w.ID)
 }
 
 // TreeModel manages the collapsible tree view.
@@ -33,6 +53,46 @@ type TreeModel struct {
 	Height           int
 	Filter           types.FilterMode
 	DisplayMode      types.TabDisplayMode
+	Sort             types.SortMode // ordering of tabs within each group
+	ContainerFilter  string         // container shown by FilterContainer
+	Query            string         // search: show only tabs whose title or URL contain it
+	HideGitHubBadges bool           // hide PR check / review-requested badges
+	ByWindow         bool           // nest groups under their windows
+	Windows          []*WindowNode  // per-window layout, built from Groups
+
+	// nodes caches VisibleNodes. It is shared by copies of the model, so
+	// invalidating it through any copy is seen by all.
+	nodes *nodeCache
+}
+
+// nodeCache holds the visible rows and the settings they were built with.
+// Settings changes are noticed by comparing keys; changes to Expanded or to
+// the tabs need Invalidate.
+type nodeCache struct {
+	valid bool
+	key   nodeCacheKey
+	nodes []TreeNode
+}
+
+type nodeCacheKey struct {
+	filter    types.FilterMode
+	container string
+	query     string
+	sort      types.SortMode
+	byWindow  bool
+	summaries string
+}
+
+func (m TreeModel) cacheKey() nodeCacheKey {
+	return nodeCacheKey{m.Filter, m.ContainerFilter, m.Query, m.Sort, m.ByWindow, m.SummaryDir}
+}
+
+// Invalidate drops the cached rows, after groups are expanded or collapsed
+// or tabs change in ways the active filter looks at.
+func (m TreeModel) Invalidate() {
+	if m.nodes != nil {
+		m.nodes.valid = false
+	}
 }
 
 func NewTreeModel(groups []*types.TabGroup) TreeModel {
@@ -40,21 +100,135 @@ func NewTreeModel(groups []*types.TabGroup) TreeModel {
 	for _, g := range groups {
 		expanded[g.ID] = !g.Collapsed
 	}
+	windows := buildWindows(groups)
+	for _, w := range windows {
+		expanded[w.key()] = true
+		for _, g := range w.Groups {
+			expanded[g.ID] = !g.Collapsed
+		}
+	}
 	return TreeModel{
 		Groups:      groups,
+		Windows:     windows,
 		Expanded:    expanded,
 		Selected:    make(map[int]bool),
 		DisplayMode: types.TabDisplayTitle,
+		nodes:       &nodeCache{},
 	}
 }
 
-// VisibleNodes returns the flat list of currently visible nodes.
-func (m TreeModel) VisibleNodes() []TreeNode {
-	var nodes []TreeNode
+// buildWindows splits groups by the windows their tabs are in. Windows are
+// ordered by first appearance; a group with tabs in several windows (such
+// as "Ungrouped" in live mode) appears under each of them.
+func buildWindows(groups []*types.TabGroup) []*WindowNode {
+	byID := make(map[int]*WindowNode)
+	var windows []*WindowNode
+	for _, g := range groups {
+		perWindow := make(map[int]*types.TabGroup)
+		for _, tab := range g.Tabs {
+			w, ok := byID[tab.WindowID]
+			if !ok {
+				w = &WindowNode{ID: tab.WindowID}
+				byID[tab.WindowID] = w
+				windows = append(windows, w)
+			}
+			wg, ok := perWindow[tab.WindowID]
+			if !ok {
+				wg = &types.TabGroup{
+					ID:        fmt.Sprintf("%d/%s", tab.WindowID, g.ID),
+					Name:      g.Name,
+					Color:     g.Color,
+					Collapsed: g.Collapsed,
+				}
+				perWindow[tab.WindowID] = wg
+				w.Groups = append(w.Groups, wg)
+			}
+			wg.Tabs = append(wg.Tabs, tab)
+		}
+	}
+	for i, w := range windows {
+		w.Label = fmt.Sprintf("Window %d", i+1)
+	}
+	return windows
+}
+
+// ToggleByWindow switches between the flat and the per-window layout.
+func (m *TreeModel) ToggleByWindow() {
+	m.ByWindow = !m.ByWindow
+	if m.Filter != types.FilterAll {
+		for _, id := range m.groupIDs() {
+			m.Expanded[id] = true
+		}
+		m.Invalidate()
+	}
+	m.Cursor = 0
+	m.Offset = 0
+}
+
+// groupIDs returns the Expanded keys of every group and window header.
+func (m TreeModel) groupIDs() []string {
+	var ids []string
 	for _, g := range m.Groups {
+		ids = append(ids, g.ID)
+	}
+	for _, w := range m.Windows {
+		ids = append(ids, w.key())
+		for _, g := range w.Groups {
+			ids = append(ids, g.ID)
+		}
+	}
+	return ids
+}
+
+// VisibleNodes returns the flat list of currently visible nodes. The list
+// is cached until the tree changes; callers must not modify it.
+func (m TreeModel) VisibleNodes() []TreeNode {
+	if m.nodes == nil {
+		return m.buildNodes()
+	}
+	key := m.cacheKey()
+	if !m.nodes.valid || m.nodes.key != key {
+		m.nodes.nodes = m.buildNodes()
+		m.nodes.key = key
+		m.nodes.valid = true
+	}
+	return m.nodes.nodes
+}
+
+// buildNodes walks the groups to list the visible nodes.
+func (m TreeModel) buildNodes() []TreeNode {
+	if m.ByWindow {
+		var nodes []TreeNode
+		for _, w := range m.Windows {
+			nodes = append(nodes, TreeNode{Window: w})
+			if m.Expanded[w.key()] {
+				nodes = m.appendGroupNodes(nodes, w.Groups)
+			}
+		}
+		return nodes
+	}
+	return m.appendGroupNodes(nil, m.Groups)
+}
+
+func (m TreeModel) appendGroupNodes(nodes []TreeNode, groups []*types.TabGroup) []TreeNode {
+	for _, g := range groups {
+		if m.Query != "" {
+			// Searching: list every group with matches, expanded, and
+			// hide the rest.
+			if !slices.ContainsFunc(g.Tabs, m.matchesFilter) {
+				continue
+			}
+			nodes = append(nodes, TreeNode{Group: g})
+			for _, tab := range m.sortedTabs(g) {
+				if m.matchesFilter(tab) {
+					nodes = append(nodes, TreeNode{Tab: tab})
+				}
+			}
+			continue
+		}
 		nodes = append(nodes, TreeNode{Group: g})
 		if m.Expanded[g.ID] {
-			for _, tab := range g.Tabs {
+			for _, tab := range m.sortedTabs(g) {
 				if m.matchesFilter(tab) {
 					nodes = append(nodes, TreeNode{Tab: tab})
 				}
@@ -65,38 +239,38 @@ func (m TreeModel) VisibleNodes() []TreeNode {
 }
 
 func (m TreeModel) matchesFilter(tab *types.Tab) bool {
-	switch m.Filter {
-	case types.FilterStale:
-		return tab.IsStale
-	case types.FilterDead:
-		return tab.IsDead
-	case types.FilterDuplicate:
-		return tab.IsDuplicate
-	case types.FilterAge7:
-		return tab.StaleDays > 7
-	case types.FilterAge30:
-		return tab.StaleDays > 30
-	case types.FilterAge90:
-		return tab.StaleDays > 90
-	case types.FilterGitHubDone:
-		return tab.GitHubStatus == "closed" || tab.GitHubStatus == "merged"
-	case types.FilterHasSummary:
-		if m.SummaryDir == "" {
-			return false
-		}
-		p := summarize.SummaryPath(m.SummaryDir, tab.URL, tab.Title)
-		_, err := os.Stat(p)
-		return err == nil
-	case types.FilterNoSummary:
-		if m.SummaryDir == "" {
-			return true
-		}
-		p := summarize.SummaryPath(m.SummaryDir, tab.URL, tab.Title)
-		_, err := os.Stat(p)
-		return err != nil
-	default:
+	return m.activeFilter().Match(tab) && m.matchesQuery(tab)
+}
+
+// matchesQuery reports whether the tab's title or URL contains the search
+// query, ignoring case.
+func (m TreeModel) matchesQuery(tab *types.Tab) bool {
+	if m.Query == "" {
 		return true
 	}
+	q := strings.ToLower(m.Query)
+	return strings.Contains(strings.ToLower(tab.Title), q) || strings.Contains(strings.ToLower(tab.URL), q)
+}
+
+// SetQuery changes the search query, moving the cursor to the first match.
+func (m *TreeModel) SetQuery(q string) {
+	m.Query = q
+	m.Cursor = 0
+	m.Offset = 0
+	if q == "" {
+		return
+	}
+	for i, n := range m.VisibleNodes() {
+		if n.Tab != nil {
+			m.Cursor = i
+			break
+		}
+	}
+}
+
+// activeFilter is the tree's active filter with the inputs it needs.
+func (m TreeModel) activeFilter() filter.Filter {
+	return filter.Filter{Mode: m.Filter, Container: m.ContainerFilter, SummaryDir: m.SummaryDir}
 }
 
 // SetFilter changes the active filter and manages expanded-state save/restore.
@@ -113,8 +287,8 @@ func (m *TreeModel) SetFilter(f types.FilterMode) {
 			}
 		}
 		// Force all groups expanded.
-		for _, g := range m.Groups {
-			m.Expanded[g.ID] = true
+		for _, id := range m.groupIDs() {
+			m.Expanded[id] = true
 		}
 	} else if m.SavedExpanded != nil {
 		// Restore saved state when switching back to "all".
@@ -123,6 +297,7 @@ func (m *TreeModel) SetFilter(f types.FilterMode) {
 		}
 		m.SavedExpanded = nil
 	}
+	m.Invalidate()
 
 	m.Cursor = 0
 	m.Offset = 0
@@ -162,11 +337,126 @@ func (m *TreeModel) MoveDown() {
 	}
 }
 
+// JumpToFlagged moves the cursor to the next (dir > 0) or previous tab of
+// interest, wrapping around and skipping headers. Tabs in collapsed groups
+// count too; the group of the tab jumped to is expanded. With a filter
+// active every shown tab matching it counts; otherwise tabs that are stale,
+// dead, duplicated or placeholders do. Reports whether the cursor moved.
+func (m *TreeModel) JumpToFlagged(dir int) bool {
+	var selected TreeNode
+	if node := m.SelectedNode(); node != nil {
+		selected = *node
+	}
+
+	// Walk the tree as it would look with everything expanded.
+	saved := maps.Clone(m.Expanded)
+	for _, g := range m.Groups {
+		m.Expanded[g.ID] = true
+	}
+	for _, w := range m.Windows {
+		m.Expanded[w.key()] = true
+		for _, g := range w.Groups {
+			m.Expanded[g.ID] = true
+		}
+	}
+	m.Invalidate()
+	nodes := m.VisibleNodes()
+	m.Expanded = saved
+	m.Invalidate()
+
+	n := len(nodes)
+	start := 0
+	for i, node := range nodes {
+		if selected.Tab != nil && node.Tab == selected.Tab ||
+			selected.Tab == nil && selected.Group != nil && node.Group == selected.Group ||
+			selected.Tab == nil && selected.Window != nil && node.Window == selected.Window {
+			start = i
+			break
+		}
+	}
+	for step := 1; step < n; step++ {
+		i := ((start+dir*step)%n + n) % n
+		tab := nodes[i].Tab
+		if tab == nil || tab == selected.Tab {
+			continue
+		}
+		if m.Filter != types.FilterAll {
+			if !m.matchesFilter(tab) {
+				continue
+			}
+		} else if !(tab.IsStale || tab.IsDead || tab.IsDuplicate || tab.IsPlaceholder) {
+			continue
+		}
+		// Expand the headers above the tab: its group, and its window in
+		// the per-window layout.
+		group := false
+		for j := i - 1; j >= 0; j-- {
+			if g := nodes[j].Group; g != nil && !group {
+				m.Expanded[g.ID] = true
+				group = true
+				if !m.ByWindow {
+					break
+				}
+			}
+			if w := nodes[j].Window; w != nil {
+				m.Expanded[w.key()] = true
+				break
+			}
+		}
+		m.Invalidate()
+		for j, node := range m.VisibleNodes() {
+			if node.Tab == tab {
+				m.Cursor = j
+				break
+			}
+		}
+		visibleRows := m.Height - 2
+		if visibleRows < 1 {
+			visibleRows = 1
+		}
+		if m.Cursor < m.Offset {
+			m.Offset = m.Cursor
+		} else if m.Cursor >= m.Offset+visibleRows {
+			m.Offset = m.Cursor - visibleRows + 1
+		}
+		return true
+	}
+	return false
+}
+
 // CycleDisplayMode advances the tab display mode: URL → Title → Both → URL.
 func (m *TreeModel) CycleDisplayMode() {
 	m.DisplayMode = (m.DisplayMode + 1) % 3
 }
 
+func (m *TreeModel) CycleSort() {
+	m.Sort = (m.Sort + 1) % 4
+}
+
+// sortedTabs returns the group's tabs in the current sort order. The group's
+// own slice is left untouched; ties keep browser order.
+func (m TreeModel) sortedTabs(g *types.TabGroup) []*types.Tab {
+	if m.Sort == types.SortNative {
+		return g.Tabs
+	}
+	tabs := append([]*types.Tab(nil), g.Tabs...)
+	switch m.Sort {
+	case types.SortByTitle:
+		sort.SliceStable(tabs, func(i, j int) bool {
+			return strings.ToLower(tabs[i].Title) < strings.ToLower(tabs[j].Title)
+		})
+	case types.SortByLastAccessed:
+		sort.SliceStable(tabs, func(i, j int) bool {
+			return tabs[i].LastAccessed.After(tabs[j].LastAccessed)
+		})
+	case types.SortByStaleness:
+		sort.SliceStable(tabs, func(i, j int) bool {
+			return tabs[i].LastAccessed.Before(tabs[j].LastAccessed)
+		})
+	}
+	return tabs
+}
+
 // tabLabel returns the display text for a tab, truncated to fit availWidth.
 func (m *TreeModel) tabLabel(tab *types.Tab, availWidth int) string {
 	url := tab.URL
@@ -197,13 +487,136 @@ func (m *TreeModel) tabLabel(tab *types.Tab, availWidth int) string {
 	}
 }
 
-// Toggle expands/collapses the selected group.
+// SelectedGroup returns the selected group header, or the group of the
+// selected tab.
+func (m TreeModel) SelectedGroup() *types.TabGroup {
+	nodes := m.VisibleNodes()
+	for i := m.Cursor; i >= 0 && i < len(nodes); i-- {
+		if nodes[i].Window != nil {
+			return nil
+		}
+		if nodes[i].Group != nil {
+			return nodes[i].Group
+		}
+	}
+	return nil
+}
+
+// Toggle expands/collapses the selected group or window.
 func (m *TreeModel) Toggle() {
 	node := m.SelectedNode()
-	if node == nil || node.Group == nil {
+	if node == nil {
+		return
+	}
+	if node.Window != nil {
+		m.Expanded[node.Window.key()] = !m.Expanded[node.Window.key()]
+		m.Invalidate()
+		return
+	}
+	if node.Group == nil {
 		return
 	}
 	m.Expanded[node.Group.ID] = !m.Expanded[node.Group.ID]
+	m.Invalidate()
+}
+
+// SetAllExpanded expands or collapses every group, leaving windows
+// expanded so a collapsed tree lists all group headers. The cursor stays on
+// the selected row, or moves to its group header when that row is hidden.
+func (m *TreeModel) SetAllExpanded(expanded bool) {
+	var selected TreeNode
+	if node := m.SelectedNode(); node != nil {
+		selected = *node
+	}
+	for _, g := range m.Groups {
+		m.Expanded[g.ID] = expanded
+	}
+	for _, w := range m.Windows {
+		m.Expanded[w.key()] = true
+		for _, g := range w.Groups {
+			m.Expanded[g.ID] = expanded
+		}
+	}
+	m.Invalidate()
+
+	nodes := m.VisibleNodes()
+	group := -1
+	m.Cursor = 0
+	for i, n := range nodes {
+		if selected.Tab != nil && n.Tab == selected.Tab ||
+			selected.Group != nil && n.Group == selected.Group ||
+			selected.Window != nil && n.Window == selected.Window {
+			group = i
+			break
+		}
+		if selected.Tab != nil && n.Group != nil && slices.Contains(n.Group.Tabs, selected.Tab) {
+			group = i
+		}
+	}
+	if group >= 0 {
+		m.Cursor = group
+	}
+
+	visibleRows := m.Height - 2
+	if visibleRows < 1 {
+		visibleRows = 1
+	}
+	if m.Cursor < m.Offset {
+		m.Offset = m.Cursor
+	}
+	if m.Cursor >= m.Offset+visibleRows {
+		m.Offset = m.Cursor - visibleRows + 1
+	}
+}
+
+// JumpToGroup expands the group with the given ID and moves the cursor to
+// its header. In the per-window layout it jumps to the group's first window.
+// A search that hides the group is cleared.
+func (m *TreeModel) JumpToGroup(id string) {
+	m.Expanded[id] = true
+	for _, w := range m.Windows {
+		for _, g := range w.Groups {
+			if strings.HasSuffix(g.ID, "/"+id) {
+				m.Expanded[g.ID] = true
+			}
+		}
+	}
+	m.Invalidate()
+
+	find := func() int {
+		for i, n := range m.VisibleNodes() {
+			if n.Group != nil && (n.Group.ID == id || strings.HasSuffix(n.Group.ID, "/"+id)) {
+				return i
+			}
+		}
+		return -1
+	}
+	i := find()
+	if i < 0 && m.Query != "" {
+		m.Query = ""
+		i = find()
+	}
+	if i < 0 && m.ByWindow {
+		for _, w := range m.Windows {
+			m.Expanded[w.key()] = true
+		}
+		m.Invalidate()
+		i = find()
+	}
+	if i < 0 {
+		return
+	}
+
+	m.Cursor = i
+	visibleRows := m.Height - 2
+	if visibleRows < 1 {
+		visibleRows = 1
+	}
+	// Show the header at the top, with as many of its tabs as fit.
+	m.Offset = m.Cursor
+	if n := len(m.VisibleNodes()); m.Offset > n-visibleRows {
+		m.Offset = max(n-visibleRows, 0)
+	}
 }
 
 // CollapseOrParent collapses the selected group if expanded, or jumps to the
@@ -213,17 +626,27 @@ func (m *TreeModel) CollapseOrParent() {
 	if node == nil {
 		return
 	}
+	if node.Window != nil {
+		m.Expanded[node.Window.key()] = false
+		m.Invalidate()
+		return
+	}
 	if node.Group != nil {
-		// On a group header: collapse it if expanded.
+		// On a group header: collapse it if expanded, otherwise jump to
+		// the window header in the per-window layout.
 		if m.Expanded[node.Group.ID] {
 			m.Expanded[node.Group.ID] = false
+			m.Invalidate()
+			return
+		}
+		if !m.ByWindow {
+			return
 		}
-		return
 	}
-	// On a tab: jump to the parent group header.
+	// Jump to the parent header.
 	nodes := m.VisibleNodes()
 	for i := m.Cursor - 1; i >= 0; i-- {
-		if nodes[i].Group != nil {
+		if (node.Tab != nil && nodes[i].Group != nil) || (node.Group != nil && nodes[i].Window != nil) {
 			m.Cursor = i
 			if m.Cursor < m.Offset {
 				m.Offset = m.Cursor
@@ -237,16 +660,23 @@ func (m *TreeModel) CollapseOrParent() {
 // first child tab if already expanded.
 func (m *TreeModel) ExpandOrEnter() {
 	node := m.SelectedNode()
-	if node == nil || node.Group == nil {
+	if node == nil || (node.Group == nil && node.Window == nil) {
 		return
 	}
-	if !m.Expanded[node.Group.ID] {
-		m.Expanded[node.Group.ID] = true
+	key := ""
+	if node.Window != nil {
+		key = node.Window.key()
+	} else {
+		key = node.Group.ID
+	}
+	if !m.Expanded[key] {
+		m.Expanded[key] = true
+		m.Invalidate()
 		return
 	}
-	// Already expanded: move to first child tab.
+	// Already expanded: move to first child.
 	nodes := m.VisibleNodes()
-	if m.Cursor+1 < len(nodes) && nodes[m.Cursor+1].Tab != nil {
+	if m.Cursor+1 < len(nodes) && (nodes[m.Cursor+1].Tab != nil || nodes[m.Cursor+1].Group != nil) {
 		m.Cursor++
 		visibleRows := m.Height - 2
 		if visibleRows < 1 {
@@ -277,57 +707,89 @@ func (m TreeModel) View() string {
 	}
 
 	cursorStyle := lipgloss.NewStyle().Bold(true).Reverse(true)
-	staleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))    // orange
-	deadStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))     // red
-	dupStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("33"))       // blue
-	ghDoneStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("42"))    // green
-	ghOpenStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("135"))   // purple
-	summaryStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("51"))        // cyan
-	summarizingStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214")) // yellow
-	signalStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("220"))       // yellow
+	staleStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	deadStyle := lipgloss.NewStyle().Foreground(theme.Error)
+	placeholderStyle := lipgloss.NewStyle().Foreground(theme.Dim)
+	dupStyle := lipgloss.NewStyle().Foreground(theme.Info)
+	dupAcrossStyle := lipgloss.NewStyle().Foreground(theme.InfoMuted)
+	ghDoneStyle := lipgloss.NewStyle().Foreground(theme.OK)
+	ghOpenStyle := lipgloss.NewStyle().Foreground(theme.Open)
+	summaryStyle := lipgloss.NewStyle().Foreground(theme.Summary)
+	summarizingStyle := lipgloss.NewStyle().Foreground(theme.Warn)
+	signalStyle := lipgloss.NewStyle().Foreground(theme.Highlight)
 	groupStyle := lipgloss.NewStyle().Bold(true)
+	closedStyle := lipgloss.NewStyle().Foreground(theme.Dim)
 
 	for i := m.Offset; i < end; i++ {
 		node := nodes[i]
 		var line string
 
-		if node.Group != nil {
+		indent := ""
+		if m.ByWindow {
+			indent = "  "
+		}
+
+		if node.Window != nil {
+			icon := "▶"
+			if m.Expanded[node.Window.key()] {
+				icon = "▼"
+			}
+			tabs := 0
+			for _, g := range node.Window.Groups {
+				tabs += len(g.Tabs)
+			}
+			line = groupStyle.Render(fmt.Sprintf("%s %s \u00b7 %d groups \u00b7 %d tabs", icon, node.Window.Label, len(node.Window.Groups), tabs))
+		} else if node.Group != nil {
 			icon := "▶"
 			if m.Expanded[node.Group.ID] {
 				icon = "▼"
 			}
-			var label string
-			if m.Filter == types.FilterAll {
-				label = fmt.Sprintf("%s %s (%d tabs)", icon, node.Group.Name, len(node.Group.Tabs))
-			} else {
+			count := fmt.Sprintf("%d tabs", len(node.Group.Tabs))
+			if m.Filter != types.FilterAll || m.Query != "" {
 				matched := 0
 				for _, tab := range node.Group.Tabs {
 					if m.matchesFilter(tab) {
 						matched++
 					}
 				}
-				label = fmt.Sprintf("%s %s (%d/%d tabs)", icon, node.Group.Name, matched, len(node.Group.Tabs))
+				count = fmt.Sprintf("%d/%d tabs", matched, len(node.Group.Tabs))
+			}
+			label := fmt.Sprintf("%s %s (%s%s)", icon, node.Group.Name, count, groupHealth(node.Group))
+			if maxLen := m.Width - len(indent) - 2; maxLen > 10 {
+				label = truncateString(label, maxLen)
 			}
-			line = groupStyle.Render(label)
+			line = indent + groupStyle.Render(label)
 		} else if node.Tab != nil {
 			prefix := "  "
 			if m.Selected[node.Tab.BrowserID] {
 				prefix = "\u25b8 "
 			}
-			var markers []string
+			markers := []string{faviconDot(node.Tab)}
 			if node.Tab.IsDead {
 				markers = append(markers, deadStyle.Render("●"))
 			}
 			if node.Tab.IsStale {
 				markers = append(markers, staleStyle.Render("◷"))
 			}
-			if node.Tab.IsDuplicate {
+			if node.Tab.IsPlaceholder {
+				markers = append(markers, placeholderStyle.Render("◌"))
+			}
+			if node.Tab.DuplicateInGroup {
 				markers = append(markers, dupStyle.Render("⇄"))
+			} else if node.Tab.DuplicateAcrossGroups {
+				markers = append(markers, dupAcrossStyle.Render("⇆"))
+			} else if node.Tab.DuplicateAcrossProfiles {
+				markers = append(markers, dupAcrossStyle.Render("⇋"))
 			}
 			if node.Tab.GitHubStatus == "closed" || node.Tab.GitHubStatus == "merged" {
 				markers = append(markers, ghDoneStyle.Render("✓"))
 			} else if node.Tab.GitHubStatus == "open" {
 				markers = append(markers, ghOpenStyle.Render("○"))
+				if !m.HideGitHubBadges {
+					markers = append(markers, githubBadges(node.Tab.GitHubTriage)...)
+				}
+			} else if node.Tab.GitHubStatus == types.GitHubNotFound || node.Tab.GitHubStatus == types.GitHubNoAccess {
+				markers = append(markers, deadStyle.Render("?"))
 			}
 			if m.SummarizingURLs[node.Tab.URL] {
 				markers = append(markers, summarizingStyle.Render("⟳"))
@@ -343,20 +805,24 @@ func (m TreeModel) View() string {
 					if u, ok := m.SignalUrgency[src]; ok {
 						switch u {
 						case "urgent":
-							style = lipgloss.NewStyle().Foreground(lipgloss.Color("196")).Bold(true)
+							style = lipgloss.NewStyle().Foreground(theme.Error).Bold(true)
 						case "review":
-							style = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+							style = lipgloss.NewStyle().Foreground(theme.Warn)
 						case "fyi":
-							style = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+							style = lipgloss.NewStyle().Foreground(theme.Dim)
 						}
 					}
 					markers = append(markers, style.Render(fmt.Sprintf("⚡%d", n)))
 				}
 			}
 
-			marker := ""
-			if len(markers) > 0 {
-				marker = strings.Join(markers, "") + " "
+			marker := strings.Join(markers, "") + " "
+			if c := node.Tab.Container; c != "" {
+				c = truncateString(c, 10)
+				marker += containerStyle().Render("["+c+"]") + " "
+			}
+			if !node.Tab.ClosedAt.IsZero() {
+				marker += closedStyle.Render(formatSignalAge(node.Tab.ClosedAt)) + " "
 			}
 
 			// Build tab label according to current display mode
@@ -365,7 +831,7 @@ func (m TreeModel) View() string {
 				maxLabelLen = 10
 			}
 			label := m.tabLabel(node.Tab, maxLabelLen)
-			line = prefix + marker + label
+			line = indent + prefix + marker + highlightQuery(label, m.Query)
 		}
 
 		// Apply cursor highlight
@@ -385,3 +851,132 @@ func (m TreeModel) View() string {
 
 	return b.String()
 }
+
+// highlightQuery underlines the first case-insensitive match of q in s.
+func highlightQuery(s, q string) string {
+	if q == "" {
+		return s
+	}
+	// Match rune by rune: lowercasing can change a string's byte length
+	// (the Kelvin sign "\u212a" folds to "k"), so offsets found in
+	// strings.ToLower(s) don't index s.
+	n := utf8.RuneCountInString(q)
+	for i := range s {
+		j, runes := i, 0
+		for ; runes < n && j < len(s); runes++ {
+			_, w := utf8.DecodeRuneInString(s[j:])
+			j += w
+		}
+		if runes == n && strings.EqualFold(s[i:j], q) {
+			matchStyle := lipgloss.NewStyle().Underline(true).Bold(true)
+			return s[:i] + matchStyle.Render(s[i:j]) + s[j:]
+		}
+	}
+	return s
+}
+
+// groupHealth summarizes the flagged tabs of a group for its header, e.g.
+// " · 3 stale · 1 dead", or "" when nothing is flagged.
+func groupHealth(g *types.TabGroup) string {
+	var stale, dead, dups int
+	for _, tab := range g.Tabs {
+		if tab.IsStale {
+			stale++
+		}
+		if tab.IsDead {
+			dead++
+		}
+		if tab.IsDuplicate {
+			dups++
+		}
+	}
+	var s string
+	if stale > 0 {
+		s += fmt.Sprintf(" \u00b7 %d stale", stale)
+	}
+	if dead > 0 {
+		s += fmt.Sprintf(" \u00b7 %d dead", dead)
+	}
+	if dups > 0 {
+		s += fmt.Sprintf(" \u00b7 %d dup", dups)
+	}
+	return s
+}
+
+// faviconPalette are the colors of favicon dots, picked to be readable on
+// dark and light backgrounds.
+var faviconPalette = []lipgloss.Color{
+	"33", "37", "42", "69", "76", "99", "135", "141",
+	"166", "170", "178", "203", "204", "208", "214", "39",
+}
+
+// faviconDot marks a tab with a dot whose color is derived from its
+// favicon, so tabs of the same site share a color. Tabs without a favicon,
+// such as about: pages and tabs that never loaded, get a hollow dot.
+func faviconDot(tab *types.Tab) string {
+	if tab.Favicon == "" {
+		return lipgloss.NewStyle().Foreground(theme.Dim).Render("◦")
+	}
+	if colorDisabled {
+		return "•"
+	}
+	h := fnv.New32a()
+	h.Write([]byte(tab.Favicon))
+	color := faviconPalette[h.Sum32()%uint32(len(faviconPalette))]
+	return lipgloss.NewStyle().Foreground(color).Render("•")
+}
+
+// githubBadges returns the compact triage badges for an open issue or PR:
+// CI check state, and whether it is waiting on the current user.
+func githubBadges(info *types.GitHubTriageInfo) []string {
+	if info == nil {
+		return nil
+	}
+	var badges []string
+	switch info.ChecksStatus {
+	case "passing":
+		badges = append(badges, lipgloss.NewStyle().Foreground(theme.OK).Render("✔"))
+	case "failing":
+		badges = append(badges, lipgloss.NewStyle().Foreground(theme.Error).Render("✘"))
+	case "pending":
+		badges = append(badges, lipgloss.NewStyle().Foreground(theme.Warn).Render("◌"))
+	}
+	if info.ReviewRequested {
+		badges = append(badges, lipgloss.NewStyle().Foreground(theme.Highlight).Bold(true).Render("👁"))
+	}
+	if info.Assigned {
+		badges = append(badges, lipgloss.NewStyle().Foreground(theme.Highlight).Render("@"))
+	}
+	return badges
+}
+
+// containerStyle renders Firefox container names in the tree and detail pane.
+func containerStyle() lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(theme.Container)
+}
+
+// FilteredSession returns a copy of session holding only the tabs the
+// current filter shows, in display order, with empty groups dropped.
+// Recently closed tabs are left out.
+func (m TreeModel) FilteredSession(session *types.SessionData) *types.SessionData {
+	out := &types.SessionData{Profile: session.Profile, ParsedAt: session.ParsedAt}
+	for _, g := range m.Groups {
+		if g.ID == closedGroupID {
+			continue
+		}
+		var tabs []*types.Tab
+		for _, tab := range m.sortedTabs(g) {
+			if m.matchesFilter(tab) {
+				tabs = append(tabs, tab)
+			}
+		}
+		if len(tabs) == 0 {
+			continue
+		}
+		fg := *g
+		fg.Tabs = tabs
+		out.Groups = append(out.Groups, &fg)
+		out.AllTabs = append(out.AllTabs, tabs...)
+	}
+	return out
+}
@@ -0,0 +1,74 @@
+package tui
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+func TestHighlightQuery(t *testing.T) {
+	matchStyle := lipgloss.NewStyle().Underline(true).Bold(true)
+	for _, tc := range []struct{ s, q, before, match, after string }{
+		{"Go Docs", "docs", "Go ", "Docs", ""},
+		{"Go Docs", "rust", "Go Docs", "", ""},
+		// Case folding that changes byte width must neither panic nor
+		// highlight the wrong bytes.
+		{"k", "\u212a", "", "k", ""},
+		{"kelvin", "\u212a", "", "k", "elvin"},
+		{"\u212aelvin", "k", "", "\u212a", "elvin"},
+		{"Straße über", "ÜBER", "Straße ", "über", ""},
+	} {
+		want := tc.before
+		if tc.match != "" {
+			want += matchStyle.Render(tc.match) + tc.after
+		}
+		if got := highlightQuery(tc.s, tc.q); got != want {
+			t.Errorf("highlightQuery(%q, %q) = %q, want %q", tc.s, tc.q, got, want)
+		}
+	}
+}
+
+func TestTruncateKeepsRunesWhole(t *testing.T) {
+	for _, tc := range []struct {
+		s    string
+		n    int
+		want string
+	}{
+		{"Work", 10, "Work"},
+		{"Persönliches Konto", 10, "Persönlic…"},
+		{"日本語のコンテナ名です", 5, "日本語の…"},
+		{"ab", 1, "…"},
+		{"ab", 0, "ab"},
+	} {
+		if got := truncateString(tc.s, tc.n); got != tc.want {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", tc.s, tc.n, got, tc.want)
+		}
+	}
+}
+
+func TestJumpToFlaggedIntoCollapsedGroup(t *testing.T) {
+	a := &types.Tab{URL: "https://a.com", Title: "A"}
+	b := &types.Tab{URL: "https://b.com", Title: "B"}
+	stale := &types.Tab{URL: "https://c.com", Title: "C", IsStale: true}
+	m := NewTreeModel([]*types.TabGroup{
+		{ID: "g1", Name: "Open", Tabs: []*types.Tab{a, b}},
+		{ID: "g2", Name: "Closed", Tabs: []*types.Tab{stale}, Collapsed: true},
+	})
+	m.Height = 20
+	m.Cursor = 1 // tab a
+
+	if !m.JumpToFlagged(1) {
+		t.Fatal("JumpToFlagged found no tab in the collapsed group")
+	}
+	if node := m.SelectedNode(); node == nil || node.Tab != stale {
+		t.Fatalf("cursor on %+v, want the stale tab", node)
+	}
+	if !m.Expanded["g2"] {
+		t.Error("the stale tab's group was not expanded")
+	}
+	// It is the only flagged tab: jumping again goes nowhere.
+	if m.JumpToFlagged(-1) {
+		t.Error("JumpToFlagged moved away from the only flagged tab")
+	}
+}
@@ -0,0 +1,93 @@
+package tui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/lotas/tabsordnung/internal/applog"
+	"github.com/lotas/tabsordnung/internal/storage"
+	"github.com/lotas/tabsordnung/internal/types"
+)
+
+// uiStateKey is the profile the UI state is saved under. Live mode is not
+// tied to a profile, so it has a single entry of its own.
+func (m *Model) uiStateKey() string {
+	if m.mode == ModeLive {
+		return "live"
+	}
+	return m.profile.Name
+}
+
+// saveUIState remembers the tree expansion, cursor, filter and active view
+// for the current profile.
+func (m *Model) saveUIState() {
+	if !m.rememberUI || m.db == nil || m.session == nil {
+		return
+	}
+	key := m.uiStateKey()
+	if key == "" {
+		return
+	}
+	if err := storage.SaveUIState(m.db, key, m.tabsView.uiState(m.activeView)); err != nil {
+		applog.Error("tui.uistate.save", err, "profile", key)
+	}
+}
+
+// restoreUIState applies the saved state for the current profile. It runs
+// after the tree is built and only once per profile, so later snapshots and
+// reloads keep whatever the user has changed since.
+func (m *Model) restoreUIState() tea.Cmd {
+	if !m.rememberUI || m.db == nil {
+		return nil
+	}
+	key := m.uiStateKey()
+	if key == "" || key == m.restoredUIKey {
+		return nil
+	}
+	m.restoredUIKey = key
+	state, ok, err := storage.LoadUIState(m.db, key)
+	if err != nil {
+		applog.Error("tui.uistate.load", err, "profile", key)
+		return nil
+	}
+	if !ok {
+		return nil
+	}
+	m.tabsView.applyUIState(state)
+	return m.switchView(ViewType(state.View))
+}
+
+// quit saves the UI state, cancels running checks, stops the live mode
+// server and exits.
+func (m *Model) quit() tea.Cmd {
+	m.saveUIState()
+	m.cancelChecks()
+	m.stopServer()
+	return tea.Quit
+}
+
+func (v *TabsView) uiState(view ViewType) storage.UIState {
+	return storage.UIState{
+		View:          int(view),
+		Filter:        int(v.tree.Filter),
+		Container:     v.tree.ContainerFilter,
+		Cursor:        v.tree.Cursor,
+		Offset:        v.tree.Offset,
+		Expanded:      v.tree.Expanded,
+		SavedExpanded: v.tree.SavedExpanded,
+	}
+}
+
+func (v *TabsView) applyUIState(s storage.UIState) {
+	if f := types.FilterMode(s.Filter); f >= types.FilterAll && f <= types.FilterPinned {
+		v.tree.Filter = f
+		v.tree.ContainerFilter = s.Container
+	}
+	for id, exp := range s.Expanded {
+		v.tree.Expanded[id] = exp
+	}
+	v.tree.SavedExpanded = s.SavedExpanded
+	v.tree.Invalidate()
+
+	nodes := v.tree.VisibleNodes()
+	v.tree.Cursor = max(0, min(s.Cursor, len(nodes)-1))
+	v.tree.Offset = max(0, min(s.Offset, v.tree.Cursor))
+}
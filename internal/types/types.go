@@ -9,27 +9,55 @@ type Tab struct {
 	LastAccessed time.Time
 	GroupID      string // empty if ungrouped
 	Favicon      string
-	WindowIndex  int
+	WindowID     int // window index in the session file; Firefox window ID in live mode
 	TabIndex     int
 	BrowserID    int // live Firefox tab ID; 0 in offline mode
 	Pinned       bool
+	Container    string // Firefox container name; empty for the default container
+	ClosedAt     time.Time // when the tab was closed; only set on SessionData.ClosedTabs
+	SessionID    string    // browser.sessions ID used to restore a closed tab (live mode)
+	Profile      string    // source profile when several profiles are merged; empty otherwise
 
 	// Analyzer findings (populated after analysis)
 	IsStale      bool
 	IsDead       bool
 	IsDuplicate  bool
+	// IsPlaceholder is set for tabs showing a blank or loading page
+	// (about:blank, about:newtab, a Reader View without a target).
+	IsPlaceholder bool
 	DeadReason   string // e.g. "404", "timeout", "dns"
 	StaleDays    int
 	DuplicateOf  []int  // indices of duplicate tabs
-	GitHubStatus string           // "open", "closed", "merged", "" (not a GitHub URL)
+	// DuplicateInGroup is set when a copy of the tab is in the same group
+	// (usually clutter); DuplicateAcrossGroups when a copy is in another
+	// group (often a deliberate cross-reference). Both can be set.
+	DuplicateInGroup      bool
+	DuplicateAcrossGroups bool
+	// DuplicateAcrossProfiles is set when a copy is open in another
+	// profile of a merged session.
+	DuplicateAcrossProfiles bool
+	GitHubStatus string           // "open", "closed", "merged", GitHubNotFound, GitHubNoAccess, "" (not a GitHub URL)
 	GitHubTriage *GitHubTriageInfo // populated by triage analyzer; nil if not a GitHub URL
+
+	// WordCount is the number of words in the page's readable content, or
+	// 0 if it has not been measured.
+	WordCount int
 }
 
+// GitHubStatus values for issues and PRs GitHub couldn't return. GitHub
+// reports private repositories as not found too.
+const (
+	GitHubNotFound = "not_found" // deleted, renamed or private
+	GitHubNoAccess = "no_access" // exists, but the token may not read it
+)
+
 // GitHubTriageInfo holds extended GitHub metadata for triage classification.
 type GitHubTriageInfo struct {
 	ReviewRequested bool      // current user is a requested reviewer
 	Assigned        bool      // current user is an assignee
+	Authored        bool      // current user opened the issue/PR
 	UpdatedAt       time.Time // last update time on GitHub
+	ChecksStatus    string    // "passing", "failing", "pending", or "" (issues, PRs without checks)
 }
 
 // TabGroup represents a Firefox tab group.
@@ -47,6 +75,9 @@ type Profile struct {
 	Path       string // absolute path to profile directory
 	IsDefault  bool
 	IsRelative bool
+	// Members lists the profiles merged into this one; empty for a real
+	// Firefox profile.
+	Members []Profile
 }
 
 // SessionData holds all parsed data from a Firefox session.
@@ -55,16 +86,38 @@ type SessionData struct {
 	AllTabs  []*Tab
 	Profile  Profile
 	ParsedAt time.Time
+	// ClosedTabs are recently closed tabs, newest first. They are not part
+	// of Groups or AllTabs.
+	ClosedTabs []*Tab
+	// Warnings are problems that didn't stop the session from loading,
+	// such as a profile of a merged session that could not be read.
+	Warnings []string
+}
+
+// WindowIDs returns the IDs of the windows the session's tabs are in, in
+// order of first appearance.
+func (s *SessionData) WindowIDs() []int {
+	seen := make(map[int]bool)
+	var ids []int
+	for _, tab := range s.AllTabs {
+		if !seen[tab.WindowID] {
+			seen[tab.WindowID] = true
+			ids = append(ids, tab.WindowID)
+		}
+	}
+	return ids
 }
 
 // Stats holds aggregate statistics.
 type Stats struct {
+	TotalWindows   int
 	TotalTabs      int
 	TotalGroups    int
 	StaleTabs      int
 	DeadTabs       int
 	DuplicateTabs  int
 	GitHubDoneTabs int
+	PinnedTabs     int
 }
 
 // FilterMode controls which tabs are shown.
@@ -81,15 +134,21 @@ const (
 	FilterGitHubDone
 	FilterHasSummary
 	FilterNoSummary
+	FilterGitHubWaiting // open issues/PRs assigned to me or awaiting my review
+	FilterDuplicateInGroup
+	FilterContainer   // tabs in TreeModel.ContainerFilter
+	FilterPlaceholder // blank or loading tabs
+	FilterPinned
 )
 
-// SortMode controls tab ordering.
+// SortMode controls tab ordering within a group.
 type SortMode int
 
 const (
-	SortByGroup SortMode = iota
-	SortByAge
-	SortByStatus
+	SortNative         SortMode = iota // browser order
+	SortByTitle                        // alphabetical by title
+	SortByLastAccessed                 // most recently accessed first
+	SortByStaleness                    // least recently accessed first
 )
 
 // TabDisplayMode controls what text is shown for each tab in the tree.
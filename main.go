@@ -4,11 +4,14 @@ import (
 	"bufio"
 	"context"
 	"database/sql"
+	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
@@ -18,8 +21,13 @@ import (
 	"github.com/lotas/tabsordnung/internal/applog"
 	"github.com/lotas/tabsordnung/internal/classify"
 	"github.com/lotas/tabsordnung/internal/export"
+	"github.com/lotas/tabsordnung/internal/filter"
 	"github.com/lotas/tabsordnung/internal/firefox"
+	"github.com/lotas/tabsordnung/internal/github"
+	"github.com/lotas/tabsordnung/internal/metrics"
+	"github.com/lotas/tabsordnung/internal/notify"
 	"github.com/lotas/tabsordnung/internal/server"
+	"github.com/lotas/tabsordnung/internal/signal"
 	"github.com/lotas/tabsordnung/internal/snapshot"
 	"github.com/lotas/tabsordnung/internal/storage"
 	"github.com/lotas/tabsordnung/internal/summarize"
@@ -28,7 +36,35 @@ import (
 	"github.com/lotas/tabsordnung/internal/types"
 )
 
+// Build info, set at build time with
+// -ldflags "-X main.version=... -X main.commit=... -X main.date=..."
+// (see the Makefile). Without ldflags commit and date fall back to the VCS
+// stamp Go embeds in the binary.
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
+
+// sourcesErr is why the custom signal sources could not be loaded. Commands
+// carry on with the built-in sources; doctor reports it as a failed check.
+var sourcesErr error
+
 func main() {
+	// The log flags apply to every command, so they are taken out before
+	// the command's own flags are parsed.
+	args, logFile, logLevel, logDB := extractLogFlags(os.Args[1:])
+	os.Args = append(os.Args[:1], args...)
+	if err := openLog(logFile, logLevel, logDB); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	defer applog.Close()
+
+	if sourcesErr = signal.LoadSources(signal.SourcesFilePath()); sourcesErr != nil {
+		fmt.Fprintf(os.Stderr, "Warning: using the built-in signal sources: %v\n", sourcesErr)
+	}
+
 	if len(os.Args) > 1 {
 		switch os.Args[1] {
 		case "snapshot":
@@ -46,6 +82,15 @@ func main() {
 		case "profiles":
 			runProfiles()
 			return
+		case "doctor":
+			runDoctor(os.Args[2:])
+			return
+		case "stats":
+			runStats(os.Args[2:])
+			return
+		case "ws-token":
+			runWSToken(os.Args[2:])
+			return
 		case "signals":
 			runSignals(os.Args[2:])
 			return
@@ -55,12 +100,21 @@ func main() {
 		case "bugzilla":
 			runBugzilla(os.Args[2:])
 			return
+		case "gitlab":
+			runGitLab(os.Args[2:])
+			return
 		case "rules":
 			runRules(os.Args[2:])
 			return
+		case "db":
+			runDB(os.Args[2:])
+			return
 		case "history":
 			runHistory(os.Args[2:])
 			return
+		case "version", "--version":
+			runVersion(os.Args[2:])
+			return
 		case "help", "--help", "-h":
 			printHelp()
 			return
@@ -68,12 +122,46 @@ func main() {
 	}
 
 	fs := flag.NewFlagSet("tabsordnung", flag.ExitOnError)
-	profileName := fs.String("profile", "", "Firefox profile name (skip picker)")
+	var profileNames profileList
+	fs.Var(&profileNames, "profile", "Firefox profile name (skip picker); repeat to merge several profiles")
+	allProfiles := fs.Bool("all-profiles", false, "Merge the sessions of all Firefox profiles into one view")
 	staleDays := fs.Int("stale-days", 7, "Days before a tab is considered stale")
 	liveMode := fs.Bool("live", false, "Start in live mode (connect to extension)")
 	port := fs.Int("port", 19191, "WebSocket port for live mode")
+	bind := fs.String("bind", server.DefaultBind, "Address the live mode WebSocket server listens on")
+	ghTTL := fs.Duration("gh-ttl", 30*time.Minute, "Reuse cached GitHub status younger than this (0 disables the cache)")
+	ghTimeout := fs.Duration("gh-timeout", github.QueryTimeout, "Timeout for each GitHub status request")
+	ghSince := fs.Duration("gh-since", github.RefreshSince, "Skip tracked GitHub entities refreshed more recently than this when refreshing")
+	exactDups := fs.Bool("exact-dups", false, "Only treat identical URLs as duplicates (no URL normalization)")
+	sessionFile := fs.String("session-file", "", "Read this session file instead of the profile's newest one")
+	promptFile := fs.String("prompt-file", "", "Summarization prompt template (default: $TABSORDNUNG_PROMPT_FILE or built-in)")
+	noRestore := fs.Bool("no-restore", false, "Start with a clean layout instead of restoring expanded groups, cursor, filter and view")
+	notifyFlag := fs.Bool("notify", false, "Show a desktop notification when a tracked PR, issue or bug changes state")
+	lastFlag := fs.Bool("last", false, "Open the profile (or live mode) selected last time instead of showing the picker")
+	noColor := fs.Bool("no-color", os.Getenv("NO_COLOR") != "", "Disable colors (also set by NO_COLOR)")
+	themeName := fs.String("theme", os.Getenv("TABSORDNUNG_THEME"), "Color theme: "+strings.Join(tui.ThemeNames(), ", "))
+	dbFile := fs.String("db", "", dbFlagUsage)
+	metricsPort := fs.Int("metrics-port", 0, "Serve Prometheus metrics on this port at /metrics (default: off)")
 	fs.Parse(os.Args[1:])
 
+	if err := tui.SetTheme(*themeName, tui.ThemeFilePath()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if *noColor {
+		tui.DisableColor()
+	}
+
+	if *notifyFlag {
+		notify.Enable()
+	}
+	if *ghTimeout > 0 {
+		github.QueryTimeout = *ghTimeout
+	}
+	if *ghSince >= 0 {
+		github.RefreshSince = *ghSince
+	}
+
 	profiles, err := firefox.DiscoverProfiles()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error discovering Firefox profiles: %v\n", err)
@@ -84,59 +172,102 @@ func main() {
 		os.Exit(1)
 	}
 
-	// If --profile flag or TABSORDNUNG_PROFILE env var is set, filter to just that profile
-	resolved := resolveProfileName(*profileName)
-	if resolved != "" {
-		var filtered []types.Profile
-		for _, p := range profiles {
-			if p.Name == resolved {
-				filtered = append(filtered, p)
-				break
-			}
+	// If --profile flags or the TABSORDNUNG_PROFILE env var are set, filter
+	// to just those profiles. Several profiles are merged into one source.
+	names := []string(profileNames)
+	if len(names) == 0 {
+		if resolved := resolveProfileName(""); resolved != "" {
+			names = []string{resolved}
 		}
-		if len(filtered) == 0 {
-			fmt.Fprintf(os.Stderr, "Profile %q not found. Available profiles:\n", resolved)
+	}
+	if !*allProfiles && len(names) > 0 {
+		var filtered []types.Profile
+		for _, name := range names {
+			found := false
 			for _, p := range profiles {
-				fmt.Fprintf(os.Stderr, "  - %s\n", p.Name)
+				if p.Name == name {
+					filtered = append(filtered, p)
+					found = true
+					break
+				}
+			}
+			if !found {
+				fmt.Fprintf(os.Stderr, "Profile %q not found. Available profiles:\n", name)
+				for _, p := range profiles {
+					fmt.Fprintf(os.Stderr, "  - %s\n", p.Name)
+				}
+				os.Exit(1)
 			}
-			os.Exit(1)
 		}
 		profiles = filtered
 	}
+	if (*allProfiles || len(names) > 1) && len(profiles) > 1 {
+		profiles = []types.Profile{firefox.MergedProfile(profiles)}
+	}
 
 	// Always create the server — it's cheap (just a struct + channel).
 	// ListenAndServe is only called when the user actually enters live mode.
-	srv := server.New(*port)
+	srv := liveServer(*bind, *port)
 
 	// Resolve summarize config
 	resolvedModel := os.Getenv("TABSORDNUNG_MODEL")
 	if resolvedModel == "" {
-		resolvedModel = "llama3.2"
+		resolvedModel = summarize.DefaultModel
 	}
 	ollamaHost := os.Getenv("OLLAMA_HOST")
 	if ollamaHost == "" {
-		ollamaHost = "http://localhost:11434"
-	}
-	summaryDir := os.Getenv("TABSORDNUNG_SUMMARY_DIR")
-	if summaryDir == "" {
-		home, _ := os.UserHomeDir()
-		summaryDir = filepath.Join(home, ".local", "share", "tabsordnung", "summaries")
+		ollamaHost = summarize.DefaultOllamaHost
 	}
+	summaryDir := defaultSummaryDir()
+	prompt := loadPrompt(*promptFile)
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	// Initialize application log in the same directory as the database.
-	if dbPath, err := storage.DefaultDBPath(); err == nil {
-		applog.Init(filepath.Dir(dbPath))
+	var exporter *metrics.Exporter
+	if *metricsPort != 0 {
+		addr := net.JoinHostPort(*bind, strconv.Itoa(*metricsPort))
+		ln, err := net.Listen("tcp", addr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error starting metrics server: %v\n", err)
+			os.Exit(1)
+		}
+		exporter = metrics.New()
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go func() {
+			if err := exporter.Serve(ctx, ln); err != nil {
+				applog.Error("metrics.serve", err)
+			}
+		}()
+		applog.Info("metrics.listen", "addr", ln.Addr().String())
+	}
+
+	model := tui.NewModel(tui.Options{
+		Profiles:        profiles,
+		StaleDays:       *staleDays,
+		Live:            *liveMode,
+		Server:          srv,
+		SummaryDir:      summaryDir,
+		OllamaModel:     resolvedModel,
+		OllamaHost:      ollamaHost,
+		SummaryPrompt:   prompt,
+		DB:              db,
+		GitHubTTL:       *ghTTL,
+		ExactDuplicates: *exactDups,
+		SessionFile:     *sessionFile,
+		RememberUI:      !*noRestore,
+	})
+	if *lastFlag {
+		model.OpenLastSource()
+	}
+	if exporter != nil {
+		model.SetMetrics(exporter)
 	}
-	defer applog.Close()
-
-	model := tui.NewModel(profiles, *staleDays, *liveMode, srv, summaryDir, resolvedModel, ollamaHost, db)
 	p := tea.NewProgram(model, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
@@ -145,15 +276,89 @@ func main() {
 	}
 }
 
+// extractLogFlags removes --log-file and --log-level (with the value as the
+// next argument or after "=") from args, wherever they appear. It also
+// reports --db, which it leaves in place, so that the log can default to
+// the database's directory.
+func extractLogFlags(args []string) (rest []string, logFile, logLevel, dbFile string) {
+	for i := 0; i < len(args); i++ {
+		name, value, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		if !strings.HasPrefix(args[i], "-") {
+			name = ""
+		}
+		switch name {
+		case "log-file", "log-level":
+			if !hasValue && i+1 < len(args) {
+				i++
+				value = args[i]
+			}
+			if name == "log-file" {
+				logFile = value
+			} else {
+				logLevel = value
+			}
+			continue
+		case "db":
+			if !hasValue && i+1 < len(args) {
+				value = args[i+1]
+			}
+			dbFile = value
+		}
+		rest = append(rest, args[i])
+	}
+	return rest, logFile, logLevel, dbFile
+}
+
+// openLog starts logging. Logging is off unless asked for; --log-level
+// alone logs to the same directory as the database.
+func openLog(path, levelName, dbOverride string) error {
+	level := applog.LevelInfo
+	if levelName != "" {
+		l, err := applog.ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+		level = l
+	}
+	if path == "" && levelName != "" {
+		if dbPath, err := storage.ResolveDBPath(dbOverride); err == nil {
+			path = filepath.Join(filepath.Dir(dbPath), applog.FileName)
+		}
+	}
+	if path == "" {
+		return nil
+	}
+	if err := applog.Open(path, level); err != nil {
+		return fmt.Errorf("opening log file: %w", err)
+	}
+	return nil
+}
+
 func printHelp() {
 	fmt.Print(`tabsordnung — Firefox tab analyzer
 
 Usage:
   tabsordnung                                          Start the TUI (default)
-    --profile <name>       Firefox profile name (skips picker)
+    --profile <name>       Firefox profile name (skips picker); repeat to merge several profiles
+    --all-profiles         Merge the sessions of all Firefox profiles into one view
     --stale-days <n>       Days before a tab is considered stale (default: 7)
     --live                 Start in live mode (connect to extension)
     --port <n>             WebSocket port for live mode (default: 19191)
+    --bind <addr>          Address the live mode server listens on (default: 127.0.0.1)
+    --gh-ttl <duration>    Reuse cached GitHub status younger than this (default: 30m, 0 disables)
+    --gh-timeout <dur>     Timeout for each GitHub status request (default: 10s)
+    --gh-since <dur>       Skip GitHub entities refreshed more recently than this (default: 10m)
+    --exact-dups           Only treat identical URLs as duplicates (no URL normalization)
+    --session-file <path>  Read this session file (mozlz4 or JSON) instead of the profile's newest one
+    --prompt-file <path>   Summarization prompt template ({{.Title}}, {{.URL}}, {{.Content}})
+    --no-restore           Don't restore expanded groups, cursor, filter and view from the last session
+    --notify               Desktop notification when a tracked PR, issue or bug changes state
+    --last                 Open the profile (or live mode) selected last time, skipping the picker
+    --db <path>            Database file (default: ~/.local/share/tabsordnung/tabsordnung.db); also
+                           accepted by every command that reads or writes the database
+    --metrics-port <n>     Serve Prometheus metrics at http://<bind>:<n>/metrics (default: off)
+    --theme <name>         Color theme: auto, dark, light, high-contrast (default: auto)
+    --no-color             Disable colors; cursors use reverse video (also set by NO_COLOR)
 
   tabsordnung export                                   Export tabs to stdout or file
     --profile <name>       Firefox profile name
@@ -161,24 +366,48 @@ Usage:
     --out <file>           Output file path (default: stdout)
     --live                 Export from live extension instead of session file
     --port <n>             WebSocket port for live mode (default: 19191)
+    --bind <addr>          Address the live mode server listens on (default: 127.0.0.1)
+    --timeout <dur>        How long --live waits for the extension to connect (default: 10s)
+    --session-file <path>  Read this session file instead of the profile's newest one
+    --with-summaries       Embed Ollama summaries under their tabs ($TABSORDNUNG_SUMMARY_DIR)
+    --filter <name>        Only export matching tabs: stale, dead, duplicate, duplicate-in-group,
+                           age7, age30, age90, github-done, github-waiting, summarized,
+                           unsummarized, placeholder, pinned, container:NAME
+    --stale-days <n>       Days before a tab is considered stale, for --filter and --json (default: 7)
+    --no-analyze           With --json, skip the dead link and GitHub checks (no network)
 
   tabsordnung profiles                                 List Firefox profiles
+  tabsordnung version [--db path]                      Show the version, commit, build date and schema version
+  tabsordnung doctor [--profile X] [--port N] [--bind addr]  Check the setup and suggest fixes
+
+  tabsordnung stats                                    Show tab counts and a last-accessed age histogram
+    --profile <name>       Firefox profile name
+    --session-file <path>  Read this session file instead of the profile's newest one
+    --stale-days <n>       Days before a tab is considered stale (default: 7)
+    --all-profiles         Count the tabs of all Firefox profiles together
 
-  tabsordnung snapshot [--profile X] [--label "text"]  Auto-snapshot (only if changed)
+  tabsordnung snapshot [--profile X] [--label "text"] [--check]  Auto-snapshot (only if changed)
   tabsordnung snapshot list                            List saved snapshots
   tabsordnung snapshot diff [rev] [rev2] [--profile X] Compare snapshots or current tabs
   tabsordnung snapshot delete <rev> [--profile X] [--yes]  Delete a snapshot
-  tabsordnung snapshot restore <rev> [--profile X] [--port N]  Restore tabs via live mode
+  tabsordnung snapshot restore <rev> [--profile X] [--port N] [--bind addr] [--group "Name"] [--timeout D]  Restore tabs via live mode
+  tabsordnung snapshot export <rev> [--profile X] [--json] [--out file]  Export a snapshot
 
   tabsordnung signals                                    List active signals
-  tabsordnung signals list [--all] [--json] [--source X] List signals
+  tabsordnung signals list [--all|--active] [--json] [--source X] [--urgency U]  List signals
   tabsordnung signals complete <id>                      Mark signal as completed
   tabsordnung signals reopen <id>                        Reopen a completed signal
+  tabsordnung signals unsnooze <id>                      Bring back a snoozed signal now
+  tabsordnung signals export --ics [--all] [--urgency U] [--out FILE]  Export signals as iCal tasks
+                                                         (--urgency: urgent, review, fyi or unclassified)
 
   tabsordnung github                                     List open GitHub entities
-  tabsordnung github list [--all] [--json] [--state X] [--kind X] [--repo owner/repo]  List tracked GitHub entities
+  tabsordnung github list [--all] [--json|--csv] [--state X] [--kind X] [--repo owner/repo]  List tracked GitHub entities
+  tabsordnung github refresh [--since D] [--force]       Refresh tracked GitHub entities older than --since (default: 10m)
   tabsordnung bugzilla                                   List tracked Bugzilla issues
   tabsordnung bugzilla list [--json] [--host domain]    List tracked Bugzilla issues
+  tabsordnung gitlab                                     List tracked GitLab merge requests and issues
+  tabsordnung gitlab list [--json] [--project group/project]  List tracked GitLab merge requests and issues
 
   tabsordnung history                                  Show tab visit history
     --date <YYYY-MM-DD>    Date to query (default: today)
@@ -186,24 +415,44 @@ Usage:
     --month                Query the current calendar month
     --json                 Output as JSON
 
+  tabsordnung ws-token [--new]                         Print the live mode WebSocket token, generating one if needed
+
+  tabsordnung db info [--db path]                      Show database size, migration version and row counts
+  tabsordnung db vacuum [--db path]                    Compact the database and truncate its write-ahead log
+
   tabsordnung rules view                               Show urgency classification rules
   tabsordnung rules edit                               Open rules file in $EDITOR
 
   tabsordnung triage                                   Classify GitHub tabs into groups
     --profile <name>       Firefox profile name
     --apply                Apply moves without confirmation
+    --dry-run              Show proposed moves and exit
+    --json                 Print proposed moves as JSON (implies --dry-run unless --apply)
+    --rules <path>         Triage rules file (default: ~/.config/tabsordnung/triage.json)
     --port <n>             WebSocket port for live mode (default: 19191)
+    --bind <addr>          Address the live mode server listens on (default: 127.0.0.1)
 
   tabsordnung summarize                                  Summarize tabs via Ollama
     --profile <name>       Firefox profile name
     --model <name>         Ollama model (env: TABSORDNUNG_MODEL, default: llama3.2)
     --out-dir <path>       Output directory (default: ~/.local/share/tabsordnung/summaries/)
     --group <name>         Tab group to summarize (default: "Summarize This")
+    --prompt-file <path>   Prompt template ({{.Title}}, {{.URL}}, {{.Content}}; env: TABSORDNUNG_PROMPT_FILE)
+
+Global flags (accepted before or after any command):
+  --log-file <path>        Write a log to this file to tail while debugging (default: off)
+  --log-level <level>      debug, info or error (default: info); alone, logs next to the database
 
 Environment:
   TABSORDNUNG_PROFILE    Default Firefox profile (overridden by --profile flag)
   TABSORDNUNG_MODEL      Default Ollama model (overridden by --model flag)
   OLLAMA_HOST            Ollama server URL (default: http://localhost:11434)
+  TABSORDNUNG_DB         Database file (overridden by --db)
+  TABSORDNUNG_WS_TOKEN   Token the extension must present in live mode (overrides ~/.config/tabsordnung/ws-token)
+  BUGZILLA_API_KEY       Bugzilla API key for private bugs (per host: BUGZILLA_API_KEY_<HOST>,
+                         or ~/.config/tabsordnung/bugzilla-keys.json)
+  GITLAB_TOKEN           GitLab token (read_api) for private projects, sent to gitlab.com and GITLAB_HOST
+  GITLAB_HOST            Self-managed GitLab host that also receives GITLAB_TOKEN
 `)
 }
 
@@ -214,13 +463,33 @@ func runExport(args []string) {
 	outFile := fs.String("out", "", "Output file path (default: stdout)")
 	liveMode := fs.Bool("live", false, "Export from live extension instead of session file")
 	port := fs.Int("port", 19191, "WebSocket port for live mode")
+	bind := fs.String("bind", server.DefaultBind, "Address the live mode WebSocket server listens on")
+	timeout := fs.Duration("timeout", server.DefaultConnectTimeout, "How long --live waits for the extension to connect")
+	sessionFile := fs.String("session-file", "", "Read this session file instead of the profile's newest one")
+	withSummaries := fs.Bool("with-summaries", false, "Embed Ollama summaries under their tabs (markdown only)")
+	filterFlag := fs.String("filter", "", "Only export tabs matching this filter (stale, dead, age30, github-done, container:NAME, ...)")
+	staleDays := fs.Int("stale-days", 7, "Days before a tab is considered stale (for --filter and --json)")
+	noAnalyze := fs.Bool("no-analyze", false, "Skip the dead link and GitHub checks before a JSON export (faster, no network)")
 	fs.Parse(args)
 
+	var tabFilter filter.Filter
+	if *filterFlag != "" {
+		f, err := filter.Parse(*filterFlag)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		f.SummaryDir = defaultSummaryDir()
+		tabFilter = f
+	}
+
 	var data *types.SessionData
 	var err error
 
 	if *liveMode {
-		data, err = exportLive(*port)
+		data, err = exportLive(*bind, *port, *timeout)
+	} else if *sessionFile != "" {
+		data, err = firefox.ReadSession(*sessionFile, "")
 	} else {
 		data, err = resolveSession(resolveProfileName(*profileName))
 	}
@@ -229,13 +498,26 @@ func runExport(args []string) {
 		os.Exit(1)
 	}
 
+	var analyses []string
+	switch {
+	case *jsonFlag:
+		analyses = analyzeForExport(data, tabFilter, *staleDays, !*noAnalyze)
+	case *filterFlag != "":
+		analyzeForFilter(data, tabFilter, *staleDays)
+	}
+	if *filterFlag != "" {
+		data = filter.Apply(data, tabFilter)
+	}
+
 	var output string
 	if *jsonFlag {
-		output, err = export.JSON(data)
+		output, err = export.JSONWithAnalysis(data, analyses)
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", err)
 			os.Exit(1)
 		}
+	} else if *withSummaries {
+		output = export.MarkdownWithSummaries(data, defaultSummaryDir())
 	} else {
 		output = export.Markdown(data)
 	}
@@ -250,6 +532,208 @@ func runExport(args []string) {
 	}
 }
 
+// analyzeForFilter runs the analysis f depends on. Staleness and
+// duplicates are cheap and always computed; dead links and GitHub status
+// are only checked when the filter needs them.
+// analyzeForFilter runs the analysis f needs. It reports whether the
+// GitHub status of every tab was looked up, for the GitHub filters.
+func analyzeForFilter(data *types.SessionData, f filter.Filter, staleDays int) (githubDone bool) {
+	analyzer.AnalyzeStale(data.AllTabs, staleDays)
+	analyzer.AnalyzePlaceholders(data.AllTabs)
+	analyzer.AnalyzeDuplicates(data.AllTabs, false)
+	switch f.Mode {
+	case types.FilterDead:
+		checkDeadLinks(data.AllTabs)
+	case types.FilterGitHubDone, types.FilterGitHubWaiting:
+		token := analyzer.ResolveGitHubToken()
+		if token == "" {
+			fmt.Fprintln(os.Stderr, "Warning: no GitHub token available, GitHub filters match nothing. Run 'gh auth login' or set GITHUB_TOKEN.")
+			return false
+		}
+		username, err := analyzer.ResolveGitHubUser(token)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: resolving GitHub user: %v\n", err)
+			return false
+		}
+		if err := analyzer.AnalyzeGitHubTriage(context.Background(), data.AllTabs, username); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: GitHub status incomplete: %v\n", err)
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// analyzeForExport runs the analysis for a JSON export and returns the
+// names of the analyses that completed; one that failed part-way is left
+// out, as its verdicts are missing for some tabs. The network checks, dead
+// links and GitHub status, only run if network is set or f needs them.
+func analyzeForExport(data *types.SessionData, f filter.Filter, staleDays int, network bool) []string {
+	githubDone := analyzeForFilter(data, f, staleDays)
+	analyses := []string{export.AnalysisStale, export.AnalysisDuplicates}
+	switch {
+	case f.Mode == types.FilterDead:
+		analyses = append(analyses, export.AnalysisDead)
+	case network:
+		checkDeadLinks(data.AllTabs)
+		analyses = append(analyses, export.AnalysisDead)
+	}
+	switch {
+	case f.Mode == types.FilterGitHubDone || f.Mode == types.FilterGitHubWaiting:
+		if githubDone {
+			analyses = append(analyses, export.AnalysisGitHub)
+		}
+	case !network:
+	case analyzer.ResolveGitHubToken() == "":
+		fmt.Fprintln(os.Stderr, "Warning: no GitHub token available, skipping GitHub status. Run 'gh auth login' or set GITHUB_TOKEN.")
+	default:
+		if err := analyzer.AnalyzeGitHub(context.Background(), data.AllTabs); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: GitHub status incomplete, not listing github in analyses: %v\n", err)
+			break
+		}
+		analyses = append(analyses, export.AnalysisGitHub)
+	}
+	return analyses
+}
+
+// checkDeadLinks checks tabs for dead links, reporting progress on stderr.
+func checkDeadLinks(tabs []*types.Tab) {
+	fmt.Fprintf(os.Stderr, "Checking %d tabs for dead links...\n", len(tabs))
+	results := make(chan analyzer.DeadLinkResult, len(tabs))
+	go func() {
+		analyzer.AnalyzeDeadLinks(context.Background(), tabs, results)
+		close(results)
+	}()
+	for range results {
+	}
+}
+
+// wsToken returns the token live mode connections must present, or "" if
+// none is configured. It exits on error.
+func wsToken() string {
+	token, err := server.ResolveToken()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return token
+}
+
+// liveServer creates the live mode WebSocket server with the configured
+// bind address and token. Listening beyond loopback prints a warning.
+func liveServer(bind string, port int) *server.Server {
+	srv := server.New(port)
+	srv.SetBind(bind)
+	srv.SetToken(wsToken())
+	if !server.IsLoopback(bind) {
+		fmt.Fprintf(os.Stderr, "WARNING: live mode server listens on %s and is reachable from other machines.\n", srv.Addr())
+		if !srv.TokenRequired() {
+			fmt.Fprintln(os.Stderr, "WARNING: no token is configured -- anyone who can reach this port can read and control your tabs. Run 'tabsordnung ws-token' to create one.")
+		}
+	}
+	return srv
+}
+
+func runWSToken(args []string) {
+	fs := flag.NewFlagSet("ws-token", flag.ExitOnError)
+	regenerate := fs.Bool("new", false, "Generate a new token, replacing the saved one")
+	fs.Parse(args)
+
+	if os.Getenv(server.TokenEnv) != "" && !*regenerate {
+		fmt.Println(wsToken())
+		fmt.Fprintf(os.Stderr, "(from $%s)\n", server.TokenEnv)
+		return
+	}
+	token := ""
+	if !*regenerate {
+		token = wsToken()
+	}
+	if token == "" {
+		var err error
+		token, err = server.GenerateToken(server.TokenFilePath())
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Saved new token to %s\n", server.TokenFilePath())
+		if os.Getenv(server.TokenEnv) != "" {
+			fmt.Fprintf(os.Stderr, "Note: $%s is set and takes precedence over the saved token\n", server.TokenEnv)
+		}
+	}
+	fmt.Println(token)
+}
+
+// versionString describes the build as "tabsordnung VERSION (COMMIT, DATE)".
+func versionString() string {
+	rev, at := commit, date
+	dirty := false
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && rev == "":
+				rev = s.Value
+			case s.Key == "vcs.time" && at == "":
+				at = s.Value
+			case s.Key == "vcs.modified" && s.Value == "true" && commit == "":
+				dirty = true
+			}
+		}
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	if dirty && rev != "" {
+		rev += "-dirty"
+	}
+	var parts []string
+	for _, p := range []string{rev, at} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	if len(parts) == 0 {
+		return "tabsordnung " + version
+	}
+	return fmt.Sprintf("tabsordnung %s (%s)", version, strings.Join(parts, ", "))
+}
+
+// schemaString describes the migration version of the database at override
+// (or the default database) next to the newest one this build knows.
+func schemaString(override string) (string, error) {
+	path, err := storage.ResolveDBPath(override)
+	if err != nil {
+		return "", err
+	}
+	applied, latest, err := storage.SchemaVersion(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Sprintf("%d (no database at %s yet)", latest, path), nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	switch {
+	case applied > latest:
+		return fmt.Sprintf("%d in %s, newer than this build (%d)", applied, path, latest), nil
+	case applied < latest:
+		return fmt.Sprintf("%d in %s (%d after the next start)", applied, path, latest), nil
+	}
+	return fmt.Sprintf("%d in %s", applied, path), nil
+}
+
+func runVersion(args []string) {
+	fs := flag.NewFlagSet("version", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(args)
+
+	fmt.Println(versionString())
+	fmt.Printf("protocol  %s\n", server.ProtocolVersion)
+	schema, err := schemaString(*dbFile)
+	if err != nil {
+		schema = "unknown: " + err.Error()
+	}
+	fmt.Printf("schema    %s\n", schema)
+}
+
 func runProfiles() {
 	profiles, err := firefox.DiscoverProfiles()
 	if err != nil {
@@ -270,26 +754,281 @@ func runProfiles() {
 	}
 }
 
-func exportLive(port int) (*types.SessionData, error) {
-	srv := server.New(port)
-	ctx, cancel := context.WithCancel(context.Background())
+// doctorCheck is one line of the doctor checklist. A failed check carries
+// a hint on how to fix it.
+type doctorCheck struct {
+	name   string
+	ok     bool
+	detail string
+	hint   string
+}
+
+func runDoctor(args []string) {
+	fs := flag.NewFlagSet("doctor", flag.ExitOnError)
+	profileName := fs.String("profile", "", "Firefox profile to read the session of")
+	port := fs.Int("port", 19191, "WebSocket port for live mode")
+	bind := fs.String("bind", server.DefaultBind, "Address the live mode WebSocket server listens on")
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(args)
+
+	checks := []doctorCheck{
+		checkVersion(*dbFile),
+		checkProfiles(resolveProfileName(*profileName)),
+		checkDatabase(*dbFile),
+		checkGitHubToken(),
+		checkOllama(),
+		checkSignalSources(),
+		checkPort(*bind, *port),
+	}
+
+	failed := 0
+	for _, c := range checks {
+		mark := "ok  "
+		if !c.ok {
+			mark = "FAIL"
+			failed++
+		}
+		fmt.Printf("[%s] %-17s %s\n", mark, c.name, c.detail)
+		if !c.ok && c.hint != "" {
+			fmt.Printf("%25s→ %s\n", "", c.hint)
+		}
+	}
+	if failed > 0 {
+		fmt.Printf("\n%d of %d checks failed.\n", failed, len(checks))
+		os.Exit(1)
+	}
+	fmt.Println("\nAll checks passed.")
+}
+
+func checkVersion(dbOverride string) doctorCheck {
+	c := doctorCheck{name: "Version", ok: true}
+	c.detail = strings.TrimPrefix(versionString(), "tabsordnung ")
+	path, err := storage.ResolveDBPath(dbOverride)
+	if err != nil {
+		return c
+	}
+	applied, latest, err := storage.SchemaVersion(path)
+	if err != nil {
+		c.detail += fmt.Sprintf(", schema %d", latest)
+		return c
+	}
+	c.detail += fmt.Sprintf(", schema %d of %d", applied, latest)
+	if applied > latest {
+		c.ok = false
+		c.hint = "The database was migrated by a newer build; upgrade tabsordnung before using it."
+	}
+	return c
+}
+
+func checkSignalSources() doctorCheck {
+	c := doctorCheck{name: "Signal sources", ok: sourcesErr == nil}
+	path := signal.SourcesFilePath()
+	switch _, err := os.Stat(path); {
+	case sourcesErr != nil:
+		c.detail = sourcesErr.Error()
+		c.hint = "Fix or remove " + path + "; until then only the built-in sources are used."
+	case err != nil:
+		c.detail = "built-in only"
+	default:
+		c.detail = "built-in and " + path
+	}
+	return c
+}
+
+func checkProfiles(profileName string) doctorCheck {
+	c := doctorCheck{name: "Firefox profiles"}
+	profiles, err := firefox.DiscoverProfiles()
+	if err != nil {
+		c.detail = err.Error()
+		c.hint = "Is Firefox installed? Run it once to create a profile."
+		return c
+	}
+	if len(profiles) == 0 {
+		c.detail = "no profiles found"
+		c.hint = "Run Firefox once to create a profile, or pass --session-file to the TUI."
+		return c
+	}
+	data, err := resolveSession(profileName)
+	if err != nil {
+		c.detail = fmt.Sprintf("%d found, but: %v", len(profiles), err)
+		c.hint = "Check --profile or TABSORDNUNG_PROFILE against 'tabsordnung profiles'; Firefox writes the session file a few seconds after it starts."
+		return c
+	}
+	stats := analyzer.ComputeStats(data)
+	c.ok = true
+	c.detail = fmt.Sprintf("%d found; %s has %d tabs in %d groups", len(profiles), data.Profile.Name, stats.TotalTabs, stats.TotalGroups)
+	return c
+}
+
+func checkDatabase(override string) doctorCheck {
+	c := doctorCheck{name: "Database"}
+	path, err := storage.ResolveDBPath(override)
+	if err != nil {
+		c.detail = err.Error()
+		return c
+	}
+	hint := fmt.Sprintf("Check the permissions of %s and that the disk is not full.", filepath.Dir(path))
+	db, err := storage.OpenDB(path)
+	if err != nil {
+		c.detail, c.hint = err.Error(), hint
+		return c
+	}
+	defer db.Close()
+	if err := storage.CheckWritable(db); err != nil {
+		c.detail, c.hint = fmt.Sprintf("%s: %v", path, err), hint
+		return c
+	}
+	c.ok = true
+	c.detail = path + " is writable"
+	return c
+}
+
+func checkGitHubToken() doctorCheck {
+	c := doctorCheck{name: "GitHub token"}
+	if _, err := exec.LookPath("gh"); err != nil {
+		c.detail = "gh not found in PATH"
+		c.hint = "Install the GitHub CLI (https://cli.github.com) and run 'gh auth login'. Without it GitHub status is not shown."
+		return c
+	}
+	if analyzer.ResolveGitHubToken() == "" {
+		c.detail = "gh auth token returned no token"
+		c.hint = "Run 'gh auth login'."
+		return c
+	}
+	c.ok = true
+	c.detail = "gh auth token works"
+	return c
+}
+
+func checkOllama() doctorCheck {
+	c := doctorCheck{name: "Ollama"}
+	host := os.Getenv("OLLAMA_HOST")
+	if host == "" {
+		host = summarize.DefaultOllamaHost
+	}
+	model := os.Getenv("TABSORDNUNG_MODEL")
+	if model == "" {
+		model = summarize.DefaultModel
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
+	models, err := summarize.OllamaModels(ctx, host)
+	if err != nil {
+		c.detail = fmt.Sprintf("%s unreachable: %v", host, err)
+		c.hint = "Start it with 'ollama serve', or point OLLAMA_HOST at your instance. Only summaries and signal classification need it."
+		return c
+	}
+	if !summarize.HasModel(models, model) {
+		c.detail = fmt.Sprintf("%s is up, but model %s is not pulled", host, model)
+		c.hint = fmt.Sprintf("Run 'ollama pull %s', or set TABSORDNUNG_MODEL to one of the %d installed models.", model, len(models))
+		return c
+	}
+	c.ok = true
+	c.detail = fmt.Sprintf("%s is up with model %s", host, model)
+	return c
+}
+
+func checkPort(bind string, port int) doctorCheck {
+	c := doctorCheck{name: "Live mode port"}
+	if err := server.CheckPort(bind, port); err != nil {
+		c.detail = err.Error()
+		var inUse *server.PortInUseError
+		if errors.As(err, &inUse) {
+			c.hint = "Quit the other tabsordnung, or use --port and set the same address in the extension options."
+		} else {
+			c.hint = "Check --bind."
+		}
+		return c
+	}
+	c.ok = true
+	c.detail = fmt.Sprintf("%s is free", net.JoinHostPort(bind, strconv.Itoa(port)))
+	return c
+}
+
+// profileList collects repeated --profile flags.
+type profileList []string
 
-	go srv.ListenAndServe(ctx)
+func (l *profileList) String() string { return strings.Join(*l, ",") }
 
-	fmt.Fprintf(os.Stderr, "Waiting for Firefox extension on port %d...\n", port)
+func (l *profileList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runStats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	profileName := fs.String("profile", "", "Firefox profile name")
+	sessionFile := fs.String("session-file", "", "Read this session file instead of the profile's newest one")
+	staleDays := fs.Int("stale-days", 7, "Days before a tab is considered stale")
+	allProfiles := fs.Bool("all-profiles", false, "Count the tabs of all Firefox profiles together")
+	fs.Parse(args)
 
-	timeout := time.After(10 * time.Second)
-	for {
-		select {
-		case msg := <-srv.Messages():
-			if msg.Type == "snapshot" {
-				return server.ParseSnapshot(msg)
+	var data *types.SessionData
+	var err error
+	if *allProfiles {
+		var profiles []types.Profile
+		if profiles, err = firefox.DiscoverProfiles(); err == nil {
+			data, err = firefox.ReadProfiles(profiles)
+		}
+	} else if *sessionFile != "" {
+		data, err = firefox.ReadSession(*sessionFile, "")
+	} else {
+		data, err = resolveSession(resolveProfileName(*profileName))
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	for _, w := range data.Warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", w)
+	}
+
+	analyzer.AnalyzeStale(data.AllTabs, *staleDays)
+	analyzer.AnalyzePlaceholders(data.AllTabs)
+	analyzer.AnalyzeDuplicates(data.AllTabs, false)
+	stats := analyzer.ComputeStats(data)
+
+	fmt.Printf("Tabs:       %d\n", stats.TotalTabs)
+	fmt.Printf("Windows:    %d\n", stats.TotalWindows)
+	fmt.Printf("Groups:     %d\n", stats.TotalGroups)
+	fmt.Printf("Pinned:     %d\n", stats.PinnedTabs)
+	fmt.Printf("Stale:      %d (>%d days)\n", stats.StaleTabs, *staleDays)
+	fmt.Printf("Duplicates: %d\n", stats.DuplicateTabs)
+	if *allProfiles {
+		across := 0
+		for _, tab := range data.AllTabs {
+			if tab.DuplicateAcrossProfiles {
+				across++
 			}
-		case <-timeout:
-			return nil, fmt.Errorf("timed out waiting for extension (10s)")
 		}
+		fmt.Printf("  in another profile: %d\n", across)
+	}
+
+	fmt.Println("\nLast accessed:")
+	hist := analyzer.AgeHistogram(data.AllTabs)
+	for _, bucket := range analyzer.AgeBuckets {
+		n := hist[bucket]
+		bar := ""
+		if stats.TotalTabs > 0 {
+			bar = strings.Repeat("#", (n*40+stats.TotalTabs-1)/stats.TotalTabs)
+		}
+		fmt.Println(strings.TrimRight(fmt.Sprintf("  %-7s %5d  %s", bucket, n, bar), " "))
+	}
+}
+
+func exportLive(bind string, port int, timeout time.Duration) (*types.SessionData, error) {
+	srv := liveServer(bind, port)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe(ctx) }()
+
+	msg, err := srv.AwaitSnapshot(errc, timeout, os.Stderr)
+	if err != nil {
+		return nil, err
 	}
+	return server.ParseSnapshot(msg)
 }
 
 // resolveSession discovers profiles and reads session data for the given
@@ -336,8 +1075,39 @@ func resolveSession(profileName string) (*types.SessionData, error) {
 	return session, nil
 }
 
-func openDB() (*sql.DB, error) {
-	dbPath, err := storage.DefaultDBPath()
+// defaultSummaryDir returns the directory summaries are written to:
+// TABSORDNUNG_SUMMARY_DIR, or ~/.local/share/tabsordnung/summaries.
+func defaultSummaryDir() string {
+	if dir := os.Getenv("TABSORDNUNG_SUMMARY_DIR"); dir != "" {
+		return dir
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".local", "share", "tabsordnung", "summaries")
+}
+
+// loadPrompt loads the summarization prompt template: the given file, else
+// TABSORDNUNG_PROMPT_FILE, else the built-in prompt. It exits on errors so a
+// typo in the template is caught before any summaries are written.
+func loadPrompt(path string) *summarize.Prompt {
+	if path == "" {
+		path = os.Getenv("TABSORDNUNG_PROMPT_FILE")
+	}
+	prompt, err := summarize.LoadPrompt(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return prompt
+}
+
+// dbFlagUsage describes the --db flag every command that opens the
+// database accepts.
+const dbFlagUsage = "Database file (default: $" + storage.DBPathEnv + " or ~/.local/share/tabsordnung/tabsordnung.db)"
+
+// openDB opens the database at path, falling back to TABSORDNUNG_DB and
+// then the default location when path is empty.
+func openDB(path string) (*sql.DB, error) {
+	dbPath, err := storage.ResolveDBPath(path)
 	if err != nil {
 		return nil, err
 	}
@@ -385,23 +1155,28 @@ func runSnapshot(args []string) {
 	case "create":
 		runSnapshotCreate(subArgs)
 	case "list":
-		runSnapshotList()
+		runSnapshotList(subArgs)
 	case "diff":
 		runSnapshotDiff(subArgs)
 	case "delete":
 		runSnapshotDelete(subArgs)
 	case "restore":
 		runSnapshotRestore(subArgs)
+	case "export":
+		runSnapshotExport(subArgs)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown snapshot command %q. Use list, diff, delete, or restore.\n", subcmd)
+		fmt.Fprintf(os.Stderr, "Unknown snapshot command %q. Use list, diff, delete, restore, or export.\n", subcmd)
 		os.Exit(1)
 	}
 }
 
 func runSnapshotCreate(args []string) {
 	fs := flag.NewFlagSet("snapshot", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	profileName := fs.String("profile", "", "Firefox profile name")
 	label := fs.String("label", "", "Optional label for the snapshot")
+	staleDays := fs.Int("stale-days", 7, "Days before a tab is considered stale")
+	check := fs.Bool("check", false, "Also check for dead links and GitHub status (uses the network)")
 	fs.Parse(args)
 
 	session, err := resolveSession(resolveProfileName(*profileName))
@@ -410,7 +1185,16 @@ func runSnapshotCreate(args []string) {
 		os.Exit(1)
 	}
 
-	db, err := openDB()
+	analyzer.AnalyzeStale(session.AllTabs, *staleDays)
+	analyzer.AnalyzeDuplicates(session.AllTabs, false)
+	if *check {
+		checkDeadLinks(session.AllTabs)
+		if err := analyzer.AnalyzeGitHub(context.Background(), session.AllTabs); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: GitHub status incomplete: %v\n", err)
+		}
+	}
+
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -464,8 +1248,12 @@ func runSnapshotCreate(args []string) {
 	}
 }
 
-func runSnapshotList() {
-	db, err := openDB()
+func runSnapshotList(args []string) {
+	fs := flag.NewFlagSet("snapshot list", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(args)
+
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -497,12 +1285,13 @@ func runSnapshotList() {
 
 func runSnapshotDiff(args []string) {
 	fs := flag.NewFlagSet("snapshot diff", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	profileName := fs.String("profile", "", "Firefox profile name")
 	fs.Parse(reorderArgs(args))
 
 	profile := resolveProfileName(*profileName)
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -580,6 +1369,7 @@ func runSnapshotDiff(args []string) {
 
 func runSnapshotDelete(args []string) {
 	fs := flag.NewFlagSet("snapshot delete", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	profileName := fs.String("profile", "", "Firefox profile name")
 	yes := fs.Bool("yes", false, "Skip confirmation prompt")
 	fs.Parse(reorderArgs(args))
@@ -617,7 +1407,7 @@ func runSnapshotDelete(args []string) {
 		}
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -633,12 +1423,16 @@ func runSnapshotDelete(args []string) {
 
 func runSnapshotRestore(args []string) {
 	fs := flag.NewFlagSet("snapshot restore", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	profileName := fs.String("profile", "", "Firefox profile name")
 	port := fs.Int("port", 19191, "WebSocket port for live mode")
+	bind := fs.String("bind", server.DefaultBind, "Address the live mode WebSocket server listens on")
+	group := fs.String("group", "", "Restore only tabs from this group")
+	timeout := fs.Duration("timeout", server.DefaultConnectTimeout, "How long to wait for the extension to connect")
 	fs.Parse(reorderArgs(args))
 
 	if fs.NArg() < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: tabsordnung snapshot restore <rev> [--profile name] [--port N]")
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung snapshot restore <rev> [--profile name] [--port N] [--bind addr] [--group name] [--timeout D]")
 		os.Exit(1)
 	}
 
@@ -659,26 +1453,120 @@ func runSnapshotRestore(args []string) {
 		profile = session.Profile.Name
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	if err := snapshot.Restore(db, profile, rev, *port); err != nil {
+	var keep func(storage.SnapshotTab) bool
+	if *group != "" {
+		keep = snapshot.InGroup(*group)
+	}
+
+	if err := snapshot.Restore(db, profile, rev, liveServer(*bind, *port), keep, *timeout); err != nil {
 		fmt.Fprintf(os.Stderr, "Error restoring snapshot: %v\n", err)
 		os.Exit(1)
 	}
 }
 
-func runTriage(args []string) {
-	fs := flag.NewFlagSet("triage", flag.ExitOnError)
+func runSnapshotExport(args []string) {
+	fs := flag.NewFlagSet("snapshot export", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	profileName := fs.String("profile", "", "Firefox profile name")
-	apply := fs.Bool("apply", false, "Apply moves via live mode (skip confirmation)")
-	port := fs.Int("port", 19191, "WebSocket port for live mode")
-	fs.Parse(args)
-
+	jsonFlag := fs.Bool("json", false, "Export as JSON instead of markdown")
+	outFile := fs.String("out", "", "Output file path (default: stdout)")
+	fs.Parse(reorderArgs(args))
+
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung snapshot export <rev> [--profile name] [--json] [--out file]")
+		os.Exit(1)
+	}
+
+	rev, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid revision number: %s\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	// Resolve profile.
+	profile := resolveProfileName(*profileName)
+	if profile == "" {
+		session, err := resolveSession("")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		profile = session.Profile.Name
+	}
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	snap, err := storage.GetSnapshot(db, profile, rev)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	data := snapshot.ToSessionData(snap)
+
+	var output string
+	if *jsonFlag {
+		output, err = export.JSON(data)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		output = export.Markdown(data)
+	}
+
+	if *outFile != "" {
+		if err := os.WriteFile(*outFile, []byte(output), 0644); err != nil {
+			fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		fmt.Print(output)
+	}
+}
+
+func runTriage(args []string) {
+	fs := flag.NewFlagSet("triage", flag.ExitOnError)
+	profileName := fs.String("profile", "", "Firefox profile name")
+	apply := fs.Bool("apply", false, "Apply moves via live mode (skip confirmation)")
+	port := fs.Int("port", 19191, "WebSocket port for live mode")
+	bind := fs.String("bind", server.DefaultBind, "Address the live mode WebSocket server listens on")
+	dryRun := fs.Bool("dry-run", false, "Show proposed moves and exit without applying")
+	jsonFlag := fs.Bool("json", false, "Print proposed moves as JSON (implies --dry-run unless --apply is given)")
+	rulesFile := fs.String("rules", "", "Triage rules file (default: ~/.config/tabsordnung/triage.json)")
+	fs.Parse(args)
+
+	if *dryRun && *apply {
+		fmt.Fprintln(os.Stderr, "Error: --dry-run and --apply are mutually exclusive")
+		os.Exit(1)
+	}
+
+	// Only the default rules file may be missing; a --rules path that
+	// doesn't exist is a typo, not a request for the built-in rules.
+	rulesPath := *rulesFile
+	if rulesPath == "" {
+		rulesPath = triage.RulesFilePath()
+	} else if _, err := os.Stat(rulesPath); err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading triage rules: %v\n", err)
+		os.Exit(1)
+	}
+	rules, err := triage.LoadRules(rulesPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading triage rules: %v\n", err)
+		os.Exit(1)
+	}
+
 	session, err := resolveSession(resolveProfileName(*profileName))
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
@@ -698,15 +1586,30 @@ func runTriage(args []string) {
 	}
 
 	fmt.Fprintf(os.Stderr, "Fetching GitHub status for %d tabs (as @%s)...\n", len(session.AllTabs), username)
-	analyzer.AnalyzeGitHubTriage(session.AllTabs, username)
-
-	result := triage.Classify(session.AllTabs)
-	fmt.Print(triage.FormatDryRun(result))
+	if err := analyzer.AnalyzeGitHubTriage(context.Background(), session.AllTabs, username); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: GitHub status incomplete: %v\n", err)
+	}
 
-	total := len(result.NeedsAttention) + len(result.OpenPRs) + len(result.OpenIssues) + len(result.ClosedMerged)
-	if total == 0 {
-		fmt.Println("No GitHub tabs to triage.")
-		return
+	result := triage.Classify(session.AllTabs, rules)
+	if *jsonFlag {
+		out, err := triage.DryRunJSON(result)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error generating JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(out)
+		if !*apply || result.Total() == 0 {
+			return
+		}
+	} else {
+		fmt.Print(triage.FormatDryRun(result))
+		if result.Total() == 0 {
+			fmt.Println("No GitHub tabs to triage.")
+			return
+		}
+		if *dryRun {
+			return
+		}
 	}
 
 	if !*apply {
@@ -720,7 +1623,7 @@ func runTriage(args []string) {
 		}
 	}
 
-	if err := triage.Apply(result, *port); err != nil {
+	if err := triage.Apply(result, liveServer(*bind, *port)); err != nil {
 		fmt.Fprintf(os.Stderr, "Error applying triage: %v\n", err)
 		os.Exit(1)
 	}
@@ -729,9 +1632,10 @@ func runTriage(args []string) {
 func runSummarize(args []string) {
 	fs := flag.NewFlagSet("summarize", flag.ExitOnError)
 	profileName := fs.String("profile", "", "Firefox profile name")
-	model := fs.String("model", "", "Ollama model name (default: llama3.2)")
+	model := fs.String("model", "", "Ollama model name (default: "+summarize.DefaultModel+")")
 	outDir := fs.String("out-dir", "", "Output directory for summary files")
 	groupName := fs.String("group", "Summarize This", "Tab group name to summarize")
+	promptFile := fs.String("prompt-file", "", "Prompt template file (default: $TABSORDNUNG_PROMPT_FILE or built-in)")
 	fs.Parse(args)
 
 	session, err := resolveSession(resolveProfileName(*profileName))
@@ -746,24 +1650,19 @@ func runSummarize(args []string) {
 		resolvedModel = os.Getenv("TABSORDNUNG_MODEL")
 	}
 	if resolvedModel == "" {
-		resolvedModel = "llama3.2"
+		resolvedModel = summarize.DefaultModel
 	}
 
 	// Resolve Ollama host: env > default.
 	ollamaHost := os.Getenv("OLLAMA_HOST")
 	if ollamaHost == "" {
-		ollamaHost = "http://localhost:11434"
+		ollamaHost = summarize.DefaultOllamaHost
 	}
 
-	// Resolve output directory: flag > default.
+	// Resolve output directory: flag > env > default.
 	resolvedOutDir := *outDir
 	if resolvedOutDir == "" {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			os.Exit(1)
-		}
-		resolvedOutDir = filepath.Join(home, ".local", "share", "tabsordnung", "summaries")
+		resolvedOutDir = defaultSummaryDir()
 	}
 
 	cfg := summarize.Config{
@@ -772,6 +1671,7 @@ func runSummarize(args []string) {
 		OllamaHost: ollamaHost,
 		GroupName:  *groupName,
 		Session:    session,
+		Prompt:     loadPrompt(*promptFile),
 	}
 
 	if err := summarize.Run(cfg); err != nil {
@@ -796,32 +1696,54 @@ func runSignals(args []string) {
 		runSignalsComplete(subArgs)
 	case "reopen":
 		runSignalsReopen(subArgs)
+	case "unsnooze":
+		runSignalsUnsnooze(subArgs)
+	case "export":
+		runSignalsExport(subArgs)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown signals command %q. Use list, complete, or reopen.\n", subcmd)
+		fmt.Fprintf(os.Stderr, "Unknown signals command %q. Use list, complete, reopen, unsnooze, or export.\n", subcmd)
 		os.Exit(1)
 	}
 }
 
+// urgencyFlagUsage describes the --urgency flag of the signals commands.
+var urgencyFlagUsage = "Only signals of this urgency: " + strings.Join(storage.Urgencies, ", ")
+
 func runSignalsList(args []string) {
 	fs := flag.NewFlagSet("signals list", flag.ExitOnError)
-	showAll := fs.Bool("all", false, "Include completed signals")
+	dbFile := fs.String("db", "", dbFlagUsage)
+	showAll := fs.Bool("all", false, "Include completed and snoozed signals")
+	activeOnly := fs.Bool("active", false, "Only active signals (the default unless --all is given)")
 	jsonFlag := fs.Bool("json", false, "Output as JSON")
 	source := fs.String("source", "", "Filter by source (gmail, slack, matrix)")
+	urgency := fs.String("urgency", "", urgencyFlagUsage)
 	fs.Parse(args)
 
-	db, err := openDB()
+	if *showAll && *activeOnly {
+		fmt.Fprintln(os.Stderr, "--all and --active cannot be combined.")
+		os.Exit(1)
+	}
+
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	sigs, err := storage.ListSignals(db, *source, *showAll)
+	sigs, err := storage.ListSignalsByUrgency(db, *source, *urgency, *showAll, *showAll)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error listing signals: %v\n", err)
 		os.Exit(1)
 	}
 
+	if len(sigs) == 0 && !*jsonFlag {
+		if total, err := storage.CountSignals(db); err == nil && total == 0 {
+			fmt.Println("No signals captured yet. Open a Gmail, Slack or Matrix tab in the TUI and press c to capture signals.")
+			return
+		}
+	}
+
 	if *jsonFlag {
 		out, err := storage.FormatSignalsJSON(sigs)
 		if err != nil {
@@ -834,19 +1756,62 @@ func runSignalsList(args []string) {
 	}
 }
 
+func runSignalsExport(args []string) {
+	fs := flag.NewFlagSet("signals export", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	icsFlag := fs.Bool("ics", false, "Output as iCalendar VTODO entries")
+	showAll := fs.Bool("all", false, "Include completed and snoozed signals")
+	source := fs.String("source", "", "Filter by source (gmail, slack, matrix)")
+	urgency := fs.String("urgency", "", urgencyFlagUsage)
+	outFlag := fs.String("out", "", "Write to file instead of stdout")
+	fs.Parse(args)
+
+	if !*icsFlag {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals export --ics [--all] [--source X] [--urgency U] [--out file]")
+		os.Exit(1)
+	}
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	sigs, err := storage.ListSignalsByUrgency(db, *source, *urgency, *showAll, *showAll)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing signals: %v\n", err)
+		os.Exit(1)
+	}
+
+	out := storage.FormatSignalsICS(sigs)
+	if *outFlag == "" {
+		fmt.Print(out)
+		return
+	}
+	if err := os.WriteFile(*outFlag, []byte(out), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing %s: %v\n", *outFlag, err)
+		os.Exit(1)
+	}
+	fmt.Printf("Exported %d signals to %s\n", len(sigs), *outFlag)
+}
+
 func runSignalsComplete(args []string) {
-	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals complete <id>")
+	fs := flag.NewFlagSet("signals complete", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(reorderArgs(args))
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals complete <id> [--db path]")
 		os.Exit(1)
 	}
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid signal ID: %s\n", args[0])
+		fmt.Fprintf(os.Stderr, "Invalid signal ID: %s\n", fs.Arg(0))
 		os.Exit(1)
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -861,18 +1826,21 @@ func runSignalsComplete(args []string) {
 }
 
 func runSignalsReopen(args []string) {
-	if len(args) < 1 {
-		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals reopen <id>")
+	fs := flag.NewFlagSet("signals reopen", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(reorderArgs(args))
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals reopen <id> [--db path]")
 		os.Exit(1)
 	}
 
-	id, err := strconv.ParseInt(args[0], 10, 64)
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Invalid signal ID: %s\n", args[0])
+		fmt.Fprintf(os.Stderr, "Invalid signal ID: %s\n", fs.Arg(0))
 		os.Exit(1)
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -886,8 +1854,38 @@ func runSignalsReopen(args []string) {
 	fmt.Printf("Signal %d reopened.\n", id)
 }
 
+func runSignalsUnsnooze(args []string) {
+	fs := flag.NewFlagSet("signals unsnooze", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(reorderArgs(args))
+	if fs.NArg() < 1 {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung signals unsnooze <id> [--db path]")
+		os.Exit(1)
+	}
+
+	id, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid signal ID: %s\n", fs.Arg(0))
+		os.Exit(1)
+	}
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	if err := storage.UnsnoozeSignal(db, id); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Signal %d unsnoozed.\n", id)
+}
+
 func runHistory(args []string) {
 	fs := flag.NewFlagSet("history", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	dateFlag := fs.String("date", "", "Date to query (YYYY-MM-DD), default: today")
 	weekFlag := fs.Bool("week", false, "Query the current week (Mon–Sun)")
 	monthFlag := fs.Bool("month", false, "Query the current calendar month")
@@ -927,7 +1925,7 @@ func runHistory(args []string) {
 		label = from.Format("2006-01-02")
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -970,12 +1968,54 @@ func runGitHub(args []string) {
 	switch subcmd {
 	case "list":
 		runGitHubList(subArgs)
+	case "refresh":
+		runGitHubRefresh(subArgs)
 	default:
-		fmt.Fprintf(os.Stderr, "Unknown github command %q. Use list.\n", subcmd)
+		fmt.Fprintf(os.Stderr, "Unknown github command %q. Use list or refresh.\n", subcmd)
 		os.Exit(1)
 	}
 }
 
+func runGitHubRefresh(args []string) {
+	fs := flag.NewFlagSet("github refresh", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	since := fs.Duration("since", github.RefreshSince, "Skip entities refreshed more recently than this")
+	force := fs.Bool("force", false, "Refresh every entity regardless of --since")
+	fs.Parse(args)
+
+	if *since < 0 {
+		fmt.Fprintf(os.Stderr, "Invalid --since %s. Must not be negative.\n", *since)
+		os.Exit(1)
+	}
+	github.RefreshSince = *since
+
+	token := analyzer.ResolveGitHubToken()
+	if token == "" {
+		fmt.Fprintln(os.Stderr, "No GitHub token. Run gh auth login or set GITHUB_TOKEN.")
+		os.Exit(1)
+	}
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	entities, err := storage.ListGitHubEntities(db, storage.GitHubFilter{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing github entities: %v\n", err)
+		os.Exit(1)
+	}
+
+	n, err := github.RefreshEntities(context.Background(), db, entities, token, *force)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error refreshing github entities: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Println(github.RefreshStatus(n, len(entities)))
+}
+
 func runBugzilla(args []string) {
 	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
 		runBugzillaList(args)
@@ -996,11 +2036,12 @@ func runBugzilla(args []string) {
 
 func runBugzillaList(args []string) {
 	fs := flag.NewFlagSet("bugzilla list", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	jsonFlag := fs.Bool("json", false, "Output as JSON")
 	host := fs.String("host", "", "Filter by Bugzilla host (e.g. bugzilla.mozilla.org)")
 	fs.Parse(args)
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -1046,12 +2087,85 @@ func runBugzillaList(args []string) {
 	fmt.Print(storage.FormatBugzillaMarkdown(entities, events))
 }
 
+func runGitLab(args []string) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		runGitLabList(args)
+		return
+	}
+
+	subcmd := args[0]
+	subArgs := args[1:]
+
+	switch subcmd {
+	case "list":
+		runGitLabList(subArgs)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown gitlab command %q. Use list.\n", subcmd)
+		os.Exit(1)
+	}
+}
+
+func runGitLabList(args []string) {
+	fs := flag.NewFlagSet("gitlab list", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	jsonFlag := fs.Bool("json", false, "Output as JSON")
+	project := fs.String("project", "", "Filter by project path (e.g. group/project)")
+	fs.Parse(args)
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	entities, err := storage.ListGitLabEntities(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error listing gitlab entities: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *project != "" {
+		filtered := make([]storage.GitLabEntity, 0, len(entities))
+		for _, e := range entities {
+			if e.Project == *project {
+				filtered = append(filtered, e)
+			}
+		}
+		entities = filtered
+	}
+
+	if *jsonFlag {
+		out, err := storage.FormatGitLabJSON(entities)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(out)
+		return
+	}
+
+	events := make(map[int64][]storage.GitLabEntityEvent, len(entities))
+	for _, entity := range entities {
+		ev, err := storage.ListGitLabEntityEvents(db, entity.ID)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing gitlab events for entity %d: %v\n", entity.ID, err)
+			os.Exit(1)
+		}
+		events[entity.ID] = ev
+	}
+
+	fmt.Print(storage.FormatGitLabMarkdown(entities, events))
+}
+
 func runGitHubList(args []string) {
 	fs := flag.NewFlagSet("github list", flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
 	jsonFlag := fs.Bool("json", false, "Output as JSON")
+	csvFlag := fs.Bool("csv", false, "Output as CSV")
 	showAll := fs.Bool("all", false, "Include closed and merged entities")
 	state := fs.String("state", "", "Filter by state (open, closed, merged)")
-	kind := fs.String("kind", "", "Filter by kind (pull, issue)")
+	kind := fs.String("kind", "", "Filter by kind (pull, issue, discussion)")
 	repo := fs.String("repo", "", "Filter by repo (owner/repo)")
 	fs.Parse(args)
 
@@ -1059,8 +2173,8 @@ func runGitHubList(args []string) {
 		fmt.Fprintf(os.Stderr, "Invalid --state %q. Use open, closed, or merged.\n", *state)
 		os.Exit(1)
 	}
-	if *kind != "" && *kind != "pull" && *kind != "issue" {
-		fmt.Fprintf(os.Stderr, "Invalid --kind %q. Use pull or issue.\n", *kind)
+	if *kind != "" && *kind != "pull" && *kind != "issue" && *kind != "discussion" {
+		fmt.Fprintf(os.Stderr, "Invalid --kind %q. Use pull, issue, or discussion.\n", *kind)
 		os.Exit(1)
 	}
 	if *repo != "" && !strings.Contains(*repo, "/") {
@@ -1076,7 +2190,7 @@ func runGitHubList(args []string) {
 		filterState = *state
 	}
 
-	db, err := openDB()
+	db, err := openDB(*dbFile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
@@ -1102,6 +2216,15 @@ func runGitHubList(args []string) {
 		fmt.Print(out)
 		return
 	}
+	if *csvFlag {
+		out, err := storage.FormatGitHubCSV(entities)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error formatting CSV: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Print(out)
+		return
+	}
 
 	events := make(map[int64][]storage.GitHubEntityEvent, len(entities))
 	for _, entity := range entities {
@@ -1116,6 +2239,87 @@ func runGitHubList(args []string) {
 	fmt.Print(storage.FormatGitHubMarkdown(entities, events))
 }
 
+func runDB(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: tabsordnung db info|vacuum [--db path]")
+		os.Exit(1)
+	}
+	if args[0] != "info" && args[0] != "vacuum" {
+		fmt.Fprintf(os.Stderr, "Unknown db command %q. Use info or vacuum.\n", args[0])
+		os.Exit(1)
+	}
+	fs := flag.NewFlagSet("db "+args[0], flag.ExitOnError)
+	dbFile := fs.String("db", "", dbFlagUsage)
+	fs.Parse(args[1:])
+
+	db, err := openDB(*dbFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
+		os.Exit(1)
+	}
+	defer db.Close()
+
+	switch args[0] {
+	case "info":
+		info, err := storage.DBInfo(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Path:       %s\n", info.Path)
+		fmt.Printf("Size:       %s", formatBytes(info.Size))
+		if info.WALSize > 0 {
+			fmt.Printf(" (+ %s write-ahead log)", formatBytes(info.WALSize))
+		}
+		fmt.Println()
+		fmt.Printf("Free pages: %d of %d (%s reclaimable with 'db vacuum')\n",
+			info.FreePages, info.Pages, formatBytes(int64(info.FreePages)*int64(info.PageSize)))
+		fmt.Printf("Migration:  %d", info.Migration)
+		if info.Migration < info.LatestMigration {
+			fmt.Printf(" (this build knows %d)", info.LatestMigration)
+		}
+		fmt.Println()
+		fmt.Println()
+		w := 0
+		for _, t := range info.Tables {
+			w = max(w, len(t.Name))
+		}
+		for _, t := range info.Tables {
+			fmt.Printf("  %-*s  %d\n", w, t.Name, t.Rows)
+		}
+
+	case "vacuum":
+		before, err := storage.DBInfo(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if err := storage.Vacuum(db); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			fmt.Fprintln(os.Stderr, "Close the TUI and other tabsordnung commands and try again.")
+			os.Exit(1)
+		}
+		after, err := storage.DBInfo(db)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s: %s -> %s\n", after.Path,
+			formatBytes(before.Size+before.WALSize), formatBytes(after.Size+after.WALSize))
+	}
+}
+
+// formatBytes renders n as B, KB or MB.
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
 func runRules(args []string) {
 	if len(args) == 0 {
 		fmt.Fprintln(os.Stderr, "Usage: tabsordnung rules view|edit")